@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"gameintegrationapi/internal/usecase"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// runAdminCommand handles the operator commands that must not be reachable
+// over the API.
+func runAdminCommand(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "create":
+			return runAdminCreate(args[1:])
+		case "enroll":
+			return runAdminEnroll(args[1:])
+		case "wallet-token":
+			return runWalletToken(args[1:])
+		}
+	}
+	fmt.Fprintln(os.Stderr, "usage: game-integration-api admin create <username> < password-file")
+	fmt.Fprintln(os.Stderr, "       game-integration-api admin enroll <username>")
+	fmt.Fprintln(os.Stderr, "       game-integration-api admin wallet-token [--ttl 24h] <wallet_id>")
+	return 2
+}
+
+// runWalletToken prints a link token with which the owner of a wallet can
+// register, for wallet operators that do not issue tokens themselves.
+func runWalletToken(args []string) int {
+	fs := flag.NewFlagSet("admin wallet-token", flag.ContinueOnError)
+	ttl := fs.Duration("ttl", 24*time.Hour, "how long the token stays valid")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: game-integration-api admin wallet-token [--ttl 24h] <wallet_id>")
+		return 2
+	}
+	walletID, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil || walletID <= 0 {
+		fmt.Fprintln(os.Stderr, "wallet ID must be a positive number")
+		return 2
+	}
+	cfg, err := infrastructure.LoadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if cfg.Auth.WalletLinkSecret == "" {
+		fmt.Fprintln(os.Stderr, "auth.wallet_link_secret (AUTH_WALLET_LINK_SECRET) is not set")
+		return 1
+	}
+	fmt.Println(infrastructure.NewWalletLinkToken(cfg.Auth.WalletLinkSecret, strconv.FormatInt(walletID, 10), time.Now().Add(*ttl)))
+	return 0
+}
+
+// runAdminCreate handles "admin create <username>". It creates an admin
+// account with the password read from the first line of stdin, so that it
+// never appears in the process list or shell history.
+func runAdminCreate(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: game-integration-api admin create <username> < password-file")
+		return 2
+	}
+	username := args[0]
+	cfg, err := infrastructure.LoadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		fmt.Fprintln(os.Stderr, "failed to read password from stdin:", err)
+		return 1
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if len(password) < cfg.Auth.PasswordMinLength || len(password) > 72 {
+		fmt.Fprintf(os.Stderr, "password must be between %d and 72 characters\n", cfg.Auth.PasswordMinLength)
+		return 1
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	db, err := infrastructure.NewDB(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to connect to db:", err)
+		return 1
+	}
+	users := repository.NewUserRepository(db)
+	ctx := context.Background()
+	// Admins have no wallet; the prefix keeps their IDs clear of real ones.
+	walletID := "admin:" + username
+	usernameTaken, walletTaken, err := users.ExistsByUsernameOrWalletID(ctx, username, walletID)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if usernameTaken || walletTaken {
+		fmt.Fprintf(os.Stderr, "user %q already exists\n", username)
+		return 1
+	}
+	now := time.Now()
+	if err := users.Create(ctx, &domain.User{
+		WalletID:  walletID,
+		Username:  username,
+		Password:  string(hash),
+		Currency:  cfg.FX.BaseCurrency,
+		Role:      domain.RoleAdmin,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Created admin %q. Enrol two-factor authentication with \"admin enroll %s\" before using admin routes.\n", username, username)
+	return 0
+}
+
+// runAdminEnroll handles "admin enroll <username>". Admins cannot enrol
+// through the API, where their password alone would be enough; the operator
+// enrols them here and hands over the secret and recovery codes.
+func runAdminEnroll(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: game-integration-api admin enroll <username>")
+		return 2
+	}
+	cfg, err := infrastructure.LoadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	box, err := infrastructure.NewSecretBox(cfg.Auth.TOTPEncryptionKey)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	db, err := infrastructure.NewDB(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to connect to db:", err)
+		return 1
+	}
+	twoFactor := usecase.NewTwoFactorUseCase(repository.NewUserRepository(db), repository.NewRecoveryCodeRepository(db), box, cfg.Auth)
+	enrollment, codes, err := twoFactor.Provision(context.Background(), args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Println("Secret:          ", enrollment.Secret)
+	fmt.Println("Provisioning URI:", enrollment.ProvisioningURI)
+	fmt.Println("Recovery codes:  ", strings.Join(codes, " "))
+	return 0
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"gameintegrationapi/internal/infrastructure"
+	"os"
+)
+
+// runConfigCommand handles "config print [--redacted]". It prints the fully
+// resolved configuration and exits non-zero if it does not validate.
+func runConfigCommand(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "usage: game-integration-api config print [--redacted]")
+		return 2
+	}
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	redacted := fs.Bool("redacted", false, "mask secret values")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	cfg, err := infrastructure.LoadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	out, err := cfg.YAML(*redacted)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	os.Stdout.Write(out)
+	return 0
+}
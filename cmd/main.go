@@ -10,6 +10,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	_ "gameintegrationapi/docs"
 	"gameintegrationapi/internal/delivery/http"
 	"gameintegrationapi/internal/domain"
@@ -18,8 +20,12 @@ import (
 	"gameintegrationapi/internal/usecase"
 	"io/ioutil"
 	"log"
+	nethttp "net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -43,8 +49,23 @@ func runSQLMigrations(db *gorm.DB, dir string) {
 }
 
 func main() {
-	cfg := infrastructure.LoadConfig()
-	infrastructure.Logger.Println("Loaded config")
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "admin" {
+		os.Exit(runAdminCommand(os.Args[2:]))
+	}
+
+	cfg, err := infrastructure.LoadConfig()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	infrastructure.Logger.Printf("Loaded config (profile %s)", cfg.Profile)
+
+	shutdownTracer, err := infrastructure.InitTracer(cfg)
+	if err != nil {
+		log.Fatalf("failed to init tracer: %v", err)
+	}
 
 	db, err := infrastructure.NewDB(cfg)
 	if err != nil {
@@ -55,34 +76,224 @@ func main() {
 	if err := db.AutoMigrate(
 		&domain.User{},
 		&domain.Transaction{},
+		&domain.LoginAttempt{},
+		&domain.LoginLockout{},
+		&domain.PasswordResetToken{},
+		&domain.RecoveryCode{},
+		&domain.PlayerLimit{},
+		&domain.PlayerDeposit{},
+		&domain.Exclusion{},
+		&domain.GameSession{},
+		&domain.BetRule{},
+		&domain.BonusCampaign{},
+		&domain.PlayerBonus{},
+		&domain.FreeRoundGrant{},
+		&domain.JackpotPool{},
+		&domain.JackpotGame{},
+		&domain.PlayerBalance{},
+		&domain.ReconciliationRun{},
+		&domain.ReconciliationMismatch{},
+		&domain.DailyAggregate{},
 	); err != nil {
 		log.Fatalf("failed to run migrations: %v", err)
 	}
 
-	infrastructure.SeedTestUsers(db)
+	if cfg.Profile == infrastructure.ProfileDev || cfg.Profile == infrastructure.ProfileTest {
+		infrastructure.SeedTestUsers(db)
+	}
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	txRepo := repository.NewTransactionRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(db)
+	playerLimitRepo := repository.NewPlayerLimitRepository(db)
+	exclusionRepo := repository.NewExclusionRepository(db)
+	gameSessionRepo := repository.NewGameSessionRepository(db)
+	betRuleRepo := repository.NewBetRuleRepository(db)
+	bonusRepo := repository.NewBonusRepository(db)
+	freeRoundRepo := repository.NewFreeRoundRepository(db)
+	jackpotRepo := repository.NewJackpotRepository(db)
+	balanceRepo := repository.NewBalanceRepository(db)
+	reconciliationRepo := repository.NewReconciliationRepository(db)
+	revenueRepo := repository.NewRevenueRepository(db)
 
 	// Initialize use cases
-	walletClient := infrastructure.NewWalletClient(cfg.WalletURL, cfg.WalletToken)
-	log.Printf("WalletClient initialized with URL: %s", cfg.WalletURL)
+	walletClient := infrastructure.NewWalletClient(cfg.Wallet)
+	log.Printf("WalletClient initialized with URL: %s", cfg.Wallet.URL)
+
+	notifier, err := infrastructure.NewNotifier(cfg.Notifier)
+	if err != nil {
+		log.Fatalf("failed to init notifier: %v", err)
+	}
 
-	authUseCase := usecase.NewAuthUseCase(userRepo)
-	playerUseCase := usecase.NewPlayerUseCase(userRepo, walletClient)
-	walletUseCase := usecase.NewWalletUseCase(userRepo, txRepo, db, walletClient)
+	totpBox, err := infrastructure.NewSecretBox(cfg.Auth.TOTPEncryptionKey)
+	if err != nil {
+		log.Fatalf("failed to init TOTP encryption: %v", err)
+	}
+
+	rates, err := infrastructure.NewStaticRateProvider(cfg.FX)
+	if err != nil {
+		log.Fatalf("failed to load FX rates: %v", err)
+	}
+
+	jwtKeys, err := infrastructure.LoadJWTKeys(cfg.Auth)
+	if err != nil {
+		log.Fatalf("failed to load JWT keys: %v", err)
+	}
+
+	authUseCase := usecase.NewAuthUseCase(userRepo, loginAttemptRepo, recoveryCodeRepo, totpBox, jwtKeys, cfg.Auth)
+	twoFactorUseCase := usecase.NewTwoFactorUseCase(userRepo, recoveryCodeRepo, totpBox, cfg.Auth)
+	accountUseCase := usecase.NewAccountUseCase(userRepo, passwordResetRepo, walletClient, notifier, cfg.Auth)
+	playerUseCase := usecase.NewPlayerUseCase(userRepo, balanceRepo, walletClient)
+	tracker := usecase.NewOperationTracker()
+	responsibleGamingUseCase := usecase.NewResponsibleGamingUseCase(playerLimitRepo, exclusionRepo, gameSessionRepo, userRepo, txRepo, rates, cfg.ResponsibleGaming)
+	betRuleUseCase := usecase.NewBetRuleUseCase(betRuleRepo)
+	bonusUseCase := usecase.NewBonusUseCase(bonusRepo, userRepo, txRepo, db, walletClient, rates, cfg.Bonus)
+	freeRoundUseCase := usecase.NewFreeRoundUseCase(freeRoundRepo, userRepo, bonusUseCase)
+	jackpotUseCase := usecase.NewJackpotUseCase(jackpotRepo)
+	currencyUseCase := usecase.NewCurrencyUseCase(balanceRepo, userRepo, txRepo, rates)
+	reconciliationUseCase := usecase.NewReconciliationUseCase(reconciliationRepo, userRepo, txRepo, walletClient)
+	revenueUseCase := usecase.NewRevenueUseCase(revenueRepo)
+	statementUseCase := usecase.NewStatementUseCase(userRepo, balanceRepo, txRepo)
+	platformLogUseCase := usecase.NewPlatformLogUseCase(txRepo, cfg.PlatformLog)
+	roundUseCase := usecase.NewRoundUseCase(txRepo, infrastructure.NewRoundHistoryClient(cfg.RoundHistory))
+	walletUseCase := usecase.NewWalletUseCase(userRepo, txRepo, db, walletClient, tracker, responsibleGamingUseCase, betRuleUseCase, bonusUseCase, freeRoundUseCase, jackpotUseCase, currencyUseCase, cfg.DB.MaxPlayerLocks)
+
+	healthChecker := infrastructure.NewHealthChecker(db, walletClient, cfg.Wallet.ProbeID, "migrations")
+
+	rateLimiter, err := infrastructure.NewRateLimiterFromConfig(cfg.RateLimit, db)
+	if err != nil {
+		log.Fatalf("failed to init rate limiter: %v", err)
+	}
 
 	// Initialize handlers
-	handlers := http.NewHandlers(authUseCase, playerUseCase, walletUseCase)
+	handlers := http.NewHandlers(authUseCase, accountUseCase, twoFactorUseCase, playerUseCase, walletUseCase, responsibleGamingUseCase, betRuleUseCase, bonusUseCase, freeRoundUseCase, jackpotUseCase, currencyUseCase, reconciliationUseCase, revenueUseCase, statementUseCase, roundUseCase, platformLogUseCase, healthChecker, rateLimiter, jwtKeys, cfg.Providers.KeyMap(), cfg.ResponsibleGaming.CashierKey)
 
 	// Setup router
-	r := http.NewRouter(handlers)
+	r, err := http.NewRouter(handlers, cfg.Server.Proxies())
+	if err != nil {
+		log.Fatalf("failed to set up router: %v", err)
+	}
+
+	srv := &nethttp.Server{
+		Addr:         ":" + cfg.Server.Port,
+		Handler:      r,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+	go func() {
+		infrastructure.Logger.Printf("Server starting on :%s", cfg.Server.Port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
+			log.Fatalf("server error: %v", err)
+		}
+	}()
+
+	// Forfeit lapsed bonuses even for players who do not come back.
+	jobs, stopJobs := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(cfg.Bonus.ExpiryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-jobs.Done():
+				return
+			case <-ticker.C:
+				if n, err := bonusUseCase.ExpireDue(jobs); err != nil {
+					infrastructure.Logger.Printf("Bonus expiry failed: %v", err)
+				} else if n > 0 {
+					infrastructure.Logger.Printf("Expired %d bonuses", n)
+				}
+			}
+		}
+	}()
+
+	// Compare the previous day with the wallet every night.
+	if cfg.Reconciliation.RunAt != "" {
+		go func() {
+			for {
+				next := cfg.Reconciliation.NextRun(time.Now())
+				select {
+				case <-jobs.Done():
+					return
+				case <-time.After(time.Until(next)):
+					_, err := reconciliationUseCase.Run(jobs, next.AddDate(0, 0, -1))
+					switch {
+					case errors.Is(err, usecase.ErrReconciliationRunning):
+						// Every replica schedules it; one runs it.
+						infrastructure.Logger.Printf("Reconciliation skipped: another instance is running it")
+					case err != nil:
+						infrastructure.Logger.Printf("Reconciliation failed: %v", err)
+					}
+				}
+			}
+		}()
+	}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// Drop stored provider and wallet payloads past their retention.
+	if cfg.PlatformLog.Retention > 0 {
+		go func() {
+			ticker := time.NewTicker(cfg.PlatformLog.PurgeInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-jobs.Done():
+					return
+				case <-ticker.C:
+					if n, err := platformLogUseCase.Purge(jobs); err != nil {
+						infrastructure.Logger.Printf("Platform log purge failed: %v", err)
+					} else if n > 0 {
+						infrastructure.Logger.Printf("Purged platform log of %d transactions", n)
+					}
+				}
+			}
+		}()
+	}
+
+	// Aggregate the previous day's revenue every night.
+	if cfg.Reporting.AggregateAt != "" {
+		go func() {
+			for {
+				next := cfg.Reporting.NextRun(time.Now())
+				select {
+				case <-jobs.Done():
+					return
+				case <-time.After(time.Until(next)):
+					if _, err := revenueUseCase.Aggregate(jobs, next.AddDate(0, 0, -1)); err != nil {
+						infrastructure.Logger.Printf("Revenue aggregation failed: %v", err)
+					}
+				}
+			}
+		}()
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-quit
+	infrastructure.Logger.Printf("Received %s, shutting down", sig)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	// Stop accepting connections and new wallet operations, then let the
+	// in-flight ones reach their DB commit before the pool goes away.
+	stopJobs()
+	tracker.Close()
+	if err := srv.Shutdown(ctx); err != nil {
+		infrastructure.Logger.Printf("HTTP server shutdown: %v", err)
+	}
+	if err := tracker.Wait(ctx); err != nil {
+		infrastructure.Logger.Printf("Timed out waiting for in-flight wallet operations: %v", err)
+	}
+	if err := shutdownTracer(ctx); err != nil {
+		infrastructure.Logger.Printf("Failed to flush traces: %v", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			infrastructure.Logger.Printf("Failed to close DB pool: %v", err)
+		}
 	}
-	infrastructure.Logger.Printf("Server starting on :%s", port)
-	r.Run(":" + port)
+	infrastructure.Logger.Println("Shutdown complete")
 }
@@ -15,60 +15,72 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
-            "post": {
-                "description": "Authenticate user and return JWT token and username",
-                "consumes": [
-                    "application/json"
-                ],
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Public keys for verifying player tokens. Tokens name their key in the kid header; retired keys stay listed until their tokens expire.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Auth"
                 ],
-                "summary": "Authenticate user",
-                "parameters": [
-                    {
-                        "description": "User credentials",
-                        "name": "credentials",
-                        "in": "body",
-                        "required": true,
+                "summary": "JSON Web Key Set",
+                "responses": {
+                    "200": {
+                        "description": "Key set",
                         "schema": {
-                            "$ref": "#/definitions/http.loginRequest"
+                            "$ref": "#/definitions/infrastructure.JWKSet"
                         }
                     }
+                }
+            }
+        },
+        "/admin/bet-rules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "List the configured rules. Empty game, provider or currency matches any value; the most specific matching rule applies.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List stake and payout rules",
                 "responses": {
                     "200": {
-                        "description": "Login response",
+                        "description": "Rules",
                         "schema": {
-                            "$ref": "#/definitions/http.LoginResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.BetRuleResponse"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Invalid request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/http.LoginErrorResponse"
+                            "$ref": "#/definitions/http.BetErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Invalid credentials",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/http.LoginErrorResponse"
+                            "$ref": "#/definitions/http.BetErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/bet/cancel": {
-            "post": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Cancel a bet transaction",
+                "description": "Set the stake range and maximum win per round for a game, provider and currency combination. A limit of 0 is not enforced.",
                 "consumes": [
                     "application/json"
                 ],
@@ -76,25 +88,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Bet"
+                    "Admin"
                 ],
-                "summary": "Cancel a transaction",
+                "summary": "Create or replace a stake and payout rule",
                 "parameters": [
                     {
-                        "description": "Cancel details",
+                        "description": "Rule",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/http.cancelRequest"
+                            "$ref": "#/definitions/http.betRuleRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Bet response",
+                        "description": "Saved rule",
                         "schema": {
-                            "$ref": "#/definitions/http.BetResponse"
+                            "$ref": "#/definitions/http.BetRuleResponse"
                         }
                     },
                     "400": {
@@ -108,69 +120,109 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/http.BetErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/bet/deposit": {
-            "post": {
+        "/admin/bet-rules/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Settle a bet by depositing funds",
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Bet"
+                    "Admin"
                 ],
-                "summary": "Settle a bet (deposit)",
+                "summary": "Delete a stake and payout rule",
                 "parameters": [
                     {
-                        "description": "Deposit details",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
+                        "type": "integer",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Deleted"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/http.depositRequest"
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
                         }
                     }
+                }
+            }
+        },
+        "/admin/bonus-campaigns": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
                 ],
+                "summary": "List bonus campaigns",
                 "responses": {
                     "200": {
-                        "description": "Bet response",
+                        "description": "Campaigns",
                         "schema": {
-                            "$ref": "#/definitions/http.BetResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.BonusCampaignResponse"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Invalid request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/http.BetErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/http.BetErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/bet/withdraw": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Place a bet by withdrawing funds",
+                "description": "A granted bonus becomes real money once the player has staked wagering_multiplier times the bonus amount within valid_days; otherwise it is forfeited.",
                 "consumes": [
                     "application/json"
                 ],
@@ -178,25 +230,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Bet"
+                    "Admin"
                 ],
-                "summary": "Place a bet (withdraw)",
+                "summary": "Create a bonus campaign",
                 "parameters": [
                     {
-                        "description": "Withdraw details",
+                        "description": "Campaign",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/http.withdrawRequest"
+                            "$ref": "#/definitions/http.createCampaignRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Bet response",
+                    "201": {
+                        "description": "Created campaign",
                         "schema": {
-                            "$ref": "#/definitions/http.BetResponse"
+                            "$ref": "#/definitions/http.BonusCampaignResponse"
                         }
                     },
                     "400": {
@@ -210,153 +262,3942 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/http.BetErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/metrics": {
-            "get": {
-                "description": "Get application metrics in Prometheus format.",
+        "/admin/bonuses/{id}/forfeit": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancel an active bonus, for example for bonus abuse. The remaining bonus balance is forfeited.",
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "Metrics"
+                    "Admin"
+                ],
+                "summary": "Cancel a player's bonus",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Bonus ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get application metrics",
                 "responses": {
                     "200": {
-                        "description": "Prometheus metrics",
+                        "description": "Forfeited bonus",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/http.BonusResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Bonus is not active",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/profile": {
+        "/admin/exclusions": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get the authenticated player's profile",
+                "description": "Report of players currently self-excluded or on a time-out",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Player"
+                    "Admin"
                 ],
-                "summary": "Get player profile",
+                "summary": "List excluded accounts",
                 "responses": {
                     "200": {
-                        "description": "Profile response",
+                        "description": "Active exclusions",
                         "schema": {
-                            "$ref": "#/definitions/http.ProfileResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.ExclusionResponse"
+                            }
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "http.BetErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "insufficient funds"
-                }
-            }
-        },
-        "http.BetResponse": {
-            "type": "object",
-            "properties": {
-                "new_balance": {
-                    "type": "number",
-                    "example": 90
-                },
-                "old_balance": {
-                    "type": "number",
-                    "example": 100
-                },
-                "provider_transaction_id": {
-                    "type": "string",
-                    "example": "tx123"
-                },
-                "status": {
-                    "type": "string",
-                    "example": "COMPLETED"
-                },
-                "transaction_id": {
-                    "type": "integer",
-                    "example": 123
-                }
-            }
         },
-        "http.LoginErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "invalid credentials"
+        "/admin/free-rounds/{id}/revoke": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stop the remaining rounds of a grant. Rounds already played still settle.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Revoke free rounds",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Grant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Revoked",
+                        "schema": {
+                            "$ref": "#/definitions/http.FreeRoundGrantResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already revoked",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "http.LoginResponse": {
-            "type": "object",
-            "properties": {
-                "token": {
-                    "type": "string",
-                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9"
-                },
-                "username": {
-                    "type": "string",
-                    "example": "testuser1"
+        "/admin/held-wins": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Wins that took a round over its maximum payout. They are not credited until approved.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List wins held for review",
+                "responses": {
+                    "200": {
+                        "description": "Held wins",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.HeldWinResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/held-wins/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Credit the held win to the player's wallet",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Approve a held win",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Credited win",
+                        "schema": {
+                            "$ref": "#/definitions/http.HeldWinResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already reviewed",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/held-wins/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Close the held win without crediting it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Reject a held win",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rejected win",
+                        "schema": {
+                            "$ref": "#/definitions/http.HeldWinResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already reviewed",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jackpot-games": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stakes on the game contribute to every pool of the group in the player's currency. A game belongs to one group; assigning it again moves it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Put a game in a jackpot group",
+                "parameters": [
+                    {
+                        "description": "Game and group",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.assignJackpotGameRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Assigned"
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jackpots": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a progressive pool for a game group in one currency. Every stake on the group's games adds contribution_pct percent to it; a win pays the pool and resets it to seed_amount.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Create a jackpot pool",
+                "parameters": [
+                    {
+                        "description": "Jackpot pool",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.createJackpotRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/http.JackpotResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/lockouts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List usernames and client IPs currently locked out after failed logins",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List active login lockouts",
+                "responses": {
+                    "200": {
+                        "description": "Active lockouts",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.LockoutResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/lockouts/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a lockout and reset its failed-attempt counter",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Clear a login lockout",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Lockout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Cleared"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reconciliations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The last 90 days compared with the wallet, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List reconciliation runs",
+                "responses": {
+                    "200": {
+                        "description": "Runs",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.ReconciliationRunResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reconciliations/{day}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mismatches between the local ledger and the wallet for a UTC day: missing_locally, missing_remotely, amount_differs, or unchecked when the user's wallet ledger could not be read. Amounts are signed; withdrawals are negative.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get a reconciliation report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Day (YYYY-MM-DD)",
+                        "name": "day",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default) or csv",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report",
+                        "schema": {
+                            "$ref": "#/definitions/http.ReconciliationReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid day or format",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Day not reconciled",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reconciliations/{day}/run": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Compare a UTC day with the wallet again in the background, replacing its report. Only one reconciliation runs at a time.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Reconcile a day",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Day (YYYY-MM-DD)",
+                        "name": "day",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Started",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid day",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A reconciliation is already running",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reports/currencies": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stakes and wins per balance currency in [from, to), as booked and converted to the base currency at the rate stored on each transaction",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Activity per currency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End, exclusive (RFC 3339 or YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report",
+                        "schema": {
+                            "$ref": "#/definitions/http.CurrencyReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid period",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reports/revenue": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Turnover, GGR and NGR from the daily aggregates of UTC days in [from, to). Currency is always grouped by. Days are aggregated nightly; aggregate a day again to pick up late changes such as approved held wins.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Revenue report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End day, exclusive (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated: day, game, provider, currency, player (default day)",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default) or csv",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report",
+                        "schema": {
+                            "$ref": "#/definitions/http.RevenueReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid period, grouping or format",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reports/revenue/{day}/aggregate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rebuild the daily aggregates of a UTC day from its transactions, replacing the previous ones",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Aggregate a day's revenue",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Day (YYYY-MM-DD)",
+                        "name": "day",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Aggregated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid day",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rounds/{id}/details": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Any player's ledger entries of a round with the provider's description of it, for dispute handling",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Round details for support",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Round ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider, when several used the round ID",
+                        "name": "provider_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Player, when several played the round ID",
+                        "name": "user_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Round",
+                        "schema": {
+                            "$ref": "#/definitions/http.RoundDetailsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ambiguous round",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Round not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/bonuses": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Credit a campaign's bonus to a player. A player can hold one active bonus at a time.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Grant a bonus",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Campaign",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.grantBonusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Granted bonus",
+                        "schema": {
+                            "$ref": "#/definitions/http.BonusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/exclusion": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Start a time-out or self-exclusion on behalf of a player, for example at their request through support",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Exclude a player",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Exclusion",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.excludeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Exclusion started",
+                        "schema": {
+                            "$ref": "#/definitions/http.ExclusionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/free-rounds": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List a player's free rounds",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Free-round grants",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.FreeRoundGrantResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Give a player zero-cost rounds on a game. The provider plays them with free_round set on withdraw; only wins are deposited, to the real balance or to the player's active bonus.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Grant free rounds",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Free rounds",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.grantFreeRoundsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Granted",
+                        "schema": {
+                            "$ref": "#/definitions/http.FreeRoundGrantResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/transactions/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "A player's statement of one balance in [from, to), as sent to them by support. Streamed as CSV or PDF.",
+                "produces": [
+                    "text/csv",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Export a player's statement",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End, exclusive (RFC 3339 or YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv (default) or pdf",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Balance currency (default primary)",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Statement",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid period, format or currency",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Enable two-factor authentication with a code from the authenticator app. Returns single-use recovery codes, shown only once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Confirm two-factor enrolment",
+                "parameters": [
+                    {
+                        "description": "TOTP code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.twoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Recovery codes",
+                        "schema": {
+                            "$ref": "#/definitions/http.TwoFactorConfirmResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or code",
+                        "schema": {
+                            "$ref": "#/definitions/http.AccountErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.AccountErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/http.AccountErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Turn off two-factor authentication. Requires the password and a TOTP or recovery code. Not allowed for admin accounts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Disable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "Password and code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.twoFactorDisableRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Disabled"
+                    },
+                    "400": {
+                        "description": "Invalid request or code",
+                        "schema": {
+                            "$ref": "#/definitions/http.AccountErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized or wrong password",
+                        "schema": {
+                            "$ref": "#/definitions/http.AccountErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Required for this role",
+                        "schema": {
+                            "$ref": "#/definitions/http.AccountErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Generate a TOTP secret for the authenticated player after re-checking their password. It takes effect after /auth/2fa/confirm. Admins are enrolled by an operator instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Start two-factor enrolment",
+                "parameters": [
+                    {
+                        "description": "Current password",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.twoFactorEnrollRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Secret and provisioning URI",
+                        "schema": {
+                            "$ref": "#/definitions/http.TwoFactorEnrollResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.AccountErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized or wrong password",
+                        "schema": {
+                            "$ref": "#/definitions/http.AccountErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Role is enrolled by an operator",
+                        "schema": {
+                            "$ref": "#/definitions/http.AccountErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/http.AccountErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/change-password": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the authenticated user's password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Change password",
+                "parameters": [
+                    {
+                        "description": "Current and new password",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.changePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Password changed"
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.AccountErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized or wrong current password",
+                        "schema": {
+                            "$ref": "#/definitions/http.AccountErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Authenticate user and return JWT token and username. Users with two-factor authentication get a challenge token to complete at /auth/login/2fa instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Authenticate user",
+                "parameters": [
+                    {
+                        "description": "User credentials",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.loginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login response",
+                        "schema": {
+                            "$ref": "#/definitions/http.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.LoginErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/http.LoginErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts",
+                        "schema": {
+                            "$ref": "#/definitions/http.LoginErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login/2fa": {
+            "post": {
+                "description": "Exchange the challenge token from /auth/login and a TOTP or recovery code for a JWT token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Complete a two-factor login",
+                "parameters": [
+                    {
+                        "description": "Challenge token and code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.loginTwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login response",
+                        "schema": {
+                            "$ref": "#/definitions/http.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.LoginErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid challenge or code",
+                        "schema": {
+                            "$ref": "#/definitions/http.LoginErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts",
+                        "schema": {
+                            "$ref": "#/definitions/http.LoginErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password-reset/confirm": {
+            "post": {
+                "description": "Set a new password using a reset token. Each token works once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Reset password with a token",
+                "parameters": [
+                    {
+                        "description": "Token and new password",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.passwordResetConfirmRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Password reset"
+                    },
+                    "400": {
+                        "description": "Invalid request or token",
+                        "schema": {
+                            "$ref": "#/definitions/http.AccountErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password-reset/request": {
+            "post": {
+                "description": "Send a single-use, time-limited reset token to the user. Always returns 202 so usernames cannot be probed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Request a password reset",
+                "parameters": [
+                    {
+                        "description": "Username",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.passwordResetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Reset requested"
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.AccountErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Create a player account linked to an existing wallet account. wallet_token is the link token the wallet operator issued for the wallet.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Register a player",
+                "parameters": [
+                    {
+                        "description": "Registration details",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.registerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Registered",
+                        "schema": {
+                            "$ref": "#/definitions/http.RegisterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.AccountErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Registration is not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/http.AccountErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Username or wallet already in use",
+                        "schema": {
+                            "$ref": "#/definitions/http.AccountErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/balances": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add an empty balance in another currency. Stakes in that currency are then booked on it without conversion.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Player"
+                ],
+                "summary": "Open a currency balance",
+                "parameters": [
+                    {
+                        "description": "Currency",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.openBalanceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Opened",
+                        "schema": {
+                            "$ref": "#/definitions/http.BalanceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or unsupported currency",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Balance already held",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/bet/batch": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Apply up to 100 withdraw, deposit and cancel operations for the player, in order. With atomic, either every item is applied or none is, and the wallet is called once per operation type and currency; free rounds and jackpot payouts cannot be included. Otherwise each item is applied on its own and reported with its own status. In both modes an item whose provider transaction was already applied is not applied again and is reported as replayed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bet"
+                ],
+                "summary": "Apply several bet operations",
+                "parameters": [
+                    {
+                        "description": "Operations",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.batchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Result of each item",
+                        "schema": {
+                            "$ref": "#/definitions/http.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.BatchErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Responsible-gaming limit reached or player excluded",
+                        "schema": {
+                            "$ref": "#/definitions/http.BatchErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Stake outside the game's limits, currency not supported or insufficient funds",
+                        "schema": {
+                            "$ref": "#/definitions/http.BatchErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Wallet service not available",
+                        "schema": {
+                            "$ref": "#/definitions/http.BatchErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/bet/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancel all or part of a transaction. A cancelled stake is refunded; a cancelled win is taken back (type CLAWBACK). The amounts cancelled can never exceed the amount of the transaction. A cancel of a transaction that has not arrived succeeds and is recorded; if the stake arrives later it is ignored and answered with the cancel (status CANCELLED) without debiting the player. Repeating a cancel returns the recorded one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bet"
+                ],
+                "summary": "Cancel a transaction",
+                "parameters": [
+                    {
+                        "description": "Cancel details",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.cancelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Bet response",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already cancelled under another cancel ID",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Amount exceeds what is left to cancel, or not enough funds to take a win back",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/bet/deposit": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Settle a bet by depositing funds. Wins on free rounds go to the real or bonus balance as set on the grant. A win that takes the round over the game's maximum payout is not credited; it is recorded with status HELD for manual review. With jackpot_id set, the pool's current value is paid instead of amount and the pool resets to its seed; provider_withdrawn_transaction_id must then name the player's stake on one of the pool's games in the pool's currency. A repeated jackpot payout returns the recorded one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bet"
+                ],
+                "summary": "Settle a bet (deposit)",
+                "parameters": [
+                    {
+                        "description": "Deposit details",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.depositRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Bet response",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Jackpot not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Provider transaction ID already used for another transaction",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Currency not supported, or no stake qualifying for the jackpot",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/bet/withdraw": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Place a bet by withdrawing funds. The stake must be positive and within the game's configured stake range. With free_round, one round of the player's free-round grant for game_id is used instead and nothing is debited. The stake is taken from the player's balance in currency if they hold one; otherwise it is converted to their primary currency at the current rate. A stake the provider cancelled before it arrived is not debited; the cancel is returned instead, with status CANCELLED.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bet"
+                ],
+                "summary": "Place a bet (withdraw)",
+                "parameters": [
+                    {
+                        "description": "Withdraw details",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.withdrawRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Bet response",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Responsible-gaming limit reached or player excluded",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "No free rounds left for the game and bet value",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Stake outside the game's limits, currency not supported, or not enough funds",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/bonuses": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the player's bonuses with wagering progress. Stakes are paid from the real balance first unless configured otherwise, and wins on bonus-funded stakes are credited to the bonus balance in proportion.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Player"
+                ],
+                "summary": "List bonuses",
+                "responses": {
+                    "200": {
+                        "description": "Bonuses",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.BonusResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/bonuses/{id}/forfeit": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancel the player's active bonus. The remaining bonus balance is forfeited.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Player"
+                ],
+                "summary": "Cancel a bonus",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Bonus ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Forfeited bonus",
+                        "schema": {
+                            "$ref": "#/definitions/http.BonusResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Bonus is not active",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cashier/deposits": {
+            "post": {
+                "description": "Called by the cashier before the wallet credits a deposit. Approves and records it, or refuses it with RG_LIMIT_EXCEEDED when it would break the player's deposit limit, or PLAYER_EXCLUDED. Repeating a reference approves it again without counting it twice.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cashier"
+                ],
+                "summary": "Authorize a player deposit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cashier key",
+                        "name": "X-Cashier-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Deposit",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.authorizeDepositRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Approved",
+                        "schema": {
+                            "$ref": "#/definitions/http.DepositAuthorizationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid cashier key",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Limit exceeded or player excluded",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown wallet",
+                        "schema": {
+                            "$ref": "#/definitions/http.BetErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exclusion": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Show whether the player is self-excluded or on a time-out",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Player"
+                ],
+                "summary": "Get exclusion status",
+                "responses": {
+                    "200": {
+                        "description": "Exclusion status",
+                        "schema": {
+                            "$ref": "#/definitions/http.ExclusionStatusResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Block all betting for 1-42 days (timeout) or at least 180 days or permanently (self_exclusion). It cannot be shortened or lifted; open bets still settle.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Player"
+                ],
+                "summary": "Self-exclude or take a time-out",
+                "parameters": [
+                    {
+                        "description": "Exclusion",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.excludeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Exclusion started",
+                        "schema": {
+                            "$ref": "#/definitions/http.ExclusionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/free-rounds": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the player's free-round grants",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Player"
+                ],
+                "summary": "List free rounds",
+                "responses": {
+                    "200": {
+                        "description": "Free-round grants",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.FreeRoundGrantResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Report that the process is running. Does not check dependencies.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "Alive",
+                        "schema": {
+                            "$ref": "#/definitions/http.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jackpots": {
+            "get": {
+                "description": "Live values of the progressive jackpot pools",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jackpot"
+                ],
+                "summary": "List jackpots",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Game group",
+                        "name": "game_group",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Jackpot pools",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.JackpotResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/limits": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the player's loss, wager and deposit limits with usage over each rolling window (24 hours, 7 days, 30 days)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Player"
+                ],
+                "summary": "Get responsible-gaming limits",
+                "responses": {
+                    "200": {
+                        "description": "Limits",
+                        "schema": {
+                            "$ref": "#/definitions/http.LimitsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set loss, wager or deposit limits. Lower limits apply immediately; higher limits and removals (amount 0) apply after the cooling-off period.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Player"
+                ],
+                "summary": "Set responsible-gaming limits",
+                "parameters": [
+                    {
+                        "description": "Limits to set",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.setLimitsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Limits",
+                        "schema": {
+                            "$ref": "#/definitions/http.LimitsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics": {
+            "get": {
+                "description": "Get application metrics in Prometheus format.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Metrics"
+                ],
+                "summary": "Get application metrics",
+                "responses": {
+                    "200": {
+                        "description": "Prometheus metrics",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the authenticated player's profile",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Player"
+                ],
+                "summary": "Get player profile",
+                "responses": {
+                    "200": {
+                        "description": "Profile response",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Check the database and wallet service. Results are cached for a few seconds.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "Ready",
+                        "schema": {
+                            "$ref": "#/definitions/infrastructure.ReadinessReport"
+                        }
+                    },
+                    "503": {
+                        "description": "Not ready",
+                        "schema": {
+                            "$ref": "#/definitions/infrastructure.ReadinessReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/rounds/{id}/details": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The player's ledger entries of a round with the provider's description of it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Player"
+                ],
+                "summary": "Round details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Round ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider, when several used the round ID",
+                        "name": "provider_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Round",
+                        "schema": {
+                            "$ref": "#/definitions/http.RoundDetailsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ambiguous round",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Round not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/status": {
+            "get": {
+                "description": "Report build version, migration version, dependency latencies and wallet circuit-breaker state.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Detailed service status",
+                "responses": {
+                    "200": {
+                        "description": "Status",
+                        "schema": {
+                            "$ref": "#/definitions/infrastructure.StatusReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The player's statement of one balance in [from, to): opening balance, every transaction with its signed amount and the balance after it, and closing balance. Streamed as CSV or PDF.",
+                "produces": [
+                    "text/csv",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "Player"
+                ],
+                "summary": "Export a statement",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End, exclusive (RFC 3339 or YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv (default) or pdf",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Balance currency (default primary)",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Statement",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid period, format or currency",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProfileErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "http.AccountErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "username is already taken"
+                }
+            }
+        },
+        "http.BalanceResponse": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number",
+                    "example": 50
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                }
+            }
+        },
+        "http.BatchErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "RG_LIMIT_EXCEEDED"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "item 1: insufficient funds"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "http.BatchItemResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "status": {
+                    "description": "Status is ok, replayed (already applied by an earlier request) or\nfailed.",
+                    "type": "string",
+                    "example": "ok"
+                },
+                "transaction": {
+                    "$ref": "#/definitions/http.BetResponse"
+                }
+            }
+        },
+        "http.BatchResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.BatchItemResponse"
+                    }
+                }
+            }
+        },
+        "http.BetErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is set for errors the provider should show to the player.",
+                    "type": "string",
+                    "example": "RG_LIMIT_EXCEEDED"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "insufficient funds"
+                }
+            }
+        },
+        "http.BetResponse": {
+            "type": "object",
+            "properties": {
+                "new_balance": {
+                    "type": "number",
+                    "example": 90
+                },
+                "old_balance": {
+                    "type": "number",
+                    "example": 100
+                },
+                "provider_transaction_id": {
+                    "type": "string",
+                    "example": "tx123"
+                },
+                "reality_check": {
+                    "description": "Only on withdraw, when a reminder is due.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/http.RealityCheckResponse"
+                        }
+                    ]
+                },
+                "status": {
+                    "type": "string",
+                    "example": "COMPLETED"
+                },
+                "transaction_id": {
+                    "type": "integer",
+                    "example": 123
+                }
+            }
+        },
+        "http.BetRuleResponse": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "game_id": {
+                    "type": "string",
+                    "example": "book-of-ra"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "max_stake": {
+                    "type": "number",
+                    "example": 100
+                },
+                "max_win_per_round": {
+                    "type": "number",
+                    "example": 50000
+                },
+                "min_stake": {
+                    "type": "number",
+                    "example": 0.1
+                },
+                "provider_id": {
+                    "type": "string",
+                    "example": "novomatic"
+                }
+            }
+        },
+        "http.BonusCampaignResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "amount": {
+                    "type": "number",
+                    "example": 50
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Welcome bonus"
+                },
+                "valid_days": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "wagering_multiplier": {
+                    "type": "number",
+                    "example": 30
+                }
+            }
+        },
+        "http.BonusResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 50
+                },
+                "campaign_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "campaign_name": {
+                    "type": "string",
+                    "example": "Welcome bonus"
+                },
+                "closed_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "forfeited_amount": {
+                    "type": "number",
+                    "example": 0
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "released_amount": {
+                    "type": "number",
+                    "example": 0
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "wagered": {
+                    "type": "number",
+                    "example": 420
+                },
+                "wagering_required": {
+                    "type": "number",
+                    "example": 1500
+                }
+            }
+        },
+        "http.CurrencyReportResponse": {
+            "type": "object",
+            "properties": {
+                "base_currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "totals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.CurrencyTotalResponse"
+                    }
+                }
+            }
+        },
+        "http.CurrencyTotalResponse": {
+            "type": "object",
+            "properties": {
+                "base_currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "base_wagered": {
+                    "type": "number",
+                    "example": 1000
+                },
+                "base_won": {
+                    "type": "number",
+                    "example": 900
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "transactions": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "unconverted": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "wagered": {
+                    "type": "number",
+                    "example": 920
+                },
+                "won": {
+                    "type": "number",
+                    "example": 828
+                }
+            }
+        },
+        "http.DepositAuthorizationResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 50
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "dep-8c1f2a"
+                }
+            }
+        },
+        "http.ExclusionResponse": {
+            "type": "object",
+            "properties": {
+                "created_by": {
+                    "type": "string",
+                    "example": "testuser1"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "permanent": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "player request"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "timeout"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "username": {
+                    "type": "string",
+                    "example": "testuser1"
+                }
+            }
+        },
+        "http.ExclusionStatusResponse": {
+            "type": "object",
+            "properties": {
+                "excluded": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "exclusion": {
+                    "$ref": "#/definitions/http.ExclusionResponse"
+                }
+            }
+        },
+        "http.FreeRoundGrantResponse": {
+            "type": "object",
+            "properties": {
+                "bet_value": {
+                    "type": "number",
+                    "example": 0.2
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "admin"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "game_id": {
+                    "type": "string",
+                    "example": "book-of-ra"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "remaining": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "revoked_by": {
+                    "type": "string",
+                    "example": "admin"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "win_to": {
+                    "type": "string",
+                    "example": "real"
+                }
+            }
+        },
+        "http.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "http.HeldWinResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 75000
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "game_id": {
+                    "type": "string",
+                    "example": "book-of-ra"
+                },
+                "provider_id": {
+                    "type": "string",
+                    "example": "novomatic"
+                },
+                "provider_transaction_id": {
+                    "type": "string",
+                    "example": "tx123"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewed_by": {
+                    "type": "string",
+                    "example": "admin"
+                },
+                "round_id": {
+                    "type": "string",
+                    "example": "round-42"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "HELD"
+                },
+                "transaction_id": {
+                    "type": "integer",
+                    "example": 123
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "http.JackpotResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 12345.67
+                },
+                "contribution_pct": {
+                    "type": "number",
+                    "example": 1.5
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "game_group": {
+                    "type": "string",
+                    "example": "fruits"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_win_amount": {
+                    "type": "number",
+                    "example": 25000
+                },
+                "last_won_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Mega Fruits"
+                },
+                "seed_amount": {
+                    "type": "number",
+                    "example": 10000
+                }
+            }
+        },
+        "http.LimitResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 100
+                },
+                "pending_amount": {
+                    "type": "number",
+                    "example": 200
+                },
+                "pending_from": {
+                    "type": "string"
+                },
+                "period": {
+                    "type": "string",
+                    "example": "daily"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "loss"
+                },
+                "used": {
+                    "type": "number",
+                    "example": 35.5
+                }
+            }
+        },
+        "http.LimitsResponse": {
+            "type": "object",
+            "properties": {
+                "limits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.LimitResponse"
+                    }
+                }
+            }
+        },
+        "http.LockoutResponse": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "key": {
+                    "type": "string",
+                    "example": "user:testuser1"
+                },
+                "last_failure_at": {
+                    "type": "string"
+                },
+                "locked_until": {
+                    "type": "string"
+                }
+            }
+        },
+        "http.LoginErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "invalid credentials"
+                }
+            }
+        },
+        "http.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "mfa_enrollment_required": {
+                    "description": "Set for roles that must enrol in two-factor authentication.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "mfa_required": {
+                    "description": "Set instead of Token when the user has two-factor authentication on.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "testuser1"
+                }
+            }
+        },
+        "http.ProfileErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "unauthorized"
+                }
+            }
+        },
+        "http.ProfileResponse": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number",
+                    "example": 100
+                },
+                "balances": {
+                    "description": "Balances in currencies other than the primary one.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.BalanceResponse"
+                    }
+                },
+                "bonus_balance": {
+                    "description": "BonusBalance cannot be cashed out until the bonus is wagered.",
+                    "type": "number",
+                    "example": 25
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "http.RealityCheckResponse": {
+            "type": "object",
+            "properties": {
+                "elapsed_minutes": {
+                    "type": "integer",
+                    "example": 60
+                },
+                "net": {
+                    "type": "number",
+                    "example": -70
+                },
+                "session_started_at": {
+                    "type": "string"
+                },
+                "wagered": {
+                    "type": "number",
+                    "example": 250
+                },
+                "won": {
+                    "type": "number",
+                    "example": 180
+                }
+            }
+        },
+        "http.ReconciliationMismatchResponse": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "missing_remotely"
+                },
+                "local_amount": {
+                    "type": "number",
+                    "example": -10
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "provider-tx-1"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "wallet_amount": {
+                    "type": "number",
+                    "example": 0
+                }
+            }
+        },
+        "http.ReconciliationReportResponse": {
+            "type": "object",
+            "properties": {
+                "day": {
+                    "type": "string",
+                    "example": "2025-01-31"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.ReconciliationMismatchResponse"
+                    }
+                },
+                "local_net": {
+                    "type": "number",
+                    "example": -1520.5
+                },
+                "mismatches": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "completed"
+                },
+                "users": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "wallet_net": {
+                    "type": "number",
+                    "example": -1510.5
+                }
+            }
+        },
+        "http.ReconciliationRunResponse": {
+            "type": "object",
+            "properties": {
+                "day": {
+                    "type": "string",
+                    "example": "2025-01-31"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "local_net": {
+                    "type": "number",
+                    "example": -1520.5
+                },
+                "mismatches": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "completed"
+                },
+                "users": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "wallet_net": {
+                    "type": "number",
+                    "example": -1510.5
                 }
             }
         },
-        "http.ProfileErrorResponse": {
+        "http.RegisterResponse": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "username": {
+                    "type": "string",
+                    "example": "newplayer"
+                }
+            }
+        },
+        "http.RevenueLineResponse": {
+            "type": "object",
+            "properties": {
+                "base_ggr": {
+                    "type": "number",
+                    "example": 653
+                },
+                "base_turnover": {
+                    "type": "number",
+                    "example": 10870
+                },
+                "base_wins": {
+                    "type": "number",
+                    "example": 10217
+                },
+                "bets": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "bonus_released": {
+                    "type": "number",
+                    "example": 20
+                },
+                "bonus_stakes": {
+                    "type": "number",
+                    "example": 200
+                },
+                "bonus_wins": {
+                    "type": "number",
+                    "example": 150
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "day": {
+                    "type": "string",
+                    "example": "2025-01-31"
+                },
+                "game_id": {
+                    "type": "string",
+                    "example": "game-1"
+                },
+                "ggr": {
+                    "type": "number",
+                    "example": 600
+                },
+                "ngr": {
+                    "type": "number",
+                    "example": 530
+                },
+                "provider_id": {
+                    "type": "string",
+                    "example": "provider-1"
+                },
+                "turnover": {
+                    "type": "number",
+                    "example": 10000
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "wins": {
+                    "type": "number",
+                    "example": 9400
+                }
+            }
+        },
+        "http.RevenueReportResponse": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "group_by": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "day",
+                        "currency"
+                    ]
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.RevenueLineResponse"
+                    }
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-02-01"
+                }
+            }
+        },
+        "http.RoundDetailsResponse": {
             "type": "object",
             "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.RoundEntryResponse"
+                    }
+                },
                 "error": {
+                    "type": "string"
+                },
+                "game_id": {
                     "type": "string",
-                    "example": "unauthorized"
+                    "example": "book-of-ra"
+                },
+                "provider_data": {
+                    "type": "object"
+                },
+                "provider_id": {
+                    "type": "string",
+                    "example": "novomatic"
+                },
+                "round_id": {
+                    "type": "string",
+                    "example": "round-42"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "stored"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 5
                 }
             }
         },
-        "http.ProfileResponse": {
+        "http.RoundEntryResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 10
+                },
+                "bonus_amount": {
+                    "type": "number",
+                    "example": 0
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "new_balance": {
+                    "type": "number",
+                    "example": 90
+                },
+                "old_balance": {
+                    "type": "number",
+                    "example": 100
+                },
+                "provider_parent_transaction_id": {
+                    "type": "string",
+                    "example": "tx122"
+                },
+                "provider_transaction_id": {
+                    "type": "string",
+                    "example": "tx123"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "lost"
+                },
+                "transaction_id": {
+                    "type": "integer",
+                    "example": 123
+                },
+                "type": {
+                    "type": "string",
+                    "example": "withdraw"
+                }
+            }
+        },
+        "http.TwoFactorConfirmResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "k3j9d-p2x7q",
+                        "m8w4t-a6z2n"
+                    ]
+                }
+            }
+        },
+        "http.TwoFactorEnrollResponse": {
+            "type": "object",
+            "properties": {
+                "provisioning_uri": {
+                    "type": "string",
+                    "example": "otpauth://totp/Game%20Integration%20API:testuser1?secret=JBSWY3DPEHPK3PXP\u0026issuer=Game%20Integration%20API"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXP"
+                }
+            }
+        },
+        "http.assignJackpotGameRequest": {
+            "type": "object",
+            "required": [
+                "game_group",
+                "game_id"
+            ],
+            "properties": {
+                "game_group": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "fruits"
+                },
+                "game_id": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "fruit-party"
+                }
+            }
+        },
+        "http.authorizeDepositRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "currency",
+                "reference",
+                "wallet_id"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 50
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "dep-8c1f2a"
+                },
+                "wallet_id": {
+                    "type": "string",
+                    "example": "34633089486"
+                }
+            }
+        },
+        "http.batchItemRequest": {
+            "type": "object",
+            "required": [
+                "provider_transaction_id",
+                "type"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "cancel_transaction_id": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "free_round": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "game_id": {
+                    "type": "string"
+                },
+                "jackpot_id": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "provider_transaction_id": {
+                    "type": "string"
+                },
+                "provider_withdrawn_transaction_id": {
+                    "type": "string"
+                },
+                "round_details": {
+                    "type": "object"
+                },
+                "round_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "withdraw",
+                        "deposit",
+                        "cancel"
+                    ],
+                    "example": "withdraw"
+                }
+            }
+        },
+        "http.batchRequest": {
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "atomic": {
+                    "description": "Atomic applies every item or none.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "items": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/http.batchItemRequest"
+                    }
+                }
+            }
+        },
+        "http.betRuleRequest": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "game_id": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "book-of-ra"
+                },
+                "max_stake": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 100
+                },
+                "max_win_per_round": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 50000
+                },
+                "min_stake": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 0.1
+                },
+                "provider_id": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "novomatic"
+                }
+            }
+        },
+        "http.cancelRequest": {
+            "type": "object",
+            "required": [
+                "provider_transaction_id"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Amount is the part to cancel, in the currency the transaction was\nplayed in. Omitted, all that is left is cancelled.",
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 5
+                },
+                "cancel_transaction_id": {
+                    "description": "CancelTransaction is the provider's ID for this cancel; each partial\ncancel of a transaction needs its own.",
+                    "type": "string"
+                },
+                "provider_transaction_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "http.changePasswordRequest": {
+            "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "http.createCampaignRequest": {
             "type": "object",
+            "required": [
+                "amount",
+                "name",
+                "valid_days"
+            ],
             "properties": {
-                "balance": {
+                "amount": {
                     "type": "number",
-                    "example": 100
+                    "example": 50
                 },
-                "currency": {
+                "name": {
                     "type": "string",
-                    "example": "USD"
+                    "maxLength": 100,
+                    "example": "Welcome bonus"
                 },
-                "user_id": {
+                "valid_days": {
                     "type": "integer",
-                    "example": 1
+                    "example": 14
+                },
+                "wagering_multiplier": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 30
                 }
             }
         },
-        "http.cancelRequest": {
+        "http.createJackpotRequest": {
             "type": "object",
             "required": [
-                "provider_transaction_id"
+                "contribution_pct",
+                "currency",
+                "game_group",
+                "name"
             ],
             "properties": {
-                "provider_transaction_id": {
-                    "type": "string"
+                "contribution_pct": {
+                    "type": "number",
+                    "maximum": 100,
+                    "example": 1.5
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "game_group": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "fruits"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Mega Fruits"
+                },
+                "seed_amount": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 10000
                 }
             }
         },
@@ -369,16 +4210,142 @@ const docTemplate = `{
             ],
             "properties": {
                 "amount": {
-                    "type": "number"
+                    "type": "number",
+                    "minimum": 0
                 },
                 "currency": {
                     "type": "string"
                 },
+                "free_round": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "jackpot_id": {
+                    "type": "integer",
+                    "example": 0
+                },
                 "provider_transaction_id": {
                     "type": "string"
                 },
                 "provider_withdrawn_transaction_id": {
                     "type": "string"
+                },
+                "round_details": {
+                    "description": "RoundDetails is the provider's description of the round, kept for\ndispute handling.",
+                    "type": "object"
+                }
+            }
+        },
+        "http.excludeRequest": {
+            "type": "object",
+            "required": [
+                "type"
+            ],
+            "properties": {
+                "days": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 7
+                },
+                "permanent": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "taking a break"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "timeout",
+                        "self_exclusion"
+                    ],
+                    "example": "timeout"
+                }
+            }
+        },
+        "http.grantBonusRequest": {
+            "type": "object",
+            "required": [
+                "campaign_id"
+            ],
+            "properties": {
+                "campaign_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "http.grantFreeRoundsRequest": {
+            "type": "object",
+            "required": [
+                "bet_value",
+                "count",
+                "game_id",
+                "valid_days",
+                "win_to"
+            ],
+            "properties": {
+                "bet_value": {
+                    "type": "number",
+                    "example": 0.2
+                },
+                "count": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "example": 20
+                },
+                "game_id": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "book-of-ra"
+                },
+                "valid_days": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "example": 7
+                },
+                "win_to": {
+                    "type": "string",
+                    "enum": [
+                        "real",
+                        "bonus"
+                    ],
+                    "example": "real"
+                }
+            }
+        },
+        "http.limitItem": {
+            "type": "object",
+            "required": [
+                "period",
+                "type"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 100
+                },
+                "period": {
+                    "type": "string",
+                    "enum": [
+                        "daily",
+                        "weekly",
+                        "monthly"
+                    ],
+                    "example": "daily"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "loss",
+                        "wager",
+                        "deposit"
+                    ],
+                    "example": "loss"
                 }
             }
         },
@@ -399,6 +4366,152 @@ const docTemplate = `{
                 }
             }
         },
+        "http.loginTwoFactorRequest": {
+            "type": "object",
+            "required": [
+                "challenge_token",
+                "code"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "http.openBalanceRequest": {
+            "type": "object",
+            "required": [
+                "currency"
+            ],
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                }
+            }
+        },
+        "http.passwordResetConfirmRequest": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "token"
+            ],
+            "properties": {
+                "new_password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "http.passwordResetRequest": {
+            "type": "object",
+            "required": [
+                "username"
+            ],
+            "properties": {
+                "username": {
+                    "type": "string",
+                    "example": "testuser1"
+                }
+            }
+        },
+        "http.registerRequest": {
+            "type": "object",
+            "required": [
+                "currency",
+                "password",
+                "username",
+                "wallet_id",
+                "wallet_token"
+            ],
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "player@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "s3cretpass"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "newplayer"
+                },
+                "wallet_id": {
+                    "type": "string",
+                    "example": "34633089486"
+                },
+                "wallet_token": {
+                    "description": "WalletToken is issued by the wallet operator to prove the player owns\nthe wallet.",
+                    "type": "string",
+                    "example": "1767225600.5d41402abc4b2a76b9719d911017c592"
+                }
+            }
+        },
+        "http.setLimitsRequest": {
+            "type": "object",
+            "required": [
+                "limits"
+            ],
+            "properties": {
+                "limits": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/http.limitItem"
+                    }
+                }
+            }
+        },
+        "http.twoFactorCodeRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "http.twoFactorDisableRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "password"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "http.twoFactorEnrollRequest": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
         "http.withdrawRequest": {
             "type": "object",
             "required": [
@@ -413,16 +4526,133 @@ const docTemplate = `{
                 "currency": {
                     "type": "string"
                 },
+                "free_round": {
+                    "type": "boolean",
+                    "example": false
+                },
                 "game_id": {
                     "type": "string"
                 },
                 "provider_transaction_id": {
                     "type": "string"
                 },
+                "round_details": {
+                    "description": "RoundDetails is the provider's description of the round, kept for\ndispute handling.",
+                    "type": "object"
+                },
                 "round_id": {
                     "type": "string"
                 }
             }
+        },
+        "infrastructure.DependencyStatus": {
+            "type": "object",
+            "properties": {
+                "circuit_breaker": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "infrastructure.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string",
+                    "example": "EdDSA"
+                },
+                "crv": {
+                    "type": "string",
+                    "example": "Ed25519"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string",
+                    "example": "2025-01"
+                },
+                "kty": {
+                    "type": "string",
+                    "example": "OKP"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string",
+                    "example": "sig"
+                },
+                "x": {
+                    "type": "string",
+                    "example": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"
+                }
+            }
+        },
+        "infrastructure.JWKSet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/infrastructure.JWK"
+                    }
+                }
+            }
+        },
+        "infrastructure.ReadinessReport": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "dependencies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/infrastructure.DependencyStatus"
+                    }
+                },
+                "ready": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "infrastructure.StatusReport": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "dependencies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/infrastructure.DependencyStatus"
+                    }
+                },
+                "migration_version": {
+                    "type": "string"
+                },
+                "ready": {
+                    "type": "boolean"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "uptime": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type registerRequest struct {
+	Username string `json:"username" binding:"required" example:"newplayer"`
+	Password string `json:"password" binding:"required" example:"s3cretpass"`
+	Email    string `json:"email" binding:"omitempty,email" example:"player@example.com"`
+	Currency string `json:"currency" binding:"required" example:"USD"`
+	WalletID string `json:"wallet_id" binding:"required" example:"34633089486"`
+	// WalletToken is issued by the wallet operator to prove the player owns
+	// the wallet.
+	WalletToken string `json:"wallet_token" binding:"required" example:"1767225600.5d41402abc4b2a76b9719d911017c592"`
+}
+
+func (r *registerRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		Username    string `json:"username"`
+		Password    string `json:"password"`
+		Email       string `json:"email"`
+		Currency    string `json:"currency"`
+		WalletID    string `json:"wallet_id"`
+		WalletToken string `json:"wallet_token"`
+	})(r))
+}
+
+type RegisterResponse struct {
+	UserID   uint   `json:"user_id" example:"5"`
+	Username string `json:"username" example:"newplayer"`
+	Currency string `json:"currency" example:"USD"`
+}
+
+type AccountErrorResponse struct {
+	Error string `json:"error" example:"username is already taken"`
+}
+
+// Register godoc
+// @Summary Register a player
+// @Tags Auth
+// @Description Create a player account linked to an existing wallet account. wallet_token is the link token the wallet operator issued for the wallet.
+// @Accept json
+// @Produce json
+// @Param body body registerRequest true "Registration details"
+// @Success 201 {object} RegisterResponse "Registered"
+// @Failure 400 {object} AccountErrorResponse "Invalid request"
+// @Failure 403 {object} AccountErrorResponse "Registration is not enabled"
+// @Failure 409 {object} AccountErrorResponse "Username or wallet already in use"
+// @Router /auth/register [post]
+func (h *Handlers) Register(c *gin.Context) {
+	var req registerRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, AccountErrorResponse{Error: err.Error()})
+		return
+	}
+	user, err := h.AccountUseCase.Register(c.Request.Context(), usecase.RegisterInput{
+		Username:    req.Username,
+		Password:    req.Password,
+		Email:       req.Email,
+		Currency:    req.Currency,
+		WalletID:    req.WalletID,
+		WalletToken: req.WalletToken,
+	})
+	if err != nil {
+		writeAccountError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, RegisterResponse{UserID: user.ID, Username: user.Username, Currency: user.Currency})
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+func (r *changePasswordRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	})(r))
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Tags Auth
+// @Description Change the authenticated user's password
+// @Accept json
+// @Produce json
+// @Param body body changePasswordRequest true "Current and new password"
+// @Success 204 "Password changed"
+// @Failure 400 {object} AccountErrorResponse "Invalid request"
+// @Failure 401 {object} AccountErrorResponse "Unauthorized or wrong current password"
+// @Security BearerAuth
+// @Router /auth/change-password [post]
+func (h *Handlers) ChangePassword(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req changePasswordRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, AccountErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := h.AccountUseCase.ChangePassword(c.Request.Context(), userID.(uint), req.CurrentPassword, req.NewPassword); err != nil {
+		writeAccountError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type passwordResetRequest struct {
+	Username string `json:"username" binding:"required" example:"testuser1"`
+}
+
+func (r *passwordResetRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		Username string `json:"username"`
+	})(r))
+}
+
+// RequestPasswordReset godoc
+// @Summary Request a password reset
+// @Tags Auth
+// @Description Send a single-use, time-limited reset token to the user. Always returns 202 so usernames cannot be probed.
+// @Accept json
+// @Produce json
+// @Param body body passwordResetRequest true "Username"
+// @Success 202 "Reset requested"
+// @Failure 400 {object} AccountErrorResponse "Invalid request"
+// @Router /auth/password-reset/request [post]
+func (h *Handlers) RequestPasswordReset(c *gin.Context) {
+	var req passwordResetRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, AccountErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := h.AccountUseCase.RequestPasswordReset(c.Request.Context(), req.Username); err != nil {
+		c.JSON(http.StatusInternalServerError, AccountErrorResponse{Error: "could not request password reset"})
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+type passwordResetConfirmRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+func (r *passwordResetConfirmRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		Token       string `json:"token"`
+		NewPassword string `json:"new_password"`
+	})(r))
+}
+
+// ConfirmPasswordReset godoc
+// @Summary Reset password with a token
+// @Tags Auth
+// @Description Set a new password using a reset token. Each token works once.
+// @Accept json
+// @Produce json
+// @Param body body passwordResetConfirmRequest true "Token and new password"
+// @Success 204 "Password reset"
+// @Failure 400 {object} AccountErrorResponse "Invalid request or token"
+// @Router /auth/password-reset/confirm [post]
+func (h *Handlers) ConfirmPasswordReset(c *gin.Context) {
+	var req passwordResetConfirmRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, AccountErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := h.AccountUseCase.ResetPassword(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		writeAccountError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func writeAccountError(c *gin.Context, err error) {
+	var validation *usecase.ValidationError
+	switch {
+	case errors.As(err, &validation),
+		errors.Is(err, usecase.ErrInvalidWallet),
+		errors.Is(err, usecase.ErrInvalidResetToken):
+		c.JSON(http.StatusBadRequest, AccountErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrRegistrationClosed):
+		c.JSON(http.StatusForbidden, AccountErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrUsernameTaken), errors.Is(err, usecase.ErrWalletAlreadyLinked):
+		c.JSON(http.StatusConflict, AccountErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, AccountErrorResponse{Error: err.Error()})
+	case err == usecase.ErrWalletServiceUnavailable:
+		c.JSON(http.StatusServiceUnavailable, AccountErrorResponse{Error: "wallet service is not available"})
+	default:
+		c.JSON(http.StatusInternalServerError, AccountErrorResponse{Error: err.Error()})
+	}
+}
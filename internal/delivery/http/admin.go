@@ -0,0 +1,77 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type LockoutResponse struct {
+	ID            uint      `json:"id" example:"1"`
+	Key           string    `json:"key" example:"user:testuser1"`
+	Failures      int       `json:"failures" example:"5"`
+	LastFailureAt time.Time `json:"last_failure_at"`
+	LockedUntil   time.Time `json:"locked_until"`
+}
+
+// ListLockouts godoc
+// @Summary List active login lockouts
+// @Tags Admin
+// @Description List usernames and client IPs currently locked out after failed logins
+// @Produce json
+// @Success 200 {array} LockoutResponse "Active lockouts"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Security BearerAuth
+// @Router /admin/lockouts [get]
+func (h *Handlers) ListLockouts(c *gin.Context) {
+	lockouts, err := h.AuthUseCase.ListLockouts(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]LockoutResponse, 0, len(lockouts))
+	for _, l := range lockouts {
+		resp = append(resp, LockoutResponse{
+			ID:            l.ID,
+			Key:           l.Key,
+			Failures:      l.Failures,
+			LastFailureAt: l.LastFailureAt,
+			LockedUntil:   *l.LockedUntil,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ClearLockout godoc
+// @Summary Clear a login lockout
+// @Tags Admin
+// @Description Remove a lockout and reset its failed-attempt counter
+// @Produce json
+// @Param id path int true "Lockout ID"
+// @Success 204 "Cleared"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Failure 404 {object} BetErrorResponse "Not found"
+// @Security BearerAuth
+// @Router /admin/lockouts/{id} [delete]
+func (h *Handlers) ClearLockout(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lockout id"})
+		return
+	}
+	if err := h.AuthUseCase.ClearLockout(c.Request.Context(), uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "lockout not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -3,7 +3,13 @@ package http
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"time"
+
+	"gameintegrationapi/internal/usecase"
 
 	"github.com/gin-gonic/gin"
 )
@@ -23,8 +29,13 @@ func (r *loginRequest) UnmarshalJSON(data []byte) error {
 }
 
 type LoginResponse struct {
-	Token    string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9"`
+	Token    string `json:"token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9"`
 	Username string `json:"username" example:"testuser1"`
+	// Set instead of Token when the user has two-factor authentication on.
+	MFARequired    bool   `json:"mfa_required,omitempty" example:"false"`
+	ChallengeToken string `json:"challenge_token,omitempty"`
+	// Set for roles that must enrol in two-factor authentication.
+	MFAEnrollmentRequired bool `json:"mfa_enrollment_required,omitempty" example:"false"`
 }
 
 type LoginErrorResponse struct {
@@ -34,13 +45,14 @@ type LoginErrorResponse struct {
 // Login godoc
 // @Summary Authenticate user
 // @Tags Auth
-// @Description Authenticate user and return JWT token and username
+// @Description Authenticate user and return JWT token and username. Users with two-factor authentication get a challenge token to complete at /auth/login/2fa instead.
 // @Accept json
 // @Produce json
 // @Param credentials body loginRequest true "User credentials" example({"username": "testuser1", "password": "testpass"})
 // @Success 200 {object} LoginResponse "Login response"
 // @Failure 400 {object} LoginErrorResponse "Invalid request"
 // @Failure 401 {object} LoginErrorResponse "Invalid credentials"
+// @Failure 429 {object} LoginErrorResponse "Too many failed attempts"
 // @Router /auth/login [post]
 func (h *Handlers) Login(c *gin.Context) {
 	var req loginRequest
@@ -48,10 +60,66 @@ func (h *Handlers) Login(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, LoginErrorResponse{Error: err.Error()})
 		return
 	}
-	token, err := h.AuthUseCase.Login(req.Username, req.Password)
+	result, err := h.AuthUseCase.Login(c.Request.Context(), req.Username, req.Password, c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, LoginErrorResponse{Error: err.Error()})
+		writeLoginError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, LoginResponse{
+		Token:                 result.Token,
+		Username:              req.Username,
+		MFARequired:           result.ChallengeToken != "",
+		ChallengeToken:        result.ChallengeToken,
+		MFAEnrollmentRequired: result.EnrollmentRequired,
+	})
+}
+
+type loginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required" example:"123456"`
+}
+
+func (r *loginTwoFactorRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		ChallengeToken string `json:"challenge_token"`
+		Code           string `json:"code"`
+	})(r))
+}
+
+// LoginTwoFactor godoc
+// @Summary Complete a two-factor login
+// @Tags Auth
+// @Description Exchange the challenge token from /auth/login and a TOTP or recovery code for a JWT token
+// @Accept json
+// @Produce json
+// @Param body body loginTwoFactorRequest true "Challenge token and code"
+// @Success 200 {object} LoginResponse "Login response"
+// @Failure 400 {object} LoginErrorResponse "Invalid request"
+// @Failure 401 {object} LoginErrorResponse "Invalid challenge or code"
+// @Failure 429 {object} LoginErrorResponse "Too many failed attempts"
+// @Router /auth/login/2fa [post]
+func (h *Handlers) LoginTwoFactor(c *gin.Context) {
+	var req loginTwoFactorRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, LoginErrorResponse{Error: err.Error()})
+		return
+	}
+	result, err := h.AuthUseCase.CompleteLogin(c.Request.Context(), req.ChallengeToken, req.Code, c.ClientIP())
+	if err != nil {
+		writeLoginError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, LoginResponse{Token: result.Token, Username: result.Username})
+}
+
+func writeLoginError(c *gin.Context, err error) {
+	var locked *usecase.AccountLockedError
+	if errors.As(err, &locked) {
+		c.Header("Retry-After", fmt.Sprintf("%d", int(math.Ceil(time.Until(locked.Until).Seconds()))))
+		c.JSON(http.StatusTooManyRequests, LoginErrorResponse{Error: err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, LoginResponse{Token: token, Username: req.Username})
+	c.JSON(http.StatusUnauthorized, LoginErrorResponse{Error: err.Error()})
 }
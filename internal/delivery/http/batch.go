@@ -0,0 +1,229 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type batchRequest struct {
+	// Atomic applies every item or none.
+	Atomic bool               `json:"atomic" example:"true"`
+	Items  []batchItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+func (r *batchRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		Atomic bool               `json:"atomic"`
+		Items  []batchItemRequest `json:"items"`
+	})(r))
+}
+
+// batchItemRequest carries the fields of the matching single-operation
+// request. For a cancel, provider_transaction_id is the transaction to cancel
+// and amount, when set, the part to cancel.
+type batchItemRequest struct {
+	Type                  string          `json:"type" binding:"required,oneof=withdraw deposit cancel" example:"withdraw"`
+	Currency              string          `json:"currency" binding:"required_unless=Type cancel"`
+	Amount                float64         `json:"amount"`
+	ProviderTransaction   string          `json:"provider_transaction_id" binding:"required"`
+	ProviderWithdrawnTxID string          `json:"provider_withdrawn_transaction_id" binding:"required_if=Type deposit"`
+	CancelTransaction     string          `json:"cancel_transaction_id"`
+	RoundID               string          `json:"round_id"`
+	GameID                string          `json:"game_id"`
+	FreeRound             bool            `json:"free_round" example:"false"`
+	JackpotID             uint            `json:"jackpot_id,omitempty" example:"0"`
+	RoundDetails          json.RawMessage `json:"round_details,omitempty" swaggertype:"object"`
+}
+
+func (r *batchItemRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		Type                  string          `json:"type"`
+		Currency              string          `json:"currency"`
+		Amount                float64         `json:"amount"`
+		ProviderTransaction   string          `json:"provider_transaction_id"`
+		ProviderWithdrawnTxID string          `json:"provider_withdrawn_transaction_id"`
+		CancelTransaction     string          `json:"cancel_transaction_id"`
+		RoundID               string          `json:"round_id"`
+		GameID                string          `json:"game_id"`
+		FreeRound             bool            `json:"free_round"`
+		JackpotID             uint            `json:"jackpot_id"`
+		RoundDetails          json.RawMessage `json:"round_details"`
+	})(r))
+}
+
+type BatchResponse struct {
+	Items []BatchItemResponse `json:"items"`
+}
+
+type BatchItemResponse struct {
+	Index int `json:"index" example:"0"`
+	// Status is ok, replayed (already applied by an earlier request) or
+	// failed.
+	Status      string       `json:"status" example:"ok"`
+	Transaction *BetResponse `json:"transaction,omitempty"`
+	Error       string       `json:"error,omitempty"`
+	Code        string       `json:"code,omitempty"`
+}
+
+// BatchErrorResponse rejects an atomic batch. Index is the failing item, or
+// -1 when the batch as a whole failed.
+type BatchErrorResponse struct {
+	Error string `json:"error" example:"item 1: insufficient funds"`
+	Code  string `json:"code,omitempty" example:"RG_LIMIT_EXCEEDED"`
+	Index int    `json:"index" example:"1"`
+}
+
+// Batch godoc
+// @Summary Apply several bet operations
+// @Tags Bet
+// @Description Apply up to 100 withdraw, deposit and cancel operations for the player, in order. With atomic, either every item is applied or none is, and the wallet is called once per operation type and currency; free rounds and jackpot payouts cannot be included. Otherwise each item is applied on its own and reported with its own status. In both modes an item whose provider transaction was already applied is not applied again and is reported as replayed.
+// @Accept json
+// @Produce json
+// @Param body body batchRequest true "Operations"
+// @Success 200 {object} BatchResponse "Result of each item"
+// @Failure 400 {object} BatchErrorResponse "Invalid request"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BatchErrorResponse "Responsible-gaming limit reached or player excluded"
+// @Failure 422 {object} BatchErrorResponse "Stake outside the game's limits, currency not supported or insufficient funds"
+// @Failure 503 {object} BatchErrorResponse "Wallet service not available"
+// @Security BearerAuth
+// @Router /bet/batch [post]
+func (h *Handlers) Batch(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req batchRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// The route lets excluded players settle their bets; stakes are checked
+	// here as on /bet/withdraw.
+	for _, item := range req.Items {
+		if item.Type == usecase.BatchWithdraw {
+			if h.rejectExcluded(c, userID.(uint)) {
+				return
+			}
+			break
+		}
+	}
+	in := usecase.BatchInput{UserID: userID.(uint), Atomic: req.Atomic}
+	for i, item := range req.Items {
+		if err := checkRoundDetails(item.RoundDetails); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("item %d: %v", i, err)})
+			return
+		}
+		in.Items = append(in.Items, usecase.BatchItem{
+			Op: item.Type,
+			Withdraw: usecase.WithdrawInput{
+				Amount:       item.Amount,
+				Currency:     item.Currency,
+				ProviderTxID: item.ProviderTransaction,
+				RoundID:      item.RoundID,
+				GameID:       item.GameID,
+				ProviderID:   c.GetString("providerID"),
+				FreeRound:    item.FreeRound,
+				RoundDetails: item.RoundDetails,
+			},
+			Deposit: usecase.DepositInput{
+				Amount:             item.Amount,
+				Currency:           item.Currency,
+				ProviderTxID:       item.ProviderTransaction,
+				ProviderParentTxID: item.ProviderWithdrawnTxID,
+				FreeRound:          item.FreeRound,
+				JackpotID:          item.JackpotID,
+				RoundDetails:       item.RoundDetails,
+			},
+			Cancel: usecase.CancelInput{
+				ProviderTxID: item.ProviderTransaction,
+				Amount:       item.Amount,
+				CancelTxID:   item.CancelTransaction,
+			},
+		})
+	}
+	results, err := h.WalletUseCase.Batch(c.Request.Context(), in)
+	if err != nil {
+		status, code := betErrorStatus(err)
+		index := -1
+		var batchErr *usecase.BatchError
+		if errors.As(err, &batchErr) {
+			index = batchErr.Index
+		}
+		c.JSON(status, BatchErrorResponse{Error: err.Error(), Code: code, Index: index})
+		return
+	}
+	resp := BatchResponse{Items: make([]BatchItemResponse, len(results))}
+	for i, r := range results {
+		item := BatchItemResponse{Index: i, Status: "ok"}
+		switch {
+		case r.Err != nil:
+			_, item.Code = betErrorStatus(r.Err)
+			item.Status, item.Error = "failed", r.Err.Error()
+		case r.Replayed:
+			item.Status = "replayed"
+		}
+		if r.Transaction != nil {
+			item.Transaction = toBetResponse(r.Transaction)
+		}
+		resp.Items[i] = item
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func toBetResponse(tx *domain.Transaction) *BetResponse {
+	return &BetResponse{
+		TransactionID:         tx.ID,
+		ProviderTransactionID: tx.ProviderTxID,
+		OldBalance:            tx.OldBalance,
+		NewBalance:            tx.NewBalance,
+		Status:                tx.Status,
+	}
+}
+
+// betErrorStatus maps a bet operation error to the status the
+// single-operation endpoints answer with, and the code shown to the player.
+func betErrorStatus(err error) (int, string) {
+	var stakeErr *usecase.StakeRuleError
+	var limitErr *usecase.LimitExceededError
+	var excludedErr *usecase.ExcludedError
+	var validation *usecase.ValidationError
+	switch {
+	case errors.As(err, &stakeErr):
+		return http.StatusUnprocessableEntity, stakeErr.Code
+	case errors.As(err, &limitErr):
+		return http.StatusForbidden, usecase.LimitExceededCode
+	case errors.As(err, &excludedErr):
+		return http.StatusForbidden, usecase.PlayerExcludedCode
+	case errors.Is(err, usecase.ErrCurrencyUnsupported):
+		return http.StatusUnprocessableEntity, usecase.CurrencyUnsupportedCode
+	case errors.Is(err, usecase.ErrNoFreeRounds):
+		return http.StatusConflict, usecase.NoFreeRoundsCode
+	case errors.Is(err, usecase.ErrAlreadyCancelled):
+		return http.StatusConflict, ""
+	case errors.Is(err, usecase.ErrInsufficientFunds), errors.Is(err, usecase.ErrCancelExceedsAmount):
+		return http.StatusUnprocessableEntity, ""
+	case errors.Is(err, usecase.ErrJackpotNotFound):
+		return http.StatusNotFound, ""
+	case errors.Is(err, usecase.ErrJackpotNotQualified):
+		return http.StatusUnprocessableEntity, usecase.JackpotNotQualifiedCode
+	case errors.Is(err, usecase.ErrDuplicateTransaction):
+		return http.StatusConflict, ""
+	case errors.As(err, &validation), errors.Is(err, usecase.ErrInvalidStake), errors.Is(err, usecase.ErrInvalidAmount),
+		errors.Is(err, usecase.ErrNotFreeRound), errors.Is(err, infrastructure.ErrWalletServiceBadRequest):
+		return http.StatusBadRequest, ""
+	case errors.Is(err, usecase.ErrWalletServiceUnavailable), errors.Is(err, usecase.ErrShuttingDown):
+		return http.StatusServiceUnavailable, ""
+	}
+	return http.StatusInternalServerError, ""
+}
@@ -3,10 +3,10 @@ package http
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
+	"log"
 	"net/http"
+	"time"
 
-	"gameintegrationapi/internal/infrastructure"
 	"gameintegrationapi/internal/usecase"
 
 	"github.com/gin-gonic/gin"
@@ -14,21 +14,27 @@ import (
 
 type withdrawRequest struct {
 	Currency            string  `json:"currency" binding:"required"`
-	Amount              float64 `json:"amount" binding:"required"`
+	Amount              float64 `json:"amount" binding:"required,gt=0"`
 	ProviderTransaction string  `json:"provider_transaction_id" binding:"required"`
 	RoundID             string  `json:"round_id"`
-	GameID              string  `json:"game_id"`
+	GameID              string  `json:"game_id" binding:"required_if=FreeRound true"`
+	FreeRound           bool    `json:"free_round" example:"false"`
+	// RoundDetails is the provider's description of the round, kept for
+	// dispute handling.
+	RoundDetails json.RawMessage `json:"round_details,omitempty" swaggertype:"object"`
 }
 
 func (r *withdrawRequest) UnmarshalJSON(data []byte) error {
 	dec := json.NewDecoder(bytes.NewReader(data))
 	dec.DisallowUnknownFields()
 	return dec.Decode((*struct {
-		Currency            string  `json:"currency"`
-		Amount              float64 `json:"amount"`
-		ProviderTransaction string  `json:"provider_transaction_id"`
-		RoundID             string  `json:"round_id"`
-		GameID              string  `json:"game_id"`
+		Currency            string          `json:"currency"`
+		Amount              float64         `json:"amount"`
+		ProviderTransaction string          `json:"provider_transaction_id"`
+		RoundID             string          `json:"round_id"`
+		GameID              string          `json:"game_id"`
+		FreeRound           bool            `json:"free_round"`
+		RoundDetails        json.RawMessage `json:"round_details"`
 	})(r))
 }
 
@@ -38,22 +44,40 @@ type BetResponse struct {
 	OldBalance            float64 `json:"old_balance" example:"100.0"`
 	NewBalance            float64 `json:"new_balance" example:"90.0"`
 	Status                string  `json:"status" example:"COMPLETED"`
+	// Only on withdraw, when a reminder is due.
+	RealityCheck *RealityCheckResponse `json:"reality_check,omitempty"`
+}
+
+// RealityCheckResponse is included in a bet response when the player is due
+// a reminder of how long they have been playing and their net result. The
+// provider should show it in the game.
+type RealityCheckResponse struct {
+	SessionStartedAt time.Time `json:"session_started_at"`
+	ElapsedMinutes   int       `json:"elapsed_minutes" example:"60"`
+	Wagered          float64   `json:"wagered" example:"250"`
+	Won              float64   `json:"won" example:"180"`
+	Net              float64   `json:"net" example:"-70"`
 }
 
 type BetErrorResponse struct {
 	Error string `json:"error" example:"insufficient funds"`
+	// Code is set for errors the provider should show to the player.
+	Code string `json:"code,omitempty" example:"RG_LIMIT_EXCEEDED"`
 }
 
 // Withdraw godoc
 // @Summary Place a bet (withdraw)
 // @Tags Bet
-// @Description Place a bet by withdrawing funds
+// @Description Place a bet by withdrawing funds. The stake must be positive and within the game's configured stake range. With free_round, one round of the player's free-round grant for game_id is used instead and nothing is debited. The stake is taken from the player's balance in currency if they hold one; otherwise it is converted to their primary currency at the current rate. A stake the provider cancelled before it arrived is not debited; the cancel is returned instead, with status CANCELLED.
 // @Accept json
 // @Produce json
 // @Param body body withdrawRequest true "Withdraw details"
 // @Success 200 {object} BetResponse "Bet response"
 // @Failure 400 {object} BetErrorResponse "Invalid request"
 // @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Responsible-gaming limit reached or player excluded"
+// @Failure 409 {object} BetErrorResponse "No free rounds left for the game and bet value"
+// @Failure 422 {object} BetErrorResponse "Stake outside the game's limits, currency not supported, or not enough funds"
 // @Security BearerAuth
 // @Router /bet/withdraw [post]
 func (h *Handlers) Withdraw(c *gin.Context) {
@@ -63,56 +87,88 @@ func (h *Handlers) Withdraw(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	tx, err := h.WalletUseCase.Withdraw(userID.(uint), req.Amount, req.Currency, req.ProviderTransaction, req.RoundID, req.GameID)
+	if err := checkRoundDetails(req.RoundDetails); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tx, err := h.WalletUseCase.Withdraw(c.Request.Context(), usecase.WithdrawInput{
+		UserID:       userID.(uint),
+		Amount:       req.Amount,
+		Currency:     req.Currency,
+		ProviderTxID: req.ProviderTransaction,
+		RoundID:      req.RoundID,
+		GameID:       req.GameID,
+		ProviderID:   c.GetString("providerID"),
+		FreeRound:    req.FreeRound,
+		RoundDetails: req.RoundDetails,
+	})
 	if err != nil {
-		if err == usecase.ErrWalletServiceUnavailable {
-			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "wallet service is not available"})
-			return
-		}
-		if errors.Is(err, infrastructure.ErrWalletServiceBadRequest) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		status, code := betErrorStatus(err)
+		c.JSON(status, BetErrorResponse{Error: err.Error(), Code: code})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{
+	resp := gin.H{
 		"transaction_id":          tx.ID,
 		"provider_transaction_id": tx.ProviderTxID,
 		"old_balance":             tx.OldBalance,
 		"new_balance":             tx.NewBalance,
 		"status":                  tx.Status,
-	})
+	}
+	check, err := h.ResponsibleGamingUseCase.TouchSession(c.Request.Context(), userID.(uint))
+	if err != nil {
+		// The bet is placed; a missed reminder must not fail it.
+		log.Printf("Withdraw: failed to update game session for user %d: %v", userID, err)
+	} else if check != nil {
+		resp["reality_check"] = RealityCheckResponse{
+			SessionStartedAt: check.SessionStartedAt,
+			ElapsedMinutes:   int(check.Elapsed.Minutes()),
+			Wagered:          check.Wagered,
+			Won:              check.Won,
+			Net:              check.Won - check.Wagered,
+		}
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 type depositRequest struct {
 	Currency              string  `json:"currency" binding:"required"`
-	Amount                float64 `json:"amount"`
+	Amount                float64 `json:"amount" binding:"gte=0"`
 	ProviderTransaction   string  `json:"provider_transaction_id" binding:"required"`
 	ProviderWithdrawnTxID string  `json:"provider_withdrawn_transaction_id" binding:"required"`
+	FreeRound             bool    `json:"free_round" example:"false"`
+	JackpotID             uint    `json:"jackpot_id,omitempty" example:"0"`
+	// RoundDetails is the provider's description of the round, kept for
+	// dispute handling.
+	RoundDetails json.RawMessage `json:"round_details,omitempty" swaggertype:"object"`
 }
 
 func (r *depositRequest) UnmarshalJSON(data []byte) error {
 	dec := json.NewDecoder(bytes.NewReader(data))
 	dec.DisallowUnknownFields()
 	return dec.Decode((*struct {
-		Currency              string  `json:"currency"`
-		Amount                float64 `json:"amount"`
-		ProviderTransaction   string  `json:"provider_transaction_id"`
-		ProviderWithdrawnTxID string  `json:"provider_withdrawn_transaction_id"`
+		Currency              string          `json:"currency"`
+		Amount                float64         `json:"amount"`
+		ProviderTransaction   string          `json:"provider_transaction_id"`
+		ProviderWithdrawnTxID string          `json:"provider_withdrawn_transaction_id"`
+		FreeRound             bool            `json:"free_round"`
+		JackpotID             uint            `json:"jackpot_id"`
+		RoundDetails          json.RawMessage `json:"round_details"`
 	})(r))
 }
 
 // Deposit godoc
 // @Summary Settle a bet (deposit)
 // @Tags Bet
-// @Description Settle a bet by depositing funds
+// @Description Settle a bet by depositing funds. Wins on free rounds go to the real or bonus balance as set on the grant. A win that takes the round over the game's maximum payout is not credited; it is recorded with status HELD for manual review. With jackpot_id set, the pool's current value is paid instead of amount and the pool resets to its seed; provider_withdrawn_transaction_id must then name the player's stake on one of the pool's games in the pool's currency. A repeated jackpot payout returns the recorded one.
 // @Accept json
 // @Produce json
 // @Param body body depositRequest true "Deposit details"
 // @Success 200 {object} BetResponse "Bet response"
 // @Failure 400 {object} BetErrorResponse "Invalid request"
 // @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 404 {object} BetErrorResponse "Jackpot not found"
+// @Failure 409 {object} BetErrorResponse "Provider transaction ID already used for another transaction"
+// @Failure 422 {object} BetErrorResponse "Currency not supported, or no stake qualifying for the jackpot"
 // @Security BearerAuth
 // @Router /bet/deposit [post]
 func (h *Handlers) Deposit(c *gin.Context) {
@@ -122,17 +178,23 @@ func (h *Handlers) Deposit(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	tx, err := h.WalletUseCase.Deposit(userID.(uint), req.Amount, req.Currency, req.ProviderTransaction, req.ProviderWithdrawnTxID)
+	if err := checkRoundDetails(req.RoundDetails); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tx, err := h.WalletUseCase.Deposit(c.Request.Context(), usecase.DepositInput{
+		UserID:             userID.(uint),
+		Amount:             req.Amount,
+		Currency:           req.Currency,
+		ProviderTxID:       req.ProviderTransaction,
+		ProviderParentTxID: req.ProviderWithdrawnTxID,
+		FreeRound:          req.FreeRound,
+		JackpotID:          req.JackpotID,
+		RoundDetails:       req.RoundDetails,
+	})
 	if err != nil {
-		if err == usecase.ErrWalletServiceUnavailable {
-			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "wallet service is not available"})
-			return
-		}
-		if errors.Is(err, infrastructure.ErrWalletServiceBadRequest) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		status, code := betErrorStatus(err)
+		c.JSON(status, BetErrorResponse{Error: err.Error(), Code: code})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -146,26 +208,36 @@ func (h *Handlers) Deposit(c *gin.Context) {
 
 type cancelRequest struct {
 	ProviderTransaction string `json:"provider_transaction_id" binding:"required"`
+	// Amount is the part to cancel, in the currency the transaction was
+	// played in. Omitted, all that is left is cancelled.
+	Amount float64 `json:"amount,omitempty" binding:"gte=0" example:"5"`
+	// CancelTransaction is the provider's ID for this cancel; each partial
+	// cancel of a transaction needs its own.
+	CancelTransaction string `json:"cancel_transaction_id,omitempty"`
 }
 
 func (r *cancelRequest) UnmarshalJSON(data []byte) error {
 	dec := json.NewDecoder(bytes.NewReader(data))
 	dec.DisallowUnknownFields()
 	return dec.Decode((*struct {
-		ProviderTransaction string `json:"provider_transaction_id"`
+		ProviderTransaction string  `json:"provider_transaction_id"`
+		Amount              float64 `json:"amount"`
+		CancelTransaction   string  `json:"cancel_transaction_id"`
 	})(r))
 }
 
 // Cancel godoc
 // @Summary Cancel a transaction
 // @Tags Bet
-// @Description Cancel a bet transaction
+// @Description Cancel all or part of a transaction. A cancelled stake is refunded; a cancelled win is taken back (type CLAWBACK). The amounts cancelled can never exceed the amount of the transaction. A cancel of a transaction that has not arrived succeeds and is recorded; if the stake arrives later it is ignored and answered with the cancel (status CANCELLED) without debiting the player. Repeating a cancel returns the recorded one.
 // @Accept json
 // @Produce json
 // @Param body body cancelRequest true "Cancel details"
 // @Success 200 {object} BetResponse "Bet response"
 // @Failure 400 {object} BetErrorResponse "Invalid request"
 // @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 409 {object} BetErrorResponse "Already cancelled under another cancel ID"
+// @Failure 422 {object} BetErrorResponse "Amount exceeds what is left to cancel, or not enough funds to take a win back"
 // @Security BearerAuth
 // @Router /bet/cancel [post]
 func (h *Handlers) Cancel(c *gin.Context) {
@@ -175,17 +247,15 @@ func (h *Handlers) Cancel(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	tx, err := h.WalletUseCase.Cancel(userID.(uint), req.ProviderTransaction)
+	tx, err := h.WalletUseCase.Cancel(c.Request.Context(), usecase.CancelInput{
+		UserID:       userID.(uint),
+		ProviderTxID: req.ProviderTransaction,
+		Amount:       req.Amount,
+		CancelTxID:   req.CancelTransaction,
+	})
 	if err != nil {
-		if err == usecase.ErrWalletServiceUnavailable {
-			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "wallet service is not available"})
-			return
-		}
-		if errors.Is(err, infrastructure.ErrWalletServiceBadRequest) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		status, code := betErrorStatus(err)
+		c.JSON(status, BetErrorResponse{Error: err.Error(), Code: code})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -0,0 +1,260 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type BetRuleResponse struct {
+	ID             uint    `json:"id" example:"1"`
+	GameID         string  `json:"game_id,omitempty" example:"book-of-ra"`
+	ProviderID     string  `json:"provider_id,omitempty" example:"novomatic"`
+	Currency       string  `json:"currency,omitempty" example:"EUR"`
+	MinStake       float64 `json:"min_stake" example:"0.1"`
+	MaxStake       float64 `json:"max_stake" example:"100"`
+	MaxWinPerRound float64 `json:"max_win_per_round" example:"50000"`
+}
+
+type betRuleRequest struct {
+	GameID         string  `json:"game_id" binding:"max=100" example:"book-of-ra"`
+	ProviderID     string  `json:"provider_id" binding:"max=100" example:"novomatic"`
+	Currency       string  `json:"currency" binding:"omitempty,len=3" example:"EUR"`
+	MinStake       float64 `json:"min_stake" binding:"gte=0" example:"0.1"`
+	MaxStake       float64 `json:"max_stake" binding:"gte=0" example:"100"`
+	MaxWinPerRound float64 `json:"max_win_per_round" binding:"gte=0" example:"50000"`
+}
+
+func (r *betRuleRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		GameID         string  `json:"game_id"`
+		ProviderID     string  `json:"provider_id"`
+		Currency       string  `json:"currency"`
+		MinStake       float64 `json:"min_stake"`
+		MaxStake       float64 `json:"max_stake"`
+		MaxWinPerRound float64 `json:"max_win_per_round"`
+	})(r))
+}
+
+type HeldWinResponse struct {
+	TransactionID         uint       `json:"transaction_id" example:"123"`
+	UserID                uint       `json:"user_id" example:"5"`
+	Amount                float64    `json:"amount" example:"75000"`
+	ProviderTransactionID string     `json:"provider_transaction_id" example:"tx123"`
+	RoundID               string     `json:"round_id,omitempty" example:"round-42"`
+	GameID                string     `json:"game_id,omitempty" example:"book-of-ra"`
+	ProviderID            string     `json:"provider_id,omitempty" example:"novomatic"`
+	Status                string     `json:"status" example:"HELD"`
+	CreatedAt             time.Time  `json:"created_at"`
+	ReviewedBy            string     `json:"reviewed_by,omitempty" example:"admin"`
+	ReviewedAt            *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// ListBetRules godoc
+// @Summary List stake and payout rules
+// @Tags Admin
+// @Description List the configured rules. Empty game, provider or currency matches any value; the most specific matching rule applies.
+// @Produce json
+// @Success 200 {array} BetRuleResponse "Rules"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Security BearerAuth
+// @Router /admin/bet-rules [get]
+func (h *Handlers) ListBetRules(c *gin.Context) {
+	rules, err := h.BetRuleUseCase.ListRules(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]BetRuleResponse, 0, len(rules))
+	for _, r := range rules {
+		resp = append(resp, toBetRuleResponse(r))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// SaveBetRule godoc
+// @Summary Create or replace a stake and payout rule
+// @Tags Admin
+// @Description Set the stake range and maximum win per round for a game, provider and currency combination. A limit of 0 is not enforced.
+// @Accept json
+// @Produce json
+// @Param body body betRuleRequest true "Rule"
+// @Success 200 {object} BetRuleResponse "Saved rule"
+// @Failure 400 {object} BetErrorResponse "Invalid request"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Security BearerAuth
+// @Router /admin/bet-rules [put]
+func (h *Handlers) SaveBetRule(c *gin.Context) {
+	var req betRuleRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rule := &domain.BetRule{
+		GameID:         req.GameID,
+		ProviderID:     req.ProviderID,
+		Currency:       req.Currency,
+		MinStake:       req.MinStake,
+		MaxStake:       req.MaxStake,
+		MaxWinPerRound: req.MaxWinPerRound,
+	}
+	if err := h.BetRuleUseCase.SaveRule(c.Request.Context(), rule); err != nil {
+		var validation *usecase.ValidationError
+		if errors.As(err, &validation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not save rule"})
+		return
+	}
+	c.JSON(http.StatusOK, toBetRuleResponse(*rule))
+}
+
+// DeleteBetRule godoc
+// @Summary Delete a stake and payout rule
+// @Tags Admin
+// @Produce json
+// @Param id path int true "Rule ID"
+// @Success 204 "Deleted"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Failure 404 {object} BetErrorResponse "Not found"
+// @Security BearerAuth
+// @Router /admin/bet-rules/{id} [delete]
+func (h *Handlers) DeleteBetRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule id"})
+		return
+	}
+	if err := h.BetRuleUseCase.DeleteRule(c.Request.Context(), uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListHeldWins godoc
+// @Summary List wins held for review
+// @Tags Admin
+// @Description Wins that took a round over its maximum payout. They are not credited until approved.
+// @Produce json
+// @Success 200 {array} HeldWinResponse "Held wins"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Security BearerAuth
+// @Router /admin/held-wins [get]
+func (h *Handlers) ListHeldWins(c *gin.Context) {
+	txs, err := h.WalletUseCase.ListHeldWins(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]HeldWinResponse, 0, len(txs))
+	for _, tx := range txs {
+		resp = append(resp, toHeldWinResponse(tx))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ApproveHeldWin godoc
+// @Summary Approve a held win
+// @Tags Admin
+// @Description Credit the held win to the player's wallet
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} HeldWinResponse "Credited win"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Failure 404 {object} BetErrorResponse "Not found"
+// @Failure 409 {object} BetErrorResponse "Already reviewed"
+// @Security BearerAuth
+// @Router /admin/held-wins/{id}/approve [post]
+func (h *Handlers) ApproveHeldWin(c *gin.Context) {
+	h.reviewHeldWin(c, true)
+}
+
+// RejectHeldWin godoc
+// @Summary Reject a held win
+// @Tags Admin
+// @Description Close the held win without crediting it
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} HeldWinResponse "Rejected win"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Failure 404 {object} BetErrorResponse "Not found"
+// @Failure 409 {object} BetErrorResponse "Already reviewed"
+// @Security BearerAuth
+// @Router /admin/held-wins/{id}/reject [post]
+func (h *Handlers) RejectHeldWin(c *gin.Context) {
+	h.reviewHeldWin(c, false)
+}
+
+func (h *Handlers) reviewHeldWin(c *gin.Context, approve bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid transaction id"})
+		return
+	}
+	tx, err := h.WalletUseCase.ReviewHeldWin(c.Request.Context(), uint(id), approve, c.GetString("username"))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+		case err == usecase.ErrNotHeld:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case err == usecase.ErrWalletServiceUnavailable, err == usecase.ErrShuttingDown:
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, toHeldWinResponse(*tx))
+}
+
+func toBetRuleResponse(r domain.BetRule) BetRuleResponse {
+	return BetRuleResponse{
+		ID:             r.ID,
+		GameID:         r.GameID,
+		ProviderID:     r.ProviderID,
+		Currency:       r.Currency,
+		MinStake:       r.MinStake,
+		MaxStake:       r.MaxStake,
+		MaxWinPerRound: r.MaxWinPerRound,
+	}
+}
+
+func toHeldWinResponse(tx domain.Transaction) HeldWinResponse {
+	return HeldWinResponse{
+		TransactionID:         tx.ID,
+		UserID:                tx.UserID,
+		Amount:                tx.Amount,
+		ProviderTransactionID: tx.ProviderTxID,
+		RoundID:               tx.ProviderRoundID,
+		GameID:                tx.ProviderGameID,
+		ProviderID:            tx.ProviderID,
+		Status:                tx.Status,
+		CreatedAt:             tx.CreatedAt,
+		ReviewedBy:            tx.ReviewedBy,
+		ReviewedAt:            tx.ReviewedAt,
+	}
+}
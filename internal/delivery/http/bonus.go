@@ -0,0 +1,281 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type BonusCampaignResponse struct {
+	ID                 uint    `json:"id" example:"1"`
+	Name               string  `json:"name" example:"Welcome bonus"`
+	Amount             float64 `json:"amount" example:"50"`
+	WageringMultiplier float64 `json:"wagering_multiplier" example:"30"`
+	ValidDays          int     `json:"valid_days" example:"14"`
+	Active             bool    `json:"active" example:"true"`
+}
+
+type BonusResponse struct {
+	ID               uint       `json:"id" example:"7"`
+	UserID           uint       `json:"user_id" example:"5"`
+	CampaignID       uint       `json:"campaign_id" example:"1"`
+	CampaignName     string     `json:"campaign_name,omitempty" example:"Welcome bonus"`
+	Amount           float64    `json:"amount" example:"50"`
+	WageringRequired float64    `json:"wagering_required" example:"1500"`
+	Wagered          float64    `json:"wagered" example:"420"`
+	Status           string     `json:"status" example:"active"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	ClosedAt         *time.Time `json:"closed_at,omitempty"`
+	ReleasedAmount   float64    `json:"released_amount,omitempty" example:"0"`
+	ForfeitedAmount  float64    `json:"forfeited_amount,omitempty" example:"0"`
+}
+
+type createCampaignRequest struct {
+	Name               string  `json:"name" binding:"required,max=100" example:"Welcome bonus"`
+	Amount             float64 `json:"amount" binding:"required,gt=0" example:"50"`
+	WageringMultiplier float64 `json:"wagering_multiplier" binding:"gte=0" example:"30"`
+	ValidDays          int     `json:"valid_days" binding:"required,gt=0" example:"14"`
+}
+
+func (r *createCampaignRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		Name               string  `json:"name"`
+		Amount             float64 `json:"amount"`
+		WageringMultiplier float64 `json:"wagering_multiplier"`
+		ValidDays          int     `json:"valid_days"`
+	})(r))
+}
+
+type grantBonusRequest struct {
+	CampaignID uint `json:"campaign_id" binding:"required" example:"1"`
+}
+
+func (r *grantBonusRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		CampaignID uint `json:"campaign_id"`
+	})(r))
+}
+
+// ListBonuses godoc
+// @Summary List bonuses
+// @Tags Player
+// @Description List the player's bonuses with wagering progress. Stakes are paid from the real balance first unless configured otherwise, and wins on bonus-funded stakes are credited to the bonus balance in proportion.
+// @Produce json
+// @Success 200 {array} BonusResponse "Bonuses"
+// @Failure 401 {object} ProfileErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /bonuses [get]
+func (h *Handlers) ListBonuses(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	bonuses, err := h.BonusUseCase.ListBonuses(c.Request.Context(), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not load bonuses"})
+		return
+	}
+	resp := make([]BonusResponse, 0, len(bonuses))
+	for _, b := range bonuses {
+		resp = append(resp, toBonusResponse(b.PlayerBonus, b.Wagered))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ForfeitBonus godoc
+// @Summary Cancel a bonus
+// @Tags Player
+// @Description Cancel the player's active bonus. The remaining bonus balance is forfeited.
+// @Produce json
+// @Param id path int true "Bonus ID"
+// @Success 200 {object} BonusResponse "Forfeited bonus"
+// @Failure 401 {object} ProfileErrorResponse "Unauthorized"
+// @Failure 404 {object} ProfileErrorResponse "Not found"
+// @Failure 409 {object} ProfileErrorResponse "Bonus is not active"
+// @Security BearerAuth
+// @Router /bonuses/{id}/forfeit [post]
+func (h *Handlers) ForfeitBonus(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	h.forfeitBonus(c, userID.(uint))
+}
+
+// AdminForfeitBonus godoc
+// @Summary Cancel a player's bonus
+// @Tags Admin
+// @Description Cancel an active bonus, for example for bonus abuse. The remaining bonus balance is forfeited.
+// @Produce json
+// @Param id path int true "Bonus ID"
+// @Success 200 {object} BonusResponse "Forfeited bonus"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Failure 404 {object} BetErrorResponse "Not found"
+// @Failure 409 {object} BetErrorResponse "Bonus is not active"
+// @Security BearerAuth
+// @Router /admin/bonuses/{id}/forfeit [post]
+func (h *Handlers) AdminForfeitBonus(c *gin.Context) {
+	h.forfeitBonus(c, 0)
+}
+
+func (h *Handlers) forfeitBonus(c *gin.Context, userID uint) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bonus id"})
+		return
+	}
+	bonus, err := h.BonusUseCase.Forfeit(c.Request.Context(), uint(id), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "bonus not found"})
+		case err == usecase.ErrBonusNotActive:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not cancel bonus"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, toBonusResponse(*bonus, 0))
+}
+
+// ListBonusCampaigns godoc
+// @Summary List bonus campaigns
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} BonusCampaignResponse "Campaigns"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Security BearerAuth
+// @Router /admin/bonus-campaigns [get]
+func (h *Handlers) ListBonusCampaigns(c *gin.Context) {
+	campaigns, err := h.BonusUseCase.ListCampaigns(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]BonusCampaignResponse, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		resp = append(resp, toBonusCampaignResponse(campaign))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// CreateBonusCampaign godoc
+// @Summary Create a bonus campaign
+// @Tags Admin
+// @Description A granted bonus becomes real money once the player has staked wagering_multiplier times the bonus amount within valid_days; otherwise it is forfeited.
+// @Accept json
+// @Produce json
+// @Param body body createCampaignRequest true "Campaign"
+// @Success 201 {object} BonusCampaignResponse "Created campaign"
+// @Failure 400 {object} BetErrorResponse "Invalid request"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Security BearerAuth
+// @Router /admin/bonus-campaigns [post]
+func (h *Handlers) CreateBonusCampaign(c *gin.Context) {
+	var req createCampaignRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	campaign := &domain.BonusCampaign{
+		Name:               req.Name,
+		Amount:             req.Amount,
+		WageringMultiplier: req.WageringMultiplier,
+		ValidDays:          req.ValidDays,
+	}
+	if err := h.BonusUseCase.CreateCampaign(c.Request.Context(), campaign); err != nil {
+		var validation *usecase.ValidationError
+		if errors.As(err, &validation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create campaign"})
+		return
+	}
+	c.JSON(http.StatusCreated, toBonusCampaignResponse(*campaign))
+}
+
+// GrantBonus godoc
+// @Summary Grant a bonus
+// @Tags Admin
+// @Description Credit a campaign's bonus to a player. A player can hold one active bonus at a time.
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param body body grantBonusRequest true "Campaign"
+// @Success 201 {object} BonusResponse "Granted bonus"
+// @Failure 400 {object} BetErrorResponse "Invalid request"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Failure 404 {object} BetErrorResponse "User not found"
+// @Security BearerAuth
+// @Router /admin/users/{id}/bonuses [post]
+func (h *Handlers) GrantBonus(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	var req grantBonusRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	bonus, err := h.BonusUseCase.Grant(c.Request.Context(), uint(userID), req.CampaignID)
+	if err != nil {
+		var validation *usecase.ValidationError
+		switch {
+		case errors.As(err, &validation):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not grant bonus"})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, toBonusResponse(*bonus, 0))
+}
+
+func toBonusCampaignResponse(c domain.BonusCampaign) BonusCampaignResponse {
+	return BonusCampaignResponse{
+		ID:                 c.ID,
+		Name:               c.Name,
+		Amount:             c.Amount,
+		WageringMultiplier: c.WageringMultiplier,
+		ValidDays:          c.ValidDays,
+		Active:             c.Active,
+	}
+}
+
+func toBonusResponse(b domain.PlayerBonus, wagered float64) BonusResponse {
+	resp := BonusResponse{
+		ID:               b.ID,
+		UserID:           b.UserID,
+		CampaignID:       b.CampaignID,
+		Amount:           b.Amount,
+		WageringRequired: b.WageringRequired,
+		Wagered:          wagered,
+		Status:           strings.ToLower(b.Status),
+		ExpiresAt:        b.ExpiresAt,
+		ClosedAt:         b.ClosedAt,
+		ReleasedAmount:   b.ReleasedAmount,
+		ForfeitedAmount:  b.ForfeitedAmount,
+	}
+	if b.Campaign != nil {
+		resp.CampaignName = b.Campaign.Name
+	}
+	return resp
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BalanceResponse struct {
+	Currency string  `json:"currency" example:"EUR"`
+	Balance  float64 `json:"balance" example:"50.0"`
+}
+
+type openBalanceRequest struct {
+	Currency string `json:"currency" binding:"required,len=3" example:"EUR"`
+}
+
+func (r *openBalanceRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		Currency string `json:"currency"`
+	})(r))
+}
+
+// CurrencyTotalResponse is the activity booked in one currency, as booked
+// and in the base currency. Unconverted transactions had no base rate and
+// are missing from the base amounts.
+type CurrencyTotalResponse struct {
+	Currency     string  `json:"currency" example:"EUR"`
+	Wagered      float64 `json:"wagered" example:"920.0"`
+	Won          float64 `json:"won" example:"828.0"`
+	BaseCurrency string  `json:"base_currency" example:"USD"`
+	BaseWagered  float64 `json:"base_wagered" example:"1000.0"`
+	BaseWon      float64 `json:"base_won" example:"900.0"`
+	Transactions int64   `json:"transactions" example:"120"`
+	Unconverted  int64   `json:"unconverted" example:"0"`
+}
+
+type CurrencyReportResponse struct {
+	From         time.Time               `json:"from"`
+	To           time.Time               `json:"to"`
+	BaseCurrency string                  `json:"base_currency" example:"USD"`
+	Totals       []CurrencyTotalResponse `json:"totals"`
+}
+
+// OpenBalance godoc
+// @Summary Open a currency balance
+// @Tags Player
+// @Description Add an empty balance in another currency. Stakes in that currency are then booked on it without conversion.
+// @Accept json
+// @Produce json
+// @Param body body openBalanceRequest true "Currency"
+// @Success 201 {object} BalanceResponse "Opened"
+// @Failure 400 {object} ProfileErrorResponse "Invalid or unsupported currency"
+// @Failure 401 {object} ProfileErrorResponse "Unauthorized"
+// @Failure 409 {object} ProfileErrorResponse "Balance already held"
+// @Security BearerAuth
+// @Router /balances [post]
+func (h *Handlers) OpenBalance(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req openBalanceRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	balance, err := h.CurrencyUseCase.OpenBalance(c.Request.Context(), userID.(uint), req.Currency)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrCurrencyUnsupported):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrBalanceExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not open balance"})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, toBalanceResponse(*balance))
+}
+
+// CurrencyReport godoc
+// @Summary Activity per currency
+// @Tags Admin
+// @Description Stakes and wins per balance currency in [from, to), as booked and converted to the base currency at the rate stored on each transaction
+// @Produce json
+// @Param from query string true "Start (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string true "End, exclusive (RFC 3339 or YYYY-MM-DD)"
+// @Success 200 {object} CurrencyReportResponse "Report"
+// @Failure 400 {object} BetErrorResponse "Invalid period"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Security BearerAuth
+// @Router /admin/reports/currencies [get]
+func (h *Handlers) CurrencyReport(c *gin.Context) {
+	from, err := parseReportTime(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+		return
+	}
+	to, err := parseReportTime(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+		return
+	}
+	report, err := h.CurrencyUseCase.Report(c.Request.Context(), from, to)
+	if err != nil {
+		var validation *usecase.ValidationError
+		if errors.As(err, &validation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not build report"})
+		return
+	}
+	resp := CurrencyReportResponse{From: report.From, To: report.To, BaseCurrency: report.BaseCurrency, Totals: make([]CurrencyTotalResponse, 0, len(report.Totals))}
+	for _, t := range report.Totals {
+		resp.Totals = append(resp.Totals, CurrencyTotalResponse{
+			Currency:     t.Currency,
+			Wagered:      t.Wagered,
+			Won:          t.Won,
+			BaseCurrency: t.BaseCurrency,
+			BaseWagered:  t.BaseWagered,
+			BaseWon:      t.BaseWon,
+			Transactions: t.Transactions,
+			Unconverted:  t.Unconverted,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// parseReportTime accepts an RFC 3339 timestamp or a UTC date.
+func parseReportTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, v)
+}
+
+func toBalanceResponse(b domain.PlayerBalance) BalanceResponse {
+	return BalanceResponse{Currency: b.Currency, Balance: b.Balance}
+}
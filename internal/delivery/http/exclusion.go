@@ -0,0 +1,185 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ExclusionResponse struct {
+	ID        uint       `json:"id" example:"1"`
+	UserID    uint       `json:"user_id" example:"5"`
+	Username  string     `json:"username,omitempty" example:"testuser1"`
+	Type      string     `json:"type" example:"timeout"`
+	StartsAt  time.Time  `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	Permanent bool       `json:"permanent" example:"false"`
+	Reason    string     `json:"reason,omitempty" example:"player request"`
+	CreatedBy string     `json:"created_by" example:"testuser1"`
+}
+
+type ExclusionStatusResponse struct {
+	Excluded  bool               `json:"excluded" example:"true"`
+	Exclusion *ExclusionResponse `json:"exclusion,omitempty"`
+}
+
+type excludeRequest struct {
+	Type      string `json:"type" binding:"required,oneof=timeout self_exclusion" example:"timeout"`
+	Days      int    `json:"days" binding:"gte=0" example:"7"`
+	Permanent bool   `json:"permanent" example:"false"`
+	Reason    string `json:"reason" binding:"max=500" example:"taking a break"`
+}
+
+func (r *excludeRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		Type      string `json:"type"`
+		Days      int    `json:"days"`
+		Permanent bool   `json:"permanent"`
+		Reason    string `json:"reason"`
+	})(r))
+}
+
+// GetExclusion godoc
+// @Summary Get exclusion status
+// @Tags Player
+// @Description Show whether the player is self-excluded or on a time-out
+// @Produce json
+// @Success 200 {object} ExclusionStatusResponse "Exclusion status"
+// @Failure 401 {object} ProfileErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /exclusion [get]
+func (h *Handlers) GetExclusion(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	exclusion, err := h.ResponsibleGamingUseCase.ActiveExclusion(c.Request.Context(), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not load exclusion"})
+		return
+	}
+	if exclusion == nil {
+		c.JSON(http.StatusOK, ExclusionStatusResponse{})
+		return
+	}
+	resp := toExclusionResponse(*exclusion)
+	c.JSON(http.StatusOK, ExclusionStatusResponse{Excluded: true, Exclusion: &resp})
+}
+
+// Exclude godoc
+// @Summary Self-exclude or take a time-out
+// @Tags Player
+// @Description Block all betting for 1-42 days (timeout) or at least 180 days or permanently (self_exclusion). It cannot be shortened or lifted; open bets still settle.
+// @Accept json
+// @Produce json
+// @Param body body excludeRequest true "Exclusion"
+// @Success 201 {object} ExclusionResponse "Exclusion started"
+// @Failure 400 {object} ProfileErrorResponse "Invalid request"
+// @Failure 401 {object} ProfileErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /exclusion [post]
+func (h *Handlers) Exclude(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	h.exclude(c, userID.(uint))
+}
+
+// ExcludeUser godoc
+// @Summary Exclude a player
+// @Tags Admin
+// @Description Start a time-out or self-exclusion on behalf of a player, for example at their request through support
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param body body excludeRequest true "Exclusion"
+// @Success 201 {object} ExclusionResponse "Exclusion started"
+// @Failure 400 {object} BetErrorResponse "Invalid request"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Failure 404 {object} BetErrorResponse "User not found"
+// @Security BearerAuth
+// @Router /admin/users/{id}/exclusion [post]
+func (h *Handlers) ExcludeUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	h.exclude(c, uint(id))
+}
+
+func (h *Handlers) exclude(c *gin.Context, userID uint) {
+	var req excludeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	exclusion, err := h.ResponsibleGamingUseCase.Exclude(c.Request.Context(), userID, usecase.ExclusionInput{
+		Type:      req.Type,
+		Days:      req.Days,
+		Permanent: req.Permanent,
+		Reason:    req.Reason,
+		CreatedBy: c.GetString("username"),
+	})
+	if err != nil {
+		var validation *usecase.ValidationError
+		switch {
+		case errors.As(err, &validation):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not start exclusion"})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, toExclusionResponse(*exclusion))
+}
+
+// ListExclusions godoc
+// @Summary List excluded accounts
+// @Tags Admin
+// @Description Report of players currently self-excluded or on a time-out
+// @Produce json
+// @Success 200 {array} ExclusionResponse "Active exclusions"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Security BearerAuth
+// @Router /admin/exclusions [get]
+func (h *Handlers) ListExclusions(c *gin.Context) {
+	exclusions, err := h.ResponsibleGamingUseCase.ListExclusions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]ExclusionResponse, 0, len(exclusions))
+	for _, e := range exclusions {
+		resp = append(resp, toExclusionResponse(e))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func toExclusionResponse(e domain.Exclusion) ExclusionResponse {
+	resp := ExclusionResponse{
+		ID:        e.ID,
+		UserID:    e.UserID,
+		Type:      strings.ToLower(e.Type),
+		StartsAt:  e.StartsAt,
+		EndsAt:    e.EndsAt,
+		Permanent: e.EndsAt == nil,
+		Reason:    e.Reason,
+		CreatedBy: e.CreatedBy,
+	}
+	if e.User != nil {
+		resp.Username = e.User.Username
+	}
+	return resp
+}
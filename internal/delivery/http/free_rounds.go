@@ -0,0 +1,199 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type FreeRoundGrantResponse struct {
+	ID        uint       `json:"id" example:"3"`
+	UserID    uint       `json:"user_id" example:"5"`
+	GameID    string     `json:"game_id" example:"book-of-ra"`
+	Count     int        `json:"count" example:"20"`
+	Remaining int        `json:"remaining" example:"12"`
+	BetValue  float64    `json:"bet_value" example:"0.2"`
+	WinTo     string     `json:"win_to" example:"real"`
+	Status    string     `json:"status" example:"active"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	CreatedBy string     `json:"created_by,omitempty" example:"admin"`
+	RevokedBy string     `json:"revoked_by,omitempty" example:"admin"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+type grantFreeRoundsRequest struct {
+	GameID    string  `json:"game_id" binding:"required,max=100" example:"book-of-ra"`
+	Count     int     `json:"count" binding:"required,gt=0,lte=1000" example:"20"`
+	BetValue  float64 `json:"bet_value" binding:"required,gt=0" example:"0.2"`
+	ValidDays int     `json:"valid_days" binding:"required,gt=0,lte=365" example:"7"`
+	WinTo     string  `json:"win_to" binding:"required,oneof=real bonus" example:"real"`
+}
+
+func (r *grantFreeRoundsRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		GameID    string  `json:"game_id"`
+		Count     int     `json:"count"`
+		BetValue  float64 `json:"bet_value"`
+		ValidDays int     `json:"valid_days"`
+		WinTo     string  `json:"win_to"`
+	})(r))
+}
+
+// ListFreeRounds godoc
+// @Summary List free rounds
+// @Tags Player
+// @Description List the player's free-round grants
+// @Produce json
+// @Success 200 {array} FreeRoundGrantResponse "Free-round grants"
+// @Failure 401 {object} ProfileErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /free-rounds [get]
+func (h *Handlers) ListFreeRounds(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	h.listFreeRounds(c, userID.(uint))
+}
+
+// ListUserFreeRounds godoc
+// @Summary List a player's free rounds
+// @Tags Admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} FreeRoundGrantResponse "Free-round grants"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Security BearerAuth
+// @Router /admin/users/{id}/free-rounds [get]
+func (h *Handlers) ListUserFreeRounds(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	h.listFreeRounds(c, uint(id))
+}
+
+func (h *Handlers) listFreeRounds(c *gin.Context, userID uint) {
+	grants, err := h.FreeRoundUseCase.ListGrants(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not load free rounds"})
+		return
+	}
+	resp := make([]FreeRoundGrantResponse, 0, len(grants))
+	for _, g := range grants {
+		resp = append(resp, toFreeRoundGrantResponse(g))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GrantFreeRounds godoc
+// @Summary Grant free rounds
+// @Tags Admin
+// @Description Give a player zero-cost rounds on a game. The provider plays them with free_round set on withdraw; only wins are deposited, to the real balance or to the player's active bonus.
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param body body grantFreeRoundsRequest true "Free rounds"
+// @Success 201 {object} FreeRoundGrantResponse "Granted"
+// @Failure 400 {object} BetErrorResponse "Invalid request"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Failure 404 {object} BetErrorResponse "User not found"
+// @Security BearerAuth
+// @Router /admin/users/{id}/free-rounds [post]
+func (h *Handlers) GrantFreeRounds(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	var req grantFreeRoundsRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	grant, err := h.FreeRoundUseCase.Grant(c.Request.Context(), usecase.FreeRoundInput{
+		UserID:    uint(id),
+		GameID:    req.GameID,
+		Count:     req.Count,
+		BetValue:  req.BetValue,
+		ValidDays: req.ValidDays,
+		WinTo:     req.WinTo,
+		CreatedBy: c.GetString("username"),
+	})
+	if err != nil {
+		var validation *usecase.ValidationError
+		switch {
+		case errors.As(err, &validation):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not grant free rounds"})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, toFreeRoundGrantResponse(*grant))
+}
+
+// RevokeFreeRounds godoc
+// @Summary Revoke free rounds
+// @Tags Admin
+// @Description Stop the remaining rounds of a grant. Rounds already played still settle.
+// @Produce json
+// @Param id path int true "Grant ID"
+// @Success 200 {object} FreeRoundGrantResponse "Revoked"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Failure 404 {object} BetErrorResponse "Not found"
+// @Failure 409 {object} BetErrorResponse "Already revoked"
+// @Security BearerAuth
+// @Router /admin/free-rounds/{id}/revoke [post]
+func (h *Handlers) RevokeFreeRounds(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid grant id"})
+		return
+	}
+	grant, err := h.FreeRoundUseCase.Revoke(c.Request.Context(), uint(id), c.GetString("username"))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "grant not found"})
+		case err == usecase.ErrFreeRoundsRevoked:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not revoke free rounds"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, toFreeRoundGrantResponse(*grant))
+}
+
+func toFreeRoundGrantResponse(g domain.FreeRoundGrant) FreeRoundGrantResponse {
+	return FreeRoundGrantResponse{
+		ID:        g.ID,
+		UserID:    g.UserID,
+		GameID:    g.GameID,
+		Count:     g.Count,
+		Remaining: g.Remaining,
+		BetValue:  g.BetValue,
+		WinTo:     strings.ToLower(g.WinTo),
+		Status:    strings.ToLower(g.Status),
+		ExpiresAt: g.ExpiresAt,
+		CreatedBy: g.CreatedBy,
+		RevokedBy: g.RevokedBy,
+		RevokedAt: g.RevokedAt,
+	}
+}
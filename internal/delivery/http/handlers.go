@@ -1,6 +1,7 @@
 package http
 
 import (
+	"gameintegrationapi/internal/domain"
 	"gameintegrationapi/internal/infrastructure"
 	"gameintegrationapi/internal/usecase"
 	"net/http"
@@ -8,23 +9,90 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-var jwtKey = []byte("your-secret-key") // TODO: Move to config
-
 type Handlers struct {
-	AuthUseCase   usecase.AuthUseCase
-	PlayerUseCase usecase.PlayerUseCase
-	WalletUseCase usecase.WalletUseCase
+	AuthUseCase              usecase.AuthUseCase
+	AccountUseCase           usecase.AccountUseCase
+	TwoFactorUseCase         usecase.TwoFactorUseCase
+	PlayerUseCase            usecase.PlayerUseCase
+	WalletUseCase            usecase.WalletUseCase
+	ResponsibleGamingUseCase usecase.ResponsibleGamingUseCase
+	BetRuleUseCase           usecase.BetRuleUseCase
+	BonusUseCase             usecase.BonusUseCase
+	FreeRoundUseCase         usecase.FreeRoundUseCase
+	JackpotUseCase           usecase.JackpotUseCase
+	CurrencyUseCase          usecase.CurrencyUseCase
+	ReconciliationUseCase    usecase.ReconciliationUseCase
+	RevenueUseCase           usecase.RevenueUseCase
+	StatementUseCase         usecase.StatementUseCase
+	RoundUseCase             usecase.RoundUseCase
+	PlatformLogUseCase       usecase.PlatformLogUseCase
+	HealthChecker            *infrastructure.HealthChecker
+	RateLimiter              *infrastructure.RateLimiter
+	JWTKeys                  *infrastructure.JWTKeys
+	// ProviderKeys holds the key of each game provider by provider ID.
+	ProviderKeys map[string]string
+	// CashierKey authenticates the cashier's deposit checks.
+	CashierKey string
 }
 
-func NewHandlers(authUseCase usecase.AuthUseCase, playerUseCase usecase.PlayerUseCase, walletUseCase usecase.WalletUseCase) *Handlers {
+func NewHandlers(authUseCase usecase.AuthUseCase, accountUseCase usecase.AccountUseCase, twoFactorUseCase usecase.TwoFactorUseCase, playerUseCase usecase.PlayerUseCase, walletUseCase usecase.WalletUseCase, responsibleGamingUseCase usecase.ResponsibleGamingUseCase, betRuleUseCase usecase.BetRuleUseCase, bonusUseCase usecase.BonusUseCase, freeRoundUseCase usecase.FreeRoundUseCase, jackpotUseCase usecase.JackpotUseCase, currencyUseCase usecase.CurrencyUseCase, reconciliationUseCase usecase.ReconciliationUseCase, revenueUseCase usecase.RevenueUseCase, statementUseCase usecase.StatementUseCase, roundUseCase usecase.RoundUseCase, platformLogUseCase usecase.PlatformLogUseCase, healthChecker *infrastructure.HealthChecker, rateLimiter *infrastructure.RateLimiter, jwtKeys *infrastructure.JWTKeys, providerKeys map[string]string, cashierKey string) *Handlers {
 	return &Handlers{
-		AuthUseCase:   authUseCase,
-		PlayerUseCase: playerUseCase,
-		WalletUseCase: walletUseCase,
+		AuthUseCase:              authUseCase,
+		AccountUseCase:           accountUseCase,
+		TwoFactorUseCase:         twoFactorUseCase,
+		PlayerUseCase:            playerUseCase,
+		WalletUseCase:            walletUseCase,
+		ResponsibleGamingUseCase: responsibleGamingUseCase,
+		BetRuleUseCase:           betRuleUseCase,
+		BonusUseCase:             bonusUseCase,
+		FreeRoundUseCase:         freeRoundUseCase,
+		JackpotUseCase:           jackpotUseCase,
+		CurrencyUseCase:          currencyUseCase,
+		ReconciliationUseCase:    reconciliationUseCase,
+		RevenueUseCase:           revenueUseCase,
+		StatementUseCase:         statementUseCase,
+		RoundUseCase:             roundUseCase,
+		PlatformLogUseCase:       platformLogUseCase,
+		HealthChecker:            healthChecker,
+		RateLimiter:              rateLimiter,
+		JWTKeys:                  jwtKeys,
+		ProviderKeys:             providerKeys,
+		CashierKey:               cashierKey,
 	}
 }
 
+// AuthMiddleware authenticates the request and rejects players who are
+// self-excluded or on a time-out.
 func (h *Handlers) AuthMiddleware() gin.HandlerFunc {
+	return h.authMiddleware(true)
+}
+
+// AuthMiddlewareAllowExcluded authenticates the request but lets excluded
+// players through. It is for settlements and cancels of bets placed before
+// the exclusion, and for account pages.
+func (h *Handlers) AuthMiddlewareAllowExcluded() gin.HandlerFunc {
+	return h.authMiddleware(false)
+}
+
+// rejectExcluded answers and aborts the request if the player is excluded
+// from play, and reports whether it did.
+func (h *Handlers) rejectExcluded(c *gin.Context, userID uint) bool {
+	exclusion, err := h.ResponsibleGamingUseCase.ActiveExclusion(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not check account status"})
+		c.Abort()
+		return true
+	}
+	if exclusion != nil {
+		err := &usecase.ExcludedError{Until: exclusion.EndsAt}
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": usecase.PlayerExcludedCode})
+		c.Abort()
+		return true
+	}
+	return false
+}
+
+func (h *Handlers) authMiddleware(blockExcluded bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
 		if tokenString == "" {
@@ -34,15 +102,40 @@ func (h *Handlers) AuthMiddleware() gin.HandlerFunc {
 		}
 		tokenString = tokenString[len("Bearer "):]
 
-		claims, err := infrastructure.ParseJWT(tokenString, string(jwtKey))
-		if err != nil {
+		claims, err := h.JWTKeys.ParseJWT(tokenString)
+		// Purpose-bound tokens such as 2FA challenges are not access tokens.
+		if err != nil || claims.Purpose != "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			c.Abort()
 			return
 		}
 
+		if blockExcluded && h.rejectExcluded(c, claims.UserID) {
+			return
+		}
+
 		c.Set("userID", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Set("role", claims.Role)
+		c.Set("mfa", claims.MFA)
+		c.Next()
+	}
+}
+
+// AdminMiddleware must run after AuthMiddleware. Admins must have signed in
+// with a second factor.
+func (h *Handlers) AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != domain.RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "admin role required"})
+			c.Abort()
+			return
+		}
+		if !c.GetBool("mfa") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "two-factor authentication required"})
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthResponse struct {
+	Status string `json:"status" example:"ok"`
+}
+
+// Healthz godoc
+// @Summary Liveness probe
+// @Tags Health
+// @Description Report that the process is running. Does not check dependencies.
+// @Produce json
+// @Success 200 {object} HealthResponse "Alive"
+// @Router /healthz [get]
+func (h *Handlers) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+// Readyz godoc
+// @Summary Readiness probe
+// @Tags Health
+// @Description Check the database and wallet service. Results are cached for a few seconds.
+// @Produce json
+// @Success 200 {object} infrastructure.ReadinessReport "Ready"
+// @Failure 503 {object} infrastructure.ReadinessReport "Not ready"
+// @Router /readyz [get]
+func (h *Handlers) Readyz(c *gin.Context) {
+	report := h.HealthChecker.Ready(c.Request.Context())
+	if !report.Ready {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// Status godoc
+// @Summary Detailed service status
+// @Tags Health
+// @Description Report build version, migration version, dependency latencies and wallet circuit-breaker state.
+// @Produce json
+// @Success 200 {object} infrastructure.StatusReport "Status"
+// @Router /status [get]
+func (h *Handlers) Status(c *gin.Context) {
+	c.JSON(http.StatusOK, h.HealthChecker.Status(c.Request.Context()))
+}
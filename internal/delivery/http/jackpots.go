@@ -0,0 +1,165 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JackpotResponse struct {
+	ID              uint       `json:"id" example:"1"`
+	Name            string     `json:"name" example:"Mega Fruits"`
+	GameGroup       string     `json:"game_group" example:"fruits"`
+	Currency        string     `json:"currency" example:"USD"`
+	ContributionPct float64    `json:"contribution_pct" example:"1.5"`
+	SeedAmount      float64    `json:"seed_amount" example:"10000"`
+	Amount          float64    `json:"amount" example:"12345.67"`
+	LastWinAmount   float64    `json:"last_win_amount,omitempty" example:"25000"`
+	LastWonAt       *time.Time `json:"last_won_at,omitempty"`
+}
+
+type createJackpotRequest struct {
+	Name            string  `json:"name" binding:"required,max=100" example:"Mega Fruits"`
+	GameGroup       string  `json:"game_group" binding:"required,max=100" example:"fruits"`
+	Currency        string  `json:"currency" binding:"required,len=3" example:"USD"`
+	ContributionPct float64 `json:"contribution_pct" binding:"required,gt=0,lte=100" example:"1.5"`
+	SeedAmount      float64 `json:"seed_amount" binding:"gte=0" example:"10000"`
+}
+
+func (r *createJackpotRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		Name            string  `json:"name"`
+		GameGroup       string  `json:"game_group"`
+		Currency        string  `json:"currency"`
+		ContributionPct float64 `json:"contribution_pct"`
+		SeedAmount      float64 `json:"seed_amount"`
+	})(r))
+}
+
+type assignJackpotGameRequest struct {
+	GameID    string `json:"game_id" binding:"required,max=100" example:"fruit-party"`
+	GameGroup string `json:"game_group" binding:"required,max=100" example:"fruits"`
+}
+
+func (r *assignJackpotGameRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		GameID    string `json:"game_id"`
+		GameGroup string `json:"game_group"`
+	})(r))
+}
+
+// ListJackpots godoc
+// @Summary List jackpots
+// @Tags Jackpot
+// @Description Live values of the progressive jackpot pools
+// @Produce json
+// @Param currency query string false "Currency"
+// @Param game_group query string false "Game group"
+// @Success 200 {array} JackpotResponse "Jackpot pools"
+// @Router /jackpots [get]
+func (h *Handlers) ListJackpots(c *gin.Context) {
+	pools, err := h.JackpotUseCase.ListPools(c.Request.Context(), c.Query("currency"), c.Query("game_group"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not load jackpots"})
+		return
+	}
+	resp := make([]JackpotResponse, 0, len(pools))
+	for _, p := range pools {
+		resp = append(resp, toJackpotResponse(p))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// CreateJackpot godoc
+// @Summary Create a jackpot pool
+// @Tags Admin
+// @Description Create a progressive pool for a game group in one currency. Every stake on the group's games adds contribution_pct percent to it; a win pays the pool and resets it to seed_amount.
+// @Accept json
+// @Produce json
+// @Param body body createJackpotRequest true "Jackpot pool"
+// @Success 201 {object} JackpotResponse "Created"
+// @Failure 400 {object} BetErrorResponse "Invalid request"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Security BearerAuth
+// @Router /admin/jackpots [post]
+func (h *Handlers) CreateJackpot(c *gin.Context) {
+	var req createJackpotRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	pool := &domain.JackpotPool{
+		Name:            req.Name,
+		GameGroup:       req.GameGroup,
+		Currency:        req.Currency,
+		ContributionPct: req.ContributionPct,
+		SeedAmount:      req.SeedAmount,
+	}
+	if err := h.JackpotUseCase.CreatePool(c.Request.Context(), pool); err != nil {
+		var validation *usecase.ValidationError
+		if errors.As(err, &validation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create jackpot"})
+		return
+	}
+	c.JSON(http.StatusCreated, toJackpotResponse(*pool))
+}
+
+// AssignJackpotGame godoc
+// @Summary Put a game in a jackpot group
+// @Tags Admin
+// @Description Stakes on the game contribute to every pool of the group in the player's currency. A game belongs to one group; assigning it again moves it.
+// @Accept json
+// @Produce json
+// @Param body body assignJackpotGameRequest true "Game and group"
+// @Success 204 "Assigned"
+// @Failure 400 {object} BetErrorResponse "Invalid request"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Security BearerAuth
+// @Router /admin/jackpot-games [put]
+func (h *Handlers) AssignJackpotGame(c *gin.Context) {
+	var req assignJackpotGameRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.JackpotUseCase.AssignGame(c.Request.Context(), req.GameID, req.GameGroup); err != nil {
+		var validation *usecase.ValidationError
+		if errors.As(err, &validation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not assign game"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func toJackpotResponse(p domain.JackpotPool) JackpotResponse {
+	return JackpotResponse{
+		ID:              p.ID,
+		Name:            p.Name,
+		GameGroup:       p.GameGroup,
+		Currency:        p.Currency,
+		ContributionPct: p.ContributionPct,
+		SeedAmount:      p.SeedAmount,
+		Amount:          p.Amount,
+		LastWinAmount:   p.LastWinAmount,
+		LastWonAt:       p.LastWonAt,
+	}
+}
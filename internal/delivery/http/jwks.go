@@ -0,0 +1,19 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Tags Auth
+// @Description Public keys for verifying player tokens. Tokens name their key in the kid header; retired keys stay listed until their tokens expire.
+// @Produce json
+// @Success 200 {object} infrastructure.JWKSet "Key set"
+// @Router /.well-known/jwks.json [get]
+func (h *Handlers) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.JWTKeys.JWKS())
+}
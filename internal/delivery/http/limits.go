@@ -0,0 +1,216 @@
+package http
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LimitResponse struct {
+	Type          string     `json:"type" example:"loss"`
+	Period        string     `json:"period" example:"daily"`
+	Amount        float64    `json:"amount" example:"100"`
+	Used          float64    `json:"used" example:"35.5"`
+	PendingAmount *float64   `json:"pending_amount,omitempty" example:"200"`
+	PendingFrom   *time.Time `json:"pending_from,omitempty"`
+}
+
+type LimitsResponse struct {
+	Limits []LimitResponse `json:"limits"`
+}
+
+type limitItem struct {
+	Type   string  `json:"type" binding:"required,oneof=loss wager deposit" example:"loss"`
+	Period string  `json:"period" binding:"required,oneof=daily weekly monthly" example:"daily"`
+	Amount float64 `json:"amount" binding:"gte=0" example:"100"`
+}
+
+func (r *limitItem) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		Type   string  `json:"type"`
+		Period string  `json:"period"`
+		Amount float64 `json:"amount"`
+	})(r))
+}
+
+type setLimitsRequest struct {
+	Limits []limitItem `json:"limits" binding:"required,min=1,dive"`
+}
+
+func (r *setLimitsRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		Limits []limitItem `json:"limits"`
+	})(r))
+}
+
+// GetLimits godoc
+// @Summary Get responsible-gaming limits
+// @Tags Player
+// @Description List the player's loss, wager and deposit limits with usage over each rolling window (24 hours, 7 days, 30 days)
+// @Produce json
+// @Success 200 {object} LimitsResponse "Limits"
+// @Failure 401 {object} ProfileErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /limits [get]
+func (h *Handlers) GetLimits(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	statuses, err := h.ResponsibleGamingUseCase.GetLimits(c.Request.Context(), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not load limits"})
+		return
+	}
+	c.JSON(http.StatusOK, toLimitsResponse(statuses))
+}
+
+// SetLimits godoc
+// @Summary Set responsible-gaming limits
+// @Tags Player
+// @Description Set loss, wager or deposit limits. Lower limits apply immediately; higher limits and removals (amount 0) apply after the cooling-off period.
+// @Accept json
+// @Produce json
+// @Param body body setLimitsRequest true "Limits to set"
+// @Success 200 {object} LimitsResponse "Limits"
+// @Failure 400 {object} ProfileErrorResponse "Invalid request"
+// @Failure 401 {object} ProfileErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /limits [put]
+func (h *Handlers) SetLimits(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req setLimitsRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	inputs := make([]usecase.LimitInput, 0, len(req.Limits))
+	for _, l := range req.Limits {
+		inputs = append(inputs, usecase.LimitInput{Type: l.Type, Period: l.Period, Amount: l.Amount})
+	}
+	statuses, err := h.ResponsibleGamingUseCase.SetLimits(c.Request.Context(), userID.(uint), inputs)
+	if err != nil {
+		var validation *usecase.ValidationError
+		if errors.As(err, &validation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not save limits"})
+		return
+	}
+	c.JSON(http.StatusOK, toLimitsResponse(statuses))
+}
+
+// CashierKeyHeader carries the cashier's key on deposit checks.
+const CashierKeyHeader = "X-Cashier-Key"
+
+type authorizeDepositRequest struct {
+	WalletID  string  `json:"wallet_id" binding:"required" example:"34633089486"`
+	Amount    float64 `json:"amount" binding:"required,gt=0" example:"50"`
+	Currency  string  `json:"currency" binding:"required" example:"USD"`
+	Reference string  `json:"reference" binding:"required" example:"dep-8c1f2a"`
+}
+
+func (r *authorizeDepositRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		WalletID  string  `json:"wallet_id"`
+		Amount    float64 `json:"amount"`
+		Currency  string  `json:"currency"`
+		Reference string  `json:"reference"`
+	})(r))
+}
+
+type DepositAuthorizationResponse struct {
+	Reference string  `json:"reference" example:"dep-8c1f2a"`
+	Amount    float64 `json:"amount" example:"50"`
+}
+
+// CashierAuth admits requests carrying the configured cashier key. All are
+// refused while no key is configured.
+func (h *Handlers) CashierAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(CashierKeyHeader)
+		if h.CashierKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(h.CashierKey)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid cashier credentials"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// AuthorizeDeposit godoc
+// @Summary Authorize a player deposit
+// @Tags Cashier
+// @Description Called by the cashier before the wallet credits a deposit. Approves and records it, or refuses it with RG_LIMIT_EXCEEDED when it would break the player's deposit limit, or PLAYER_EXCLUDED. Repeating a reference approves it again without counting it twice.
+// @Accept json
+// @Produce json
+// @Param X-Cashier-Key header string true "Cashier key"
+// @Param body body authorizeDepositRequest true "Deposit"
+// @Success 200 {object} DepositAuthorizationResponse "Approved"
+// @Failure 400 {object} BetErrorResponse "Invalid request"
+// @Failure 401 {object} BetErrorResponse "Invalid cashier key"
+// @Failure 403 {object} BetErrorResponse "Limit exceeded or player excluded"
+// @Failure 404 {object} BetErrorResponse "Unknown wallet"
+// @Router /cashier/deposits [post]
+func (h *Handlers) AuthorizeDeposit(c *gin.Context) {
+	var req authorizeDepositRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	deposit, err := h.ResponsibleGamingUseCase.AuthorizeDeposit(c.Request.Context(), usecase.DepositAuthorization{
+		WalletID:  req.WalletID,
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+		Reference: req.Reference,
+	})
+	if err != nil {
+		var validation *usecase.ValidationError
+		var limitErr *usecase.LimitExceededError
+		var excludedErr *usecase.ExcludedError
+		switch {
+		case errors.As(err, &validation):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.As(err, &limitErr):
+			c.JSON(http.StatusForbidden, BetErrorResponse{Error: err.Error(), Code: usecase.LimitExceededCode})
+		case errors.As(err, &excludedErr):
+			c.JSON(http.StatusForbidden, BetErrorResponse{Error: err.Error(), Code: usecase.PlayerExcludedCode})
+		case errors.Is(err, usecase.ErrPlayerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not check deposit"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, DepositAuthorizationResponse{Reference: deposit.Reference, Amount: deposit.Amount})
+}
+
+func toLimitsResponse(statuses []usecase.LimitStatus) LimitsResponse {
+	resp := LimitsResponse{Limits: make([]LimitResponse, 0, len(statuses))}
+	for _, s := range statuses {
+		l := LimitResponse{
+			Type:   strings.ToLower(s.Type),
+			Period: strings.ToLower(s.Period),
+			Amount: s.Amount,
+			Used:   s.Used,
+		}
+		if s.PendingFrom != nil {
+			pending := s.PendingAmount
+			l.PendingAmount, l.PendingFrom = &pending, s.PendingFrom
+		}
+		resp.Limits = append(resp.Limits, l)
+	}
+	return resp
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// platformLogMaxCapture bounds the bodies buffered for the platform log.
+// Larger ones are still served; the log keeps only a note of their size.
+const platformLogMaxCapture = 1 << 20
+
+// capturingWriter copies the response body as it is written.
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *capturingWriter) capture(b []byte) {
+	if w.body.Len() <= platformLogMaxCapture {
+		w.body.Write(b)
+	}
+}
+
+// RecordExchange keeps the provider's request, our response, the wallet
+// calls made in between and their timings on the transactions the call
+// records. Storing them never fails the call.
+func (h *Handlers) RecordExchange() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.PlatformLogUseCase == nil {
+			c.Next()
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, platformLogMaxCapture+1))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "could not read request"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+		ctx, exchange := h.PlatformLogUseCase.Start(c.Request.Context(), c.Request.Method, c.Request.URL.Path, body)
+		if exchange == nil {
+			c.Next()
+			return
+		}
+		c.Request = c.Request.WithContext(ctx)
+		w := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		userID, ok := c.Get("userID")
+		if !ok {
+			return
+		}
+		if err := h.PlatformLogUseCase.Finish(context.WithoutCancel(ctx), userID.(uint), exchange, w.Status(), w.body.Bytes()); err != nil {
+			log.Printf("RecordExchange: could not store exchange of user %d: %v", userID, err)
+		}
+	}
+}
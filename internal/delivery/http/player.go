@@ -5,14 +5,19 @@ import (
 	"strings"
 
 	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/usecase"
 
 	"github.com/gin-gonic/gin"
 )
 
 type ProfileResponse struct {
-	UserID   uint    `json:"user_id" example:"1"`
-	Balance  float64 `json:"balance" example:"100.0"`
-	Currency string  `json:"currency" example:"USD"`
+	UserID  uint    `json:"user_id" example:"1"`
+	Balance float64 `json:"balance" example:"100.0"`
+	// BonusBalance cannot be cashed out until the bonus is wagered.
+	BonusBalance float64 `json:"bonus_balance" example:"25.0"`
+	Currency     string  `json:"currency" example:"USD"`
+	// Balances in currencies other than the primary one.
+	Balances []BalanceResponse `json:"balances"`
 }
 
 type ProfileErrorResponse struct {
@@ -30,12 +35,16 @@ type ProfileErrorResponse struct {
 // @Router /profile [get]
 func (h *Handlers) Profile(c *gin.Context) {
 	userID, _ := c.Get("userID")
-	user, err := h.PlayerUseCase.GetPlayerInfo(userID.(uint))
+	user, err := h.PlayerUseCase.GetPlayerInfo(c.Request.Context(), userID.(uint))
 	if err != nil {
 		if err == infrastructure.ErrWalletUserNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 			return
 		}
+		if err == usecase.ErrWalletServiceUnavailable {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "wallet service is not available"})
+			return
+		}
 		if strings.Contains(err.Error(), infrastructure.ErrWalletServiceBadRequest.Error()) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
@@ -43,9 +52,15 @@ func (h *Handlers) Profile(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get user info"})
 		return
 	}
+	balances := make([]BalanceResponse, 0, len(user.Balances))
+	for _, b := range user.Balances {
+		balances = append(balances, toBalanceResponse(b))
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"user_id":  user.WalletID,
-		"balance":  user.Balance,
-		"currency": user.Currency,
+		"user_id":       user.WalletID,
+		"balance":       user.Balance,
+		"bonus_balance": user.BonusBalance,
+		"currency":      user.Currency,
+		"balances":      balances,
 	})
 }
@@ -0,0 +1,193 @@
+package http
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ReconciliationRunResponse struct {
+	Day        string     `json:"day" example:"2025-01-31"`
+	Status     string     `json:"status" example:"completed"`
+	Users      int        `json:"users" example:"1200"`
+	Mismatches int        `json:"mismatches" example:"2"`
+	LocalNet   float64    `json:"local_net" example:"-1520.5"`
+	WalletNet  float64    `json:"wallet_net" example:"-1510.5"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+type ReconciliationMismatchResponse struct {
+	UserID       uint    `json:"user_id" example:"5"`
+	Kind         string  `json:"kind" example:"missing_remotely"`
+	Reference    string  `json:"reference,omitempty" example:"provider-tx-1"`
+	Currency     string  `json:"currency,omitempty" example:"USD"`
+	LocalAmount  float64 `json:"local_amount" example:"-10"`
+	WalletAmount float64 `json:"wallet_amount" example:"0"`
+	Detail       string  `json:"detail,omitempty"`
+}
+
+type ReconciliationReportResponse struct {
+	ReconciliationRunResponse
+	Items []ReconciliationMismatchResponse `json:"items"`
+}
+
+// ListReconciliations godoc
+// @Summary List reconciliation runs
+// @Tags Admin
+// @Description The last 90 days compared with the wallet, newest first
+// @Produce json
+// @Success 200 {array} ReconciliationRunResponse "Runs"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Security BearerAuth
+// @Router /admin/reconciliations [get]
+func (h *Handlers) ListReconciliations(c *gin.Context) {
+	runs, err := h.ReconciliationUseCase.ListRuns(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not load reconciliations"})
+		return
+	}
+	resp := make([]ReconciliationRunResponse, 0, len(runs))
+	for _, r := range runs {
+		resp = append(resp, toReconciliationRunResponse(r))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetReconciliation godoc
+// @Summary Get a reconciliation report
+// @Tags Admin
+// @Description Mismatches between the local ledger and the wallet for a UTC day: missing_locally, missing_remotely, amount_differs, or unchecked when the user's wallet ledger could not be read. Amounts are signed; withdrawals are negative.
+// @Produce json
+// @Produce text/csv
+// @Param day path string true "Day (YYYY-MM-DD)"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} ReconciliationReportResponse "Report"
+// @Failure 400 {object} BetErrorResponse "Invalid day or format"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Failure 404 {object} BetErrorResponse "Day not reconciled"
+// @Security BearerAuth
+// @Router /admin/reconciliations/{day} [get]
+func (h *Handlers) GetReconciliation(c *gin.Context) {
+	day, err := time.Parse(time.DateOnly, c.Param("day"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid day"})
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+	report, err := h.ReconciliationUseCase.Report(c.Request.Context(), day)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "day not reconciled"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not load reconciliation"})
+		return
+	}
+	if format == "csv" {
+		writeReconciliationCSV(c, report)
+		return
+	}
+	resp := ReconciliationReportResponse{
+		ReconciliationRunResponse: toReconciliationRunResponse(report.Run),
+		Items:                     make([]ReconciliationMismatchResponse, 0, len(report.Mismatches)),
+	}
+	for _, m := range report.Mismatches {
+		resp.Items = append(resp.Items, ReconciliationMismatchResponse{
+			UserID:       m.UserID,
+			Kind:         strings.ToLower(m.Kind),
+			Reference:    m.Reference,
+			Currency:     m.Currency,
+			LocalAmount:  m.LocalAmount,
+			WalletAmount: m.WalletAmount,
+			Detail:       m.Detail,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// RunReconciliation godoc
+// @Summary Reconcile a day
+// @Tags Admin
+// @Description Compare a UTC day with the wallet again in the background, replacing its report. Only one reconciliation runs at a time.
+// @Produce json
+// @Param day path string true "Day (YYYY-MM-DD)"
+// @Success 202 {object} map[string]string "Started"
+// @Failure 400 {object} BetErrorResponse "Invalid day"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Failure 409 {object} BetErrorResponse "A reconciliation is already running"
+// @Security BearerAuth
+// @Router /admin/reconciliations/{day}/run [post]
+func (h *Handlers) RunReconciliation(c *gin.Context) {
+	day, err := time.Parse(time.DateOnly, c.Param("day"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid day"})
+		return
+	}
+	if err := h.ReconciliationUseCase.Start(c.Request.Context(), day); err != nil {
+		var validation *usecase.ValidationError
+		switch {
+		case errors.As(err, &validation):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case err == usecase.ErrReconciliationRunning:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not start reconciliation"})
+		}
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"day": day.Format(time.DateOnly), "status": "running"})
+}
+
+func writeReconciliationCSV(c *gin.Context, report *usecase.ReconciliationReport) {
+	day := report.Run.Day.Format(time.DateOnly)
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", `attachment; filename="reconciliation-`+day+`.csv"`)
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"day", "user_id", "kind", "reference", "currency", "local_amount", "wallet_amount", "detail"})
+	for _, m := range report.Mismatches {
+		w.Write([]string{
+			day,
+			strconv.FormatUint(uint64(m.UserID), 10),
+			strings.ToLower(m.Kind),
+			m.Reference,
+			m.Currency,
+			strconv.FormatFloat(m.LocalAmount, 'f', 2, 64),
+			strconv.FormatFloat(m.WalletAmount, 'f', 2, 64),
+			m.Detail,
+		})
+	}
+	w.Flush()
+}
+
+func toReconciliationRunResponse(r domain.ReconciliationRun) ReconciliationRunResponse {
+	return ReconciliationRunResponse{
+		Day:        r.Day.Format(time.DateOnly),
+		Status:     strings.ToLower(r.Status),
+		Users:      r.Users,
+		Mismatches: r.Mismatches,
+		LocalNet:   r.LocalNet,
+		WalletNet:  r.WalletNet,
+		Error:      r.Error,
+		StartedAt:  r.StartedAt,
+		FinishedAt: r.FinishedAt,
+	}
+}
@@ -0,0 +1,192 @@
+package http
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RevenueLineResponse is one grouping of a revenue report. Keys not grouped
+// by are omitted. Turnover and wins include bonus funds; ngr is ggr less
+// the bonus-funded part of it and bonus money released to players. Base
+// amounts are converted at the rate stored on each transaction.
+type RevenueLineResponse struct {
+	Day           string  `json:"day,omitempty" example:"2025-01-31"`
+	GameID        string  `json:"game_id,omitempty" example:"game-1"`
+	ProviderID    string  `json:"provider_id,omitempty" example:"provider-1"`
+	Currency      string  `json:"currency" example:"EUR"`
+	UserID        uint    `json:"user_id,omitempty" example:"5"`
+	Bets          int64   `json:"bets" example:"1200"`
+	Turnover      float64 `json:"turnover" example:"10000.0"`
+	Wins          float64 `json:"wins" example:"9400.0"`
+	GGR           float64 `json:"ggr" example:"600.0"`
+	BonusStakes   float64 `json:"bonus_stakes" example:"200.0"`
+	BonusWins     float64 `json:"bonus_wins" example:"150.0"`
+	BonusReleased float64 `json:"bonus_released" example:"20.0"`
+	NGR           float64 `json:"ngr" example:"530.0"`
+	BaseTurnover  float64 `json:"base_turnover" example:"10870.0"`
+	BaseWins      float64 `json:"base_wins" example:"10217.0"`
+	BaseGGR       float64 `json:"base_ggr" example:"653.0"`
+}
+
+type RevenueReportResponse struct {
+	From    string                `json:"from" example:"2025-01-01"`
+	To      string                `json:"to" example:"2025-02-01"`
+	GroupBy []string              `json:"group_by" example:"day,currency"`
+	Lines   []RevenueLineResponse `json:"lines"`
+}
+
+// RevenueReport godoc
+// @Summary Revenue report
+// @Tags Admin
+// @Description Turnover, GGR and NGR from the daily aggregates of UTC days in [from, to). Currency is always grouped by. Days are aggregated nightly; aggregate a day again to pick up late changes such as approved held wins.
+// @Produce json
+// @Produce text/csv
+// @Param from query string true "First day (YYYY-MM-DD)"
+// @Param to query string true "End day, exclusive (YYYY-MM-DD)"
+// @Param group_by query string false "Comma-separated: day, game, provider, currency, player (default day)"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} RevenueReportResponse "Report"
+// @Failure 400 {object} BetErrorResponse "Invalid period, grouping or format"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Security BearerAuth
+// @Router /admin/reports/revenue [get]
+func (h *Handlers) RevenueReport(c *gin.Context) {
+	from, err := time.Parse(time.DateOnly, c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+		return
+	}
+	to, err := time.Parse(time.DateOnly, c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+	report, err := h.RevenueUseCase.Report(c.Request.Context(), from, to, strings.Split(c.DefaultQuery("group_by", "day"), ","))
+	if err != nil {
+		var validation *usecase.ValidationError
+		if errors.As(err, &validation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not build report"})
+		return
+	}
+	if format == "csv" {
+		writeRevenueCSV(c, report)
+		return
+	}
+	resp := RevenueReportResponse{
+		From:    report.From.Format(time.DateOnly),
+		To:      report.To.Format(time.DateOnly),
+		GroupBy: report.GroupBy,
+		Lines:   make([]RevenueLineResponse, 0, len(report.Lines)),
+	}
+	for _, l := range report.Lines {
+		resp.Lines = append(resp.Lines, toRevenueLineResponse(l))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// AggregateRevenue godoc
+// @Summary Aggregate a day's revenue
+// @Tags Admin
+// @Description Rebuild the daily aggregates of a UTC day from its transactions, replacing the previous ones
+// @Produce json
+// @Param day path string true "Day (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{} "Aggregated"
+// @Failure 400 {object} BetErrorResponse "Invalid day"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Security BearerAuth
+// @Router /admin/reports/revenue/{day}/aggregate [post]
+func (h *Handlers) AggregateRevenue(c *gin.Context) {
+	day, err := time.Parse(time.DateOnly, c.Param("day"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid day"})
+		return
+	}
+	rows, err := h.RevenueUseCase.Aggregate(c.Request.Context(), day)
+	if err != nil {
+		var validation *usecase.ValidationError
+		if errors.As(err, &validation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not aggregate day"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"day": day.Format(time.DateOnly), "rows": rows})
+}
+
+func writeRevenueCSV(c *gin.Context, report *usecase.RevenueReport) {
+	from, to := report.From.Format(time.DateOnly), report.To.Format(time.DateOnly)
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", `attachment; filename="revenue-`+from+`-`+to+`.csv"`)
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	header := append([]string{}, report.GroupBy...)
+	w.Write(append(header, "bets", "turnover", "wins", "ggr", "bonus_stakes", "bonus_wins", "bonus_released", "ngr", "base_turnover", "base_wins", "base_ggr"))
+	amount := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	for _, l := range report.Lines {
+		record := make([]string, 0, len(report.GroupBy)+11)
+		for _, g := range report.GroupBy {
+			switch g {
+			case "day":
+				record = append(record, l.Day.Format(time.DateOnly))
+			case "game":
+				record = append(record, l.GameID)
+			case "provider":
+				record = append(record, l.ProviderID)
+			case "currency":
+				record = append(record, l.Currency)
+			case "player":
+				record = append(record, strconv.FormatUint(uint64(l.UserID), 10))
+			}
+		}
+		w.Write(append(record,
+			strconv.FormatInt(l.Bets, 10),
+			amount(l.Turnover), amount(l.Wins), amount(l.GGR),
+			amount(l.BonusStakes), amount(l.BonusWins), amount(l.BonusReleased), amount(l.NGR),
+			amount(l.BaseTurnover), amount(l.BaseWins), amount(l.BaseGGR),
+		))
+	}
+	w.Flush()
+}
+
+func toRevenueLineResponse(l usecase.RevenueLine) RevenueLineResponse {
+	resp := RevenueLineResponse{
+		GameID:        l.GameID,
+		ProviderID:    l.ProviderID,
+		Currency:      l.Currency,
+		UserID:        l.UserID,
+		Bets:          l.Bets,
+		Turnover:      l.Turnover,
+		Wins:          l.Wins,
+		GGR:           l.GGR,
+		BonusStakes:   l.BonusStakes,
+		BonusWins:     l.BonusWins,
+		BonusReleased: l.BonusReleased,
+		NGR:           l.NGR,
+		BaseTurnover:  l.BaseTurnover,
+		BaseWins:      l.BaseWins,
+		BaseGGR:       l.BaseGGR,
+	}
+	if !l.Day.IsZero() {
+		resp.Day = l.Day.Format(time.DateOnly)
+	}
+	return resp
+}
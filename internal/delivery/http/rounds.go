@@ -0,0 +1,153 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoundEntryResponse struct {
+	TransactionID         uint      `json:"transaction_id" example:"123"`
+	ProviderTransactionID string    `json:"provider_transaction_id" example:"tx123"`
+	ParentTransactionID   string    `json:"provider_parent_transaction_id,omitempty" example:"tx122"`
+	Type                  string    `json:"type" example:"withdraw"`
+	Status                string    `json:"status" example:"lost"`
+	Amount                float64   `json:"amount" example:"10.0"`
+	BonusAmount           float64   `json:"bonus_amount" example:"0"`
+	OldBalance            float64   `json:"old_balance" example:"100.0"`
+	NewBalance            float64   `json:"new_balance" example:"90.0"`
+	Currency              string    `json:"currency,omitempty" example:"EUR"`
+	CreatedAt             time.Time `json:"created_at"`
+}
+
+// RoundDetailsResponse is our ledger of a round with the provider's
+// description of it. Source is stored when the provider sent the round with
+// a bet, provider when it came from its round history API, and unavailable
+// when neither had it.
+type RoundDetailsResponse struct {
+	RoundID    string               `json:"round_id" example:"round-42"`
+	ProviderID string               `json:"provider_id,omitempty" example:"novomatic"`
+	GameID     string               `json:"game_id,omitempty" example:"book-of-ra"`
+	UserID     uint                 `json:"user_id" example:"5"`
+	Entries    []RoundEntryResponse `json:"entries"`
+	Source     string               `json:"source" example:"stored"`
+	Provider   json.RawMessage      `json:"provider_data,omitempty" swaggertype:"object"`
+	Error      string               `json:"error,omitempty"`
+}
+
+// RoundDetails godoc
+// @Summary Round details
+// @Tags Player
+// @Description The player's ledger entries of a round with the provider's description of it
+// @Produce json
+// @Param id path string true "Round ID"
+// @Param provider_id query string false "Provider, when several used the round ID"
+// @Success 200 {object} RoundDetailsResponse "Round"
+// @Failure 400 {object} ProfileErrorResponse "Ambiguous round"
+// @Failure 401 {object} ProfileErrorResponse "Unauthorized"
+// @Failure 404 {object} ProfileErrorResponse "Round not found"
+// @Security BearerAuth
+// @Router /rounds/{id}/details [get]
+func (h *Handlers) RoundDetails(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	h.roundDetails(c, userID.(uint))
+}
+
+// UserRoundDetails godoc
+// @Summary Round details for support
+// @Tags Admin
+// @Description Any player's ledger entries of a round with the provider's description of it, for dispute handling
+// @Produce json
+// @Param id path string true "Round ID"
+// @Param provider_id query string false "Provider, when several used the round ID"
+// @Param user_id query int false "Player, when several played the round ID"
+// @Success 200 {object} RoundDetailsResponse "Round"
+// @Failure 400 {object} BetErrorResponse "Ambiguous round"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Failure 404 {object} BetErrorResponse "Round not found"
+// @Security BearerAuth
+// @Router /admin/rounds/{id}/details [get]
+func (h *Handlers) UserRoundDetails(c *gin.Context) {
+	var userID uint64
+	if raw := c.Query("user_id"); raw != "" {
+		var err error
+		if userID, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+			return
+		}
+	}
+	h.roundDetails(c, uint(userID))
+}
+
+func (h *Handlers) roundDetails(c *gin.Context, userID uint) {
+	details, err := h.RoundUseCase.Details(c.Request.Context(), c.Param("id"), userID, c.Query("provider_id"))
+	if err != nil {
+		var validation *usecase.ValidationError
+		switch {
+		case errors.As(err, &validation):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrRoundNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not load round"})
+		}
+		return
+	}
+	resp := RoundDetailsResponse{
+		RoundID:    details.RoundID,
+		ProviderID: details.ProviderID,
+		GameID:     details.GameID,
+		UserID:     details.UserID,
+		Entries:    make([]RoundEntryResponse, 0, len(details.Entries)),
+		Source:     details.Source,
+		Provider:   details.Data,
+		Error:      details.Error,
+	}
+	for _, tx := range details.Entries {
+		resp.Entries = append(resp.Entries, toRoundEntryResponse(tx))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// roundDetailsMaxSize caps the round payload a provider may send with a bet.
+const roundDetailsMaxSize = 64 << 10
+
+// checkRoundDetails accepts an absent round_details or a JSON object.
+func checkRoundDetails(raw json.RawMessage) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	if len(raw) > roundDetailsMaxSize {
+		return errors.New("round_details is too large")
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return errors.New("round_details must be a JSON object")
+	}
+	return nil
+}
+
+func toRoundEntryResponse(tx domain.Transaction) RoundEntryResponse {
+	return RoundEntryResponse{
+		TransactionID:         tx.ID,
+		ProviderTransactionID: tx.ProviderTxID,
+		ParentTransactionID:   tx.ProviderParentTxID,
+		Type:                  strings.ToLower(tx.Type),
+		Status:                strings.ToLower(tx.Status),
+		Amount:                tx.Amount,
+		BonusAmount:           tx.BonusAmount,
+		OldBalance:            tx.OldBalance,
+		NewBalance:            tx.NewBalance,
+		Currency:              tx.Currency,
+		CreatedAt:             tx.CreatedAt,
+	}
+}
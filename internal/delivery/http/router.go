@@ -1,13 +1,32 @@
 package http
 
 import (
+	"net/http"
+
+	"gameintegrationapi/internal/infrastructure"
+
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func NewRouter(handlers *Handlers) *gin.Engine {
+const serviceName = "game-integration-api"
+
+// NewRouter builds the routes. Client IPs are taken from X-Forwarded-For only
+// for requests coming through trustedProxies.
+func NewRouter(handlers *Handlers, trustedProxies []string) (*gin.Engine, error) {
 	r := gin.Default()
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
+	r.Use(otelgin.Middleware(serviceName, otelgin.WithFilter(func(req *http.Request) bool {
+		switch req.URL.Path {
+		case "/metrics", "/healthz", "/readyz":
+			return false
+		}
+		return true
+	})))
 
 	// Redirect root to Swagger UI
 	r.GET("/", func(c *gin.Context) {
@@ -16,13 +35,77 @@ func NewRouter(handlers *Handlers) *gin.Engine {
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	r.POST("/auth/login", handlers.Login)
-	r.GET("/profile", handlers.AuthMiddleware(), handlers.Profile)
-	r.POST("/bet/withdraw", handlers.AuthMiddleware(), handlers.Withdraw)
-	r.POST("/bet/deposit", handlers.AuthMiddleware(), handlers.Deposit)
-	r.POST("/bet/cancel", handlers.AuthMiddleware(), handlers.Cancel)
+	loginLimit := handlers.RateLimit(infrastructure.RateLimitPolicyLogin, ClientIPKey)
+	providerLimit := handlers.RateLimit(infrastructure.RateLimitPolicyProvider, ProviderKey)
+	betLimit := handlers.RateLimit(infrastructure.RateLimitPolicyBet, UserIDKey)
+
+	r.GET("/.well-known/jwks.json", handlers.JWKS)
+	r.POST("/auth/login", loginLimit, handlers.Login)
+	r.POST("/auth/login/2fa", loginLimit, handlers.LoginTwoFactor)
+	r.POST("/auth/register", loginLimit, handlers.Register)
+	// Excluded players keep access to their account but cannot place bets.
+	account := handlers.AuthMiddlewareAllowExcluded()
+	r.POST("/auth/change-password", account, handlers.ChangePassword)
+	r.POST("/auth/password-reset/request", loginLimit, handlers.RequestPasswordReset)
+	r.POST("/auth/password-reset/confirm", loginLimit, handlers.ConfirmPasswordReset)
+	r.POST("/auth/2fa/enroll", account, handlers.EnrollTwoFactor)
+	r.POST("/auth/2fa/confirm", account, handlers.ConfirmTwoFactor)
+	r.POST("/auth/2fa/disable", account, handlers.DisableTwoFactor)
+	r.GET("/profile", account, handlers.Profile)
+	r.GET("/limits", account, handlers.GetLimits)
+	r.PUT("/limits", account, handlers.SetLimits)
+	r.GET("/exclusion", account, handlers.GetExclusion)
+	r.POST("/exclusion", account, handlers.Exclude)
+	r.GET("/bonuses", account, handlers.ListBonuses)
+	r.POST("/bonuses/:id/forfeit", account, handlers.ForfeitBonus)
+	r.GET("/free-rounds", account, handlers.ListFreeRounds)
+	r.GET("/transactions/export", account, handlers.ExportTransactions)
+	r.GET("/rounds/:id/details", account, handlers.RoundDetails)
+	r.POST("/balances", account, handlers.OpenBalance)
+	r.GET("/jackpots", handlers.ListJackpots)
+	r.POST("/cashier/deposits", handlers.CashierAuth(), handlers.AuthorizeDeposit)
+
+	bet := r.Group("/bet", handlers.ProviderIdentity(), providerLimit, handlers.RecordExchange())
+	bet.POST("/withdraw", handlers.AuthMiddleware(), betLimit, handlers.Withdraw)
+	// Bets placed before an exclusion must still settle.
+	bet.POST("/deposit", account, betLimit, handlers.Deposit)
+	bet.POST("/cancel", account, betLimit, handlers.Cancel)
+	bet.POST("/batch", account, betLimit, handlers.Batch)
+
+	admin := r.Group("/admin", handlers.AuthMiddleware(), handlers.AdminMiddleware())
+	admin.GET("/lockouts", handlers.ListLockouts)
+	admin.DELETE("/lockouts/:id", handlers.ClearLockout)
+	admin.GET("/exclusions", handlers.ListExclusions)
+	admin.POST("/users/:id/exclusion", handlers.ExcludeUser)
+	admin.GET("/bet-rules", handlers.ListBetRules)
+	admin.PUT("/bet-rules", handlers.SaveBetRule)
+	admin.DELETE("/bet-rules/:id", handlers.DeleteBetRule)
+	admin.GET("/held-wins", handlers.ListHeldWins)
+	admin.POST("/held-wins/:id/approve", handlers.ApproveHeldWin)
+	admin.POST("/held-wins/:id/reject", handlers.RejectHeldWin)
+	admin.GET("/bonus-campaigns", handlers.ListBonusCampaigns)
+	admin.POST("/bonus-campaigns", handlers.CreateBonusCampaign)
+	admin.POST("/users/:id/bonuses", handlers.GrantBonus)
+	admin.POST("/bonuses/:id/forfeit", handlers.AdminForfeitBonus)
+	admin.GET("/users/:id/free-rounds", handlers.ListUserFreeRounds)
+	admin.POST("/users/:id/free-rounds", handlers.GrantFreeRounds)
+	admin.GET("/users/:id/transactions/export", handlers.ExportUserTransactions)
+	admin.GET("/rounds/:id/details", handlers.UserRoundDetails)
+	admin.POST("/free-rounds/:id/revoke", handlers.RevokeFreeRounds)
+	admin.POST("/jackpots", handlers.CreateJackpot)
+	admin.PUT("/jackpot-games", handlers.AssignJackpotGame)
+	admin.GET("/reports/currencies", handlers.CurrencyReport)
+	admin.GET("/reconciliations", handlers.ListReconciliations)
+	admin.GET("/reconciliations/:day", handlers.GetReconciliation)
+	admin.POST("/reconciliations/:day/run", handlers.RunReconciliation)
+	admin.GET("/reports/revenue", handlers.RevenueReport)
+	admin.POST("/reports/revenue/:day/aggregate", handlers.AggregateRevenue)
 
 	r.GET("/metrics", Metrics())
 
-	return r
+	r.GET("/healthz", handlers.Healthz)
+	r.GET("/readyz", handlers.Readyz)
+	r.GET("/status", handlers.Status)
+
+	return r, nil
 }
@@ -0,0 +1,227 @@
+package http
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// statementFlushEvery is how many lines are buffered before they are sent.
+const statementFlushEvery = 200
+
+// ExportTransactions godoc
+// @Summary Export a statement
+// @Tags Player
+// @Description The player's statement of one balance in [from, to): opening balance, every transaction with its signed amount and the balance after it, and closing balance. Streamed as CSV or PDF.
+// @Produce text/csv
+// @Produce application/pdf
+// @Param from query string true "Start (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string true "End, exclusive (RFC 3339 or YYYY-MM-DD)"
+// @Param format query string false "csv (default) or pdf"
+// @Param currency query string false "Balance currency (default primary)"
+// @Success 200 {file} file "Statement"
+// @Failure 400 {object} ProfileErrorResponse "Invalid period, format or currency"
+// @Failure 401 {object} ProfileErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /transactions/export [get]
+func (h *Handlers) ExportTransactions(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	h.exportStatement(c, userID.(uint))
+}
+
+// ExportUserTransactions godoc
+// @Summary Export a player's statement
+// @Tags Admin
+// @Description A player's statement of one balance in [from, to), as sent to them by support. Streamed as CSV or PDF.
+// @Produce text/csv
+// @Produce application/pdf
+// @Param id path int true "User ID"
+// @Param from query string true "Start (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string true "End, exclusive (RFC 3339 or YYYY-MM-DD)"
+// @Param format query string false "csv (default) or pdf"
+// @Param currency query string false "Balance currency (default primary)"
+// @Success 200 {file} file "Statement"
+// @Failure 400 {object} BetErrorResponse "Invalid period, format or currency"
+// @Failure 401 {object} BetErrorResponse "Unauthorized"
+// @Failure 403 {object} BetErrorResponse "Forbidden"
+// @Failure 404 {object} BetErrorResponse "User not found"
+// @Security BearerAuth
+// @Router /admin/users/{id}/transactions/export [get]
+func (h *Handlers) ExportUserTransactions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	h.exportStatement(c, uint(id))
+}
+
+func (h *Handlers) exportStatement(c *gin.Context, userID uint) {
+	from, err := parseReportTime(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+		return
+	}
+	to, err := parseReportTime(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+		return
+	}
+	var w usecase.StatementWriter
+	switch c.DefaultQuery("format", "csv") {
+	case "csv":
+		w = &csvStatementWriter{c: c}
+	case "pdf":
+		w = &pdfStatementWriter{c: c}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or pdf"})
+		return
+	}
+	err = h.StatementUseCase.Export(c.Request.Context(), userID, c.Query("currency"), from, to, w)
+	if err == nil {
+		return
+	}
+	if c.Writer.Written() {
+		// The statement is already being sent; all we can do is cut it short.
+		log.Printf("ExportTransactions: statement of user %d aborted: %v", userID, err)
+		c.Abort()
+		return
+	}
+	var validation *usecase.ValidationError
+	switch {
+	case errors.As(err, &validation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not export statement"})
+	}
+}
+
+// statementAmount is the signed amount a transaction moved: stakes and
+// clawed back wins are negative.
+func statementAmount(tx *domain.Transaction) float64 {
+	if tx.Debit() {
+		return -tx.Amount
+	}
+	return tx.Amount
+}
+
+func statementFilename(s *usecase.Statement, ext string) string {
+	return fmt.Sprintf("statement-%d-%s-%s.%s", s.UserID, s.From.Format(time.DateOnly), s.To.Format(time.DateOnly), ext)
+}
+
+// beginDownload sends the headers of a file download, committing to a 200.
+func beginDownload(c *gin.Context, contentType, filename string) {
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+type csvStatementWriter struct {
+	c     *gin.Context
+	w     *csv.Writer
+	lines int
+}
+
+func (sw *csvStatementWriter) Begin(s *usecase.Statement) error {
+	beginDownload(sw.c, "text/csv", statementFilename(s, "csv"))
+	sw.w = csv.NewWriter(sw.c.Writer)
+	sw.w.Write([]string{"time", "type", "status", "game_id", "round_id", "transaction_id", "amount", "balance_after", "currency"})
+	sw.w.Write([]string{s.From.UTC().Format(time.RFC3339), "opening_balance", "", "", "", "", "", formatAmount(s.Opening), s.Currency})
+	return sw.w.Error()
+}
+
+func (sw *csvStatementWriter) Line(tx *domain.Transaction) error {
+	sw.w.Write([]string{
+		tx.CreatedAt.UTC().Format(time.RFC3339),
+		strings.ToLower(tx.Type),
+		strings.ToLower(tx.Status),
+		tx.ProviderGameID,
+		tx.ProviderRoundID,
+		tx.ProviderTxID,
+		formatAmount(statementAmount(tx)),
+		formatAmount(tx.NewBalance),
+		tx.Currency,
+	})
+	if sw.lines++; sw.lines%statementFlushEvery == 0 {
+		sw.w.Flush()
+		sw.c.Writer.Flush()
+	}
+	return sw.w.Error()
+}
+
+func (sw *csvStatementWriter) End(s *usecase.Statement) error {
+	sw.w.Write([]string{s.To.UTC().Format(time.RFC3339), "closing_balance", "", "", "", "", "", formatAmount(s.Closing), s.Currency})
+	sw.w.Flush()
+	return sw.w.Error()
+}
+
+// pdfStatementLine lays out one row of the PDF statement.
+const pdfStatementLine = "%-20s %-13s %-9s %-16s %-16s %12s %12s"
+
+type pdfStatementWriter struct {
+	c *gin.Context
+	p *infrastructure.PDFTextWriter
+}
+
+func (sw *pdfStatementWriter) Begin(s *usecase.Statement) error {
+	beginDownload(sw.c, "application/pdf", statementFilename(s, "pdf"))
+	sw.p = infrastructure.NewPDFTextWriter(sw.c.Writer)
+	for _, line := range []string{
+		fmt.Sprintf("Statement for %s (player %d)", s.Username, s.UserID),
+		fmt.Sprintf("Balance: %s", s.Currency),
+		fmt.Sprintf("Period: %s to %s (UTC, end exclusive)", s.From.UTC().Format(time.DateTime), s.To.UTC().Format(time.DateTime)),
+		"",
+		fmt.Sprintf("Opening balance: %s %s", formatAmount(s.Opening), s.Currency),
+		"",
+		fmt.Sprintf(pdfStatementLine, "Time", "Type", "Status", "Game", "Round", "Amount", "Balance"),
+	} {
+		if err := sw.p.Line(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sw *pdfStatementWriter) Line(tx *domain.Transaction) error {
+	return sw.p.Line(fmt.Sprintf(pdfStatementLine,
+		tx.CreatedAt.UTC().Format(time.DateTime),
+		strings.ToLower(tx.Type),
+		strings.ToLower(tx.Status),
+		truncate(tx.ProviderGameID, 16),
+		truncate(tx.ProviderRoundID, 16),
+		formatAmount(statementAmount(tx)),
+		formatAmount(tx.NewBalance),
+	))
+}
+
+func (sw *pdfStatementWriter) End(s *usecase.Statement) error {
+	sw.p.Line("")
+	sw.p.Line(fmt.Sprintf("Closing balance: %s %s", formatAmount(s.Closing), s.Currency))
+	return sw.p.Close()
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-1] + "~"
+}
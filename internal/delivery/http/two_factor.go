@@ -0,0 +1,160 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorEnrollResponse struct {
+	Secret          string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/Game%20Integration%20API:testuser1?secret=JBSWY3DPEHPK3PXP&issuer=Game%20Integration%20API"`
+}
+
+type TwoFactorConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k3j9d-p2x7q,m8w4t-a6z2n"`
+}
+
+type twoFactorEnrollRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+func (r *twoFactorEnrollRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		Password string `json:"password"`
+	})(r))
+}
+
+type twoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+func (r *twoFactorCodeRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		Code string `json:"code"`
+	})(r))
+}
+
+type twoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required" example:"123456"`
+}
+
+func (r *twoFactorDisableRequest) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*struct {
+		Password string `json:"password"`
+		Code     string `json:"code"`
+	})(r))
+}
+
+// EnrollTwoFactor godoc
+// @Summary Start two-factor enrolment
+// @Tags Auth
+// @Description Generate a TOTP secret for the authenticated player after re-checking their password. It takes effect after /auth/2fa/confirm. Admins are enrolled by an operator instead.
+// @Accept json
+// @Produce json
+// @Param body body twoFactorEnrollRequest true "Current password"
+// @Success 200 {object} TwoFactorEnrollResponse "Secret and provisioning URI"
+// @Failure 400 {object} AccountErrorResponse "Invalid request"
+// @Failure 401 {object} AccountErrorResponse "Unauthorized or wrong password"
+// @Failure 403 {object} AccountErrorResponse "Role is enrolled by an operator"
+// @Failure 409 {object} AccountErrorResponse "Already enabled"
+// @Security BearerAuth
+// @Router /auth/2fa/enroll [post]
+func (h *Handlers) EnrollTwoFactor(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req twoFactorEnrollRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, AccountErrorResponse{Error: err.Error()})
+		return
+	}
+	enrollment, err := h.TwoFactorUseCase.Enroll(c.Request.Context(), userID.(uint), req.Password)
+	if err != nil {
+		writeTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, TwoFactorEnrollResponse{Secret: enrollment.Secret, ProvisioningURI: enrollment.ProvisioningURI})
+}
+
+// ConfirmTwoFactor godoc
+// @Summary Confirm two-factor enrolment
+// @Tags Auth
+// @Description Enable two-factor authentication with a code from the authenticator app. Returns single-use recovery codes, shown only once.
+// @Accept json
+// @Produce json
+// @Param body body twoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} TwoFactorConfirmResponse "Recovery codes"
+// @Failure 400 {object} AccountErrorResponse "Invalid request or code"
+// @Failure 401 {object} AccountErrorResponse "Unauthorized"
+// @Failure 409 {object} AccountErrorResponse "Already enabled"
+// @Security BearerAuth
+// @Router /auth/2fa/confirm [post]
+func (h *Handlers) ConfirmTwoFactor(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req twoFactorCodeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, AccountErrorResponse{Error: err.Error()})
+		return
+	}
+	codes, err := h.TwoFactorUseCase.Confirm(c.Request.Context(), userID.(uint), req.Code)
+	if err != nil {
+		writeTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, TwoFactorConfirmResponse{RecoveryCodes: codes})
+}
+
+// DisableTwoFactor godoc
+// @Summary Disable two-factor authentication
+// @Tags Auth
+// @Description Turn off two-factor authentication. Requires the password and a TOTP or recovery code. Not allowed for admin accounts.
+// @Accept json
+// @Produce json
+// @Param body body twoFactorDisableRequest true "Password and code"
+// @Success 204 "Disabled"
+// @Failure 400 {object} AccountErrorResponse "Invalid request or code"
+// @Failure 401 {object} AccountErrorResponse "Unauthorized or wrong password"
+// @Failure 403 {object} AccountErrorResponse "Required for this role"
+// @Security BearerAuth
+// @Router /auth/2fa/disable [post]
+func (h *Handlers) DisableTwoFactor(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req twoFactorDisableRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, AccountErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := h.TwoFactorUseCase.Disable(c.Request.Context(), userID.(uint), req.Password, req.Code); err != nil {
+		writeTwoFactorError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func writeTwoFactorError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidTwoFactorCode),
+		errors.Is(err, usecase.ErrTwoFactorNotEnrolled),
+		errors.Is(err, usecase.ErrTwoFactorNotEnabled):
+		c.JSON(http.StatusBadRequest, AccountErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrTwoFactorAlreadyEnabled):
+		c.JSON(http.StatusConflict, AccountErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrTwoFactorRequired), errors.Is(err, usecase.ErrEnrollmentOutOfBand):
+		c.JSON(http.StatusForbidden, AccountErrorResponse{Error: err.Error()})
+	case errors.Is(err, usecase.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, AccountErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, AccountErrorResponse{Error: err.Error()})
+	}
+}
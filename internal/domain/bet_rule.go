@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// BetRule bounds stakes and wins. An empty GameID, ProviderID or Currency
+// matches any value; when several rules match, the most specific applies.
+// Zero limits are not enforced.
+type BetRule struct {
+	ID             uint   `gorm:"primaryKey"`
+	GameID         string `gorm:"uniqueIndex:idx_bet_rule;not null;default:''"`
+	ProviderID     string `gorm:"uniqueIndex:idx_bet_rule;not null;default:''"`
+	Currency       string `gorm:"uniqueIndex:idx_bet_rule;not null;default:''"`
+	MinStake       float64
+	MaxStake       float64
+	MaxWinPerRound float64
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Matches reports whether the rule applies to a bet.
+func (r *BetRule) Matches(gameID, providerID, currency string) bool {
+	return (r.GameID == "" || r.GameID == gameID) &&
+		(r.ProviderID == "" || r.ProviderID == providerID) &&
+		(r.Currency == "" || r.Currency == currency)
+}
+
+// Specificity ranks matching rules: a game match outweighs a provider match,
+// which outweighs a currency match.
+func (r *BetRule) Specificity() int {
+	score := 0
+	if r.GameID != "" {
+		score += 4
+	}
+	if r.ProviderID != "" {
+		score += 2
+	}
+	if r.Currency != "" {
+		score++
+	}
+	return score
+}
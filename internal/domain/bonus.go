@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+const (
+	BonusStatusActive    = "ACTIVE"
+	BonusStatusCompleted = "COMPLETED"
+	BonusStatusForfeited = "FORFEITED"
+	BonusStatusExpired   = "EXPIRED"
+)
+
+// BonusCampaign is a promotion granting Amount in bonus funds. The bonus
+// becomes real money once the player has staked WageringMultiplier times
+// Amount within ValidDays of the grant.
+type BonusCampaign struct {
+	ID                 uint    `gorm:"primaryKey"`
+	Name               string  `gorm:"not null"`
+	Amount             float64 `gorm:"not null"`
+	WageringMultiplier float64 `gorm:"not null"`
+	ValidDays          int     `gorm:"not null"`
+	Active             bool    `gorm:"not null;default:true"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// PlayerBonus is a campaign granted to a player. The remaining bonus funds
+// are held in User.BonusBalance; a player has at most one active bonus.
+// Wagering progress is the sum of stakes placed since CreatedAt.
+type PlayerBonus struct {
+	ID               uint           `gorm:"primaryKey"`
+	UserID           uint           `gorm:"index;not null"`
+	CampaignID       uint           `gorm:"index;not null"`
+	Campaign         *BonusCampaign `gorm:"foreignKey:CampaignID"`
+	Amount           float64        `gorm:"not null"`
+	WageringRequired float64        `gorm:"not null"`
+	Status           string         `gorm:"index;not null"`
+	ExpiresAt        time.Time      `gorm:"index;not null"`
+	// ClosedAt is set when the bonus leaves ACTIVE. ReleasedAmount moved to
+	// the real balance on completion; ForfeitedAmount was removed on expiry
+	// or cancellation.
+	ClosedAt        *time.Time
+	ReleasedAmount  float64
+	ForfeitedAmount float64
+	CreatedAt       time.Time
+}
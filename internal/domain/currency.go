@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// PlayerBalance is a balance held in a currency other than the player's
+// primary one. The primary balance stays on User.Balance.
+type PlayerBalance struct {
+	ID        uint    `gorm:"primaryKey"`
+	UserID    uint    `gorm:"uniqueIndex:idx_player_balance_currency;not null"`
+	Currency  string  `gorm:"uniqueIndex:idx_player_balance_currency;not null"`
+	Balance   float64 `gorm:"not null;default:0"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// FXSnapshot records the rates a transaction was booked at. A bet in a
+// currency the player holds no balance in is converted from GameAmount in
+// GameCurrency at GameRate; Amount times BaseRate is its value in
+// BaseCurrency for reporting.
+type FXSnapshot struct {
+	GameCurrency string
+	GameAmount   float64
+	GameRate     float64 `gorm:"not null;default:1"`
+	BaseCurrency string
+	BaseRate     float64 `gorm:"not null;default:0"`
+	Source       string
+	AsOf         *time.Time
+}
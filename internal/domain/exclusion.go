@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+const (
+	ExclusionTypeTimeout = "TIMEOUT"
+	ExclusionTypeSelf    = "SELF_EXCLUSION"
+)
+
+// Exclusion blocks a player from betting between StartsAt and EndsAt. A nil
+// EndsAt makes it permanent. Exclusions cannot be shortened or lifted early;
+// a longer one may be added on top.
+type Exclusion struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	User      *User  `gorm:"foreignKey:UserID"`
+	Type      string `gorm:"not null"` // TIMEOUT, SELF_EXCLUSION
+	StartsAt  time.Time
+	EndsAt    *time.Time `gorm:"index"`
+	Reason    string
+	CreatedBy string `gorm:"not null"` // username of the player or admin
+	CreatedAt time.Time
+}
+
+func (e *Exclusion) ActiveAt(now time.Time) bool {
+	return !now.Before(e.StartsAt) && (e.EndsAt == nil || now.Before(*e.EndsAt))
+}
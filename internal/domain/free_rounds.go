@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+const (
+	FreeRoundsStatusActive  = "ACTIVE"
+	FreeRoundsStatusRevoked = "REVOKED"
+)
+
+// Where free-round wins are credited.
+const (
+	FreeRoundsWinToReal  = "REAL"
+	FreeRoundsWinToBonus = "BONUS"
+)
+
+// FreeRoundGrant entitles a player to Count zero-cost rounds of BetValue on
+// a game. The provider settles them: the stake is not debited and only the
+// win is deposited, to the real balance or, with WinTo BONUS, to the bonus
+// balance of PlayerBonusID while that bonus is active.
+type FreeRoundGrant struct {
+	ID            uint      `gorm:"primaryKey"`
+	UserID        uint      `gorm:"index;not null"`
+	GameID        string    `gorm:"index;not null"`
+	Count         int       `gorm:"not null"`
+	Remaining     int       `gorm:"not null"`
+	BetValue      float64   `gorm:"not null"`
+	WinTo         string    `gorm:"not null;default:REAL"`
+	PlayerBonusID *uint     `gorm:"index"`
+	Status        string    `gorm:"index;not null"`
+	ExpiresAt     time.Time `gorm:"not null"`
+	CreatedBy     string
+	RevokedBy     string
+	RevokedAt     *time.Time
+	CreatedAt     time.Time
+}
+
+// UsableAt reports whether a round can be played at t.
+func (g *FreeRoundGrant) UsableAt(t time.Time) bool {
+	return g.Status == FreeRoundsStatusActive && g.Remaining > 0 && t.Before(g.ExpiresAt)
+}
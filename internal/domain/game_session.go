@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// GameSession is a run of betting activity without a gap longer than the
+// idle timeout. It drives reality-check reminders.
+type GameSession struct {
+	ID                 uint `gorm:"primaryKey"`
+	UserID             uint `gorm:"index;not null"`
+	StartedAt          time.Time
+	LastActivityAt     time.Time
+	NextRealityCheckAt time.Time
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// JackpotPool is a progressive jackpot for a game group in one currency.
+// Every qualifying stake adds ContributionPct percent of it to Amount; a win
+// pays out Amount and resets it to SeedAmount.
+type JackpotPool struct {
+	ID              uint    `gorm:"primaryKey"`
+	Name            string  `gorm:"uniqueIndex;not null"`
+	GameGroup       string  `gorm:"index:idx_jackpot_pool_group;not null"`
+	Currency        string  `gorm:"index:idx_jackpot_pool_group;not null"`
+	ContributionPct float64 `gorm:"not null"`
+	SeedAmount      float64 `gorm:"not null"`
+	Amount          float64 `gorm:"not null"`
+	LastWinAmount   float64
+	LastWonAt       *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// JackpotGame puts a game in a jackpot game group.
+type JackpotGame struct {
+	GameID    string `gorm:"primaryKey"`
+	GameGroup string `gorm:"index;not null"`
+	UpdatedAt time.Time
+}
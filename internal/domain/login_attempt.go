@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// LoginAttempt is the audit record of a single login attempt.
+type LoginAttempt struct {
+	ID        uint   `gorm:"primaryKey"`
+	Username  string `gorm:"index;not null"`
+	IP        string `gorm:"index;not null"`
+	Success   bool   `gorm:"not null"`
+	Reason    string // INVALID_CREDENTIALS, LOCKED, ...
+	CreatedAt time.Time
+}
+
+// LoginLockout tracks consecutive failed logins for a username or client IP.
+type LoginLockout struct {
+	ID            uint   `gorm:"primaryKey"`
+	Key           string `gorm:"uniqueIndex;not null"` // user:<username> or ip:<address>
+	Failures      int    `gorm:"not null"`
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (l *LoginLockout) IsLocked(now time.Time) bool {
+	return l.LockedUntil != nil && now.Before(*l.LockedUntil)
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// PasswordResetToken is a single-use reset token. Only the SHA-256 hash of
+// the token is stored.
+type PasswordResetToken struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	TokenHash string `gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
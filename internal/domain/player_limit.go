@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+const (
+	LimitTypeLoss    = "LOSS"
+	LimitTypeWager   = "WAGER"
+	LimitTypeDeposit = "DEPOSIT"
+
+	LimitPeriodDaily   = "DAILY"
+	LimitPeriodWeekly  = "WEEKLY"
+	LimitPeriodMonthly = "MONTHLY"
+)
+
+// LimitPeriods lists the rolling windows limits are checked over.
+var LimitPeriods = map[string]time.Duration{
+	LimitPeriodDaily:   24 * time.Hour,
+	LimitPeriodWeekly:  7 * 24 * time.Hour,
+	LimitPeriodMonthly: 30 * 24 * time.Hour,
+}
+
+// PlayerLimit is a responsible-gaming limit set by the player. Lowering a
+// limit applies at once; raising or removing one is held in PendingAmount
+// until PendingFrom, after the cooling-off period.
+type PlayerLimit struct {
+	ID            uint    `gorm:"primaryKey"`
+	UserID        uint    `gorm:"uniqueIndex:idx_player_limit;not null"`
+	Type          string  `gorm:"uniqueIndex:idx_player_limit;not null"` // LOSS, WAGER, DEPOSIT
+	Period        string  `gorm:"uniqueIndex:idx_player_limit;not null"` // DAILY, WEEKLY, MONTHLY
+	Amount        float64 `gorm:"not null"`                              // 0 means no limit
+	PendingAmount float64
+	PendingFrom   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Effective returns the limit in force at now, applying a pending change
+// whose cooling-off period has passed.
+func (l *PlayerLimit) Effective(now time.Time) float64 {
+	if l.PendingFrom != nil && !now.Before(*l.PendingFrom) {
+		return l.PendingAmount
+	}
+	return l.Amount
+}
+
+// PlayerDeposit is money the player paid into their wallet, approved by the
+// cashier check before the wallet credited it. Deposits count towards
+// DEPOSIT limits; Amount is in the player's currency.
+type PlayerDeposit struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"index;not null"`
+	Reference string    `gorm:"uniqueIndex;not null"` // the cashier's deposit ID
+	Amount    float64   `gorm:"not null"`
+	CreatedAt time.Time `gorm:"index"`
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+const (
+	ReconciliationStatusRunning   = "RUNNING"
+	ReconciliationStatusCompleted = "COMPLETED"
+	ReconciliationStatusFailed    = "FAILED"
+)
+
+// Mismatch kinds found when comparing the local ledger with the wallet.
+const (
+	// MismatchMissingLocally is a wallet movement with no local transaction.
+	MismatchMissingLocally = "MISSING_LOCALLY"
+	// MismatchMissingRemotely is a local transaction the wallet never booked.
+	MismatchMissingRemotely = "MISSING_REMOTELY"
+	MismatchAmountDiffers   = "AMOUNT_DIFFERS"
+	// MismatchUnchecked marks a user whose wallet ledger could not be read.
+	MismatchUnchecked = "UNCHECKED"
+)
+
+// ReconciliationRun compares one UTC day of the local ledger with the
+// wallet. Running a day again replaces its previous run.
+type ReconciliationRun struct {
+	ID     uint      `gorm:"primaryKey"`
+	Day    time.Time `gorm:"type:date;uniqueIndex;not null"`
+	Status string    `gorm:"not null"`
+	// LocalNet and WalletNet are the day's money movements of the checked
+	// users, deposits less withdrawals, summed across currencies.
+	Users      int
+	Mismatches int
+	LocalNet   float64
+	WalletNet  float64
+	Error      string
+	StartedAt  time.Time
+	FinishedAt *time.Time
+}
+
+// ReconciliationMismatch is one disagreement found by a run. Amounts are
+// signed: withdrawals are negative.
+type ReconciliationMismatch struct {
+	ID           uint   `gorm:"primaryKey"`
+	RunID        uint   `gorm:"index;not null"`
+	UserID       uint   `gorm:"index;not null"`
+	Kind         string `gorm:"not null"`
+	Reference    string
+	Currency     string
+	LocalAmount  float64
+	WalletAmount float64
+	Detail       string
+	CreatedAt    time.Time
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// RecoveryCode is a single-use 2FA backup code. Only its hash is stored.
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	CodeHash  string `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// DailyAggregate is one UTC day of a player's play on one game, in one
+// balance currency. Aggregating a day again replaces all of its rows.
+//
+// Turnover is the total of settled stakes and Wins the total of credited
+// wins, both including bonus funds. Cancelled stakes and held or rejected
+// wins are left out. Bonus releases are not tied to a game and are
+// aggregated with an empty GameID and ProviderID.
+type DailyAggregate struct {
+	ID         uint      `gorm:"primaryKey"`
+	Day        time.Time `gorm:"type:date;not null;uniqueIndex:idx_daily_aggregate_key,priority:1"`
+	GameID     string    `gorm:"not null;uniqueIndex:idx_daily_aggregate_key,priority:2"`
+	ProviderID string    `gorm:"not null;uniqueIndex:idx_daily_aggregate_key,priority:3"`
+	Currency   string    `gorm:"not null;uniqueIndex:idx_daily_aggregate_key,priority:4"`
+	UserID     uint      `gorm:"not null;index;uniqueIndex:idx_daily_aggregate_key,priority:5"`
+	Bets       int64     `gorm:"not null;default:0"`
+	Turnover   float64   `gorm:"not null;default:0"`
+	Wins       float64   `gorm:"not null;default:0"`
+	// BonusStakes and BonusWins are the parts of Turnover and Wins staked
+	// from, or credited to, bonus balances. BonusReleased is bonus money
+	// converted to real money.
+	BonusStakes   float64 `gorm:"not null;default:0"`
+	BonusWins     float64 `gorm:"not null;default:0"`
+	BonusReleased float64 `gorm:"not null;default:0"`
+	// BaseTurnover and BaseWins are converted at the rate stored on each
+	// transaction; transactions without one are missing from them.
+	BaseTurnover float64 `gorm:"not null;default:0"`
+	BaseWins     float64 `gorm:"not null;default:0"`
+	CreatedAt    time.Time
+}
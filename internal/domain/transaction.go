@@ -1,21 +1,97 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Transaction struct {
 	ID                 uint    `gorm:"primaryKey"`
 	UserID             uint    `gorm:"index;not null"`
 	BetID              uint    `gorm:"index"`
-	Type               string  `gorm:"not null"` // WITHDRAW, DEPOSIT, CANCEL
+	Type               string  `gorm:"not null"` // WITHDRAW, DEPOSIT, CANCEL, CLAWBACK, BONUS_RELEASE
 	Amount             float64 `gorm:"not null"`
 	OldBalance         float64 `gorm:"not null"`
 	NewBalance         float64 `gorm:"not null"`
-	Status             string  `gorm:"not null"` // WON, LOST, CANCELLED
+	Status             string  `gorm:"not null"` // WON, LOST, CANCELLED, HELD, REJECTED
 	ProviderTxID       string  `gorm:"index"`
 	ProviderParentTxID string  `gorm:"index"` // To link deposit/cancel to original withdraw
 	ProviderRoundID    string  `gorm:"index"`
 	ProviderGameID     string  `gorm:"index"`
 	ProviderSessionID  string  `gorm:"index"`
-	PlatformResponse   string  `gorm:"type:jsonb"`
-	CreatedAt          time.Time
+	ProviderID         string  `gorm:"index"`
+	PlatformResponse   string  `gorm:"type:jsonb"` // a PlatformRecord
+	// Currency is the balance Amount, OldBalance and NewBalance are in.
+	Currency string     `gorm:"index"`
+	FX       FXSnapshot `gorm:"embedded;embeddedPrefix:fx_"`
+	// BonusAmount is the part of Amount staked from, or credited to, the
+	// bonus balance of PlayerBonusID. OldBalance and NewBalance are real
+	// money only.
+	BonusAmount   float64 `gorm:"not null;default:0"`
+	PlayerBonusID *uint   `gorm:"index"`
+	// FreeRoundGrantID marks a zero-cost free-round stake and its win.
+	FreeRoundGrantID *uint `gorm:"index"`
+	// JackpotContribution is the part of a stake added to jackpot pools.
+	// JackpotPoolID marks a jackpot win paid from that pool.
+	JackpotContribution float64 `gorm:"not null;default:0"`
+	JackpotPoolID       *uint   `gorm:"index"`
+	// CancelledAmount is the part of Amount reversed by partial cancels: a
+	// CANCEL refunds a stake, a CLAWBACK takes back a win. Once all of it is
+	// reversed the status is CANCELLED.
+	CancelledAmount float64 `gorm:"not null;default:0"`
+	// ReviewedBy and ReviewedAt record who released or rejected a held win.
+	ReviewedBy string
+	ReviewedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// Debit reports whether the transaction took money from the player.
+func (t *Transaction) Debit() bool {
+	return t.Type == "WITHDRAW" || t.Type == "CLAWBACK"
+}
+
+const (
+	// TransactionStatusHeld marks a win over the round's payout limit. It is
+	// not credited until an admin approves it.
+	TransactionStatusHeld     = "HELD"
+	TransactionStatusRejected = "REJECTED"
+)
+
+// PlatformRecord is the JSON document kept in Transaction.PlatformResponse.
+type PlatformRecord struct {
+	// RoundDetails is the provider's description of the round, as sent
+	// with the bet, for replaying disputed rounds.
+	RoundDetails json.RawMessage `json:"round_details,omitempty"`
+	// Exchange is the provider call that recorded the transaction, kept
+	// until the platform log retention runs out.
+	Exchange *PlatformExchange `json:"exchange,omitempty"`
+}
+
+// PlatformExchange is a provider call with the wallet calls it made. Bodies
+// are redacted; those that are not JSON or are too large are replaced by a
+// note of their size.
+type PlatformExchange struct {
+	ID         string       `json:"id"`
+	Request    *HTTPMessage `json:"request,omitempty"`
+	Response   *HTTPMessage `json:"response,omitempty"`
+	Wallet     []WalletCall `json:"wallet,omitempty"`
+	StartedAt  time.Time    `json:"started_at"`
+	DurationMS float64      `json:"duration_ms,omitempty"`
+}
+
+type HTTPMessage struct {
+	Method string          `json:"method,omitempty"`
+	URL    string          `json:"url,omitempty"`
+	Status int             `json:"status,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// WalletCall is one request to the wallet. Error is set when no response
+// arrived.
+type WalletCall struct {
+	Request    HTTPMessage  `json:"request"`
+	Response   *HTTPMessage `json:"response,omitempty"`
+	Error      string       `json:"error,omitempty"`
+	StartedAt  time.Time    `json:"started_at"`
+	DurationMS float64      `json:"duration_ms"`
 }
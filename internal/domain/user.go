@@ -2,13 +2,31 @@ package domain
 
 import "time"
 
+const (
+	RolePlayer = "player"
+	RoleAdmin  = "admin"
+)
+
 type User struct {
-	ID        uint   `gorm:"primaryKey"`
-	WalletID  string `gorm:"uniqueIndex;not null"`
-	Username  string `gorm:"uniqueIndex;not null"`
-	Password  string `gorm:"not null"`
-	Currency  string `gorm:"not null"`
-	Balance   float64
+	ID       uint   `gorm:"primaryKey"`
+	WalletID string `gorm:"uniqueIndex;not null"`
+	Username string `gorm:"uniqueIndex;not null"`
+	Email    string
+	Password string `gorm:"not null"`
+	Currency string `gorm:"not null"`
+	Role     string `gorm:"not null;default:player"`
+	Balance  float64
+	// BonusBalance holds promotional funds that cannot be cashed out until
+	// the active bonus's wagering requirement is met.
+	BonusBalance float64 `gorm:"not null;default:0"`
+	// TOTPSecret is encrypted at rest. It is set on enrolment and only
+	// enforced once TOTPEnabled is true.
+	TOTPSecret   string
+	TOTPEnabled  bool          `gorm:"not null;default:false"`
+	TOTPLastStep int64         // last accepted TOTP time step, to block code replay
+	Limits       []PlayerLimit `gorm:"foreignKey:UserID"`
+	// Balances are held in currencies other than Currency.
+	Balances  []PlayerBalance `gorm:"foreignKey:UserID"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
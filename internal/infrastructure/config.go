@@ -1,29 +1,736 @@
 package infrastructure
 
 import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
 )
 
+const (
+	ProfileDev  = "dev"
+	ProfileTest = "test"
+	ProfileProd = "prod"
+
+	defaultConfigDir = "config"
+	redactedValue    = "******"
+)
+
+// Config is loaded in layers: profile defaults, then config/config.yaml (or
+// CONFIG_FILE), then config/config.<profile>.yaml, then environment
+// variables. Any env key may instead be read from a file named by KEY_FILE.
+// Each file may be written in TOML instead, with a .toml extension and the
+// same keys.
 type Config struct {
-	DBHost      string
-	DBPort      string
-	DBUser      string
-	DBPassword  string
-	DBName      string
-	WalletURL   string
-	WalletToken string
-	JWTSecret   string
-}
-
-func LoadConfig() *Config {
-	return &Config{
-		DBHost:      os.Getenv("DB_HOST"),
-		DBPort:      os.Getenv("DB_PORT"),
-		DBUser:      os.Getenv("DB_USER"),
-		DBPassword:  os.Getenv("DB_PASSWORD"),
-		DBName:      os.Getenv("DB_NAME"),
-		WalletURL:   os.Getenv("WALLET_URL"),
-		WalletToken: os.Getenv("WALLET_TOKEN"),
-		JWTSecret:   os.Getenv("JWT_SECRET"),
+	Profile   string          `yaml:"profile" env:"APP_PROFILE"`
+	Server    ServerConfig    `yaml:"server"`
+	DB        DBConfig        `yaml:"db"`
+	Wallet    WalletConfig    `yaml:"wallet"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Providers ProvidersConfig `yaml:"providers"`
+	Notifier  NotifierConfig  `yaml:"notifier"`
+	// ResponsibleGaming holds player protection settings.
+	ResponsibleGaming ResponsibleGamingConfig `yaml:"responsible_gaming"`
+	Bonus             BonusConfig             `yaml:"bonus"`
+	FX                FXConfig                `yaml:"fx"`
+	Reconciliation    ReconciliationConfig    `yaml:"reconciliation"`
+	Reporting         ReportingConfig         `yaml:"reporting"`
+	RoundHistory      RoundHistoryConfig      `yaml:"round_history"`
+	PlatformLog       PlatformLogConfig       `yaml:"platform_log"`
+}
+
+type ServerConfig struct {
+	Port            string        `yaml:"port" env:"PORT"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	// TrustedProxies lists, comma-separated, the IPs or CIDRs of proxies
+	// whose X-Forwarded-For is believed. Empty trusts none and uses the
+	// connecting address as the client IP.
+	TrustedProxies string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+}
+
+// Proxies returns the trusted proxies, or nil when there are none.
+func (c ServerConfig) Proxies() []string {
+	return splitList(c.TrustedProxies)
+}
+
+type DBConfig struct {
+	Host            string        `yaml:"host" env:"DB_HOST"`
+	Port            int           `yaml:"port" env:"DB_PORT"`
+	User            string        `yaml:"user" env:"DB_USER"`
+	Password        string        `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name            string        `yaml:"name" env:"DB_NAME"`
+	SSLMode         string        `yaml:"sslmode" env:"DB_SSLMODE"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	// MaxPlayerLocks caps the wallet operations in flight at once. Each holds
+	// its player's row lock and a connection across the wallet call, so at
+	// most this many calls reach the wallet at a time. Zero is half of
+	// MaxOpenConns; it must stay below it so that lock holders can still
+	// get a second connection.
+	MaxPlayerLocks int `yaml:"max_player_locks" env:"DB_MAX_PLAYER_LOCKS"`
+}
+
+type WalletConfig struct {
+	URL              string        `yaml:"url" env:"WALLET_URL"`
+	Token            string        `yaml:"token" env:"WALLET_TOKEN" secret:"true"`
+	ProbeID          int64         `yaml:"probe_id" env:"WALLET_PROBE_ID"`
+	Timeout          time.Duration `yaml:"timeout" env:"WALLET_TIMEOUT"`
+	BreakerThreshold int           `yaml:"breaker_threshold" env:"WALLET_BREAKER_THRESHOLD"`
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown" env:"WALLET_BREAKER_COOLDOWN"`
+}
+
+// RoundHistoryConfig points at the providers' round history APIs. URLs maps
+// a provider ID to a URL in which {round_id} is replaced by the round; it
+// is read from the config file only.
+type RoundHistoryConfig struct {
+	URLs    map[string]string `yaml:"urls"`
+	Timeout time.Duration     `yaml:"timeout" env:"ROUND_HISTORY_TIMEOUT"`
+}
+
+// PlatformLogConfig controls the provider and wallet payloads kept on each
+// transaction. Values of JSON fields named in RedactFields, matched without
+// regard to case, are replaced before storing; RedactFields is read from the
+// config file only. Payloads are removed once older than Retention, checked
+// every PurgeInterval; zero Retention keeps them.
+type PlatformLogConfig struct {
+	Enabled       bool          `yaml:"enabled" env:"PLATFORM_LOG_ENABLED"`
+	Retention     time.Duration `yaml:"retention" env:"PLATFORM_LOG_RETENTION"`
+	PurgeInterval time.Duration `yaml:"purge_interval" env:"PLATFORM_LOG_PURGE_INTERVAL"`
+	MaxBodyBytes  int           `yaml:"max_body_bytes" env:"PLATFORM_LOG_MAX_BODY_BYTES"`
+	RedactFields  []string      `yaml:"redact_fields"`
+}
+
+type AuthConfig struct {
+	// JWTKeysDir holds RSA or Ed25519 PEM keys named <kid>.pem; public-only
+	// keys are used just for verification. JWTSigningKeyID names the key that
+	// signs new tokens.
+	JWTKeysDir      string `yaml:"jwt_keys_dir" env:"JWT_KEYS_DIR"`
+	JWTSigningKeyID string `yaml:"jwt_signing_key_id" env:"JWT_SIGNING_KEY_ID"`
+	// Failed logins within FailureWindow count towards a lockout. The first
+	// lockout lasts LockoutDuration and doubles with every further failure,
+	// up to MaxLockoutDuration.
+	MaxFailedAttempts      int           `yaml:"max_failed_attempts" env:"AUTH_MAX_FAILED_ATTEMPTS"`
+	MaxFailedAttemptsPerIP int           `yaml:"max_failed_attempts_per_ip" env:"AUTH_MAX_FAILED_ATTEMPTS_PER_IP"`
+	FailureWindow          time.Duration `yaml:"failure_window" env:"AUTH_FAILURE_WINDOW"`
+	LockoutDuration        time.Duration `yaml:"lockout_duration" env:"AUTH_LOCKOUT_DURATION"`
+	MaxLockoutDuration     time.Duration `yaml:"max_lockout_duration" env:"AUTH_MAX_LOCKOUT_DURATION"`
+	FailureDelay           time.Duration `yaml:"failure_delay" env:"AUTH_FAILURE_DELAY"`
+	MaxFailureDelay        time.Duration `yaml:"max_failure_delay" env:"AUTH_MAX_FAILURE_DELAY"`
+	PasswordMinLength      int           `yaml:"password_min_length" env:"AUTH_PASSWORD_MIN_LENGTH"`
+	ResetTokenTTL          time.Duration `yaml:"reset_token_ttl" env:"AUTH_RESET_TOKEN_TTL"`
+	// ResetURL is prefixed to the reset token in notification messages.
+	ResetURL string `yaml:"reset_url" env:"AUTH_RESET_URL"`
+	// TOTPIssuer is shown in authenticator apps. TOTPEncryptionKey encrypts
+	// TOTP secrets at rest and must not change once users have enrolled.
+	TOTPIssuer        string `yaml:"totp_issuer" env:"AUTH_TOTP_ISSUER"`
+	TOTPEncryptionKey string `yaml:"totp_encryption_key" env:"AUTH_TOTP_ENCRYPTION_KEY" secret:"true"`
+	// WalletLinkSecret signs the tokens with which players prove they own
+	// the wallet they register with. Registration is closed without it.
+	WalletLinkSecret string `yaml:"wallet_link_secret" env:"AUTH_WALLET_LINK_SECRET" secret:"true"`
+}
+
+type NotifierConfig struct {
+	Backend  string `yaml:"backend" env:"NOTIFIER_BACKEND"`
+	FilePath string `yaml:"file_path" env:"NOTIFIER_FILE_PATH"`
+}
+
+// RateLimitConfig holds token-bucket limits: login is keyed by client IP,
+// bet by user ID and provider by the authenticated provider, with requests
+// from no known provider sharing one bucket. A zero rate disables that
+// limit.
+type RateLimitConfig struct {
+	Backend  string `yaml:"backend" env:"RATE_LIMIT_BACKEND"`
+	Login    Rate   `yaml:"login" envPrefix:"RATE_LIMIT_LOGIN_"`
+	Bet      Rate   `yaml:"bet" envPrefix:"RATE_LIMIT_BET_"`
+	Provider Rate   `yaml:"provider" envPrefix:"RATE_LIMIT_PROVIDER_"`
+}
+
+// ProvidersConfig holds the keys game providers authenticate with, as
+// comma-separated id=key pairs. A provider sends its ID in X-Provider-ID and
+// its key in X-Provider-Key.
+type ProvidersConfig struct {
+	Keys string `yaml:"keys" env:"PROVIDER_KEYS" secret:"true"`
+}
+
+// KeyMap returns the provider keys by provider ID. Malformed pairs are
+// skipped; Validate reports them.
+func (c ProvidersConfig) KeyMap() map[string]string {
+	keys := make(map[string]string)
+	for _, pair := range splitList(c.Keys) {
+		id, key, ok := strings.Cut(pair, "=")
+		if id, key = strings.TrimSpace(id), strings.TrimSpace(key); ok && id != "" && key != "" {
+			keys[id] = key
+		}
+	}
+	return keys
+}
+
+// splitList splits a comma-separated value, dropping empty entries.
+func splitList(raw string) []string {
+	var out []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+type ResponsibleGamingConfig struct {
+	// LimitCoolingOff is how long a raised or removed limit waits before it
+	// applies. Lowered limits apply at once.
+	LimitCoolingOff time.Duration `yaml:"limit_cooling_off" env:"RG_LIMIT_COOLING_OFF"`
+	// RealityCheckInterval is how often a playing session reminds the player
+	// of elapsed time and net result; zero disables reminders. A session ends
+	// after SessionIdleTimeout without bets.
+	RealityCheckInterval time.Duration `yaml:"reality_check_interval" env:"RG_REALITY_CHECK_INTERVAL"`
+	SessionIdleTimeout   time.Duration `yaml:"session_idle_timeout" env:"RG_SESSION_IDLE_TIMEOUT"`
+	// CashierKey authenticates the cashier asking, in X-Cashier-Key, whether
+	// a player may deposit. Deposit checks are refused without it.
+	CashierKey string `yaml:"cashier_key" env:"RG_CASHIER_KEY" secret:"true"`
+}
+
+// Bonus spend orders: which balance a stake draws on first.
+const (
+	BonusSpendRealFirst  = "real_first"
+	BonusSpendBonusFirst = "bonus_first"
+)
+
+type BonusConfig struct {
+	SpendOrder string `yaml:"spend_order" env:"BONUS_SPEND_ORDER"`
+	// ExpiryInterval is how often expired bonuses are swept and forfeited.
+	ExpiryInterval time.Duration `yaml:"expiry_interval" env:"BONUS_EXPIRY_INTERVAL"`
+}
+
+// FXConfig sets the reporting base currency and the static rates file. Rates
+// in the file are units of each currency per one unit of BaseCurrency.
+type FXConfig struct {
+	BaseCurrency string `yaml:"base_currency" env:"FX_BASE_CURRENCY"`
+	RatesFile    string `yaml:"rates_file" env:"FX_RATES_FILE"`
+}
+
+// ReconciliationConfig schedules the nightly check of the previous UTC day
+// against the wallet. RunAt is a UTC time of day such as 02:30; empty
+// disables the job.
+type ReconciliationConfig struct {
+	RunAt string `yaml:"run_at" env:"RECONCILIATION_RUN_AT"`
+}
+
+// NextRun returns the first scheduled time after now.
+func (c ReconciliationConfig) NextRun(now time.Time) time.Time {
+	return nextDailyRun(c.RunAt, now)
+}
+
+// ReportingConfig schedules the nightly revenue aggregation of the previous
+// UTC day. AggregateAt is a UTC time of day; empty disables the job.
+type ReportingConfig struct {
+	AggregateAt string `yaml:"aggregate_at" env:"REPORTING_AGGREGATE_AT"`
+}
+
+// NextRun returns the first scheduled time after now.
+func (c ReportingConfig) NextRun(now time.Time) time.Time {
+	return nextDailyRun(c.AggregateAt, now)
+}
+
+// nextDailyRun returns the first time after now at the UTC time of day at,
+// given as HH:MM.
+func nextDailyRun(at string, now time.Time) time.Time {
+	t, _ := time.Parse("15:04", at)
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+type TracingConfig struct {
+	ServiceName string `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+	Exporter    string `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+}
+
+func DefaultConfig(profile string) *Config {
+	cfg := &Config{
+		Profile: profile,
+		Server: ServerConfig{
+			Port:            "8080",
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     120 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		DB: DBConfig{
+			Host:            "localhost",
+			Port:            5432,
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+		},
+		Wallet: WalletConfig{
+			Timeout:          10 * time.Second,
+			BreakerThreshold: 5,
+			BreakerCooldown:  30 * time.Second,
+		},
+		Auth: AuthConfig{
+			MaxFailedAttempts:      5,
+			MaxFailedAttemptsPerIP: 50,
+			FailureWindow:          15 * time.Minute,
+			LockoutDuration:        5 * time.Minute,
+			MaxLockoutDuration:     24 * time.Hour,
+			FailureDelay:           250 * time.Millisecond,
+			MaxFailureDelay:        4 * time.Second,
+			PasswordMinLength:      8,
+			ResetTokenTTL:          time.Hour,
+			ResetURL:               "http://localhost:8080/auth/password-reset?token=",
+			TOTPIssuer:             "Game Integration API",
+		},
+		Tracing: TracingConfig{
+			ServiceName: defaultServiceName,
+			Exporter:    TracingExporterStdout,
+		},
+		Notifier: NotifierConfig{
+			Backend:  NotifierBackendLog,
+			FilePath: "notifications.log",
+		},
+		RateLimit: RateLimitConfig{
+			Backend:  RateLimitBackendMemory,
+			Login:    Rate{PerSecond: 0.2, Burst: 5},
+			Bet:      Rate{PerSecond: 20, Burst: 40},
+			Provider: Rate{PerSecond: 200, Burst: 400},
+		},
+		ResponsibleGaming: ResponsibleGamingConfig{
+			LimitCoolingOff:      24 * time.Hour,
+			RealityCheckInterval: time.Hour,
+			SessionIdleTimeout:   30 * time.Minute,
+		},
+		Bonus: BonusConfig{
+			SpendOrder:     BonusSpendRealFirst,
+			ExpiryInterval: 5 * time.Minute,
+		},
+		FX: FXConfig{
+			BaseCurrency: "USD",
+		},
+		Reconciliation: ReconciliationConfig{
+			RunAt: "02:00",
+		},
+		Reporting: ReportingConfig{
+			AggregateAt: "01:00",
+		},
+		RoundHistory: RoundHistoryConfig{
+			Timeout: 5 * time.Second,
+		},
+		PlatformLog: PlatformLogConfig{
+			Enabled:       true,
+			Retention:     90 * 24 * time.Hour,
+			PurgeInterval: time.Hour,
+			MaxBodyBytes:  16 << 10,
+			RedactFields: []string{
+				"password", "token", "access_token", "refresh_token", "api_key", "secret",
+				"email", "phone", "first_name", "last_name", "address", "date_of_birth", "iban", "card_number",
+			},
+		},
+	}
+	switch profile {
+	case ProfileDev:
+		cfg.Auth.TOTPEncryptionKey = "insecure-dev-totp-key"
+	case ProfileTest:
+		cfg.Auth.TOTPEncryptionKey = "insecure-test-totp-key"
+		cfg.Tracing.Exporter = TracingExporterNone
+		cfg.Server.ShutdownTimeout = 5 * time.Second
+	case ProfileProd:
+		cfg.Tracing.Exporter = TracingExporterOTLP
+		cfg.DB.SSLMode = "require"
+	}
+	return cfg
+}
+
+// LoadConfig builds the configuration and validates it. The returned error
+// lists every problem found, one per line.
+func LoadConfig() (*Config, error) {
+	profile := os.Getenv("APP_PROFILE")
+	if profile == "" {
+		profile = ProfileDev
+	}
+	cfg := DefaultConfig(profile)
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := loadConfigFile(cfg, path, true); err != nil {
+			return nil, err
+		}
+	} else if err := loadConfigFile(cfg, configFile("config"), false); err != nil {
+		return nil, err
+	}
+	if err := loadConfigFile(cfg, configFile("config."+profile), false); err != nil {
+		return nil, err
+	}
+	// A file may not switch profile after its defaults were applied.
+	cfg.Profile = profile
+
+	if err := applyEnv(reflect.ValueOf(cfg).Elem(), ""); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// configFile returns the path of the named file in the config directory,
+// preferring YAML when both formats are present.
+func configFile(name string) string {
+	path := filepath.Join(defaultConfigDir, name+".yaml")
+	if toml := filepath.Join(defaultConfigDir, name+".toml"); !fileExists(path) && fileExists(toml) {
+		return toml
+	}
+	return path
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func loadConfigFile(cfg *Config, path string, required bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !required {
+			return nil
+		}
+		return fmt.Errorf("read config file %s: %w", path, err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		// Decoded through YAML so both formats share keys, duration
+		// parsing and the unknown-key check.
+		var doc map[string]interface{}
+		if err := toml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("parse config file %s: %w", path, err)
+		}
+		if data, err = yaml.Marshal(doc); err != nil {
+			return fmt.Errorf("parse config file %s: %w", path, err)
+		}
+	}
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv overrides fields tagged with env from KEY or, failing that, from
+// the contents of the file named by KEY_FILE. Nested structs may set
+// envPrefix to namespace the keys of their fields.
+func applyEnv(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			if err := applyEnv(fv, prefix+field.Tag.Get("envPrefix")); err != nil {
+				return err
+			}
+			continue
+		}
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		key = prefix + key
+		raw, ok := os.LookupEnv(key)
+		if !ok || raw == "" {
+			path := os.Getenv(key + "_FILE")
+			if path == "" {
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("read %s_FILE: %w", key, err)
+			}
+			raw = strings.TrimSpace(string(data))
+		}
+		if err := setField(fv, raw); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func setField(v reflect.Value, raw string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported kind %s", v.Kind())
+	}
+	return nil
+}
+
+// Validate checks required fields and ranges and reports all problems at once.
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(key, env, msg string) {
+		problems = append(problems, fmt.Sprintf("%s: %s (set %s or %s in the config file)", key, msg, env, key))
+	}
+
+	switch c.Profile {
+	case ProfileDev, ProfileTest, ProfileProd:
+	default:
+		problems = append(problems, fmt.Sprintf("profile: unknown profile %q (want dev, test or prod)", c.Profile))
+	}
+
+	if p, err := strconv.Atoi(c.Server.Port); err != nil || p <= 0 || p > 65535 {
+		add("server.port", "PORT", "must be a port number")
+	}
+	for _, d := range []struct {
+		key, env string
+		value    time.Duration
+	}{
+		{"server.read_timeout", "SERVER_READ_TIMEOUT", c.Server.ReadTimeout},
+		{"server.write_timeout", "SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout},
+		{"server.idle_timeout", "SERVER_IDLE_TIMEOUT", c.Server.IdleTimeout},
+		{"server.shutdown_timeout", "SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout},
+		{"wallet.timeout", "WALLET_TIMEOUT", c.Wallet.Timeout},
+		{"wallet.breaker_cooldown", "WALLET_BREAKER_COOLDOWN", c.Wallet.BreakerCooldown},
+		{"bonus.expiry_interval", "BONUS_EXPIRY_INTERVAL", c.Bonus.ExpiryInterval},
+		{"round_history.timeout", "ROUND_HISTORY_TIMEOUT", c.RoundHistory.Timeout},
+		{"platform_log.purge_interval", "PLATFORM_LOG_PURGE_INTERVAL", c.PlatformLog.PurgeInterval},
+	} {
+		if d.value <= 0 {
+			add(d.key, d.env, "must be a positive duration")
+		}
+	}
+
+	for _, proxy := range c.Server.Proxies() {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				add("server.trusted_proxies", "TRUSTED_PROXIES", fmt.Sprintf("%q is not an IP or CIDR", proxy))
+			}
+		}
+	}
+
+	if c.DB.Host == "" {
+		add("db.host", "DB_HOST", "required")
+	}
+	if c.DB.Port <= 0 || c.DB.Port > 65535 {
+		add("db.port", "DB_PORT", "must be a port number")
+	}
+	if c.DB.User == "" {
+		add("db.user", "DB_USER", "required")
+	}
+	if c.DB.Name == "" {
+		add("db.name", "DB_NAME", "required")
+	}
+	if c.DB.MaxOpenConns <= 0 {
+		add("db.max_open_conns", "DB_MAX_OPEN_CONNS", "must be greater than zero")
+	}
+	if c.DB.MaxIdleConns < 0 || c.DB.MaxIdleConns > c.DB.MaxOpenConns {
+		add("db.max_idle_conns", "DB_MAX_IDLE_CONNS", "must be between 0 and db.max_open_conns")
+	}
+	if c.DB.MaxPlayerLocks < 0 || (c.DB.MaxOpenConns > 0 && c.DB.MaxPlayerLocks >= c.DB.MaxOpenConns) {
+		add("db.max_player_locks", "DB_MAX_PLAYER_LOCKS", "must be at least 0 and below db.max_open_conns")
+	}
+
+	if c.Wallet.URL == "" {
+		add("wallet.url", "WALLET_URL", "required")
+	} else if u, err := url.Parse(c.Wallet.URL); err != nil || u.Scheme == "" || u.Host == "" {
+		add("wallet.url", "WALLET_URL", "must be an absolute URL such as http://wallet:8000")
+	}
+	if c.Wallet.Token == "" {
+		add("wallet.token", "WALLET_TOKEN", "required")
+	}
+	if c.Wallet.BreakerThreshold <= 0 {
+		add("wallet.breaker_threshold", "WALLET_BREAKER_THRESHOLD", "must be greater than zero")
+	}
+	if c.PlatformLog.Retention < 0 {
+		add("platform_log.retention", "PLATFORM_LOG_RETENTION", "must not be negative")
+	}
+	if c.PlatformLog.MaxBodyBytes <= 0 {
+		add("platform_log.max_body_bytes", "PLATFORM_LOG_MAX_BODY_BYTES", "must be greater than zero")
+	}
+	for provider, raw := range c.RoundHistory.URLs {
+		if u, err := url.Parse(raw); err != nil || u.Scheme == "" || u.Host == "" || !strings.Contains(raw, "{round_id}") {
+			problems = append(problems, fmt.Sprintf("round_history.urls.%s: must be an absolute URL containing {round_id}", provider))
+		}
+	}
+
+	if c.Auth.MaxFailedAttempts <= 0 {
+		add("auth.max_failed_attempts", "AUTH_MAX_FAILED_ATTEMPTS", "must be greater than zero")
+	}
+	if c.Auth.MaxFailedAttemptsPerIP <= 0 {
+		add("auth.max_failed_attempts_per_ip", "AUTH_MAX_FAILED_ATTEMPTS_PER_IP", "must be greater than zero")
+	}
+	if c.Auth.FailureWindow <= 0 || c.Auth.LockoutDuration <= 0 {
+		add("auth.lockout_duration", "AUTH_LOCKOUT_DURATION", "lockout duration and failure window must be positive")
+	}
+	if c.Auth.MaxLockoutDuration < c.Auth.LockoutDuration {
+		add("auth.max_lockout_duration", "AUTH_MAX_LOCKOUT_DURATION", "must not be shorter than auth.lockout_duration")
+	}
+	if c.Auth.FailureDelay < 0 || c.Auth.MaxFailureDelay < c.Auth.FailureDelay {
+		add("auth.max_failure_delay", "AUTH_MAX_FAILURE_DELAY", "must not be shorter than auth.failure_delay")
+	}
+
+	if c.Auth.PasswordMinLength < 8 || c.Auth.PasswordMinLength > 72 {
+		add("auth.password_min_length", "AUTH_PASSWORD_MIN_LENGTH", "must be between 8 and 72")
+	}
+	if c.Auth.ResetTokenTTL <= 0 {
+		add("auth.reset_token_ttl", "AUTH_RESET_TOKEN_TTL", "must be a positive duration")
+	}
+
+	switch c.Notifier.Backend {
+	case NotifierBackendLog:
+	case NotifierBackendFile:
+		if c.Notifier.FilePath == "" {
+			add("notifier.file_path", "NOTIFIER_FILE_PATH", "required for the file backend")
+		}
+	default:
+		add("notifier.backend", "NOTIFIER_BACKEND", "must be log or file")
+	}
+
+	switch c.Bonus.SpendOrder {
+	case BonusSpendRealFirst, BonusSpendBonusFirst:
+	default:
+		add("bonus.spend_order", "BONUS_SPEND_ORDER", "must be real_first or bonus_first")
+	}
+
+	if len(c.FX.BaseCurrency) != 3 {
+		add("fx.base_currency", "FX_BASE_CURRENCY", "must be a three-letter currency code")
+	}
+
+	for _, s := range []struct {
+		key, env, value string
+	}{
+		{"reconciliation.run_at", "RECONCILIATION_RUN_AT", c.Reconciliation.RunAt},
+		{"reporting.aggregate_at", "REPORTING_AGGREGATE_AT", c.Reporting.AggregateAt},
+	} {
+		if _, err := time.Parse("15:04", s.value); s.value != "" && err != nil {
+			add(s.key, s.env, "must be a UTC time of day such as 02:30, or empty to disable")
+		}
+	}
+
+	if c.Profile == ProfileProd && c.Auth.JWTKeysDir == "" {
+		add("auth.jwt_keys_dir", "JWT_KEYS_DIR", "required in the prod profile")
+	}
+	if c.Auth.JWTKeysDir != "" && c.Auth.JWTSigningKeyID == "" {
+		add("auth.jwt_signing_key_id", "JWT_SIGNING_KEY_ID", "required when auth.jwt_keys_dir is set")
+	}
+	if c.Auth.TOTPEncryptionKey == "" {
+		add("auth.totp_encryption_key", "AUTH_TOTP_ENCRYPTION_KEY", "required")
+	}
+
+	switch c.Tracing.Exporter {
+	case TracingExporterOTLP, TracingExporterStdout, TracingExporterNone:
+	default:
+		add("tracing.exporter", "OTEL_TRACES_EXPORTER", "must be one of otlp, stdout, none")
+	}
+
+	switch c.RateLimit.Backend {
+	case RateLimitBackendMemory, RateLimitBackendPostgres:
+	default:
+		add("rate_limit.backend", "RATE_LIMIT_BACKEND", "must be memory or postgres")
+	}
+	for _, r := range []struct {
+		key, env string
+		rate     Rate
+	}{
+		{"rate_limit.login", "RATE_LIMIT_LOGIN_", c.RateLimit.Login},
+		{"rate_limit.bet", "RATE_LIMIT_BET_", c.RateLimit.Bet},
+		{"rate_limit.provider", "RATE_LIMIT_PROVIDER_", c.RateLimit.Provider},
+	} {
+		if r.rate.PerSecond < 0 || r.rate.Burst < 0 {
+			add(r.key, r.env+"*", "must not be negative")
+		} else if r.rate.PerSecond > 0 && r.rate.Burst < 1 {
+			add(r.key+".burst", r.env+"BURST", "must be at least 1 when the limit is enabled")
+		}
+	}
+
+	if len(c.Providers.KeyMap()) != len(splitList(c.Providers.Keys)) {
+		add("providers.keys", "PROVIDER_KEYS", "must be comma-separated id=key pairs with distinct IDs")
+	}
+
+	if c.ResponsibleGaming.LimitCoolingOff < 0 {
+		add("responsible_gaming.limit_cooling_off", "RG_LIMIT_COOLING_OFF", "must not be negative")
+	}
+	if c.ResponsibleGaming.RealityCheckInterval < 0 {
+		add("responsible_gaming.reality_check_interval", "RG_REALITY_CHECK_INTERVAL", "must not be negative")
+	}
+	if c.ResponsibleGaming.SessionIdleTimeout <= 0 {
+		add("responsible_gaming.session_idle_timeout", "RG_SESSION_IDLE_TIMEOUT", "must be a positive duration")
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration:\n  - " + strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// YAML renders the configuration in file format. Secret fields are masked
+// when redacted is true.
+func (c *Config) YAML(redacted bool) ([]byte, error) {
+	return yaml.Marshal(configNode(reflect.ValueOf(c).Elem(), redacted))
+}
+
+func configNode(v reflect.Value, redacted bool) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		fv := v.Field(i)
+		var value *yaml.Node
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			value = configNode(fv, redacted)
+		case redacted && field.Tag.Get("secret") == "true" && fv.String() != "":
+			value = &yaml.Node{Kind: yaml.ScalarNode, Value: redactedValue}
+		case field.Type == reflect.TypeOf(time.Duration(0)):
+			value = &yaml.Node{Kind: yaml.ScalarNode, Value: time.Duration(fv.Int()).String()}
+		default:
+			value = &yaml.Node{}
+			value.Encode(fv.Interface())
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
 	}
+	return node
 }
@@ -0,0 +1,167 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gameintegrationapi/internal/domain"
+)
+
+const redacted = "[REDACTED]"
+
+type exchangeKey struct{}
+
+// ExchangeLogger records provider calls and the wallet calls made while
+// serving them, redacting their bodies.
+type ExchangeLogger struct {
+	enabled bool
+	maxBody int
+	redact  map[string]bool
+}
+
+func NewExchangeLogger(cfg PlatformLogConfig) *ExchangeLogger {
+	l := &ExchangeLogger{enabled: cfg.Enabled, maxBody: cfg.MaxBodyBytes, redact: make(map[string]bool)}
+	for _, f := range cfg.RedactFields {
+		l.redact[strings.ToLower(f)] = true
+	}
+	return l
+}
+
+// Start records a provider request and returns a context carrying it. It
+// returns ctx unchanged and a nil Exchange when logging is disabled.
+func (l *ExchangeLogger) Start(ctx context.Context, method, url string, body []byte) (context.Context, *Exchange) {
+	if l == nil || !l.enabled {
+		return ctx, nil
+	}
+	id := make([]byte, 12)
+	rand.Read(id)
+	e := &Exchange{logger: l, record: domain.PlatformExchange{
+		ID:        hex.EncodeToString(id),
+		Request:   &domain.HTTPMessage{Method: method, URL: url, Body: l.body(body)},
+		StartedAt: time.Now(),
+	}}
+	return context.WithValue(ctx, exchangeKey{}, e), e
+}
+
+// body redacts a JSON body, or describes one that cannot be stored.
+func (l *ExchangeLogger) body(data []byte) json.RawMessage {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil
+	}
+	if len(data) > l.maxBody {
+		return omittedBody(len(data), "too large")
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return omittedBody(len(data), "not JSON")
+	}
+	out, err := json.Marshal(l.redactValue(v))
+	if err != nil {
+		return omittedBody(len(data), "not JSON")
+	}
+	return out
+}
+
+func (l *ExchangeLogger) redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			if l.redact[strings.ToLower(k)] {
+				v[k] = redacted
+			} else {
+				v[k] = l.redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = l.redactValue(v[i])
+		}
+	}
+	return v
+}
+
+func omittedBody(size int, why string) json.RawMessage {
+	out, _ := json.Marshal(fmt.Sprintf("[%d bytes omitted: %s]", size, why))
+	return out
+}
+
+// Exchange is one provider call being recorded. A nil Exchange records
+// nothing, so callers need not check whether logging is enabled.
+type Exchange struct {
+	logger   *ExchangeLogger
+	mu       sync.Mutex
+	record   domain.PlatformExchange
+	attached bool
+}
+
+// ExchangeFrom returns the exchange recorded for ctx, or nil.
+func ExchangeFrom(ctx context.Context) *Exchange {
+	e, _ := ctx.Value(exchangeKey{}).(*Exchange)
+	return e
+}
+
+// WalletCall records a call to the wallet. status and response are ignored
+// when callErr is set.
+func (e *Exchange) WalletCall(method, url string, request []byte, status int, response []byte, started time.Time, callErr error) {
+	if e == nil {
+		return
+	}
+	call := domain.WalletCall{
+		Request:    domain.HTTPMessage{Method: method, URL: url, Body: e.logger.body(request)},
+		StartedAt:  started,
+		DurationMS: milliseconds(time.Since(started)),
+	}
+	if callErr != nil {
+		call.Error = callErr.Error()
+	} else {
+		call.Response = &domain.HTTPMessage{Status: status, Body: e.logger.body(response)}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.record.Wallet = append(e.record.Wallet, call)
+}
+
+// Attach returns the exchange so far for storing on a transaction, and
+// marks it as stored.
+func (e *Exchange) Attach() *domain.PlatformExchange {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.attached = true
+	return e.snapshot()
+}
+
+// Finish records our response and returns the complete exchange. ok is
+// false if it was never attached to a transaction.
+func (e *Exchange) Finish(status int, body []byte) (record *domain.PlatformExchange, ok bool) {
+	if e == nil {
+		return nil, false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.record.Response = &domain.HTTPMessage{Status: status, Body: e.logger.body(body)}
+	e.record.DurationMS = milliseconds(time.Since(e.record.StartedAt))
+	return e.snapshot(), e.attached
+}
+
+func (e *Exchange) snapshot() *domain.PlatformExchange {
+	record := e.record
+	record.Wallet = append([]domain.WalletCall(nil), e.record.Wallet...)
+	return &record
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,102 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrRateUnavailable = errors.New("exchange rate not available")
+
+// FXRate converts From to To: one unit of From is Rate units of To.
+type FXRate struct {
+	From   string
+	To     string
+	Rate   float64
+	Source string
+	AsOf   time.Time
+}
+
+// RateProvider quotes exchange rates. Implementations must return
+// ErrRateUnavailable for a pair they cannot quote.
+type RateProvider interface {
+	Rate(ctx context.Context, from, to string) (FXRate, error)
+	// Base is the currency reports are converted to.
+	Base() string
+}
+
+// StaticRateProvider serves rates read once from a YAML file of the form
+//
+//	as_of: 2025-01-01T00:00:00Z
+//	rates:      # units of each currency per one unit of the base
+//	  EUR: 0.92
+//	  KES: 129.5
+//
+// It is meant for local use and tests. Without a file only the base
+// currency and same-currency pairs can be quoted.
+type StaticRateProvider struct {
+	base   string
+	rates  map[string]float64
+	asOf   time.Time
+	source string
+}
+
+type staticRatesFile struct {
+	AsOf  time.Time          `yaml:"as_of"`
+	Rates map[string]float64 `yaml:"rates"`
+}
+
+func NewStaticRateProvider(cfg FXConfig) (*StaticRateProvider, error) {
+	base := strings.ToUpper(cfg.BaseCurrency)
+	p := &StaticRateProvider{base: base, rates: map[string]float64{base: 1}, source: "static"}
+	if cfg.RatesFile == "" {
+		return p, nil
+	}
+	data, err := os.ReadFile(cfg.RatesFile)
+	if err != nil {
+		return nil, fmt.Errorf("read FX rates file: %w", err)
+	}
+	var file staticRatesFile
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("parse FX rates file %s: %w", cfg.RatesFile, err)
+	}
+	for currency, rate := range file.Rates {
+		if rate <= 0 {
+			return nil, fmt.Errorf("FX rates file %s: rate for %s must be positive", cfg.RatesFile, currency)
+		}
+		p.rates[strings.ToUpper(currency)] = rate
+	}
+	if p.rates[base] != 1 {
+		return nil, fmt.Errorf("FX rates file %s: base currency %s must have rate 1", cfg.RatesFile, base)
+	}
+	p.asOf = file.AsOf
+	p.source = "static:" + cfg.RatesFile
+	return p, nil
+}
+
+func (p *StaticRateProvider) Base() string {
+	return p.base
+}
+
+func (p *StaticRateProvider) Rate(ctx context.Context, from, to string) (FXRate, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return FXRate{From: from, To: to, Rate: 1, Source: p.source, AsOf: p.asOf}, nil
+	}
+	fromRate, ok := p.rates[from]
+	if !ok {
+		return FXRate{}, fmt.Errorf("%w: %s", ErrRateUnavailable, from)
+	}
+	toRate, ok := p.rates[to]
+	if !ok {
+		return FXRate{}, fmt.Errorf("%w: %s", ErrRateUnavailable, to)
+	}
+	return FXRate{From: from, To: to, Rate: toRate / fromRate, Source: p.source, AsOf: p.asOf}, nil
+}
@@ -0,0 +1,157 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+
+	readinessCacheTTL  = 5 * time.Second
+	dependencyTimeout  = 2 * time.Second
+	defaultMigrationID = "automigrate"
+)
+
+// Version is the build version, overridden at link time with
+// -ldflags "-X gameintegrationapi/internal/infrastructure.Version=...".
+var Version = "dev"
+
+type DependencyStatus struct {
+	Status       string  `json:"status"`
+	LatencyMs    float64 `json:"latency_ms"`
+	Error        string  `json:"error,omitempty"`
+	BreakerState string  `json:"circuit_breaker,omitempty"`
+}
+
+type ReadinessReport struct {
+	Ready        bool                        `json:"ready"`
+	CheckedAt    time.Time                   `json:"checked_at"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+}
+
+type StatusReport struct {
+	Version          string    `json:"version"`
+	MigrationVersion string    `json:"migration_version"`
+	StartedAt        time.Time `json:"started_at"`
+	Uptime           string    `json:"uptime"`
+	ReadinessReport
+}
+
+// HealthChecker probes the database and wallet service. Readiness results are
+// cached briefly so that frequent orchestrator probes do not hit the wallet.
+type HealthChecker struct {
+	db               *gorm.DB
+	walletClient     *WalletClient
+	walletProbeID    int64
+	migrationVersion string
+	startedAt        time.Time
+
+	mu     sync.Mutex
+	cached *ReadinessReport
+}
+
+func NewHealthChecker(db *gorm.DB, walletClient *WalletClient, walletProbeID int64, migrationsDir string) *HealthChecker {
+	return &HealthChecker{
+		db:               db,
+		walletClient:     walletClient,
+		walletProbeID:    walletProbeID,
+		migrationVersion: LatestMigration(migrationsDir),
+		startedAt:        time.Now(),
+	}
+}
+
+// Ready returns the cached readiness report, refreshing it when stale.
+func (h *HealthChecker) Ready(ctx context.Context) ReadinessReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cached != nil && time.Since(h.cached.CheckedAt) < readinessCacheTTL {
+		return *h.cached
+	}
+	report := h.check(ctx)
+	h.cached = &report
+	return report
+}
+
+func (h *HealthChecker) Status(ctx context.Context) StatusReport {
+	return StatusReport{
+		Version:          Version,
+		MigrationVersion: h.migrationVersion,
+		StartedAt:        h.startedAt,
+		Uptime:           time.Since(h.startedAt).Round(time.Second).String(),
+		ReadinessReport:  h.Ready(ctx),
+	}
+}
+
+func (h *HealthChecker) check(ctx context.Context) ReadinessReport {
+	deps := map[string]DependencyStatus{
+		"database": probe(ctx, h.pingDB),
+		"wallet":   probe(ctx, h.pingWallet),
+	}
+	wallet := deps["wallet"]
+	wallet.BreakerState = h.walletClient.BreakerState()
+	deps["wallet"] = wallet
+
+	ready := true
+	for _, d := range deps {
+		if d.Status != HealthStatusUp {
+			ready = false
+		}
+	}
+	return ReadinessReport{Ready: ready, CheckedAt: time.Now(), Dependencies: deps}
+}
+
+func (h *HealthChecker) pingDB(ctx context.Context) error {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func (h *HealthChecker) pingWallet(ctx context.Context) error {
+	return h.walletClient.Ping(ctx, h.walletProbeID)
+}
+
+func probe(ctx context.Context, fn func(context.Context) error) DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, dependencyTimeout)
+	defer cancel()
+	start := time.Now()
+	err := fn(ctx)
+	status := DependencyStatus{
+		Status:    HealthStatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		status.Status = HealthStatusDown
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// LatestMigration returns the name of the last SQL migration in dir, or
+// "automigrate" when the schema is managed only by GORM AutoMigrate.
+func LatestMigration(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return defaultMigrationID
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".sql") {
+			names = append(names, strings.TrimSuffix(e.Name(), ".sql"))
+		}
+	}
+	if len(names) == 0 {
+		return defaultMigrationID
+	}
+	sort.Strings(names)
+	return names[len(names)-1]
+}
@@ -1,33 +1,213 @@
 package infrastructure
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// PurposeMFAChallenge marks a short-lived token that only proves the password
+// step of a two-factor login. It must not be accepted as an access token.
+const PurposeMFAChallenge = "mfa_challenge"
+
+const (
+	accessTokenTTL  = 24 * time.Hour
+	mfaChallengeTTL = 5 * time.Minute
+	minRSAKeyBits   = 2048
+)
+
 type Claims struct {
 	UserID   uint   `json:"user_id"`
 	Username string `json:"username"`
+	Role     string `json:"role"`
+	// MFA is true when the session was established with a second factor.
+	MFA     bool   `json:"mfa,omitempty"`
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID uint, username, secret string) (string, error) {
-	claims := Claims{
+// JWTKeys signs tokens with a single private key and verifies them with any
+// loaded key, picked by the token's kid header. To rotate, add the new key,
+// switch the signing key ID, and remove the old key once the tokens it signed
+// have expired. Keeping only the public half of a retired key is enough to
+// verify.
+type JWTKeys struct {
+	signing *jwtKey
+	keys    map[string]*jwtKey
+}
+
+type jwtKey struct {
+	id      string
+	method  jwt.SigningMethod
+	private interface{} // nil for verification-only keys
+	public  interface{}
+}
+
+// LoadJWTKeys reads <kid>.pem files from cfg.JWTKeysDir. Without a directory
+// it generates a throwaway Ed25519 key, so tokens do not survive a restart;
+// Validate rejects that in the prod profile.
+func LoadJWTKeys(cfg AuthConfig) (*JWTKeys, error) {
+	if cfg.JWTKeysDir == "" {
+		keys, err := NewEphemeralJWTKeys()
+		if err != nil {
+			return nil, err
+		}
+		Logger.Printf("No JWT keys directory configured, signing with ephemeral key %s", keys.signing.id)
+		return keys, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(cfg.JWTKeysDir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	pems := make(map[string][]byte, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		pems[strings.TrimSuffix(filepath.Base(path), ".pem")] = data
+	}
+	return NewJWTKeysFromPEM(cfg.JWTSigningKeyID, pems)
+}
+
+// NewJWTKeysFromPEM builds a key set from PEM blocks keyed by kid. RSA keys
+// sign with RS256 and Ed25519 keys with EdDSA.
+func NewJWTKeysFromPEM(signingKeyID string, pems map[string][]byte) (*JWTKeys, error) {
+	keys := &JWTKeys{keys: make(map[string]*jwtKey, len(pems))}
+	for id, data := range pems {
+		key, err := parseJWTKey(id, data)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", id, err)
+		}
+		keys.keys[id] = key
+	}
+	signing, ok := keys.keys[signingKeyID]
+	if !ok {
+		return nil, fmt.Errorf("jwt signing key %q not found", signingKeyID)
+	}
+	if signing.private == nil {
+		return nil, fmt.Errorf("jwt signing key %q is a public key", signingKeyID)
+	}
+	keys.signing = signing
+	return keys, nil
+}
+
+// NewEphemeralJWTKeys generates an in-memory Ed25519 signing key.
+func NewEphemeralJWTKeys() (*JWTKeys, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	key := &jwtKey{
+		id:      "ephemeral-" + hex.EncodeToString(suffix),
+		method:  jwt.SigningMethodEdDSA,
+		private: private,
+		public:  public,
+	}
+	return &JWTKeys{signing: key, keys: map[string]*jwtKey{key.id: key}}, nil
+}
+
+func parseJWTKey(id string, data []byte) (*jwtKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &jwtKey{id: id}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.method, key.public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.method, key.public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", parsed)
+	}
+	if pub, ok := key.public.(*rsa.PublicKey); ok && pub.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("RSA key must be at least %d bits", minRSAKeyBits)
+	}
+	return key, nil
+}
+
+func (k *JWTKeys) GenerateJWT(userID uint, username, role string, mfa bool) (string, error) {
+	return k.sign(Claims{
 		UserID:   userID,
 		Username: username,
+		Role:     role,
+		MFA:      mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(accessTokenTTL)),
 		},
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	})
 }
 
-func ParseJWT(tokenStr, secret string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
+func (k *JWTKeys) GenerateMFAChallengeJWT(userID uint, username string) (string, error) {
+	return k.sign(Claims{
+		UserID:   userID,
+		Username: username,
+		Purpose:  PurposeMFAChallenge,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaChallengeTTL)),
+		},
 	})
+}
+
+func (k *JWTKeys) sign(claims Claims) (string, error) {
+	token := jwt.NewWithClaims(k.signing.method, claims)
+	token.Header["kid"] = k.signing.id
+	return token.SignedString(k.signing.private)
+}
+
+func (k *JWTKeys) ParseJWT(tokenStr string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := k.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("key %q does not sign with %s", kid, token.Method.Alg())
+		}
+		return key.public, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}))
 	if err != nil {
 		return nil, err
 	}
@@ -36,3 +216,40 @@ func ParseJWT(tokenStr, secret string) (*Claims, error) {
 	}
 	return nil, jwt.ErrTokenInvalidClaims
 }
+
+// JWK is a public key in RFC 7517 form.
+type JWK struct {
+	Kty string `json:"kty" example:"OKP"`
+	Use string `json:"use" example:"sig"`
+	Alg string `json:"alg" example:"EdDSA"`
+	Kid string `json:"kid" example:"2025-01"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty" example:"Ed25519"`
+	X   string `json:"x,omitempty" example:"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public half of every key, sorted by kid.
+func (k *JWTKeys) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(k.keys))}
+	for _, key := range k.keys {
+		jwk := JWK{Use: "sig", Alg: key.method.Alg(), Kid: key.id}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
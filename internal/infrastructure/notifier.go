@@ -0,0 +1,56 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	NotifierBackendLog  = "log"
+	NotifierBackendFile = "file"
+)
+
+// Notifier delivers account messages such as password reset links to a user.
+type Notifier interface {
+	Notify(ctx context.Context, recipient, subject, body string) error
+}
+
+func NewNotifier(cfg NotifierConfig) (Notifier, error) {
+	switch cfg.Backend {
+	case NotifierBackendLog:
+		return LogNotifier{}, nil
+	case NotifierBackendFile:
+		return &FileNotifier{Path: cfg.FilePath}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier backend: %s", cfg.Backend)
+	}
+}
+
+// LogNotifier writes messages to the application log. For local use only.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(_ context.Context, recipient, subject, body string) error {
+	Logger.Printf("Notify %s: %s\n%s", recipient, subject, body)
+	return nil
+}
+
+// FileNotifier appends messages to a file, one block per message.
+type FileNotifier struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (n *FileNotifier) Notify(_ context.Context, recipient, subject, body string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	f, err := os.OpenFile(n.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n", time.Now().Format(time.RFC3339), recipient, subject, body)
+	return err
+}
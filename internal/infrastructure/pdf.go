@@ -0,0 +1,137 @@
+package infrastructure
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	pdfPageWidth    = 595 // A4 in points
+	pdfPageHeight   = 842
+	pdfMargin       = 40
+	pdfFontSize     = 8
+	pdfLeading      = 11
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+)
+
+// Objects written before any page. Pages and the catalog are written last,
+// once every page is known.
+const (
+	pdfCatalogObj = 1
+	pdfPagesObj   = 2
+	pdfFontObj    = 3
+)
+
+// PDFTextWriter streams lines of monospaced text as a PDF document of A4
+// pages. Only one page of text is held in memory; pages are written out as
+// they fill up. Characters outside printable ASCII are replaced with '?'.
+type PDFTextWriter struct {
+	w       *bufio.Writer
+	written int64
+	// offsets holds the position of each object, by object number - 1.
+	offsets []int64
+	pages   []int
+	lines   []string
+	err     error
+}
+
+func NewPDFTextWriter(w io.Writer) *PDFTextWriter {
+	p := &PDFTextWriter{w: bufio.NewWriter(w), offsets: make([]int64, pdfFontObj)}
+	p.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	p.object(pdfFontObj, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	return p
+}
+
+// Line adds a line of text, starting a new page when the current one is
+// full.
+func (p *PDFTextWriter) Line(text string) error {
+	p.lines = append(p.lines, text)
+	if len(p.lines) == pdfLinesPerPage {
+		p.flushPage()
+	}
+	return p.err
+}
+
+// Close writes the last page and the document trailer. It does not close
+// the underlying writer.
+func (p *PDFTextWriter) Close() error {
+	if len(p.lines) > 0 || len(p.pages) == 0 {
+		p.flushPage()
+	}
+	kids := make([]string, len(p.pages))
+	for i, page := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+	p.object(pdfPagesObj, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	p.object(pdfCatalogObj, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPagesObj))
+
+	xref := p.written
+	p.printf("xref\n0 %d\n0000000000 65535 f \n", len(p.offsets)+1)
+	for _, offset := range p.offsets {
+		p.printf("%010d 00000 n \n", offset)
+	}
+	p.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(p.offsets)+1, pdfCatalogObj, xref)
+	if p.err == nil {
+		p.err = p.w.Flush()
+	}
+	return p.err
+}
+
+// flushPage writes the buffered lines as a content stream and its page.
+func (p *PDFTextWriter) flushPage() {
+	var content strings.Builder
+	fmt.Fprintf(&content, "BT /F1 %d Tf %d TL %d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin)
+	for _, line := range p.lines {
+		fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscape(line))
+	}
+	fmt.Fprintf(&content, "ET\nBT /F1 %d Tf %d %d Td (Page %d) Tj ET\n", pdfFontSize, pdfPageWidth-pdfMargin-40, pdfMargin/2, len(p.pages)+1)
+
+	contentObj := p.reserve()
+	p.object(contentObj, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	pageObj := p.reserve()
+	p.object(pageObj, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+		pdfPagesObj, pdfPageWidth, pdfPageHeight, pdfFontObj, contentObj))
+	p.pages = append(p.pages, pageObj)
+	p.lines = p.lines[:0]
+	if p.err == nil {
+		p.err = p.w.Flush()
+	}
+}
+
+func (p *PDFTextWriter) reserve() int {
+	p.offsets = append(p.offsets, 0)
+	return len(p.offsets)
+}
+
+func (p *PDFTextWriter) object(num int, body string) {
+	p.offsets[num-1] = p.written
+	p.printf("%d 0 obj\n%s\nendobj\n", num, body)
+}
+
+func (p *PDFTextWriter) printf(format string, args ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(p.w, format, args...)
+	p.written += int64(n)
+	p.err = err
+}
+
+// pdfEscape makes s safe inside a PDF literal string.
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
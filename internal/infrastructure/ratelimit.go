@@ -0,0 +1,213 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	RateLimitBackendMemory   = "memory"
+	RateLimitBackendPostgres = "postgres"
+
+	RateLimitPolicyLogin    = "login"
+	RateLimitPolicyBet      = "bet"
+	RateLimitPolicyProvider = "provider"
+
+	memoryStoreSweepSize = 10000
+	// postgresStoreSweepInterval is how often each instance deletes buckets
+	// that have refilled completely.
+	postgresStoreSweepInterval = time.Minute
+)
+
+// Rate is a token bucket refilled at PerSecond tokens per second up to Burst.
+type Rate struct {
+	PerSecond float64 `yaml:"per_second" env:"PER_SECOND"`
+	Burst     int     `yaml:"burst" env:"BURST"`
+}
+
+// RateLimitStore takes one token from the bucket identified by key. It
+// returns zero when the request is allowed, or how long to wait otherwise.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, rate Rate, now time.Time) (time.Duration, error)
+}
+
+type RateLimiter struct {
+	store    RateLimitStore
+	policies map[string]Rate
+}
+
+func NewRateLimiter(store RateLimitStore, policies map[string]Rate) *RateLimiter {
+	return &RateLimiter{store: store, policies: policies}
+}
+
+// NewRateLimiterFromConfig builds a limiter with the configured backend.
+func NewRateLimiterFromConfig(cfg RateLimitConfig, db *gorm.DB) (*RateLimiter, error) {
+	var store RateLimitStore
+	switch cfg.Backend {
+	case RateLimitBackendMemory:
+		store = NewMemoryRateLimitStore()
+	case RateLimitBackendPostgres:
+		s, err := NewPostgresRateLimitStore(db)
+		if err != nil {
+			return nil, err
+		}
+		store = s
+	default:
+		return nil, fmt.Errorf("unknown rate limit backend: %s", cfg.Backend)
+	}
+	return NewRateLimiter(store, map[string]Rate{
+		RateLimitPolicyLogin:    cfg.Login,
+		RateLimitPolicyBet:      cfg.Bet,
+		RateLimitPolicyProvider: cfg.Provider,
+	}), nil
+}
+
+// Allow reports whether a request for key under policy may proceed and, if
+// not, how long the caller should wait. Unknown or disabled policies always
+// allow.
+func (l *RateLimiter) Allow(ctx context.Context, policy, key string) (bool, time.Duration, error) {
+	rate, ok := l.policies[policy]
+	if !ok || rate.PerSecond <= 0 || rate.Burst <= 0 {
+		return true, 0, nil
+	}
+	wait, err := l.store.Take(ctx, policy+":"+key, rate, time.Now())
+	if err != nil {
+		return true, 0, err
+	}
+	return wait == 0, wait, nil
+}
+
+// takeToken refills a bucket holding tokens since last and consumes one
+// token if available.
+func takeToken(tokens float64, last, now time.Time, rate Rate) (float64, time.Duration) {
+	elapsed := now.Sub(last).Seconds()
+	if elapsed > 0 {
+		tokens = math.Min(float64(rate.Burst), tokens+elapsed*rate.PerSecond)
+	}
+	if tokens >= 1 {
+		return tokens - 1, 0
+	}
+	wait := time.Duration((1 - tokens) / rate.PerSecond * float64(time.Second))
+	return tokens, wait
+}
+
+type memoryBucket struct {
+	tokens float64
+	last   time.Time
+	// refill is how long an empty bucket takes to fill up again.
+	refill time.Duration
+}
+
+// MemoryRateLimitStore keeps buckets in process memory. Limits are per
+// instance, so it suits single-replica and local deployments.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*memoryBucket)}
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, rate Rate, now time.Time) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[key]
+	if !ok {
+		if len(s.buckets) >= memoryStoreSweepSize {
+			s.sweep(now)
+		}
+		b = &memoryBucket{
+			tokens: float64(rate.Burst),
+			last:   now,
+			refill: time.Duration(float64(rate.Burst) / rate.PerSecond * float64(time.Second)),
+		}
+		s.buckets[key] = b
+	}
+	tokens, wait := takeToken(b.tokens, b.last, now, rate)
+	b.tokens = tokens
+	b.last = now
+	return wait, nil
+}
+
+// sweep drops buckets that would have refilled completely by now.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	for k, b := range s.buckets {
+		if now.Sub(b.last) > b.refill {
+			delete(s.buckets, k)
+		}
+	}
+}
+
+type RateLimitBucket struct {
+	Key       string  `gorm:"primaryKey"`
+	Tokens    float64 `gorm:"not null"`
+	UpdatedAt time.Time
+	// FullAt is when the bucket will have refilled completely; from then on
+	// it is the same as no bucket at all.
+	FullAt *time.Time `gorm:"index"`
+}
+
+// PostgresRateLimitStore shares buckets between replicas through a table,
+// locking the bucket row for the duration of each take.
+type PostgresRateLimitStore struct {
+	db *gorm.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewPostgresRateLimitStore(db *gorm.DB) (*PostgresRateLimitStore, error) {
+	if err := db.AutoMigrate(&RateLimitBucket{}); err != nil {
+		return nil, err
+	}
+	return &PostgresRateLimitStore{db: db}, nil
+}
+
+func (s *PostgresRateLimitStore) Take(ctx context.Context, key string, rate Rate, now time.Time) (time.Duration, error) {
+	var wait time.Duration
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		seed := RateLimitBucket{Key: key, Tokens: float64(rate.Burst), UpdatedAt: now, FullAt: &now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&seed).Error; err != nil {
+			return err
+		}
+		var b RateLimitBucket
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("key = ?", key).First(&b).Error; err != nil {
+			return err
+		}
+		b.Tokens, wait = takeToken(b.Tokens, b.UpdatedAt, now, rate)
+		b.UpdatedAt = now
+		fullAt := now.Add(time.Duration((float64(rate.Burst) - b.Tokens) / rate.PerSecond * float64(time.Second)))
+		return tx.Model(&b).Updates(map[string]interface{}{"tokens": b.Tokens, "updated_at": b.UpdatedAt, "full_at": fullAt}).Error
+	})
+	if err == nil && s.sweepDue(now) {
+		// A failed sweep is retried on the next interval; the take stands.
+		if err := s.sweep(ctx, now); err != nil {
+			Logger.Printf("PostgresRateLimitStore: failed to delete idle buckets: %v", err)
+		}
+	}
+	return wait, err
+}
+
+func (s *PostgresRateLimitStore) sweepDue(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastSweep) < postgresStoreSweepInterval {
+		return false
+	}
+	s.lastSweep = now
+	return true
+}
+
+// sweep deletes buckets that have refilled completely by now, and those
+// written before FullAt was recorded. A bucket taken from since the delete
+// began is kept, as its row is rechecked once the take commits.
+func (s *PostgresRateLimitStore) sweep(ctx context.Context, now time.Time) error {
+	return s.db.WithContext(ctx).Where("full_at IS NULL OR full_at < ?", now).Delete(&RateLimitBucket{}).Error
+}
@@ -0,0 +1,73 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// roundHistoryMaxBody caps the round payload read from a provider.
+const roundHistoryMaxBody = 1 << 20
+
+var ErrRoundHistoryNotConfigured = errors.New("provider has no round history URL")
+
+// RoundHistoryClient fetches a provider's description of a game round from
+// its round history API.
+type RoundHistoryClient struct {
+	urls       map[string]string
+	HTTPClient *http.Client
+}
+
+func NewRoundHistoryClient(cfg RoundHistoryConfig) *RoundHistoryClient {
+	return &RoundHistoryClient{
+		urls: cfg.URLs,
+		HTTPClient: &http.Client{
+			Timeout: cfg.Timeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport,
+				otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+					return "round history " + r.Method + " " + r.URL.Host
+				}),
+			),
+		},
+	}
+}
+
+// Fetch returns the provider's JSON payload for the round. It fails with
+// ErrRoundHistoryNotConfigured for a provider without a history URL.
+func (c *RoundHistoryClient) Fetch(ctx context.Context, providerID, roundID string) (json.RawMessage, error) {
+	tmpl, ok := c.urls[providerID]
+	if !ok {
+		return nil, ErrRoundHistoryNotConfigured
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.ReplaceAll(tmpl, "{round_id}", url.PathEscape(roundID)), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, roundHistoryMaxBody+1))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("round history returned status %d", resp.StatusCode)
+	}
+	if len(body) > roundHistoryMaxBody {
+		return nil, fmt.Errorf("round history larger than %d bytes", roundHistoryMaxBody)
+	}
+	if !json.Valid(body) {
+		return nil, errors.New("round history is not valid JSON")
+	}
+	return body, nil
+}
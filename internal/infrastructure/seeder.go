@@ -8,6 +8,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// SeedTestUsers creates sample players and an admin with well-known
+// passwords. It is only meant for the dev and test profiles; in prod, admins
+// are created with the "admin create" command.
 func SeedTestUsers(db *gorm.DB) {
 	users := []domain.User{
 		{
@@ -46,6 +49,15 @@ func SeedTestUsers(db *gorm.DB) {
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		},
+		{
+			WalletID:  "admin",
+			Username:  "admin",
+			Password:  hashPassword("adminpass"),
+			Currency:  "USD",
+			Role:      domain.RoleAdmin,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
 	}
 	for _, u := range users {
 		db.Where(domain.User{Username: u.Username}).FirstOrCreate(&u)
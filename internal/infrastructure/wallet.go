@@ -2,29 +2,38 @@ package infrastructure
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 const (
 	WalletBalanceEndpoint  = "/api/v1/balance"
 	WalletWithdrawEndpoint = "/api/v1/withdraw"
 	WalletDepositEndpoint  = "/api/v1/deposit"
-	WalletAPIKeyHeader     = "x-api-key"
-	WalletContentType      = "application/json"
+	// WalletTransactionsEndpoint lists a user's wallet ledger between two
+	// times: GET /api/v1/transactions/{userId}?from=...&to=... (RFC 3339).
+	WalletTransactionsEndpoint = "/api/v1/transactions"
+	WalletAPIKeyHeader         = "x-api-key"
+	WalletContentType          = "application/json"
 )
 
 var ErrWalletUserNotFound = errors.New("wallet user not found")
 var ErrWalletServiceBadRequest = errors.New("wallet service bad request")
 
 type WalletClient struct {
-	BaseURL string
-	APIKey  string
+	BaseURL    string
+	APIKey     string
+	HTTPClient *http.Client
+	Breaker    *CircuitBreaker
 }
 
 type WalletBalanceResponse struct {
@@ -126,23 +135,102 @@ func (w *WalletOperationResponse) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// WalletLedgerEntry is one movement on the wallet. Type is withdraw or
+// deposit; Amount is never negative.
+type WalletLedgerEntry struct {
+	ID        int       `json:"id"`
+	Reference string    `json:"reference"`
+	Type      string    `json:"type"`
+	Amount    float64   `json:"amount"`
+	Currency  string    `json:"currency"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type WalletLedgerResponse struct {
+	Transactions []WalletLedgerEntry `json:"transactions"`
+}
+
 type WalletErrorResponse struct {
 	Code string `json:"code"`
 	Msg  string `json:"msg"`
 }
 
-func NewWalletClient(baseURL, apiKey string) *WalletClient {
-	return &WalletClient{BaseURL: baseURL, APIKey: apiKey}
+// NewWalletClient returns a client whose outbound requests are traced and
+// carry the W3C trace-context of the calling span.
+func NewWalletClient(cfg WalletConfig) *WalletClient {
+	return &WalletClient{
+		BaseURL: cfg.URL,
+		APIKey:  cfg.Token,
+		HTTPClient: &http.Client{
+			Timeout: cfg.Timeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport,
+				otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+					return "wallet " + r.Method + " " + r.URL.Path
+				}),
+			),
+		},
+		Breaker: NewCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+	}
+}
+
+func (w *WalletClient) httpClient() *http.Client {
+	if w.HTTPClient != nil {
+		return w.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// do sends req through the circuit breaker. Transport errors and 5xx
+// responses count as failures; 4xx responses mean the wallet is reachable.
+func (w *WalletClient) do(req *http.Request) (*http.Response, error) {
+	if w.Breaker == nil {
+		return w.httpClient().Do(req)
+	}
+	if err := w.Breaker.Allow(); err != nil {
+		return nil, err
+	}
+	resp, err := w.httpClient().Do(req)
+	w.Breaker.Record(err == nil && resp.StatusCode < 500)
+	return resp, err
+}
+
+// BreakerState returns the current circuit-breaker state of the client.
+func (w *WalletClient) BreakerState() string {
+	if w.Breaker == nil {
+		return BreakerClosed
+	}
+	return w.Breaker.State()
 }
 
-func (w *WalletClient) GetBalance(userID int64) (*WalletBalanceResponse, error) {
+// Ping checks that the wallet service answers a balance lookup for
+// probeUserID. Any non-5xx response counts as healthy.
+func (w *WalletClient) Ping(ctx context.Context, probeUserID int64) error {
+	url := fmt.Sprintf("%s%s/%d", w.BaseURL, WalletBalanceEndpoint, probeUserID)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(WalletAPIKeyHeader, w.APIKey)
+	resp, err := w.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("wallet service error: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (w *WalletClient) GetBalance(ctx context.Context, userID int64) (*WalletBalanceResponse, error) {
 	url := fmt.Sprintf("%s%s/%d", w.BaseURL, WalletBalanceEndpoint, userID)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set(WalletAPIKeyHeader, w.APIKey)
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := w.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -163,45 +251,83 @@ func (w *WalletClient) GetBalance(userID int64) (*WalletBalanceResponse, error)
 	return &result, nil
 }
 
-func (w *WalletClient) GetBalanceStr(walletID string) (*WalletBalanceResponse, error) {
+func (w *WalletClient) GetBalanceStr(ctx context.Context, walletID string) (*WalletBalanceResponse, error) {
 	var id int64
 	_, err := fmt.Sscan(walletID, &id)
 	if err != nil {
 		return nil, fmt.Errorf("invalid wallet ID: %v", err)
 	}
-	return w.GetBalance(id)
+	return w.GetBalance(ctx, id)
+}
+
+// ListTransactions returns the user's wallet ledger in [from, to).
+func (w *WalletClient) ListTransactions(ctx context.Context, userID int64, from, to time.Time) ([]WalletLedgerEntry, error) {
+	query := url.Values{"from": {from.UTC().Format(time.RFC3339)}, "to": {to.UTC().Format(time.RFC3339)}}
+	endpoint := fmt.Sprintf("%s%s/%d?%s", w.BaseURL, WalletTransactionsEndpoint, userID, query.Encode())
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(WalletAPIKeyHeader, w.APIKey)
+	resp, err := w.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		var errResp WalletErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err == nil && errResp.Msg != "" {
+			return nil, fmt.Errorf("wallet service error: %s", errResp.Msg)
+		}
+		return nil, fmt.Errorf("wallet service error: status %d", resp.StatusCode)
+	}
+
+	var result WalletLedgerResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Transactions, nil
 }
 
-func (w *WalletClient) Withdraw(req WalletWithdrawRequest) (*WalletOperationResponse, error) {
+func (w *WalletClient) Withdraw(ctx context.Context, req WalletWithdrawRequest) (*WalletOperationResponse, error) {
 	url := fmt.Sprintf("%s%s", w.BaseURL, WalletWithdrawEndpoint)
-	return w.doOperationWithErrorMapping(url, req)
+	return w.doOperationWithErrorMapping(ctx, url, req)
 }
 
-func (w *WalletClient) Deposit(req WalletDepositRequest) (*WalletOperationResponse, error) {
+func (w *WalletClient) Deposit(ctx context.Context, req WalletDepositRequest) (*WalletOperationResponse, error) {
 	url := fmt.Sprintf("%s%s", w.BaseURL, WalletDepositEndpoint)
-	return w.doOperationWithErrorMapping(url, req)
+	return w.doOperationWithErrorMapping(ctx, url, req)
 }
 
-func (w *WalletClient) doOperationWithErrorMapping(url string, req interface{}) (*WalletOperationResponse, error) {
+func (w *WalletClient) doOperationWithErrorMapping(ctx context.Context, url string, req interface{}) (*WalletOperationResponse, error) {
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
-	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, err
 	}
 	httpReq.Header.Set(WalletAPIKeyHeader, w.APIKey)
 	httpReq.Header.Set("Content-Type", WalletContentType)
-	resp, err := http.DefaultClient.Do(httpReq)
+	started := time.Now()
+	resp, err := w.do(httpReq)
 	if err != nil {
+		ExchangeFrom(ctx).WalletCall(httpReq.Method, url, body, 0, nil, started, err)
 		return nil, err
 	}
 	defer resp.Body.Close()
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		ExchangeFrom(ctx).WalletCall(httpReq.Method, url, body, 0, nil, started, err)
+		return nil, err
+	}
+	ExchangeFrom(ctx).WalletCall(httpReq.Method, url, body, resp.StatusCode, respBytes, started, nil)
 
 	if resp.StatusCode != 200 {
 		var errResp WalletErrorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errResp); err == nil && errResp.Msg != "" {
+		if err := json.Unmarshal(respBytes, &errResp); err == nil && errResp.Msg != "" {
 			if resp.StatusCode >= 400 && resp.StatusCode < 600 {
 				return nil, fmt.Errorf("%w: %s", ErrWalletServiceBadRequest, errResp.Msg)
 			}
@@ -213,11 +339,6 @@ func (w *WalletClient) doOperationWithErrorMapping(url string, req interface{})
 		return nil, fmt.Errorf("wallet service error: status %d", resp.StatusCode)
 	}
 
-	respBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	log.Printf("Wallet operation raw response: %s", string(respBytes))
 	var result WalletOperationResponse
 	if err := json.Unmarshal(respBytes, &result); err != nil {
 		return nil, err
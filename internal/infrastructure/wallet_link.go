@@ -0,0 +1,50 @@
+package infrastructure
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidLinkToken is returned for a wallet link token that was not
+// issued for the wallet, was signed with another secret, or has expired.
+var ErrInvalidLinkToken = errors.New("wallet link token is invalid or has expired")
+
+// NewWalletLinkToken issues a token proving ownership of walletID until
+// expires. The wallet operator hands it to the wallet's owner, who presents
+// it when registering; it has the form "<unix expiry>.<hex HMAC-SHA256 of
+// walletID.expiry>" so that the operator can issue it from its own systems.
+func NewWalletLinkToken(secret, walletID string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return exp + "." + walletLinkMAC(secret, walletID, exp)
+}
+
+// VerifyWalletLinkToken checks that token was issued for walletID with
+// secret and is still valid at now. No token is valid without a secret.
+func VerifyWalletLinkToken(secret, walletID, token string, now time.Time) error {
+	if secret == "" {
+		return ErrInvalidLinkToken
+	}
+	exp, mac, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrInvalidLinkToken
+	}
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || !now.Before(time.Unix(unix, 0)) {
+		return ErrInvalidLinkToken
+	}
+	if !hmac.Equal([]byte(mac), []byte(walletLinkMAC(secret, walletID, exp))) {
+		return ErrInvalidLinkToken
+	}
+	return nil
+}
+
+func walletLinkMAC(secret, walletID, exp string) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write([]byte(walletID + "." + exp))
+	return hex.EncodeToString(m.Sum(nil))
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gameintegrationapi/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BalanceRepository interface {
+	ListByUser(ctx context.Context, userID uint) ([]domain.PlayerBalance, error)
+	// Find returns nil when the user holds no balance in the currency.
+	Find(ctx context.Context, userID uint, currency string) (*domain.PlayerBalance, error)
+	Create(ctx context.Context, balance *domain.PlayerBalance) error
+	UpdateBalance(ctx context.Context, userID uint, currency string, newBalance float64) error
+	// AddBalance adds delta to the stored balance rather than overwrite it.
+	AddBalance(ctx context.Context, userID uint, currency string, delta float64) error
+}
+
+type balanceRepository struct {
+	db *gorm.DB
+}
+
+func NewBalanceRepository(db *gorm.DB) BalanceRepository {
+	return &balanceRepository{db}
+}
+
+func (r *balanceRepository) ListByUser(ctx context.Context, userID uint) ([]domain.PlayerBalance, error) {
+	var balances []domain.PlayerBalance
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("currency").Find(&balances).Error
+	return balances, err
+}
+
+func (r *balanceRepository) Find(ctx context.Context, userID uint, currency string) (*domain.PlayerBalance, error) {
+	var balance domain.PlayerBalance
+	err := r.db.WithContext(ctx).Where("user_id = ? AND currency = ?", userID, currency).First(&balance).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
+
+func (r *balanceRepository) Create(ctx context.Context, balance *domain.PlayerBalance) error {
+	return r.db.WithContext(ctx).Create(balance).Error
+}
+
+func (r *balanceRepository) UpdateBalance(ctx context.Context, userID uint, currency string, newBalance float64) error {
+	res := r.db.WithContext(ctx).Model(&domain.PlayerBalance{}).
+		Where("user_id = ? AND currency = ?", userID, currency).
+		Updates(map[string]interface{}{"balance": newBalance, "updated_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *balanceRepository) AddBalance(ctx context.Context, userID uint, currency string, delta float64) error {
+	res := r.db.WithContext(ctx).Model(&domain.PlayerBalance{}).
+		Where("user_id = ? AND currency = ?", userID, currency).
+		Updates(map[string]interface{}{"balance": gorm.Expr("balance + ?", delta), "updated_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
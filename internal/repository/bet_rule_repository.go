@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"gameintegrationapi/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BetRuleRepository interface {
+	List(ctx context.Context) ([]domain.BetRule, error)
+	// FindCandidates returns every rule that matches the bet.
+	FindCandidates(ctx context.Context, gameID, providerID, currency string) ([]domain.BetRule, error)
+	Save(ctx context.Context, rule *domain.BetRule) error
+	Delete(ctx context.Context, id uint) error
+}
+
+type betRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewBetRuleRepository(db *gorm.DB) BetRuleRepository {
+	return &betRuleRepository{db}
+}
+
+func (r *betRuleRepository) List(ctx context.Context) ([]domain.BetRule, error) {
+	var rules []domain.BetRule
+	err := r.db.WithContext(ctx).Order("game_id, provider_id, currency").Find(&rules).Error
+	return rules, err
+}
+
+func (r *betRuleRepository) FindCandidates(ctx context.Context, gameID, providerID, currency string) ([]domain.BetRule, error) {
+	var rules []domain.BetRule
+	err := r.db.WithContext(ctx).
+		Where("game_id IN ('', ?) AND provider_id IN ('', ?) AND currency IN ('', ?)", gameID, providerID, currency).
+		Find(&rules).Error
+	return rules, err
+}
+
+// Save inserts the rule or replaces the one for the same game, provider and
+// currency.
+func (r *betRuleRepository) Save(ctx context.Context, rule *domain.BetRule) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "game_id"}, {Name: "provider_id"}, {Name: "currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"min_stake", "max_stake", "max_win_per_round", "updated_at"}),
+	}).Create(rule).Error
+}
+
+func (r *betRuleRepository) Delete(ctx context.Context, id uint) error {
+	res := r.db.WithContext(ctx).Delete(&domain.BetRule{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
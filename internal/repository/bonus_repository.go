@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gameintegrationapi/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BonusRepository interface {
+	CreateCampaign(ctx context.Context, campaign *domain.BonusCampaign) error
+	FindCampaign(ctx context.Context, id uint) (*domain.BonusCampaign, error)
+	ListCampaigns(ctx context.Context) ([]domain.BonusCampaign, error)
+	Create(ctx context.Context, bonus *domain.PlayerBonus) error
+	FindByID(ctx context.Context, id uint) (*domain.PlayerBonus, error)
+	// FindActive returns the player's active bonus, or nil.
+	FindActive(ctx context.Context, userID uint) (*domain.PlayerBonus, error)
+	ListByUser(ctx context.Context, userID uint) ([]domain.PlayerBonus, error)
+	ListExpired(ctx context.Context, now time.Time) ([]domain.PlayerBonus, error)
+	// Close moves an active bonus to its new status. It fails with
+	// gorm.ErrRecordNotFound if the bonus was closed concurrently.
+	Close(ctx context.Context, bonus *domain.PlayerBonus) error
+}
+
+type bonusRepository struct {
+	db *gorm.DB
+}
+
+func NewBonusRepository(db *gorm.DB) BonusRepository {
+	return &bonusRepository{db}
+}
+
+func (r *bonusRepository) CreateCampaign(ctx context.Context, campaign *domain.BonusCampaign) error {
+	return r.db.WithContext(ctx).Create(campaign).Error
+}
+
+func (r *bonusRepository) FindCampaign(ctx context.Context, id uint) (*domain.BonusCampaign, error) {
+	var campaign domain.BonusCampaign
+	if err := r.db.WithContext(ctx).First(&campaign, id).Error; err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+func (r *bonusRepository) ListCampaigns(ctx context.Context) ([]domain.BonusCampaign, error) {
+	var campaigns []domain.BonusCampaign
+	err := r.db.WithContext(ctx).Order("id").Find(&campaigns).Error
+	return campaigns, err
+}
+
+func (r *bonusRepository) Create(ctx context.Context, bonus *domain.PlayerBonus) error {
+	return r.db.WithContext(ctx).Create(bonus).Error
+}
+
+func (r *bonusRepository) FindByID(ctx context.Context, id uint) (*domain.PlayerBonus, error) {
+	var bonus domain.PlayerBonus
+	if err := r.db.WithContext(ctx).Preload("Campaign").First(&bonus, id).Error; err != nil {
+		return nil, err
+	}
+	return &bonus, nil
+}
+
+func (r *bonusRepository) FindActive(ctx context.Context, userID uint) (*domain.PlayerBonus, error) {
+	var bonus domain.PlayerBonus
+	err := r.db.WithContext(ctx).Preload("Campaign").
+		Where("user_id = ? AND status = ?", userID, domain.BonusStatusActive).
+		First(&bonus).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &bonus, nil
+}
+
+func (r *bonusRepository) ListByUser(ctx context.Context, userID uint) ([]domain.PlayerBonus, error) {
+	var bonuses []domain.PlayerBonus
+	err := r.db.WithContext(ctx).Preload("Campaign").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&bonuses).Error
+	return bonuses, err
+}
+
+func (r *bonusRepository) ListExpired(ctx context.Context, now time.Time) ([]domain.PlayerBonus, error) {
+	var bonuses []domain.PlayerBonus
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at <= ?", domain.BonusStatusActive, now).
+		Find(&bonuses).Error
+	return bonuses, err
+}
+
+func (r *bonusRepository) Close(ctx context.Context, bonus *domain.PlayerBonus) error {
+	res := r.db.WithContext(ctx).Model(&domain.PlayerBonus{}).
+		Where("id = ? AND status = ?", bonus.ID, domain.BonusStatusActive).
+		Updates(map[string]interface{}{
+			"status":           bonus.Status,
+			"closed_at":        bonus.ClosedAt,
+			"released_amount":  bonus.ReleasedAmount,
+			"forfeited_amount": bonus.ForfeitedAmount,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gameintegrationapi/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ExclusionRepository interface {
+	Create(ctx context.Context, exclusion *domain.Exclusion) error
+	// FindActive returns the active exclusion that ends last, or nil.
+	FindActive(ctx context.Context, userID uint, now time.Time) (*domain.Exclusion, error)
+	ListActive(ctx context.Context, now time.Time) ([]domain.Exclusion, error)
+}
+
+type exclusionRepository struct {
+	db *gorm.DB
+}
+
+func NewExclusionRepository(db *gorm.DB) ExclusionRepository {
+	return &exclusionRepository{db}
+}
+
+func (r *exclusionRepository) Create(ctx context.Context, exclusion *domain.Exclusion) error {
+	return r.db.WithContext(ctx).Create(exclusion).Error
+}
+
+func (r *exclusionRepository) FindActive(ctx context.Context, userID uint, now time.Time) (*domain.Exclusion, error) {
+	var exclusion domain.Exclusion
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND starts_at <= ? AND (ends_at IS NULL OR ends_at > ?)", userID, now, now).
+		Order("ends_at DESC NULLS FIRST").
+		First(&exclusion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &exclusion, nil
+}
+
+func (r *exclusionRepository) ListActive(ctx context.Context, now time.Time) ([]domain.Exclusion, error) {
+	var exclusions []domain.Exclusion
+	err := r.db.WithContext(ctx).Preload("User").
+		Where("starts_at <= ? AND (ends_at IS NULL OR ends_at > ?)", now, now).
+		Order("user_id, ends_at DESC NULLS FIRST").
+		Find(&exclusions).Error
+	return exclusions, err
+}
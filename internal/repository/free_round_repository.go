@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gameintegrationapi/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type FreeRoundRepository interface {
+	Create(ctx context.Context, grant *domain.FreeRoundGrant) error
+	FindByID(ctx context.Context, id uint) (*domain.FreeRoundGrant, error)
+	ListByUser(ctx context.Context, userID uint) ([]domain.FreeRoundGrant, error)
+	// FindUsable returns the usable grant for the game and bet value that
+	// expires first, or nil.
+	FindUsable(ctx context.Context, userID uint, gameID string, betValue float64, now time.Time) (*domain.FreeRoundGrant, error)
+	// Consume takes one round from the grant. It fails with
+	// gorm.ErrRecordNotFound if no round is left.
+	Consume(ctx context.Context, id uint, now time.Time) error
+	// Restore gives back a round whose stake was cancelled.
+	Restore(ctx context.Context, id uint) error
+	// Revoke fails with gorm.ErrRecordNotFound if the grant is not active.
+	Revoke(ctx context.Context, grant *domain.FreeRoundGrant) error
+}
+
+type freeRoundRepository struct {
+	db *gorm.DB
+}
+
+func NewFreeRoundRepository(db *gorm.DB) FreeRoundRepository {
+	return &freeRoundRepository{db}
+}
+
+func (r *freeRoundRepository) Create(ctx context.Context, grant *domain.FreeRoundGrant) error {
+	return r.db.WithContext(ctx).Create(grant).Error
+}
+
+func (r *freeRoundRepository) FindByID(ctx context.Context, id uint) (*domain.FreeRoundGrant, error) {
+	var grant domain.FreeRoundGrant
+	if err := r.db.WithContext(ctx).First(&grant, id).Error; err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+func (r *freeRoundRepository) ListByUser(ctx context.Context, userID uint) ([]domain.FreeRoundGrant, error) {
+	var grants []domain.FreeRoundGrant
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&grants).Error
+	return grants, err
+}
+
+func (r *freeRoundRepository) FindUsable(ctx context.Context, userID uint, gameID string, betValue float64, now time.Time) (*domain.FreeRoundGrant, error) {
+	var grant domain.FreeRoundGrant
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND game_id = ? AND bet_value = ? AND status = ? AND remaining > 0 AND expires_at > ?",
+			userID, gameID, betValue, domain.FreeRoundsStatusActive, now).
+		Order("expires_at").
+		First(&grant).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+func (r *freeRoundRepository) Consume(ctx context.Context, id uint, now time.Time) error {
+	res := r.db.WithContext(ctx).Model(&domain.FreeRoundGrant{}).
+		Where("id = ? AND status = ? AND remaining > 0 AND expires_at > ?", id, domain.FreeRoundsStatusActive, now).
+		Update("remaining", gorm.Expr("remaining - 1"))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *freeRoundRepository) Restore(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&domain.FreeRoundGrant{}).
+		Where("id = ? AND remaining < count", id).
+		Update("remaining", gorm.Expr("remaining + 1")).Error
+}
+
+func (r *freeRoundRepository) Revoke(ctx context.Context, grant *domain.FreeRoundGrant) error {
+	res := r.db.WithContext(ctx).Model(&domain.FreeRoundGrant{}).
+		Where("id = ? AND status = ?", grant.ID, domain.FreeRoundsStatusActive).
+		Updates(map[string]interface{}{
+			"status":     grant.Status,
+			"revoked_by": grant.RevokedBy,
+			"revoked_at": grant.RevokedAt,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gameintegrationapi/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type GameSessionRepository interface {
+	// FindCurrent returns the user's session with activity since idleSince,
+	// or nil.
+	FindCurrent(ctx context.Context, userID uint, idleSince time.Time) (*domain.GameSession, error)
+	Save(ctx context.Context, session *domain.GameSession) error
+}
+
+type gameSessionRepository struct {
+	db *gorm.DB
+}
+
+func NewGameSessionRepository(db *gorm.DB) GameSessionRepository {
+	return &gameSessionRepository{db}
+}
+
+func (r *gameSessionRepository) FindCurrent(ctx context.Context, userID uint, idleSince time.Time) (*domain.GameSession, error) {
+	var session domain.GameSession
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND last_activity_at > ?", userID, idleSince).
+		Order("last_activity_at DESC").
+		First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *gameSessionRepository) Save(ctx context.Context, session *domain.GameSession) error {
+	return r.db.WithContext(ctx).Save(session).Error
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"gameintegrationapi/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type JackpotRepository interface {
+	// ListPools filters by currency and game group when they are not empty.
+	ListPools(ctx context.Context, currency, gameGroup string) ([]domain.JackpotPool, error)
+	CreatePool(ctx context.Context, pool *domain.JackpotPool) error
+	// FindPoolsForGame returns the pools of the game's group in the currency.
+	FindPoolsForGame(ctx context.Context, gameID, currency string) ([]domain.JackpotPool, error)
+	SaveGame(ctx context.Context, game *domain.JackpotGame) error
+	// Contribute adds amount to the pool in a single UPDATE so concurrent
+	// stakes are never lost.
+	Contribute(ctx context.Context, poolID uint, amount float64) error
+	// Claim locks the pool, resets it to its seed and returns it as it was
+	// before the reset.
+	Claim(ctx context.Context, poolID uint, now time.Time) (*domain.JackpotPool, error)
+	// Unclaim puts a claimed amount back after a failed payout, keeping
+	// contributions made in the meantime.
+	Unclaim(ctx context.Context, poolID uint, amount float64) error
+}
+
+type jackpotRepository struct {
+	db *gorm.DB
+}
+
+func NewJackpotRepository(db *gorm.DB) JackpotRepository {
+	return &jackpotRepository{db}
+}
+
+func (r *jackpotRepository) ListPools(ctx context.Context, currency, gameGroup string) ([]domain.JackpotPool, error) {
+	var pools []domain.JackpotPool
+	q := r.db.WithContext(ctx)
+	if currency != "" {
+		q = q.Where("currency = ?", currency)
+	}
+	if gameGroup != "" {
+		q = q.Where("game_group = ?", gameGroup)
+	}
+	err := q.Order("game_group, currency, name").Find(&pools).Error
+	return pools, err
+}
+
+func (r *jackpotRepository) CreatePool(ctx context.Context, pool *domain.JackpotPool) error {
+	return r.db.WithContext(ctx).Create(pool).Error
+}
+
+func (r *jackpotRepository) FindPoolsForGame(ctx context.Context, gameID, currency string) ([]domain.JackpotPool, error) {
+	var pools []domain.JackpotPool
+	err := r.db.WithContext(ctx).
+		Joins("JOIN jackpot_games ON jackpot_games.game_group = jackpot_pools.game_group").
+		Where("jackpot_games.game_id = ? AND jackpot_pools.currency = ?", gameID, currency).
+		Find(&pools).Error
+	return pools, err
+}
+
+// SaveGame inserts the game or moves it to another group.
+func (r *jackpotRepository) SaveGame(ctx context.Context, game *domain.JackpotGame) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "game_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"game_group", "updated_at"}),
+	}).Create(game).Error
+}
+
+func (r *jackpotRepository) Contribute(ctx context.Context, poolID uint, amount float64) error {
+	return r.db.WithContext(ctx).Model(&domain.JackpotPool{}).
+		Where("id = ?", poolID).
+		Update("amount", gorm.Expr("amount + ?", amount)).Error
+}
+
+func (r *jackpotRepository) Claim(ctx context.Context, poolID uint, now time.Time) (*domain.JackpotPool, error) {
+	var pool domain.JackpotPool
+	err := r.db.WithContext(ctx).Transaction(func(txDb *gorm.DB) error {
+		if err := txDb.Clauses(clause.Locking{Strength: "UPDATE"}).First(&pool, poolID).Error; err != nil {
+			return err
+		}
+		return txDb.Model(&domain.JackpotPool{}).Where("id = ?", poolID).Updates(map[string]interface{}{
+			"amount":          pool.SeedAmount,
+			"last_win_amount": pool.Amount,
+			"last_won_at":     now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pool, nil
+}
+
+func (r *jackpotRepository) Unclaim(ctx context.Context, poolID uint, amount float64) error {
+	return r.db.WithContext(ctx).Model(&domain.JackpotPool{}).
+		Where("id = ?", poolID).
+		Update("amount", gorm.Expr("amount + ? - seed_amount", amount)).Error
+}
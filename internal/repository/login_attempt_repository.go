@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"gameintegrationapi/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginAttemptRepository interface {
+	Record(ctx context.Context, attempt *domain.LoginAttempt) error
+	FindLockout(ctx context.Context, key string) (*domain.LoginLockout, error)
+	UpdateLockout(ctx context.Context, key string, update func(*domain.LoginLockout)) (*domain.LoginLockout, error)
+	ListLockouts(ctx context.Context, lockedAt time.Time) ([]domain.LoginLockout, error)
+	DeleteLockout(ctx context.Context, id uint) error
+	ResetLockout(ctx context.Context, key string) error
+}
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db}
+}
+
+func (r *loginAttemptRepository) Record(ctx context.Context, attempt *domain.LoginAttempt) error {
+	return r.db.WithContext(ctx).Create(attempt).Error
+}
+
+// FindLockout returns the lockout row for key, or a zero-valued one if the
+// key has no recorded failures.
+func (r *loginAttemptRepository) FindLockout(ctx context.Context, key string) (*domain.LoginLockout, error) {
+	var lockout domain.LoginLockout
+	err := r.db.WithContext(ctx).Where("key = ?", key).Limit(1).Find(&lockout).Error
+	if err != nil {
+		return nil, err
+	}
+	lockout.Key = key
+	return &lockout, nil
+}
+
+// UpdateLockout applies update to the lockout row for key while holding a
+// row lock, creating the row first if needed, so concurrent failures for the
+// same key are all counted.
+func (r *loginAttemptRepository) UpdateLockout(ctx context.Context, key string, update func(*domain.LoginLockout)) (*domain.LoginLockout, error) {
+	var lockout domain.LoginLockout
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		seed := domain.LoginLockout{Key: key}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&seed).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("key = ?", key).First(&lockout).Error; err != nil {
+			return err
+		}
+		update(&lockout)
+		return tx.Save(&lockout).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &lockout, nil
+}
+
+// ListLockouts returns every lockout still in force at the given time.
+func (r *loginAttemptRepository) ListLockouts(ctx context.Context, lockedAt time.Time) ([]domain.LoginLockout, error) {
+	var lockouts []domain.LoginLockout
+	err := r.db.WithContext(ctx).Where("locked_until > ?", lockedAt).Order("locked_until DESC").Find(&lockouts).Error
+	return lockouts, err
+}
+
+func (r *loginAttemptRepository) DeleteLockout(ctx context.Context, id uint) error {
+	res := r.db.WithContext(ctx).Delete(&domain.LoginLockout{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *loginAttemptRepository) ResetLockout(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).Where("key = ?", key).Delete(&domain.LoginLockout{}).Error
+}
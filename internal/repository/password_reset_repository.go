@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"gameintegrationapi/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository interface {
+	Create(ctx context.Context, token *domain.PasswordResetToken) error
+	FindValid(ctx context.Context, tokenHash string, now time.Time) (*domain.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, token *domain.PasswordResetToken, now time.Time) error
+	InvalidateForUser(ctx context.Context, userID uint, now time.Time) error
+}
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{db}
+}
+
+func (r *passwordResetRepository) Create(ctx context.Context, token *domain.PasswordResetToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *passwordResetRepository) FindValid(ctx context.Context, tokenHash string, now time.Time) (*domain.PasswordResetToken, error) {
+	var token domain.PasswordResetToken
+	err := r.db.WithContext(ctx).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed consumes the token. It fails with gorm.ErrRecordNotFound if the
+// token was consumed concurrently.
+func (r *passwordResetRepository) MarkUsed(ctx context.Context, token *domain.PasswordResetToken, now time.Time) error {
+	res := r.db.WithContext(ctx).Model(&domain.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", token.ID).
+		Update("used_at", now)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	token.UsedAt = &now
+	return nil
+}
+
+func (r *passwordResetRepository) InvalidateForUser(ctx context.Context, userID uint, now time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", now).Error
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"gameintegrationapi/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PlayerLimitRepository interface {
+	FindByUser(ctx context.Context, userID uint) ([]domain.PlayerLimit, error)
+	Save(ctx context.Context, limit *domain.PlayerLimit) error
+	// SumDeposits returns the player's deposits since the given time.
+	SumDeposits(ctx context.Context, userID uint, since time.Time) (float64, error)
+	// AddDeposit records deposit if check, called with the repository
+	// inside the transaction, allows it. The player's row is locked
+	// meanwhile so that concurrent deposits are checked one at a time. A
+	// deposit whose reference is already recorded is returned as it was
+	// without calling check.
+	AddDeposit(ctx context.Context, deposit *domain.PlayerDeposit, check func(PlayerLimitRepository) error) (*domain.PlayerDeposit, error)
+}
+
+type playerLimitRepository struct {
+	db *gorm.DB
+}
+
+func NewPlayerLimitRepository(db *gorm.DB) PlayerLimitRepository {
+	return &playerLimitRepository{db}
+}
+
+func (r *playerLimitRepository) FindByUser(ctx context.Context, userID uint) ([]domain.PlayerLimit, error) {
+	var limits []domain.PlayerLimit
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("type, period").Find(&limits).Error
+	return limits, err
+}
+
+// Save inserts the limit or replaces the one with the same user, type and
+// period.
+func (r *playerLimitRepository) Save(ctx context.Context, limit *domain.PlayerLimit) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}, {Name: "period"}},
+		DoUpdates: clause.AssignmentColumns([]string{"amount", "pending_amount", "pending_from", "updated_at"}),
+	}).Create(limit).Error
+}
+
+func (r *playerLimitRepository) SumDeposits(ctx context.Context, userID uint, since time.Time) (float64, error) {
+	var total float64
+	err := r.db.WithContext(ctx).Model(&domain.PlayerDeposit{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *playerLimitRepository) AddDeposit(ctx context.Context, deposit *domain.PlayerDeposit, check func(PlayerLimitRepository) error) (*domain.PlayerDeposit, error) {
+	var recorded *domain.PlayerDeposit
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user domain.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&user, deposit.UserID).Error; err != nil {
+			return err
+		}
+		var existing domain.PlayerDeposit
+		if err := tx.Where("reference = ?", deposit.Reference).Limit(1).Find(&existing).Error; err != nil {
+			return err
+		}
+		if existing.ID != 0 {
+			recorded = &existing
+			return nil
+		}
+		if err := check(&playerLimitRepository{tx}); err != nil {
+			return err
+		}
+		if err := tx.Create(deposit).Error; err != nil {
+			return err
+		}
+		recorded = deposit
+		return nil
+	})
+	return recorded, err
+}
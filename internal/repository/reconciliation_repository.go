@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"gameintegrationapi/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// reconciliationLockKey is the Postgres advisory lock held while a
+// reconciliation runs.
+const reconciliationLockKey int64 = 0x7265636f6e
+
+type ReconciliationRepository interface {
+	// LockRuns takes the lock that admits one run at a time across all
+	// instances, on a connection of its own kept until unlock is called. ok
+	// is false when another run holds it.
+	LockRuns(ctx context.Context) (unlock func(), ok bool, err error)
+	// StartRun replaces any earlier run for the day with a new running one.
+	StartRun(ctx context.Context, day, now time.Time) (*domain.ReconciliationRun, error)
+	AddMismatches(ctx context.Context, mismatches []domain.ReconciliationMismatch) error
+	FinishRun(ctx context.Context, run *domain.ReconciliationRun) error
+	ListRuns(ctx context.Context, limit int) ([]domain.ReconciliationRun, error)
+	// FindRun returns nil when the day has not been reconciled.
+	FindRun(ctx context.Context, day time.Time) (*domain.ReconciliationRun, error)
+	ListMismatches(ctx context.Context, runID uint) ([]domain.ReconciliationMismatch, error)
+}
+
+type reconciliationRepository struct {
+	db *gorm.DB
+}
+
+func NewReconciliationRepository(db *gorm.DB) ReconciliationRepository {
+	return &reconciliationRepository{db}
+}
+
+func (r *reconciliationRepository) LockRuns(ctx context.Context) (func(), bool, error) {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var ok bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", reconciliationLockKey).Scan(&ok); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !ok {
+		conn.Close()
+		return nil, false, nil
+	}
+	unlock := func() {
+		// Closing the connection releases the lock should the unlock fail.
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", reconciliationLockKey); err != nil {
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}
+	return unlock, true, nil
+}
+
+func (r *reconciliationRepository) StartRun(ctx context.Context, day, now time.Time) (*domain.ReconciliationRun, error) {
+	run := &domain.ReconciliationRun{Day: day, Status: domain.ReconciliationStatusRunning, StartedAt: now}
+	err := r.db.WithContext(ctx).Transaction(func(txDb *gorm.DB) error {
+		var old domain.ReconciliationRun
+		err := txDb.Where("day = ?", day).First(&old).Error
+		switch {
+		case err == nil:
+			if err := txDb.Where("run_id = ?", old.ID).Delete(&domain.ReconciliationMismatch{}).Error; err != nil {
+				return err
+			}
+			if err := txDb.Delete(&old).Error; err != nil {
+				return err
+			}
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+		return txDb.Create(run).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (r *reconciliationRepository) AddMismatches(ctx context.Context, mismatches []domain.ReconciliationMismatch) error {
+	if len(mismatches) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(mismatches, 500).Error
+}
+
+func (r *reconciliationRepository) FinishRun(ctx context.Context, run *domain.ReconciliationRun) error {
+	return r.db.WithContext(ctx).Model(run).
+		Select("status", "users", "mismatches", "local_net", "wallet_net", "error", "finished_at").
+		Updates(run).Error
+}
+
+func (r *reconciliationRepository) ListRuns(ctx context.Context, limit int) ([]domain.ReconciliationRun, error) {
+	var runs []domain.ReconciliationRun
+	err := r.db.WithContext(ctx).Order("day DESC").Limit(limit).Find(&runs).Error
+	return runs, err
+}
+
+func (r *reconciliationRepository) FindRun(ctx context.Context, day time.Time) (*domain.ReconciliationRun, error) {
+	var run domain.ReconciliationRun
+	err := r.db.WithContext(ctx).Where("day = ?", day).First(&run).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (r *reconciliationRepository) ListMismatches(ctx context.Context, runID uint) ([]domain.ReconciliationMismatch, error) {
+	var mismatches []domain.ReconciliationMismatch
+	err := r.db.WithContext(ctx).Where("run_id = ?", runID).Order("user_id, kind, reference").Find(&mismatches).Error
+	return mismatches, err
+}
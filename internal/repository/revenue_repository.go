@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"gameintegrationapi/internal/domain"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RevenueGroupColumns maps the groupings a revenue report accepts to
+// daily_aggregates columns.
+var RevenueGroupColumns = map[string]string{
+	"day":      "day",
+	"game":     "game_id",
+	"provider": "provider_id",
+	"currency": "currency",
+	"player":   "user_id",
+}
+
+type RevenueRepository interface {
+	// AggregateDay rebuilds the aggregates of the UTC day starting at day
+	// from its transactions and returns the number of rows written.
+	AggregateDay(ctx context.Context, day, now time.Time) (int64, error)
+	// Sum totals the aggregates of days in [from, to) grouped by the
+	// given keys of RevenueGroupColumns. Columns not grouped by are zero.
+	Sum(ctx context.Context, from, to time.Time, groupBy []string) ([]RevenueTotal, error)
+}
+
+// RevenueTotal is the sum of the aggregates sharing a grouping.
+type RevenueTotal struct {
+	Day           time.Time
+	GameID        string
+	ProviderID    string
+	Currency      string
+	UserID        uint
+	Bets          int64
+	Turnover      float64
+	Wins          float64
+	BonusStakes   float64
+	BonusWins     float64
+	BonusReleased float64
+	BaseTurnover  float64
+	BaseWins      float64
+}
+
+type revenueRepository struct {
+	db *gorm.DB
+}
+
+func NewRevenueRepository(db *gorm.DB) RevenueRepository {
+	return &revenueRepository{db}
+}
+
+// aggregateDaySQL sums one day of settled transactions per game, provider,
+// balance currency and player, less what partial cancels reversed.
+// Transactions from before balances had a currency fall back to the
+// player's primary one.
+const aggregateDaySQL = `
+INSERT INTO daily_aggregates (day, game_id, provider_id, currency, user_id, bets, turnover, wins,
+	bonus_stakes, bonus_wins, bonus_released, base_turnover, base_wins, created_at)
+SELECT @day, COALESCE(t.provider_game_id, ''), COALESCE(t.provider_id, ''),
+	COALESCE(NULLIF(t.currency, ''), u.currency), t.user_id,
+	COUNT(CASE WHEN t.type = 'WITHDRAW' THEN 1 END),
+	COALESCE(SUM(CASE WHEN t.type = 'WITHDRAW' THEN t.amount - t.cancelled_amount END), 0),
+	COALESCE(SUM(CASE WHEN t.type = 'DEPOSIT' THEN t.amount - t.cancelled_amount END), 0),
+	COALESCE(SUM(CASE WHEN t.type = 'WITHDRAW' THEN t.bonus_amount * (1 - t.cancelled_amount / NULLIF(t.amount, 0)) END), 0),
+	COALESCE(SUM(CASE WHEN t.type = 'DEPOSIT' THEN t.bonus_amount * (1 - t.cancelled_amount / NULLIF(t.amount, 0)) END), 0),
+	COALESCE(SUM(CASE WHEN t.type = 'BONUS_RELEASE' THEN t.amount END), 0),
+	COALESCE(SUM(CASE WHEN t.type = 'WITHDRAW' THEN (t.amount - t.cancelled_amount) * t.fx_base_rate END), 0),
+	COALESCE(SUM(CASE WHEN t.type = 'DEPOSIT' THEN (t.amount - t.cancelled_amount) * t.fx_base_rate END), 0),
+	@now
+FROM transactions t
+JOIN users u ON u.id = t.user_id
+WHERE t.created_at >= @from AND t.created_at < @to
+	AND t.type IN ('WITHDRAW', 'DEPOSIT', 'BONUS_RELEASE')
+	AND t.status NOT IN ('CANCELLED', 'HELD', 'REJECTED')
+GROUP BY 2, 3, 4, 5`
+
+func (r *revenueRepository) AggregateDay(ctx context.Context, day, now time.Time) (int64, error) {
+	var rows int64
+	err := r.db.WithContext(ctx).Transaction(func(txDb *gorm.DB) error {
+		if err := txDb.Where("day = ?", day).Delete(&domain.DailyAggregate{}).Error; err != nil {
+			return err
+		}
+		res := txDb.Exec(aggregateDaySQL, map[string]interface{}{
+			"day":  day,
+			"from": day,
+			"to":   day.AddDate(0, 0, 1),
+			"now":  now,
+		})
+		rows = res.RowsAffected
+		return res.Error
+	})
+	return rows, err
+}
+
+func (r *revenueRepository) Sum(ctx context.Context, from, to time.Time, groupBy []string) ([]RevenueTotal, error) {
+	columns := make([]string, 0, len(groupBy))
+	for _, g := range groupBy {
+		columns = append(columns, RevenueGroupColumns[g])
+	}
+	group := strings.Join(columns, ", ")
+	var totals []RevenueTotal
+	err := r.db.WithContext(ctx).Model(&domain.DailyAggregate{}).
+		Select(group+`, SUM(bets) AS bets, SUM(turnover) AS turnover, SUM(wins) AS wins,
+			SUM(bonus_stakes) AS bonus_stakes, SUM(bonus_wins) AS bonus_wins, SUM(bonus_released) AS bonus_released,
+			SUM(base_turnover) AS base_turnover, SUM(base_wins) AS base_wins`).
+		Where("day >= ? AND day < ?", from, to).
+		Group(group).
+		Order(group).
+		Scan(&totals).Error
+	return totals, err
+}
@@ -1,14 +1,90 @@
 package repository
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"gameintegrationapi/internal/domain"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type TransactionRepository interface {
-	Create(tx *domain.Transaction) error
-	FindByProviderTxID(providerTxID string) (*domain.Transaction, error)
+	Create(ctx context.Context, tx *domain.Transaction) error
+	FindByProviderTxID(ctx context.Context, providerTxID string) (*domain.Transaction, error)
+	// FindCancel returns the user's first cancel of providerTxID, which is a
+	// tombstone if the transaction had not arrived, or nil.
+	FindCancel(ctx context.Context, userID uint, providerTxID string) (*domain.Transaction, error)
+	SumActivity(ctx context.Context, userID uint, since time.Time) ([]ActivityTotal, error)
+	FindByID(ctx context.Context, id uint) (*domain.Transaction, error)
+	// SumRoundWins totals wins credited to the user in a round.
+	SumRoundWins(ctx context.Context, userID uint, roundID string) (float64, error)
+	ListByStatus(ctx context.Context, status string) ([]domain.Transaction, error)
+	// UpdateStatus moves a transaction from one status to another and fails
+	// with gorm.ErrRecordNotFound if it is no longer in the expected status.
+	UpdateStatus(ctx context.Context, tx *domain.Transaction, from string) error
+	// SumByCurrency totals stakes and wins in [from, to) per balance
+	// currency, as booked and converted at each transaction's base rate.
+	SumByCurrency(ctx context.Context, from, to time.Time) ([]CurrencyTotal, error)
+	// ListBetween returns transactions created in [from, to), by user.
+	ListBetween(ctx context.Context, from, to time.Time) ([]domain.Transaction, error)
+	// BalanceAt is the statement balance of scope at the instant at: the
+	// balance after its last settled transaction before at, or else the
+	// balance before its first one from at. ok is false if it has none.
+	BalanceAt(ctx context.Context, scope StatementScope, at time.Time) (balance float64, ok bool, err error)
+	// Stream calls fn with each transaction of scope created in [from, to),
+	// oldest first, reading them one at a time. It stops at fn's first
+	// error and returns it.
+	Stream(ctx context.Context, scope StatementScope, from, to time.Time, fn func(*domain.Transaction) error) error
+	// ListByRound returns a round's transactions, oldest first. A zero
+	// userID or empty providerID matches any.
+	ListByRound(ctx context.Context, roundID string, userID uint, providerID string) ([]domain.Transaction, error)
+	// SaveExchange replaces the exchange stored on the user's transactions
+	// created since the exchange started with its complete record.
+	SaveExchange(ctx context.Context, userID uint, exchange *domain.PlatformExchange) error
+	// PurgeExchanges removes the exchanges stored on transactions created
+	// before the given time, keeping the rest of their platform record.
+	PurgeExchanges(ctx context.Context, before time.Time) (int64, error)
+}
+
+// StatementScope selects the transactions of one of a user's balances.
+// Transactions recorded before balances had a currency belong to the
+// primary one.
+type StatementScope struct {
+	UserID   uint
+	Currency string
+	Primary  bool
+}
+
+func (s StatementScope) apply(db *gorm.DB) *gorm.DB {
+	db = db.Where("user_id = ?", s.UserID)
+	if s.Primary {
+		return db.Where("currency IN (?, '')", s.Currency)
+	}
+	return db.Where("currency = ?", s.Currency)
+}
+
+// ActivityTotal is a user's stakes and winnings booked in one balance
+// currency.
+type ActivityTotal struct {
+	Currency string
+	Wagered  float64
+	Won      float64
+}
+
+// CurrencyTotal is the activity booked in one currency. Unconverted counts
+// transactions recorded without a base rate, which are missing from the
+// base amounts.
+type CurrencyTotal struct {
+	Currency     string
+	BaseCurrency string
+	Wagered      float64
+	Won          float64
+	BaseWagered  float64
+	BaseWon      float64
+	Transactions int64
+	Unconverted  int64
 }
 
 type transactionRepository struct {
@@ -19,14 +95,186 @@ func NewTransactionRepository(db *gorm.DB) TransactionRepository {
 	return &transactionRepository{db}
 }
 
-func (r *transactionRepository) Create(tx *domain.Transaction) error {
-	return r.db.Create(tx).Error
+func (r *transactionRepository) Create(ctx context.Context, tx *domain.Transaction) error {
+	return r.db.WithContext(ctx).Create(tx).Error
 }
 
-func (r *transactionRepository) FindByProviderTxID(providerTxID string) (*domain.Transaction, error) {
+func (r *transactionRepository) FindByProviderTxID(ctx context.Context, providerTxID string) (*domain.Transaction, error) {
 	var tx domain.Transaction
-	if err := r.db.Where("provider_tx_id = ?", providerTxID).First(&tx).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("provider_tx_id = ?", providerTxID).First(&tx).Error; err != nil {
 		return nil, err
 	}
 	return &tx, nil
 }
+
+func (r *transactionRepository) FindCancel(ctx context.Context, userID uint, providerTxID string) (*domain.Transaction, error) {
+	var tx domain.Transaction
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND provider_parent_tx_id = ? AND type IN ('CANCEL', 'CLAWBACK')", userID, providerTxID).
+		Order("id").First(&tx).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// SumActivity totals the user's stakes and winnings since the given time,
+// per balance currency. Cancelled stakes and wins, and uncredited wins, are
+// left out.
+func (r *transactionRepository) SumActivity(ctx context.Context, userID uint, since time.Time) ([]ActivityTotal, error) {
+	var totals []ActivityTotal
+	err := r.db.WithContext(ctx).Model(&domain.Transaction{}).
+		Select(`currency,
+			COALESCE(SUM(CASE WHEN type = 'WITHDRAW' AND status <> 'CANCELLED' THEN amount - cancelled_amount END), 0) AS wagered,
+			COALESCE(SUM(CASE WHEN type = 'DEPOSIT' AND status NOT IN ('HELD', 'REJECTED', 'CANCELLED') THEN amount - cancelled_amount END), 0) AS won`).
+		Where("user_id = ? AND created_at >= ? AND type IN ('WITHDRAW', 'DEPOSIT')", userID, since).
+		Group("currency").
+		Scan(&totals).Error
+	return totals, err
+}
+
+func (r *transactionRepository) FindByID(ctx context.Context, id uint) (*domain.Transaction, error) {
+	var tx domain.Transaction
+	if err := r.db.WithContext(ctx).First(&tx, id).Error; err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+func (r *transactionRepository) SumRoundWins(ctx context.Context, userID uint, roundID string) (float64, error) {
+	var total float64
+	err := r.db.WithContext(ctx).Model(&domain.Transaction{}).
+		Select("COALESCE(SUM(amount - cancelled_amount), 0)").
+		Where("user_id = ? AND provider_round_id = ? AND type = 'DEPOSIT' AND status NOT IN ('HELD', 'REJECTED', 'CANCELLED')", userID, roundID).
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *transactionRepository) ListByStatus(ctx context.Context, status string) ([]domain.Transaction, error) {
+	var txs []domain.Transaction
+	err := r.db.WithContext(ctx).Where("status = ?", status).Order("created_at").Find(&txs).Error
+	return txs, err
+}
+
+func (r *transactionRepository) UpdateStatus(ctx context.Context, tx *domain.Transaction, from string) error {
+	res := r.db.WithContext(ctx).Model(&domain.Transaction{}).
+		Where("id = ? AND status = ?", tx.ID, from).
+		Updates(map[string]interface{}{
+			"status":      tx.Status,
+			"old_balance": tx.OldBalance,
+			"new_balance": tx.NewBalance,
+			"reviewed_by": tx.ReviewedBy,
+			"reviewed_at": tx.ReviewedAt,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *transactionRepository) SumByCurrency(ctx context.Context, from, to time.Time) ([]CurrencyTotal, error) {
+	var totals []CurrencyTotal
+	err := r.db.WithContext(ctx).Model(&domain.Transaction{}).
+		Select(`currency, fx_base_currency AS base_currency,
+			COALESCE(SUM(CASE WHEN type = 'WITHDRAW' AND status <> 'CANCELLED' THEN amount - cancelled_amount END), 0) AS wagered,
+			COALESCE(SUM(CASE WHEN type = 'DEPOSIT' AND status NOT IN ('HELD', 'REJECTED', 'CANCELLED') THEN amount - cancelled_amount END), 0) AS won,
+			COALESCE(SUM(CASE WHEN type = 'WITHDRAW' AND status <> 'CANCELLED' THEN (amount - cancelled_amount) * fx_base_rate END), 0) AS base_wagered,
+			COALESCE(SUM(CASE WHEN type = 'DEPOSIT' AND status NOT IN ('HELD', 'REJECTED', 'CANCELLED') THEN (amount - cancelled_amount) * fx_base_rate END), 0) AS base_won,
+			COUNT(*) AS transactions,
+			COUNT(CASE WHEN fx_base_rate = 0 THEN 1 END) AS unconverted`).
+		Where("created_at >= ? AND created_at < ? AND type IN ('WITHDRAW', 'DEPOSIT')", from, to).
+		Group("currency, fx_base_currency").
+		Order("currency, fx_base_currency").
+		Scan(&totals).Error
+	return totals, err
+}
+
+func (r *transactionRepository) ListBetween(ctx context.Context, from, to time.Time) ([]domain.Transaction, error) {
+	var txs []domain.Transaction
+	err := r.db.WithContext(ctx).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Order("user_id, id").
+		Find(&txs).Error
+	return txs, err
+}
+
+// settledStatuses excludes wins that never moved the balance, and held
+// wins whose balance is only set once they are approved.
+const settledStatuses = "status NOT IN ('HELD', 'REJECTED')"
+
+func (r *transactionRepository) BalanceAt(ctx context.Context, scope StatementScope, at time.Time) (float64, bool, error) {
+	var tx domain.Transaction
+	err := scope.apply(r.db.WithContext(ctx)).
+		Where(settledStatuses).
+		Where("created_at < ?", at).
+		Order("created_at DESC, id DESC").
+		Limit(1).Find(&tx).Error
+	if err != nil || tx.ID != 0 {
+		return tx.NewBalance, tx.ID != 0, err
+	}
+	err = scope.apply(r.db.WithContext(ctx)).
+		Where(settledStatuses).
+		Where("created_at >= ?", at).
+		Order("created_at, id").
+		Limit(1).Find(&tx).Error
+	return tx.OldBalance, tx.ID != 0, err
+}
+
+func (r *transactionRepository) Stream(ctx context.Context, scope StatementScope, from, to time.Time, fn func(*domain.Transaction) error) error {
+	db := r.db.WithContext(ctx)
+	rows, err := scope.apply(db.Model(&domain.Transaction{})).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Order("created_at, id").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tx domain.Transaction
+		if err := db.ScanRows(rows, &tx); err != nil {
+			return err
+		}
+		if err := fn(&tx); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *transactionRepository) ListByRound(ctx context.Context, roundID string, userID uint, providerID string) ([]domain.Transaction, error) {
+	q := r.db.WithContext(ctx).Where("provider_round_id = ?", roundID)
+	if userID != 0 {
+		q = q.Where("user_id = ?", userID)
+	}
+	if providerID != "" {
+		q = q.Where("provider_id = ?", providerID)
+	}
+	var txs []domain.Transaction
+	err := q.Order("id").Find(&txs).Error
+	return txs, err
+}
+
+func (r *transactionRepository) SaveExchange(ctx context.Context, userID uint, exchange *domain.PlatformExchange) error {
+	data, err := json.Marshal(exchange)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Model(&domain.Transaction{}).
+		// The margin covers created_at being stored at a coarser precision.
+		Where("user_id = ? AND created_at >= ? AND platform_response->'exchange'->>'id' = ?", userID, exchange.StartedAt.Add(-time.Second), exchange.ID).
+		Update("platform_response", gorm.Expr("jsonb_set(platform_response, '{exchange}', ?::jsonb)", string(data))).Error
+}
+
+func (r *transactionRepository) PurgeExchanges(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Model(&domain.Transaction{}).
+		Where("created_at < ? AND platform_response->'exchange' IS NOT NULL", before).
+		Update("platform_response", gorm.Expr("platform_response - 'exchange'"))
+	return res.RowsAffected, res.Error
+}
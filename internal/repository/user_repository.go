@@ -1,19 +1,48 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"gameintegrationapi/internal/domain"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
-	FindByCredentials(username, password string) (*domain.User, error)
-	FindByID(id uint) (*domain.User, error)
-	UpdateBalance(user *domain.User, newBalance float64) error
+	FindByCredentials(ctx context.Context, username, password string) (*domain.User, error)
+	FindByID(ctx context.Context, id uint) (*domain.User, error)
+	// FindForUpdate reads the user and locks their row until the
+	// transaction ends. Every operation on a player's balances takes this
+	// lock first, so that they run one at a time.
+	FindForUpdate(ctx context.Context, id uint) (*domain.User, error)
+	FindByUsername(ctx context.Context, username string) (*domain.User, error)
+	FindByWalletID(ctx context.Context, walletID string) (*domain.User, error)
+	ExistsByUsernameOrWalletID(ctx context.Context, username, walletID string) (usernameTaken, walletTaken bool, err error)
+	Create(ctx context.Context, user *domain.User) error
+	UpdateBalance(ctx context.Context, user *domain.User, newBalance float64) error
+	UpdateBonusBalance(ctx context.Context, user *domain.User, bonusBalance float64) error
+	// AddBalance and AddBonusBalance add delta to the stored balance rather
+	// than overwrite it.
+	AddBalance(ctx context.Context, userID uint, delta float64) error
+	AddBonusBalance(ctx context.Context, userID uint, delta float64) error
+	UpdatePassword(ctx context.Context, user *domain.User, passwordHash string) error
+	UpdateTOTP(ctx context.Context, user *domain.User) error
+	AdvanceTOTPStep(ctx context.Context, userID uint, step int64) (bool, error)
+	// ListAll returns every user's ID, wallet ID and currency.
+	ListAll(ctx context.Context) ([]domain.User, error)
 }
 
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// dummyHash is compared against when the username does not exist, so that
+// unknown users take as long to reject as wrong passwords.
+var dummyHash = func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	return hash
+}()
+
 type userRepository struct {
 	db *gorm.DB
 }
@@ -22,25 +51,115 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db}
 }
 
-func (r *userRepository) FindByCredentials(username, password string) (*domain.User, error) {
+func (r *userRepository) FindByCredentials(ctx context.Context, username, password string) (*domain.User, error) {
 	var user domain.User
-	if err := r.db.Where("username = ?", username).First(&user).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidCredentials
+		}
 		return nil, err
 	}
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
 	}
 	return &user, nil
 }
 
-func (r *userRepository) FindByID(id uint) (*domain.User, error) {
+func (r *userRepository) FindByID(ctx context.Context, id uint) (*domain.User, error) {
 	var user domain.User
-	if err := r.db.First(&user, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) UpdateBalance(user *domain.User, newBalance float64) error {
-	return r.db.Model(user).Update("balance", newBalance).Error
+func (r *userRepository) FindForUpdate(ctx context.Context, id uint) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) UpdateBalance(ctx context.Context, user *domain.User, newBalance float64) error {
+	return r.db.WithContext(ctx).Model(user).Update("balance", newBalance).Error
+}
+
+func (r *userRepository) UpdateBonusBalance(ctx context.Context, user *domain.User, bonusBalance float64) error {
+	return r.db.WithContext(ctx).Model(user).Update("bonus_balance", bonusBalance).Error
+}
+
+func (r *userRepository) AddBalance(ctx context.Context, userID uint, delta float64) error {
+	return r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", userID).
+		Update("balance", gorm.Expr("balance + ?", delta)).Error
+}
+
+func (r *userRepository) AddBonusBalance(ctx context.Context, userID uint, delta float64) error {
+	return r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", userID).
+		Update("bonus_balance", gorm.Expr("bonus_balance + ?", delta)).Error
+}
+
+func (r *userRepository) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByWalletID(ctx context.Context, walletID string) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.WithContext(ctx).Where("wallet_id = ?", walletID).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) ExistsByUsernameOrWalletID(ctx context.Context, username, walletID string) (bool, bool, error) {
+	var users []domain.User
+	err := r.db.WithContext(ctx).Select("username", "wallet_id").
+		Where("username = ? OR wallet_id = ?", username, walletID).
+		Find(&users).Error
+	if err != nil {
+		return false, false, err
+	}
+	var usernameTaken, walletTaken bool
+	for _, u := range users {
+		usernameTaken = usernameTaken || u.Username == username
+		walletTaken = walletTaken || u.WalletID == walletID
+	}
+	return usernameTaken, walletTaken, nil
+}
+
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, user *domain.User, passwordHash string) error {
+	return r.db.WithContext(ctx).Model(user).Update("password", passwordHash).Error
+}
+
+// UpdateTOTP persists the user's TOTP secret, enabled flag and last step.
+func (r *userRepository) UpdateTOTP(ctx context.Context, user *domain.User) error {
+	return r.db.WithContext(ctx).Model(user).Select("totp_secret", "totp_enabled", "totp_last_step").Updates(user).Error
+}
+
+// AdvanceTOTPStep records step as the last accepted TOTP step. It returns
+// false if an equal or later step was already recorded, i.e. the code was
+// replayed concurrently.
+func (r *userRepository) AdvanceTOTPStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&domain.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *userRepository) ListAll(ctx context.Context) ([]domain.User, error) {
+	var users []domain.User
+	err := r.db.WithContext(ctx).Select("id", "wallet_id", "currency").Order("id").Find(&users).Error
+	return users, err
 }
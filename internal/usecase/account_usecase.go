@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const maxPasswordLength = 72 // bcrypt ignores anything longer
+
+var (
+	ErrUsernameTaken       = errors.New("username is already taken")
+	ErrWalletAlreadyLinked = errors.New("wallet is already linked to another user")
+	ErrInvalidWallet       = errors.New("wallet could not be verified")
+	ErrRegistrationClosed  = errors.New("registration is not enabled")
+	ErrInvalidResetToken   = errors.New("reset token is invalid or has expired")
+
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,32}$`)
+	currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// ValidationError reports a request that breaks a registration or password
+// rule. Its message is safe to show to the user.
+type ValidationError struct {
+	Msg string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Msg
+}
+
+type RegisterInput struct {
+	Username string
+	Password string
+	Email    string
+	Currency string
+	WalletID string
+	// WalletToken is the link token issued by the wallet operator to the
+	// wallet's owner.
+	WalletToken string
+}
+
+type AccountUseCase interface {
+	Register(ctx context.Context, in RegisterInput) (*domain.User, error)
+	ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) error
+	RequestPasswordReset(ctx context.Context, username string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+type accountUseCase struct {
+	userRepo     repository.UserRepository
+	resetRepo    repository.PasswordResetRepository
+	walletClient *infrastructure.WalletClient
+	notifier     infrastructure.Notifier
+	cfg          infrastructure.AuthConfig
+}
+
+func NewAccountUseCase(userRepo repository.UserRepository, resetRepo repository.PasswordResetRepository, walletClient *infrastructure.WalletClient, notifier infrastructure.Notifier, cfg infrastructure.AuthConfig) AccountUseCase {
+	return &accountUseCase{userRepo, resetRepo, walletClient, notifier, cfg}
+}
+
+// Register creates a player linked to an existing wallet account. The wallet
+// service has no account-creation API, so the wallet must already exist and
+// hold the requested currency, and the caller must present a link token
+// issued for it to prove they own it.
+func (uc *accountUseCase) Register(ctx context.Context, in RegisterInput) (user *domain.User, err error) {
+	ctx, span := tracer.Start(ctx, "AccountUseCase.Register")
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	if uc.cfg.WalletLinkSecret == "" {
+		return nil, ErrRegistrationClosed
+	}
+	in.Currency = strings.ToUpper(in.Currency)
+	if !usernamePattern.MatchString(in.Username) {
+		return nil, &ValidationError{Msg: "username must be 3-32 characters of letters, digits, '.', '_' or '-'"}
+	}
+	if !currencyPattern.MatchString(in.Currency) {
+		return nil, &ValidationError{Msg: "currency must be a 3-letter ISO code"}
+	}
+	// Wallet IDs are stored in canonical form, so that "0123" cannot link
+	// the wallet already linked as "123".
+	walletID, err := strconv.ParseInt(in.WalletID, 10, 64)
+	if err != nil || walletID <= 0 {
+		return nil, &ValidationError{Msg: "wallet ID must be a positive number"}
+	}
+	in.WalletID = strconv.FormatInt(walletID, 10)
+	if err := uc.checkPasswordPolicy(in.Password, in.Username); err != nil {
+		return nil, err
+	}
+	if err := infrastructure.VerifyWalletLinkToken(uc.cfg.WalletLinkSecret, in.WalletID, in.WalletToken, time.Now()); err != nil {
+		log.Printf("Register: rejected link token for wallet %s", in.WalletID)
+		return nil, ErrInvalidWallet
+	}
+
+	usernameTaken, walletTaken, err := uc.userRepo.ExistsByUsernameOrWalletID(ctx, in.Username, in.WalletID)
+	if err != nil {
+		return nil, err
+	}
+	if usernameTaken {
+		return nil, ErrUsernameTaken
+	}
+	if walletTaken {
+		return nil, ErrWalletAlreadyLinked
+	}
+
+	balance, err := uc.walletClient.GetBalanceStr(ctx, in.WalletID)
+	if err != nil {
+		log.Printf("Register: wallet lookup failed for %s: %v", in.WalletID, err)
+		if errors.Is(err, infrastructure.ErrCircuitOpen) {
+			return nil, ErrWalletServiceUnavailable
+		}
+		return nil, ErrInvalidWallet
+	}
+	if balance.Currency != "" && balance.Currency != in.Currency {
+		return nil, &ValidationError{Msg: fmt.Sprintf("wallet currency is %s, not %s", balance.Currency, in.Currency)}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(in.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	user = &domain.User{
+		WalletID:  in.WalletID,
+		Username:  in.Username,
+		Email:     in.Email,
+		Password:  string(hash),
+		Currency:  in.Currency,
+		Role:      domain.RolePlayer,
+		Balance:   balance.Balance,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := uc.userRepo.Create(ctx, user); err != nil {
+		log.Printf("Register: failed to create user: %v", err)
+		return nil, err
+	}
+	log.Printf("Register: created user %d (%s)", user.ID, user.Username)
+	return user, nil
+}
+
+func (uc *accountUseCase) ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) (err error) {
+	ctx, span := tracer.Start(ctx, "AccountUseCase.ChangePassword")
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)) != nil {
+		return ErrInvalidCredentials
+	}
+	if err := uc.checkPasswordPolicy(newPassword, user.Username); err != nil {
+		return err
+	}
+	if err := uc.setPassword(ctx, user, newPassword); err != nil {
+		return err
+	}
+	log.Printf("ChangePassword: password changed for user %d", userID)
+	return nil
+}
+
+// RequestPasswordReset issues a reset token and sends it through the
+// notifier. Unknown usernames succeed silently so the endpoint cannot be used
+// to discover accounts.
+func (uc *accountUseCase) RequestPasswordReset(ctx context.Context, username string) (err error) {
+	ctx, span := tracer.Start(ctx, "AccountUseCase.RequestPasswordReset")
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	user, err := uc.userRepo.FindByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("RequestPasswordReset: unknown username %q", username)
+			return nil
+		}
+		return err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(raw)
+	now := time.Now()
+	if err := uc.resetRepo.InvalidateForUser(ctx, user.ID, now); err != nil {
+		return err
+	}
+	if err := uc.resetRepo.Create(ctx, &domain.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashResetToken(token),
+		ExpiresAt: now.Add(uc.cfg.ResetTokenTTL),
+		CreatedAt: now,
+	}); err != nil {
+		return err
+	}
+
+	recipient := user.Email
+	if recipient == "" {
+		recipient = user.Username
+	}
+	body := fmt.Sprintf("Use the link below to reset your password. It expires in %s and can be used once.\n\n%s%s",
+		uc.cfg.ResetTokenTTL, uc.cfg.ResetURL, token)
+	if err := uc.notifier.Notify(ctx, recipient, "Password reset", body); err != nil {
+		log.Printf("RequestPasswordReset: failed to notify user %d: %v", user.ID, err)
+		return err
+	}
+	log.Printf("RequestPasswordReset: token issued for user %d", user.ID)
+	return nil
+}
+
+func (uc *accountUseCase) ResetPassword(ctx context.Context, token, newPassword string) (err error) {
+	ctx, span := tracer.Start(ctx, "AccountUseCase.ResetPassword")
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	now := time.Now()
+	reset, err := uc.resetRepo.FindValid(ctx, hashResetToken(token), now)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	user, err := uc.userRepo.FindByID(ctx, reset.UserID)
+	if err != nil {
+		return err
+	}
+	if err := uc.checkPasswordPolicy(newPassword, user.Username); err != nil {
+		return err
+	}
+	if err := uc.resetRepo.MarkUsed(ctx, reset, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if err := uc.setPassword(ctx, user, newPassword); err != nil {
+		return err
+	}
+	log.Printf("ResetPassword: password reset for user %d", user.ID)
+	return nil
+}
+
+func (uc *accountUseCase) setPassword(ctx context.Context, user *domain.User, password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return uc.userRepo.UpdatePassword(ctx, user, string(hash))
+}
+
+// checkPasswordPolicy requires the configured minimum length, at least one
+// letter and one digit, and a password different from the username.
+func (uc *accountUseCase) checkPasswordPolicy(password, username string) error {
+	if len(password) < uc.cfg.PasswordMinLength || len(password) > maxPasswordLength {
+		return &ValidationError{Msg: fmt.Sprintf("password must be between %d and %d characters", uc.cfg.PasswordMinLength, maxPasswordLength)}
+	}
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		hasLetter = hasLetter || unicode.IsLetter(r)
+		hasDigit = hasDigit || unicode.IsDigit(r)
+	}
+	if !hasLetter || !hasDigit {
+		return &ValidationError{Msg: "password must contain at least one letter and one digit"}
+	}
+	if strings.EqualFold(password, username) {
+		return &ValidationError{Msg: "password must not match the username"}
+	}
+	return nil
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
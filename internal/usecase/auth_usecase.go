@@ -1,36 +1,238 @@
 package usecase
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"gameintegrationapi/internal/domain"
 	"gameintegrationapi/internal/repository"
+	"log"
+	"time"
 
 	"gameintegrationapi/internal/infrastructure"
 )
 
-var jwtKey = []byte("your-secret-key") // TODO: Move to config
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// AccountLockedError is returned while a username or client IP is locked out
+// after too many failed logins.
+type AccountLockedError struct {
+	Until time.Time
+}
+
+func (e *AccountLockedError) Error() string {
+	return "too many failed login attempts, try again later"
+}
+
+var ErrInvalidChallenge = errors.New("login challenge is invalid or has expired")
+
+// LoginResult carries either an access token or, when the user has 2FA
+// enabled, a challenge token to be exchanged via CompleteLogin.
+type LoginResult struct {
+	Username       string
+	Token          string
+	ChallengeToken string
+	// EnrollmentRequired is set for roles that must use 2FA. Until an
+	// operator enrols them, their token does not work on admin routes.
+	EnrollmentRequired bool
+}
 
 type AuthUseCase interface {
-	Login(username, password string) (string, error)
+	Login(ctx context.Context, username, password, ip string) (*LoginResult, error)
+	CompleteLogin(ctx context.Context, challengeToken, code, ip string) (*LoginResult, error)
+	ListLockouts(ctx context.Context) ([]domain.LoginLockout, error)
+	ClearLockout(ctx context.Context, id uint) error
 }
 
 type authUseCase struct {
-	userRepo repository.UserRepository
+	userRepo    repository.UserRepository
+	attemptRepo repository.LoginAttemptRepository
+	factor      *secondFactor
+	jwtKeys     *infrastructure.JWTKeys
+	cfg         infrastructure.AuthConfig
 }
 
-func NewAuthUseCase(userRepo repository.UserRepository) AuthUseCase {
-	return &authUseCase{userRepo}
+func NewAuthUseCase(userRepo repository.UserRepository, attemptRepo repository.LoginAttemptRepository, recoveryRepo repository.RecoveryCodeRepository, box *infrastructure.SecretBox, jwtKeys *infrastructure.JWTKeys, cfg infrastructure.AuthConfig) AuthUseCase {
+	return &authUseCase{userRepo, attemptRepo, &secondFactor{userRepo, recoveryRepo, box}, jwtKeys, cfg}
+}
+
+func (uc *authUseCase) Login(ctx context.Context, username, password, ip string) (*LoginResult, error) {
+	ctx, span := tracer.Start(ctx, "AuthUseCase.Login")
+	defer span.End()
+
+	now := time.Now()
+	userKey, ipKey := "user:"+username, "ip:"+ip
+	if err := uc.checkLockouts(ctx, username, ip, now); err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.FindByCredentials(ctx, username, password)
+	if err != nil {
+		if !errors.Is(err, repository.ErrInvalidCredentials) {
+			log.Printf("Login: failed to look up user: %v", err)
+			return nil, err
+		}
+		failures := uc.registerFailure(ctx, userKey, uc.cfg.MaxFailedAttempts, now)
+		uc.registerFailure(ctx, ipKey, uc.cfg.MaxFailedAttemptsPerIP, now)
+		uc.audit(ctx, username, ip, false, "INVALID_CREDENTIALS")
+		uc.delay(ctx, failures)
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.TOTPEnabled {
+		// The lockout counter is kept until the second factor succeeds, so
+		// that a known password does not reset attempts at guessing codes.
+		uc.audit(ctx, username, ip, true, "PASSWORD_OK_2FA_PENDING")
+		challenge, err := uc.jwtKeys.GenerateMFAChallengeJWT(user.ID, user.Username)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{Username: user.Username, ChallengeToken: challenge}, nil
+	}
+
+	if err := uc.attemptRepo.ResetLockout(ctx, userKey); err != nil {
+		log.Printf("Login: failed to reset lockout for %s: %v", username, err)
+	}
+	uc.audit(ctx, username, ip, true, "")
+
+	token, err := uc.jwtKeys.GenerateJWT(user.ID, user.Username, user.Role, false)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Username: user.Username, Token: token, EnrollmentRequired: user.Role != domain.RolePlayer}, nil
 }
 
-func (uc *authUseCase) Login(username, password string) (string, error) {
-	user, err := uc.userRepo.FindByCredentials(username, password)
+// CompleteLogin exchanges a challenge token and a TOTP or recovery code for
+// an access token. Wrong codes count towards the same lockout as passwords.
+func (uc *authUseCase) CompleteLogin(ctx context.Context, challengeToken, code, ip string) (*LoginResult, error) {
+	ctx, span := tracer.Start(ctx, "AuthUseCase.CompleteLogin")
+	defer span.End()
+
+	claims, err := uc.jwtKeys.ParseJWT(challengeToken)
+	if err != nil || claims.Purpose != infrastructure.PurposeMFAChallenge {
+		return nil, ErrInvalidChallenge
+	}
+	now := time.Now()
+	username := claims.Username
+	userKey, ipKey := "user:"+username, "ip:"+ip
+	if err := uc.checkLockouts(ctx, username, ip, now); err != nil {
+		return nil, err
+	}
+	user, err := uc.userRepo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrInvalidChallenge
+	}
+
+	ok, err := uc.factor.verify(ctx, user, code)
 	if err != nil {
-		return "", errors.New("invalid credentials")
+		log.Printf("CompleteLogin: failed to verify code for user %d: %v", user.ID, err)
+		return nil, err
+	}
+	if !ok {
+		failures := uc.registerFailure(ctx, userKey, uc.cfg.MaxFailedAttempts, now)
+		uc.registerFailure(ctx, ipKey, uc.cfg.MaxFailedAttemptsPerIP, now)
+		uc.audit(ctx, username, ip, false, "INVALID_2FA_CODE")
+		uc.delay(ctx, failures)
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	if err := uc.attemptRepo.ResetLockout(ctx, userKey); err != nil {
+		log.Printf("CompleteLogin: failed to reset lockout for %s: %v", username, err)
+	}
+	uc.audit(ctx, username, ip, true, "2FA")
+
+	token, err := uc.jwtKeys.GenerateJWT(user.ID, user.Username, user.Role, true)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Username: user.Username, Token: token}, nil
+}
+
+func (uc *authUseCase) checkLockouts(ctx context.Context, username, ip string, now time.Time) error {
+	for _, key := range []string{"user:" + username, "ip:" + ip} {
+		lockout, err := uc.attemptRepo.FindLockout(ctx, key)
+		if err != nil {
+			log.Printf("Login: failed to load lockout %s: %v", key, err)
+			return err
+		}
+		if lockout.IsLocked(now) {
+			uc.audit(ctx, username, ip, false, "LOCKED")
+			return &AccountLockedError{Until: *lockout.LockedUntil}
+		}
+	}
+	return nil
+}
+
+func (uc *authUseCase) ListLockouts(ctx context.Context) ([]domain.LoginLockout, error) {
+	return uc.attemptRepo.ListLockouts(ctx, time.Now())
+}
+
+func (uc *authUseCase) ClearLockout(ctx context.Context, id uint) error {
+	if err := uc.attemptRepo.DeleteLockout(ctx, id); err != nil {
+		return fmt.Errorf("clear lockout %d: %w", id, err)
 	}
+	log.Printf("ClearLockout: lockout %d cleared", id)
+	return nil
+}
 
-	token, err := infrastructure.GenerateJWT(user.ID, user.Username, string(jwtKey))
+// registerFailure counts a failed attempt against key and locks it once the
+// count reaches max. It returns the number of failures in the current window.
+func (uc *authUseCase) registerFailure(ctx context.Context, key string, max int, now time.Time) int {
+	lockout, err := uc.attemptRepo.UpdateLockout(ctx, key, func(l *domain.LoginLockout) {
+		if !l.IsLocked(now) && now.Sub(l.LastFailureAt) > uc.cfg.FailureWindow {
+			l.Failures = 0
+		}
+		l.Failures++
+		l.LastFailureAt = now
+		if l.Failures >= max {
+			d := uc.cfg.LockoutDuration
+			for i := max; i < l.Failures && d < uc.cfg.MaxLockoutDuration; i++ {
+				d *= 2
+			}
+			if d > uc.cfg.MaxLockoutDuration {
+				d = uc.cfg.MaxLockoutDuration
+			}
+			until := now.Add(d)
+			l.LockedUntil = &until
+		}
+	})
 	if err != nil {
-		return "", err
+		log.Printf("Login: failed to record failure for %s: %v", key, err)
+		return 0
 	}
+	return lockout.Failures
+}
 
-	return token, nil
+// delay slows down repeated failures, doubling per failure up to the limit.
+func (uc *authUseCase) delay(ctx context.Context, failures int) {
+	if failures <= 0 || uc.cfg.FailureDelay <= 0 {
+		return
+	}
+	d := uc.cfg.FailureDelay
+	for i := 1; i < failures && d < uc.cfg.MaxFailureDelay; i++ {
+		d *= 2
+	}
+	if d > uc.cfg.MaxFailureDelay {
+		d = uc.cfg.MaxFailureDelay
+	}
+	select {
+	case <-time.After(d):
+	case <-ctx.Done():
+	}
+}
+
+func (uc *authUseCase) audit(ctx context.Context, username, ip string, success bool, reason string) {
+	attempt := &domain.LoginAttempt{
+		Username:  username,
+		IP:        ip,
+		Success:   success,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+	if err := uc.attemptRepo.Record(ctx, attempt); err != nil {
+		log.Printf("Login: failed to write audit record: %v", err)
+	}
 }
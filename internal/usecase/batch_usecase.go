@@ -0,0 +1,342 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"log"
+	"slices"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// MaxBatchItems is the most operations one batch may carry.
+const MaxBatchItems = 100
+
+const (
+	BatchWithdraw = "withdraw"
+	BatchDeposit  = "deposit"
+	BatchCancel   = "cancel"
+)
+
+// BatchItem is one operation of a batch. Op selects which of Withdraw,
+// Deposit or Cancel is used; their UserID is ignored.
+type BatchItem struct {
+	Op       string
+	Withdraw WithdrawInput
+	Deposit  DepositInput
+	Cancel   CancelInput
+}
+
+// BatchInput is a set of operations for one player, applied in order.
+//
+// An atomic batch is booked in one database transaction and sent to the
+// wallet as one withdraw call and one deposit call per currency: either every
+// item is applied or none is. Free rounds and jackpot payouts cannot be part
+// of an atomic batch, as they are claimed outside it.
+//
+// Otherwise each item is applied on its own, exactly as the single-operation
+// endpoints would, and fails on its own.
+//
+// In both modes an item whose provider transaction is already booked for the
+// player is not applied again; the booked transaction is returned instead.
+type BatchInput struct {
+	UserID uint
+	Atomic bool
+	Items  []BatchItem
+}
+
+// BatchItemResult is the outcome of one item. Err is only set outside
+// atomic batches.
+type BatchItemResult struct {
+	Transaction *domain.Transaction
+	// Replayed is true when the item had already been applied.
+	Replayed bool
+	Err      error
+}
+
+// BatchError fails an atomic batch because of one of its items.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+func (uc *walletUseCase) Batch(ctx context.Context, in BatchInput) (results []BatchItemResult, err error) {
+	ctx, span := tracer.Start(ctx, "WalletUseCase.Batch", trace.WithAttributes(
+		attribute.Int("user.id", int(in.UserID)),
+		attribute.Int("batch.items", len(in.Items)),
+		attribute.Bool("batch.atomic", in.Atomic),
+	))
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	if err := checkBatch(in); err != nil {
+		return nil, err
+	}
+	if err := uc.tracker.Begin(); err != nil {
+		return nil, err
+	}
+	defer uc.tracker.Done()
+
+	if !in.Atomic {
+		results = make([]BatchItemResult, len(in.Items))
+		for i, item := range in.Items {
+			tx, replayed, err := uc.batchItem(ctx, in.UserID, item)
+			results[i] = BatchItemResult{Transaction: tx, Replayed: replayed, Err: err}
+		}
+		return results, nil
+	}
+	return uc.atomicBatch(ctx, in)
+}
+
+func checkBatch(in BatchInput) error {
+	if len(in.Items) == 0 || len(in.Items) > MaxBatchItems {
+		return &ValidationError{Msg: fmt.Sprintf("a batch must have between 1 and %d items", MaxBatchItems)}
+	}
+	for i, item := range in.Items {
+		switch item.Op {
+		case BatchWithdraw, BatchDeposit, BatchCancel:
+		default:
+			return &ValidationError{Msg: fmt.Sprintf("item %d: unknown operation %q", i, item.Op)}
+		}
+		if in.Atomic && (item.Withdraw.FreeRound || item.Deposit.JackpotID != 0) {
+			return &ValidationError{Msg: fmt.Sprintf("item %d: free rounds and jackpot payouts cannot be batched atomically", i)}
+		}
+	}
+	return nil
+}
+
+// batchItem applies one item unless it was applied before.
+func (uc *walletUseCase) batchItem(ctx context.Context, userID uint, item BatchItem) (*domain.Transaction, bool, error) {
+	booked, err := uc.booked(ctx, userID, item)
+	if err != nil || booked != nil {
+		return booked, booked != nil, err
+	}
+	var tx *domain.Transaction
+	switch item.Op {
+	case BatchWithdraw:
+		in := item.Withdraw
+		in.UserID = userID
+		tx, err = uc.Withdraw(ctx, in)
+	case BatchDeposit:
+		in := item.Deposit
+		in.UserID = userID
+		tx, err = uc.Deposit(ctx, in)
+	case BatchCancel:
+		in := item.Cancel
+		in.UserID = userID
+		tx, err = uc.Cancel(ctx, in)
+	}
+	return tx, false, err
+}
+
+// booked returns the transaction the item already produced, or nil.
+func (uc *walletUseCase) booked(ctx context.Context, userID uint, item BatchItem) (*domain.Transaction, error) {
+	providerTxID, txTypes := item.Withdraw.ProviderTxID, []string{"WITHDRAW"}
+	switch item.Op {
+	case BatchDeposit:
+		providerTxID, txTypes = item.Deposit.ProviderTxID, []string{"DEPOSIT"}
+	case BatchCancel:
+		providerTxID, txTypes = item.Cancel.reference(), []string{"CANCEL", "CLAWBACK"}
+	}
+	tx, err := uc.transactionRepo.FindByProviderTxID(ctx, providerTxID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tx.UserID != userID || !slices.Contains(txTypes, tx.Type) {
+		return nil, nil
+	}
+	return tx, nil
+}
+
+// atomicBatch books every item in one database transaction, holding the
+// player's lock and collecting their wallet calls, and commits only once the
+// wallet has accepted them all.
+func (uc *walletUseCase) atomicBatch(ctx context.Context, in BatchInput) ([]BatchItemResult, error) {
+	batch := &walletBatch{}
+	bctx := context.WithValue(ctx, walletBatchKey{}, batch)
+	results := make([]BatchItemResult, len(in.Items))
+	err := uc.serialize(bctx, in.UserID, func(lctx context.Context, locked *walletUseCase) error {
+		locked.limits = &batchLimits{ResponsibleGamingUseCase: uc.limits}
+		for i, item := range in.Items {
+			tx, replayed, err := locked.batchItem(lctx, in.UserID, item)
+			if err != nil {
+				log.Printf("Batch: item %d for user %d failed: %v", i, in.UserID, err)
+				return &BatchError{Index: i, Err: err}
+			}
+			results[i] = BatchItemResult{Transaction: tx, Replayed: replayed}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return uc.sendBatch(ctx, batch)
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Batch: %d items booked for user %d", len(in.Items), in.UserID)
+	return results, nil
+}
+
+// sendBatch makes the collected wallet calls, withdrawals first. If a call
+// fails after others succeeded, those are reversed.
+func (uc *walletUseCase) sendBatch(ctx context.Context, b *walletBatch) error {
+	for i, req := range b.withdrawals {
+		if _, err := uc.walletClient.Withdraw(ctx, req); err != nil {
+			uc.revertBatch(ctx, b.withdrawals[:i], nil)
+			return batchWalletError(err)
+		}
+	}
+	for i, req := range b.deposits {
+		if _, err := uc.walletClient.Deposit(ctx, req); err != nil {
+			uc.revertBatch(ctx, b.withdrawals, b.deposits[:i])
+			return batchWalletError(err)
+		}
+	}
+	return nil
+}
+
+// revertBatch reverses wallet calls of a batch that is being rolled back.
+// A failure leaves the wallet and the ledger apart; reconciliation reports it.
+func (uc *walletUseCase) revertBatch(ctx context.Context, withdrawals []infrastructure.WalletWithdrawRequest, deposits []infrastructure.WalletDepositRequest) {
+	ctx = context.WithoutCancel(ctx)
+	for _, req := range withdrawals {
+		refund := infrastructure.WalletDepositRequest{Currency: req.Currency, Transactions: revertLines(req.Transactions), UserID: req.UserID}
+		if _, err := uc.walletClient.Deposit(ctx, refund); err != nil {
+			log.Printf("Batch: failed to revert withdrawal of wallet %d in %s: %v", req.UserID, req.Currency, err)
+		}
+	}
+	for _, req := range deposits {
+		clawback := infrastructure.WalletWithdrawRequest{Currency: req.Currency, Transactions: revertLines(req.Transactions), UserID: req.UserID}
+		if _, err := uc.walletClient.Withdraw(ctx, clawback); err != nil {
+			log.Printf("Batch: failed to revert deposit of wallet %d in %s: %v", req.UserID, req.Currency, err)
+		}
+	}
+}
+
+func revertLines(lines []walletLine) []walletLine {
+	out := make([]walletLine, len(lines))
+	for i, l := range lines {
+		out[i] = walletLine{Amount: l.Amount, BetID: l.BetID, Reference: "revert-" + l.Reference}
+	}
+	return out
+}
+
+func batchWalletError(err error) error {
+	log.Printf("Batch: external wallet error: %v", err)
+	if errors.Is(err, infrastructure.ErrCircuitOpen) {
+		return ErrWalletServiceUnavailable
+	}
+	return err
+}
+
+// walletLine is one line of a wallet withdraw or deposit call.
+type walletLine = struct {
+	Amount    float64 `json:"amount"`
+	BetID     int     `json:"betId"`
+	Reference string  `json:"reference"`
+}
+
+type walletBatchKey struct{}
+
+// walletBatch collects the wallet calls of an atomic batch, merged into one
+// call per currency.
+type walletBatch struct {
+	withdrawals []infrastructure.WalletWithdrawRequest
+	deposits    []infrastructure.WalletDepositRequest
+}
+
+func batchFrom(ctx context.Context) *walletBatch {
+	b, _ := ctx.Value(walletBatchKey{}).(*walletBatch)
+	return b
+}
+
+// walletWithdraw calls the wallet, or adds the call to the batch being
+// booked.
+func (uc *walletUseCase) walletWithdraw(ctx context.Context, req infrastructure.WalletWithdrawRequest) error {
+	b := batchFrom(ctx)
+	if b == nil {
+		_, err := uc.walletClient.Withdraw(ctx, req)
+		return err
+	}
+	for i := range b.withdrawals {
+		if b.withdrawals[i].Currency == req.Currency {
+			b.withdrawals[i].Transactions = append(b.withdrawals[i].Transactions, req.Transactions...)
+			return nil
+		}
+	}
+	req.Transactions = append([]walletLine(nil), req.Transactions...)
+	b.withdrawals = append(b.withdrawals, req)
+	return nil
+}
+
+// walletDeposit calls the wallet, or adds the call to the batch being
+// booked.
+func (uc *walletUseCase) walletDeposit(ctx context.Context, req infrastructure.WalletDepositRequest) error {
+	b := batchFrom(ctx)
+	if b == nil {
+		_, err := uc.walletClient.Deposit(ctx, req)
+		return err
+	}
+	for i := range b.deposits {
+		if b.deposits[i].Currency == req.Currency {
+			b.deposits[i].Transactions = append(b.deposits[i].Transactions, req.Transactions...)
+			return nil
+		}
+	}
+	req.Transactions = append([]walletLine(nil), req.Transactions...)
+	b.deposits = append(b.deposits, req)
+	return nil
+}
+
+// batchLimits counts the stakes already booked in a batch, which the limit
+// checks cannot see until it commits.
+type batchLimits struct {
+	ResponsibleGamingUseCase
+	staked float64
+}
+
+func (l *batchLimits) CheckStake(ctx context.Context, userID uint, amount float64) error {
+	if err := l.ResponsibleGamingUseCase.CheckStake(ctx, userID, l.staked+amount); err != nil {
+		return err
+	}
+	l.staked += amount
+	return nil
+}
+
+// txCurrencies reads secondary balances through the transaction holding the
+// player's lock.
+type txCurrencies struct {
+	CurrencyUseCase
+	balanceRepo repository.BalanceRepository
+}
+
+func (c *txCurrencies) Book(ctx context.Context, user *domain.User, currency string, amount float64, balanceCurrency string) (*Booking, error) {
+	b, err := c.CurrencyUseCase.Book(ctx, user, currency, amount, balanceCurrency)
+	if err != nil || b.Primary {
+		return b, err
+	}
+	held, err := c.balanceRepo.Find(ctx, user.ID, b.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if held != nil {
+		b.Balance = held.Balance
+	}
+	return b, nil
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/repository"
+	"log"
+	"strings"
+	"time"
+)
+
+// Error codes for stakes outside the configured range.
+const (
+	StakeTooLowCode  = "STAKE_TOO_LOW"
+	StakeTooHighCode = "STAKE_TOO_HIGH"
+)
+
+var (
+	ErrInvalidStake  = errors.New("stake must be greater than zero")
+	ErrInvalidAmount = errors.New("amount must not be negative")
+)
+
+// StakeRuleError reports a stake outside the bounds of the matching rule.
+type StakeRuleError struct {
+	Code  string
+	Limit float64
+}
+
+func (e *StakeRuleError) Error() string {
+	if e.Code == StakeTooLowCode {
+		return fmt.Sprintf("stake is below the minimum of %.2f", e.Limit)
+	}
+	return fmt.Sprintf("stake is above the maximum of %.2f", e.Limit)
+}
+
+type BetRuleUseCase interface {
+	ListRules(ctx context.Context) ([]domain.BetRule, error)
+	SaveRule(ctx context.Context, rule *domain.BetRule) error
+	DeleteRule(ctx context.Context, id uint) error
+	// Resolve returns the most specific rule matching the bet, or nil.
+	Resolve(ctx context.Context, gameID, providerID, currency string) (*domain.BetRule, error)
+}
+
+type betRuleUseCase struct {
+	ruleRepo repository.BetRuleRepository
+}
+
+func NewBetRuleUseCase(ruleRepo repository.BetRuleRepository) BetRuleUseCase {
+	return &betRuleUseCase{ruleRepo}
+}
+
+func (uc *betRuleUseCase) ListRules(ctx context.Context) ([]domain.BetRule, error) {
+	return uc.ruleRepo.List(ctx)
+}
+
+func (uc *betRuleUseCase) SaveRule(ctx context.Context, rule *domain.BetRule) error {
+	rule.Currency = strings.ToUpper(rule.Currency)
+	if rule.MinStake < 0 || rule.MaxStake < 0 || rule.MaxWinPerRound < 0 {
+		return &ValidationError{Msg: "limits must not be negative"}
+	}
+	if rule.MaxStake > 0 && rule.MinStake > rule.MaxStake {
+		return &ValidationError{Msg: "min_stake must not exceed max_stake"}
+	}
+	now := time.Now()
+	rule.CreatedAt, rule.UpdatedAt = now, now
+	if err := uc.ruleRepo.Save(ctx, rule); err != nil {
+		return err
+	}
+	log.Printf("SaveRule: game=%q provider=%q currency=%q stake %.2f-%.2f max win %.2f",
+		rule.GameID, rule.ProviderID, rule.Currency, rule.MinStake, rule.MaxStake, rule.MaxWinPerRound)
+	return nil
+}
+
+func (uc *betRuleUseCase) DeleteRule(ctx context.Context, id uint) error {
+	return uc.ruleRepo.Delete(ctx, id)
+}
+
+func (uc *betRuleUseCase) Resolve(ctx context.Context, gameID, providerID, currency string) (*domain.BetRule, error) {
+	rules, err := uc.ruleRepo.FindCandidates(ctx, gameID, providerID, currency)
+	if err != nil {
+		return nil, err
+	}
+	var best *domain.BetRule
+	for i := range rules {
+		if rules[i].Matches(gameID, providerID, currency) && (best == nil || rules[i].Specificity() > best.Specificity()) {
+			best = &rules[i]
+		}
+	}
+	return best, nil
+}
+
+// checkStake applies the rule's stake bounds. A nil rule allows any positive
+// stake.
+func checkStake(rule *domain.BetRule, amount float64) error {
+	if amount <= 0 {
+		return ErrInvalidStake
+	}
+	if rule == nil {
+		return nil
+	}
+	if rule.MinStake > 0 && amount < rule.MinStake {
+		return &StakeRuleError{Code: StakeTooLowCode, Limit: rule.MinStake}
+	}
+	if rule.MaxStake > 0 && amount > rule.MaxStake {
+		return &StakeRuleError{Code: StakeTooHighCode, Limit: rule.MaxStake}
+	}
+	return nil
+}
+
+// exceedsMaxWin reports whether crediting amount would take the round's
+// wins over the rule's limit.
+func exceedsMaxWin(rule *domain.BetRule, roundWins, amount float64) bool {
+	return rule != nil && rule.MaxWinPerRound > 0 && amount > 0 && roundWins+amount > rule.MaxWinPerRound
+}
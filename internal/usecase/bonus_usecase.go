@@ -0,0 +1,406 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrBonusNotActive    = errors.New("bonus is not active")
+)
+
+// BonusProgress is a player bonus with the stakes counted towards its
+// wagering requirement.
+type BonusProgress struct {
+	domain.PlayerBonus
+	Wagered float64
+}
+
+// StakeFunding splits a stake between the real and bonus balances. Bonus is
+// nil when the player has no active bonus.
+type StakeFunding struct {
+	Bonus     *domain.PlayerBonus
+	FromReal  float64
+	FromBonus float64
+}
+
+type BonusUseCase interface {
+	ListCampaigns(ctx context.Context) ([]domain.BonusCampaign, error)
+	CreateCampaign(ctx context.Context, campaign *domain.BonusCampaign) error
+	Grant(ctx context.Context, userID, campaignID uint) (*domain.PlayerBonus, error)
+	ListBonuses(ctx context.Context, userID uint) ([]BonusProgress, error)
+	// Forfeit cancels an active bonus and removes its remaining funds. A
+	// non-zero userID restricts it to that player's bonuses.
+	Forfeit(ctx context.Context, bonusID, userID uint) (*domain.PlayerBonus, error)
+	// ExpireDue forfeits every bonus past its expiry and returns how many.
+	ExpireDue(ctx context.Context) (int, error)
+	// ActiveBonus returns the player's active bonus, or nil. A bonus found
+	// past its expiry is forfeited first.
+	ActiveBonus(ctx context.Context, user *domain.User) (*domain.PlayerBonus, error)
+	// FundStake splits a stake between the real and bonus balances in the
+	// configured spend order.
+	FundStake(ctx context.Context, user *domain.User, amount float64) (*StakeFunding, error)
+	// SettleWagering moves the bonus balance into the real balance once the
+	// active bonus's wagering requirement is met.
+	SettleWagering(ctx context.Context, userID uint) error
+}
+
+type bonusUseCase struct {
+	bonusRepo       repository.BonusRepository
+	userRepo        repository.UserRepository
+	transactionRepo repository.TransactionRepository
+	db              *gorm.DB
+	walletClient    *infrastructure.WalletClient
+	rates           infrastructure.RateProvider
+	cfg             infrastructure.BonusConfig
+}
+
+func NewBonusUseCase(bonusRepo repository.BonusRepository, userRepo repository.UserRepository, transactionRepo repository.TransactionRepository, db *gorm.DB, walletClient *infrastructure.WalletClient, rates infrastructure.RateProvider, cfg infrastructure.BonusConfig) BonusUseCase {
+	return &bonusUseCase{bonusRepo, userRepo, transactionRepo, db, walletClient, rates, cfg}
+}
+
+func (uc *bonusUseCase) ListCampaigns(ctx context.Context) ([]domain.BonusCampaign, error) {
+	return uc.bonusRepo.ListCampaigns(ctx)
+}
+
+func (uc *bonusUseCase) CreateCampaign(ctx context.Context, campaign *domain.BonusCampaign) error {
+	if campaign.Name == "" {
+		return &ValidationError{Msg: "campaign name is required"}
+	}
+	if campaign.Amount <= 0 {
+		return &ValidationError{Msg: "bonus amount must be greater than zero"}
+	}
+	if campaign.WageringMultiplier < 0 {
+		return &ValidationError{Msg: "wagering multiplier must not be negative"}
+	}
+	if campaign.ValidDays <= 0 {
+		return &ValidationError{Msg: "valid_days must be greater than zero"}
+	}
+	now := time.Now()
+	campaign.Active = true
+	campaign.CreatedAt, campaign.UpdatedAt = now, now
+	if err := uc.bonusRepo.CreateCampaign(ctx, campaign); err != nil {
+		return err
+	}
+	log.Printf("CreateCampaign: %q bonus %.2f, wagering x%.1f, valid %d days", campaign.Name, campaign.Amount, campaign.WageringMultiplier, campaign.ValidDays)
+	return nil
+}
+
+func (uc *bonusUseCase) Grant(ctx context.Context, userID, campaignID uint) (bonus *domain.PlayerBonus, err error) {
+	ctx, span := tracer.Start(ctx, "BonusUseCase.Grant", trace.WithAttributes(
+		attribute.Int("user.id", int(userID)),
+		attribute.Int("campaign.id", int(campaignID)),
+	))
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	campaign, err := uc.bonusRepo.FindCampaign(ctx, campaignID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &ValidationError{Msg: "campaign not found"}
+		}
+		return nil, err
+	}
+	if !campaign.Active {
+		return nil, &ValidationError{Msg: "campaign is not active"}
+	}
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	active, err := uc.ActiveBonus(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		return nil, &ValidationError{Msg: "player already has an active bonus"}
+	}
+
+	now := time.Now()
+	bonus = &domain.PlayerBonus{
+		UserID:           userID,
+		CampaignID:       campaign.ID,
+		Amount:           campaign.Amount,
+		WageringRequired: campaign.Amount * campaign.WageringMultiplier,
+		Status:           domain.BonusStatusActive,
+		ExpiresAt:        now.AddDate(0, 0, campaign.ValidDays),
+		CreatedAt:        now,
+	}
+	err = lockedDB(ctx, uc.db, userID).WithContext(ctx).Transaction(func(txDb *gorm.DB) error {
+		users := repository.NewUserRepository(txDb)
+		if _, err := users.FindForUpdate(ctx, userID); err != nil {
+			return err
+		}
+		if err := repository.NewBonusRepository(txDb).Create(ctx, bonus); err != nil {
+			return err
+		}
+		return users.UpdateBonusBalance(ctx, user, bonus.Amount)
+	})
+	if err != nil {
+		log.Printf("Grant: failed to grant campaign %d to user %d: %v", campaignID, userID, err)
+		return nil, err
+	}
+	bonus.Campaign = campaign
+	log.Printf("Grant: user %d received bonus %d of %.2f, wagering %.2f by %s", userID, bonus.ID, bonus.Amount, bonus.WageringRequired, bonus.ExpiresAt.Format(time.RFC3339))
+	return bonus, nil
+}
+
+func (uc *bonusUseCase) ListBonuses(ctx context.Context, userID uint) (progress []BonusProgress, err error) {
+	ctx, span := tracer.Start(ctx, "BonusUseCase.ListBonuses")
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Expire a lapsed bonus before showing it.
+	if _, err := uc.ActiveBonus(ctx, user); err != nil {
+		return nil, err
+	}
+	bonuses, err := uc.bonusRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	progress = make([]BonusProgress, 0, len(bonuses))
+	for _, b := range bonuses {
+		p := BonusProgress{PlayerBonus: b}
+		if b.Status == domain.BonusStatusActive {
+			if p.Wagered, _, err = sumActivity(ctx, uc.transactionRepo, uc.rates, userID, b.CreatedAt, user.Currency); err != nil {
+				return nil, err
+			}
+		} else if b.Status == domain.BonusStatusCompleted {
+			p.Wagered = b.WageringRequired
+		}
+		progress = append(progress, p)
+	}
+	return progress, nil
+}
+
+func (uc *bonusUseCase) Forfeit(ctx context.Context, bonusID, userID uint) (bonus *domain.PlayerBonus, err error) {
+	ctx, span := tracer.Start(ctx, "BonusUseCase.Forfeit", trace.WithAttributes(
+		attribute.Int("bonus.id", int(bonusID)),
+	))
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	bonus, err = uc.bonusRepo.FindByID(ctx, bonusID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != 0 && bonus.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if bonus.Status != domain.BonusStatusActive {
+		return nil, ErrBonusNotActive
+	}
+	user, err := uc.userRepo.FindByID(ctx, bonus.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.forfeit(ctx, user, bonus, domain.BonusStatusForfeited); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBonusNotActive
+		}
+		return nil, err
+	}
+	return bonus, nil
+}
+
+func (uc *bonusUseCase) ExpireDue(ctx context.Context) (expired int, err error) {
+	ctx, span := tracer.Start(ctx, "BonusUseCase.ExpireDue")
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	bonuses, err := uc.bonusRepo.ListExpired(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	for i := range bonuses {
+		user, err := uc.userRepo.FindByID(ctx, bonuses[i].UserID)
+		if err != nil {
+			return expired, err
+		}
+		if err := uc.forfeit(ctx, user, &bonuses[i], domain.BonusStatusExpired); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+func (uc *bonusUseCase) ActiveBonus(ctx context.Context, user *domain.User) (*domain.PlayerBonus, error) {
+	bonus, err := uc.bonusRepo.FindActive(ctx, user.ID)
+	if err != nil || bonus == nil {
+		return nil, err
+	}
+	if time.Now().Before(bonus.ExpiresAt) {
+		return bonus, nil
+	}
+	if err := uc.forfeit(ctx, user, bonus, domain.BonusStatusExpired); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (uc *bonusUseCase) FundStake(ctx context.Context, user *domain.User, amount float64) (*StakeFunding, error) {
+	bonus, err := uc.ActiveBonus(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if bonus == nil {
+		return &StakeFunding{FromReal: amount}, nil
+	}
+	funding := &StakeFunding{Bonus: bonus}
+	if uc.cfg.SpendOrder == infrastructure.BonusSpendBonusFirst {
+		funding.FromBonus = math.Min(amount, user.BonusBalance)
+		funding.FromReal = amount - funding.FromBonus
+	} else {
+		funding.FromReal = math.Min(amount, math.Max(user.Balance, 0))
+		funding.FromBonus = amount - funding.FromReal
+	}
+	if funding.FromReal > user.Balance || funding.FromBonus > user.BonusBalance {
+		return nil, ErrInsufficientFunds
+	}
+	return funding, nil
+}
+
+func (uc *bonusUseCase) SettleWagering(ctx context.Context, userID uint) (err error) {
+	ctx, span := tracer.Start(ctx, "BonusUseCase.SettleWagering", trace.WithAttributes(
+		attribute.Int("user.id", int(userID)),
+	))
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	// The player's lock is held from reading the bonus balance until it is
+	// released, so no stake can spend it in between. The commit outlives the
+	// caller for the reason given on walletUseCase.serialize.
+	return lockedDB(ctx, uc.db, userID).WithContext(context.WithoutCancel(ctx)).Transaction(func(txDb *gorm.DB) error {
+		user, err := repository.NewUserRepository(txDb).FindForUpdate(ctx, userID)
+		if err != nil {
+			return err
+		}
+		l := &userLock{userID: userID, db: txDb}
+		return uc.settleWagering(context.WithValue(ctx, userLockKey{}, l), user)
+	})
+}
+
+// settleWagering releases the bonus funds of user once the wagering is met,
+// while the player's lock is held.
+func (uc *bonusUseCase) settleWagering(ctx context.Context, user *domain.User) error {
+	userID := user.ID
+	bonus, err := uc.ActiveBonus(ctx, user)
+	if err != nil || bonus == nil {
+		return err
+	}
+	wagered, _, err := sumActivity(ctx, uc.transactionRepo, uc.rates, userID, bonus.CreatedAt, user.Currency)
+	if err != nil {
+		return err
+	}
+	if wagered < bonus.WageringRequired {
+		return nil
+	}
+
+	release := user.BonusBalance
+	reference := "bonus-" + strconv.FormatUint(uint64(bonus.ID), 10)
+	if release > 0 {
+		walletID, err := strconv.ParseInt(user.WalletID, 10, 64)
+		if err != nil {
+			log.Printf("SettleWagering: invalid wallet ID: %v", err)
+			return err
+		}
+		depositReq := infrastructure.WalletDepositRequest{
+			Currency: user.Currency,
+			Transactions: []struct {
+				Amount    float64 `json:"amount"`
+				BetID     int     `json:"betId"`
+				Reference string  `json:"reference"`
+			}{
+				{
+					Amount:    release,
+					BetID:     0,
+					Reference: reference,
+				},
+			},
+			UserID: walletID,
+		}
+		if _, err := uc.walletClient.Deposit(ctx, depositReq); err != nil {
+			log.Printf("SettleWagering: external wallet error: %v", err)
+			return err
+		}
+	}
+
+	now := time.Now()
+	bonus.Status = domain.BonusStatusCompleted
+	bonus.ClosedAt = &now
+	bonus.ReleasedAmount = release
+	tx := &domain.Transaction{
+		UserID:           userID,
+		Type:             "BONUS_RELEASE",
+		Amount:           release,
+		PlayerBonusID:    &bonus.ID,
+		OldBalance:       user.Balance,
+		NewBalance:       user.Balance + release,
+		Status:           "COMPLETED",
+		ProviderTxID:     reference,
+		PlatformResponse: platformResponse(ctx, domain.PlatformRecord{}),
+		CreatedAt:        now,
+	}
+	err = lockFrom(ctx).db.Transaction(func(txDb *gorm.DB) error {
+		if err := repository.NewBonusRepository(txDb).Close(ctx, bonus); err != nil {
+			return err
+		}
+		if err := repository.NewTransactionRepository(txDb).Create(ctx, tx); err != nil {
+			return err
+		}
+		users := repository.NewUserRepository(txDb)
+		if err := users.AddBalance(ctx, userID, release); err != nil {
+			return err
+		}
+		return users.AddBonusBalance(ctx, userID, -release)
+	})
+	if err != nil {
+		log.Printf("SettleWagering: db transaction error: %v", err)
+		return err
+	}
+	log.Printf("SettleWagering: bonus %d completed for user %d, %.2f released", bonus.ID, userID, release)
+	return nil
+}
+
+// forfeit closes an active bonus with the given status and removes the
+// remaining bonus funds. It joins the player's lock when ctx holds it and
+// takes it otherwise.
+func (uc *bonusUseCase) forfeit(ctx context.Context, user *domain.User, bonus *domain.PlayerBonus, status string) error {
+	now := time.Now()
+	bonus.Status = status
+	bonus.ClosedAt = &now
+	err := lockedDB(ctx, uc.db, user.ID).WithContext(ctx).Transaction(func(txDb *gorm.DB) error {
+		users := repository.NewUserRepository(txDb)
+		locked, err := users.FindForUpdate(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		bonus.ForfeitedAmount = locked.BonusBalance
+		if err := repository.NewBonusRepository(txDb).Close(ctx, bonus); err != nil {
+			return err
+		}
+		return users.UpdateBonusBalance(ctx, user, 0)
+	})
+	if err != nil {
+		return fmt.Errorf("close bonus %d: %w", bonus.ID, err)
+	}
+	user.BonusBalance = 0
+	log.Printf("Bonus %d of user %d %s, %.2f forfeited", bonus.ID, user.ID, status, bonus.ForfeitedAmount)
+	return nil
+}
@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"log"
+	"math"
+	"strings"
+	"time"
+)
+
+// CurrencyUnsupportedCode tells the provider that a bet's currency cannot be
+// booked for the player.
+const CurrencyUnsupportedCode = "CURRENCY_NOT_SUPPORTED"
+
+var (
+	ErrCurrencyUnsupported = errors.New("currency is not supported")
+	ErrBalanceExists       = errors.New("a balance in this currency already exists")
+)
+
+// Booking is a bet amount as it lands on one of the player's balances.
+type Booking struct {
+	Currency string
+	// Primary is true for the balance on User.Balance.
+	Primary bool
+	Balance float64
+	Amount  float64
+	FX      domain.FXSnapshot
+}
+
+// CurrencyReport totals activity per balance currency between From and To,
+// in the original currency and in the base currency.
+type CurrencyReport struct {
+	From         time.Time
+	To           time.Time
+	BaseCurrency string
+	Totals       []repository.CurrencyTotal
+}
+
+type CurrencyUseCase interface {
+	ListBalances(ctx context.Context, userID uint) ([]domain.PlayerBalance, error)
+	// OpenBalance adds an empty balance in a currency the rate provider
+	// can quote.
+	OpenBalance(ctx context.Context, userID uint, currency string) (*domain.PlayerBalance, error)
+	// Book converts amount in currency to the balance it is booked against:
+	// balanceCurrency when set, otherwise the player's balance in currency
+	// if they hold one, otherwise the primary balance.
+	Book(ctx context.Context, user *domain.User, currency string, amount float64, balanceCurrency string) (*Booking, error)
+	Convert(ctx context.Context, amount float64, from, to string) (float64, error)
+	Report(ctx context.Context, from, to time.Time) (*CurrencyReport, error)
+}
+
+type currencyUseCase struct {
+	balanceRepo     repository.BalanceRepository
+	userRepo        repository.UserRepository
+	transactionRepo repository.TransactionRepository
+	rates           infrastructure.RateProvider
+}
+
+func NewCurrencyUseCase(balanceRepo repository.BalanceRepository, userRepo repository.UserRepository, transactionRepo repository.TransactionRepository, rates infrastructure.RateProvider) CurrencyUseCase {
+	return &currencyUseCase{balanceRepo, userRepo, transactionRepo, rates}
+}
+
+func (uc *currencyUseCase) ListBalances(ctx context.Context, userID uint) ([]domain.PlayerBalance, error) {
+	return uc.balanceRepo.ListByUser(ctx, userID)
+}
+
+func (uc *currencyUseCase) OpenBalance(ctx context.Context, userID uint, currency string) (*domain.PlayerBalance, error) {
+	currency = strings.ToUpper(currency)
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if currency == user.Currency {
+		return nil, ErrBalanceExists
+	}
+	if _, err := uc.rates.Rate(ctx, currency, user.Currency); err != nil {
+		if errors.Is(err, infrastructure.ErrRateUnavailable) {
+			return nil, ErrCurrencyUnsupported
+		}
+		return nil, err
+	}
+	existing, err := uc.balanceRepo.Find(ctx, user.ID, currency)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrBalanceExists
+	}
+	balance := &domain.PlayerBalance{UserID: user.ID, Currency: currency}
+	if err := uc.balanceRepo.Create(ctx, balance); err != nil {
+		return nil, err
+	}
+	log.Printf("OpenBalance: user %d opened a %s balance", user.ID, currency)
+	return balance, nil
+}
+
+func (uc *currencyUseCase) Book(ctx context.Context, user *domain.User, currency string, amount float64, balanceCurrency string) (*Booking, error) {
+	currency = strings.ToUpper(currency)
+	if currency == "" {
+		currency = user.Currency
+	}
+	b := &Booking{Currency: user.Currency, Primary: true, Balance: user.Balance}
+	target := balanceCurrency
+	if target == "" {
+		target = currency
+	}
+	if target != user.Currency {
+		held, err := uc.balanceRepo.Find(ctx, user.ID, target)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case held != nil:
+			b = &Booking{Currency: held.Currency, Balance: held.Balance}
+		case balanceCurrency != "":
+			return nil, ErrCurrencyUnsupported
+		}
+	}
+
+	rate, err := uc.rates.Rate(ctx, currency, b.Currency)
+	if err != nil {
+		if errors.Is(err, infrastructure.ErrRateUnavailable) {
+			log.Printf("Book: cannot convert %s to %s for user %d: %v", currency, b.Currency, user.ID, err)
+			return nil, ErrCurrencyUnsupported
+		}
+		return nil, err
+	}
+	b.Amount = amount
+	if currency != b.Currency {
+		b.Amount = roundAmount(amount * rate.Rate)
+	}
+	b.FX = domain.FXSnapshot{
+		GameCurrency: currency,
+		GameAmount:   amount,
+		GameRate:     rate.Rate,
+		BaseCurrency: uc.rates.Base(),
+		Source:       rate.Source,
+	}
+	if !rate.AsOf.IsZero() {
+		b.FX.AsOf = &rate.AsOf
+	}
+	// A missing base rate must not stop play; the transaction is reported
+	// as unconverted instead.
+	if base, err := uc.rates.Rate(ctx, b.Currency, uc.rates.Base()); err == nil {
+		b.FX.BaseRate = base.Rate
+	} else {
+		log.Printf("Book: no base rate for %s: %v", b.Currency, err)
+	}
+	return b, nil
+}
+
+func (uc *currencyUseCase) Convert(ctx context.Context, amount float64, from, to string) (float64, error) {
+	rate, err := uc.rates.Rate(ctx, from, to)
+	if err != nil {
+		if errors.Is(err, infrastructure.ErrRateUnavailable) {
+			return 0, ErrCurrencyUnsupported
+		}
+		return 0, err
+	}
+	return roundAmount(amount * rate.Rate), nil
+}
+
+func (uc *currencyUseCase) Report(ctx context.Context, from, to time.Time) (*CurrencyReport, error) {
+	if !from.Before(to) {
+		return nil, &ValidationError{Msg: "from must be before to"}
+	}
+	totals, err := uc.transactionRepo.SumByCurrency(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &CurrencyReport{From: from, To: to, BaseCurrency: uc.rates.Base(), Totals: totals}, nil
+}
+
+// sumActivity totals the user's stakes and winnings since the given time in
+// currency, converting what was booked in other balances at the current
+// rate. Limits and wagering requirements are set in the primary currency.
+func sumActivity(ctx context.Context, transactionRepo repository.TransactionRepository, rates infrastructure.RateProvider, userID uint, since time.Time, currency string) (wagered, won float64, err error) {
+	totals, err := transactionRepo.SumActivity(ctx, userID, since)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, t := range totals {
+		rate := 1.0
+		// Transactions from before multi-currency balances have none set.
+		if t.Currency != "" && t.Currency != currency {
+			r, err := rates.Rate(ctx, t.Currency, currency)
+			if err != nil {
+				log.Printf("sumActivity: cannot convert %s to %s for user %d: %v", t.Currency, currency, userID, err)
+				if errors.Is(err, infrastructure.ErrRateUnavailable) {
+					return 0, 0, ErrCurrencyUnsupported
+				}
+				return 0, 0, err
+			}
+			rate = r.Rate
+		}
+		wagered += t.Wagered * rate
+		won += t.Won * rate
+	}
+	return roundAmount(wagered), roundAmount(won), nil
+}
+
+// roundAmount rounds a converted amount to cents.
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"log"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// NoFreeRoundsCode is returned to providers when a free-round stake has no
+// matching entitlement.
+const NoFreeRoundsCode = "NO_FREE_ROUNDS"
+
+var (
+	ErrNoFreeRounds      = errors.New("no free rounds available for this game and bet value")
+	ErrNotFreeRound      = errors.New("stake was not a free round")
+	ErrFreeRoundsRevoked = errors.New("free rounds are not active")
+)
+
+// FreeRoundInput grants Count rounds of BetValue on GameID for ValidDays.
+// WinTo is REAL or BONUS; BONUS requires an active bonus.
+type FreeRoundInput struct {
+	UserID    uint
+	GameID    string
+	Count     int
+	BetValue  float64
+	ValidDays int
+	WinTo     string
+	CreatedBy string
+}
+
+type FreeRoundUseCase interface {
+	Grant(ctx context.Context, in FreeRoundInput) (*domain.FreeRoundGrant, error)
+	// Revoke stops further rounds. Rounds already played still settle.
+	Revoke(ctx context.Context, grantID uint, revokedBy string) (*domain.FreeRoundGrant, error)
+	ListGrants(ctx context.Context, userID uint) ([]domain.FreeRoundGrant, error)
+	FindGrant(ctx context.Context, grantID uint) (*domain.FreeRoundGrant, error)
+	// FindUsable returns the grant a free-round stake is played from, or
+	// ErrNoFreeRounds.
+	FindUsable(ctx context.Context, userID uint, gameID string, betValue float64) (*domain.FreeRoundGrant, error)
+}
+
+type freeRoundUseCase struct {
+	freeRoundRepo repository.FreeRoundRepository
+	userRepo      repository.UserRepository
+	bonuses       BonusUseCase
+}
+
+func NewFreeRoundUseCase(freeRoundRepo repository.FreeRoundRepository, userRepo repository.UserRepository, bonuses BonusUseCase) FreeRoundUseCase {
+	return &freeRoundUseCase{freeRoundRepo, userRepo, bonuses}
+}
+
+func (uc *freeRoundUseCase) Grant(ctx context.Context, in FreeRoundInput) (grant *domain.FreeRoundGrant, err error) {
+	ctx, span := tracer.Start(ctx, "FreeRoundUseCase.Grant", trace.WithAttributes(
+		attribute.Int("user.id", int(in.UserID)),
+		attribute.String("game.id", in.GameID),
+	))
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	in.WinTo = strings.ToUpper(in.WinTo)
+	switch {
+	case in.GameID == "":
+		return nil, &ValidationError{Msg: "game_id is required"}
+	case in.Count <= 0:
+		return nil, &ValidationError{Msg: "count must be greater than zero"}
+	case in.BetValue <= 0:
+		return nil, &ValidationError{Msg: "bet_value must be greater than zero"}
+	case in.ValidDays <= 0:
+		return nil, &ValidationError{Msg: "valid_days must be greater than zero"}
+	case in.WinTo != domain.FreeRoundsWinToReal && in.WinTo != domain.FreeRoundsWinToBonus:
+		return nil, &ValidationError{Msg: "win_to must be real or bonus"}
+	}
+	user, err := uc.userRepo.FindByID(ctx, in.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	grant = &domain.FreeRoundGrant{
+		UserID:    in.UserID,
+		GameID:    in.GameID,
+		Count:     in.Count,
+		Remaining: in.Count,
+		BetValue:  in.BetValue,
+		WinTo:     in.WinTo,
+		Status:    domain.FreeRoundsStatusActive,
+		ExpiresAt: now.AddDate(0, 0, in.ValidDays),
+		CreatedBy: in.CreatedBy,
+		CreatedAt: now,
+	}
+	if in.WinTo == domain.FreeRoundsWinToBonus {
+		bonus, err := uc.bonuses.ActiveBonus(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		if bonus == nil {
+			return nil, &ValidationError{Msg: "player has no active bonus to credit wins to"}
+		}
+		grant.PlayerBonusID = &bonus.ID
+	}
+	if err := uc.freeRoundRepo.Create(ctx, grant); err != nil {
+		log.Printf("Grant: failed to grant free rounds to user %d: %v", in.UserID, err)
+		return nil, err
+	}
+	log.Printf("Grant: user %d received %d free rounds of %.2f on %q, wins to %s, by %s", in.UserID, in.Count, in.BetValue, in.GameID, in.WinTo, in.CreatedBy)
+	return grant, nil
+}
+
+func (uc *freeRoundUseCase) Revoke(ctx context.Context, grantID uint, revokedBy string) (grant *domain.FreeRoundGrant, err error) {
+	ctx, span := tracer.Start(ctx, "FreeRoundUseCase.Revoke", trace.WithAttributes(
+		attribute.Int("grant.id", int(grantID)),
+	))
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	grant, err = uc.freeRoundRepo.FindByID(ctx, grantID)
+	if err != nil {
+		return nil, err
+	}
+	if grant.Status != domain.FreeRoundsStatusActive {
+		return nil, ErrFreeRoundsRevoked
+	}
+	now := time.Now()
+	grant.Status = domain.FreeRoundsStatusRevoked
+	grant.RevokedBy, grant.RevokedAt = revokedBy, &now
+	if err := uc.freeRoundRepo.Revoke(ctx, grant); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFreeRoundsRevoked
+		}
+		return nil, err
+	}
+	log.Printf("Revoke: free rounds %d of user %d revoked by %s, %d unplayed", grantID, grant.UserID, revokedBy, grant.Remaining)
+	return grant, nil
+}
+
+func (uc *freeRoundUseCase) ListGrants(ctx context.Context, userID uint) ([]domain.FreeRoundGrant, error) {
+	return uc.freeRoundRepo.ListByUser(ctx, userID)
+}
+
+func (uc *freeRoundUseCase) FindGrant(ctx context.Context, grantID uint) (*domain.FreeRoundGrant, error) {
+	return uc.freeRoundRepo.FindByID(ctx, grantID)
+}
+
+func (uc *freeRoundUseCase) FindUsable(ctx context.Context, userID uint, gameID string, betValue float64) (*domain.FreeRoundGrant, error) {
+	grant, err := uc.freeRoundRepo.FindUsable(ctx, userID, gameID, betValue, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if grant == nil {
+		return nil, ErrNoFreeRounds
+	}
+	return grant, nil
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"log"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// JackpotNotQualifiedCode is returned to providers for a jackpot win that
+// does not settle a stake eligible for the pool.
+const JackpotNotQualifiedCode = "JACKPOT_NOT_QUALIFIED"
+
+var (
+	ErrJackpotNotFound     = errors.New("jackpot not found")
+	ErrJackpotNotQualified = errors.New("jackpot win must settle a stake on one of the pool's games in its currency")
+)
+
+// JackpotContribution is the amount a stake adds to one pool.
+type JackpotContribution struct {
+	PoolID uint
+	Amount float64
+}
+
+type JackpotUseCase interface {
+	ListPools(ctx context.Context, currency, gameGroup string) ([]domain.JackpotPool, error)
+	CreatePool(ctx context.Context, pool *domain.JackpotPool) error
+	AssignGame(ctx context.Context, gameID, gameGroup string) error
+	// Contributions returns what a stake on the game adds to each of its
+	// pools. Games outside any group contribute nothing.
+	Contributions(ctx context.Context, gameID, currency string, stake float64) ([]JackpotContribution, error)
+	// Claim resets the pool to its seed and returns the amount won. The
+	// winning stake must be on a game of the pool's group and in its
+	// currency, or ErrJackpotNotQualified is returned.
+	Claim(ctx context.Context, poolID uint, gameID, currency string) (float64, error)
+	// Unclaim restores a claimed amount whose payout failed.
+	Unclaim(ctx context.Context, poolID uint, amount float64) error
+}
+
+type jackpotUseCase struct {
+	jackpotRepo repository.JackpotRepository
+}
+
+func NewJackpotUseCase(jackpotRepo repository.JackpotRepository) JackpotUseCase {
+	return &jackpotUseCase{jackpotRepo}
+}
+
+func (uc *jackpotUseCase) ListPools(ctx context.Context, currency, gameGroup string) ([]domain.JackpotPool, error) {
+	return uc.jackpotRepo.ListPools(ctx, strings.ToUpper(currency), gameGroup)
+}
+
+func (uc *jackpotUseCase) CreatePool(ctx context.Context, pool *domain.JackpotPool) error {
+	pool.Currency = strings.ToUpper(pool.Currency)
+	switch {
+	case pool.Name == "" || pool.GameGroup == "" || pool.Currency == "":
+		return &ValidationError{Msg: "name, game_group and currency are required"}
+	case pool.ContributionPct <= 0 || pool.ContributionPct > 100:
+		return &ValidationError{Msg: "contribution_pct must be greater than 0 and at most 100"}
+	case pool.SeedAmount < 0:
+		return &ValidationError{Msg: "seed_amount must not be negative"}
+	}
+	now := time.Now()
+	pool.Amount = pool.SeedAmount
+	pool.CreatedAt, pool.UpdatedAt = now, now
+	if err := uc.jackpotRepo.CreatePool(ctx, pool); err != nil {
+		return err
+	}
+	log.Printf("CreatePool: jackpot %q for group %q in %s, %.2f%% of stakes, seed %.2f", pool.Name, pool.GameGroup, pool.Currency, pool.ContributionPct, pool.SeedAmount)
+	return nil
+}
+
+func (uc *jackpotUseCase) AssignGame(ctx context.Context, gameID, gameGroup string) error {
+	if gameID == "" || gameGroup == "" {
+		return &ValidationError{Msg: "game_id and game_group are required"}
+	}
+	if err := uc.jackpotRepo.SaveGame(ctx, &domain.JackpotGame{GameID: gameID, GameGroup: gameGroup, UpdatedAt: time.Now()}); err != nil {
+		return err
+	}
+	log.Printf("AssignGame: game %q in jackpot group %q", gameID, gameGroup)
+	return nil
+}
+
+func (uc *jackpotUseCase) Contributions(ctx context.Context, gameID, currency string, stake float64) ([]JackpotContribution, error) {
+	if gameID == "" || stake <= 0 {
+		return nil, nil
+	}
+	pools, err := uc.jackpotRepo.FindPoolsForGame(ctx, gameID, currency)
+	if err != nil {
+		return nil, err
+	}
+	contributions := make([]JackpotContribution, 0, len(pools))
+	for _, p := range pools {
+		contributions = append(contributions, JackpotContribution{PoolID: p.ID, Amount: stake * p.ContributionPct / 100})
+	}
+	return contributions, nil
+}
+
+func (uc *jackpotUseCase) Claim(ctx context.Context, poolID uint, gameID, currency string) (won float64, err error) {
+	ctx, span := tracer.Start(ctx, "JackpotUseCase.Claim", trace.WithAttributes(
+		attribute.Int("jackpot.id", int(poolID)),
+	))
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	eligible, err := uc.jackpotRepo.FindPoolsForGame(ctx, gameID, currency)
+	if err != nil {
+		return 0, err
+	}
+	qualifies := false
+	for _, p := range eligible {
+		qualifies = qualifies || p.ID == poolID
+	}
+	if !qualifies {
+		log.Printf("Claim: game %q in %s does not qualify for jackpot %d", gameID, currency, poolID)
+		return 0, ErrJackpotNotQualified
+	}
+	pool, err := uc.jackpotRepo.Claim(ctx, poolID, time.Now())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrJackpotNotFound
+		}
+		return 0, err
+	}
+	log.Printf("Claim: jackpot %q won, %.2f paid, reset to %.2f", pool.Name, pool.Amount, pool.SeedAmount)
+	return pool.Amount, nil
+}
+
+func (uc *jackpotUseCase) Unclaim(ctx context.Context, poolID uint, amount float64) error {
+	if err := uc.jackpotRepo.Unclaim(ctx, poolID, amount); err != nil {
+		log.Printf("Unclaim: failed to restore %.2f to jackpot %d: %v", amount, poolID, err)
+		return err
+	}
+	return nil
+}
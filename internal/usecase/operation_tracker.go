@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrShuttingDown = errors.New("service is shutting down")
+
+// OperationTracker counts in-flight wallet operations so that shutdown can
+// wait for them to reach the database commit before the pool is closed.
+type OperationTracker struct {
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	closed bool
+}
+
+func NewOperationTracker() *OperationTracker {
+	return &OperationTracker{}
+}
+
+// Begin registers a new operation. It fails once Close has been called.
+func (t *OperationTracker) Begin() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return ErrShuttingDown
+	}
+	t.wg.Add(1)
+	return nil
+}
+
+func (t *OperationTracker) Done() {
+	t.wg.Done()
+}
+
+// Close stops new operations from starting.
+func (t *OperationTracker) Close() {
+	t.mu.Lock()
+	t.closed = true
+	t.mu.Unlock()
+}
+
+// Wait blocks until all in-flight operations finish or ctx is done.
+func (t *OperationTracker) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
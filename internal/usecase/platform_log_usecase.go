@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"time"
+)
+
+type PlatformLogUseCase interface {
+	// Start begins recording a provider call. Transactions recorded while
+	// serving it store the call so far; the returned exchange is nil when
+	// logging is disabled.
+	Start(ctx context.Context, method, url string, body []byte) (context.Context, *infrastructure.Exchange)
+	// Finish adds our response to the exchange and stores it on the user's
+	// transactions that recorded it.
+	Finish(ctx context.Context, userID uint, exchange *infrastructure.Exchange, status int, body []byte) error
+	// Purge removes stored exchanges older than the retention.
+	Purge(ctx context.Context) (int64, error)
+}
+
+type platformLogUseCase struct {
+	transactionRepo repository.TransactionRepository
+	logger          *infrastructure.ExchangeLogger
+	retention       time.Duration
+}
+
+func NewPlatformLogUseCase(transactionRepo repository.TransactionRepository, cfg infrastructure.PlatformLogConfig) PlatformLogUseCase {
+	return &platformLogUseCase{transactionRepo: transactionRepo, logger: infrastructure.NewExchangeLogger(cfg), retention: cfg.Retention}
+}
+
+func (uc *platformLogUseCase) Start(ctx context.Context, method, url string, body []byte) (context.Context, *infrastructure.Exchange) {
+	return uc.logger.Start(ctx, method, url, body)
+}
+
+func (uc *platformLogUseCase) Finish(ctx context.Context, userID uint, exchange *infrastructure.Exchange, status int, body []byte) error {
+	record, ok := exchange.Finish(status, body)
+	if !ok {
+		return nil
+	}
+	return uc.transactionRepo.SaveExchange(ctx, userID, record)
+}
+
+func (uc *platformLogUseCase) Purge(ctx context.Context) (int64, error) {
+	if uc.retention == 0 {
+		return 0, nil
+	}
+	return uc.transactionRepo.PurgeExchanges(ctx, time.Now().Add(-uc.retention))
+}
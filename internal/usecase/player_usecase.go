@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"gameintegrationapi/internal/domain"
 	"gameintegrationapi/internal/infrastructure"
@@ -10,20 +11,24 @@ import (
 )
 
 type PlayerUseCase interface {
-	GetPlayerInfo(userID uint) (*domain.User, error)
+	GetPlayerInfo(ctx context.Context, userID uint) (*domain.User, error)
 }
 
 type playerUseCase struct {
 	userRepo     repository.UserRepository
+	balanceRepo  repository.BalanceRepository
 	walletClient *infrastructure.WalletClient
 }
 
-func NewPlayerUseCase(userRepo repository.UserRepository, walletClient *infrastructure.WalletClient) PlayerUseCase {
-	return &playerUseCase{userRepo, walletClient}
+func NewPlayerUseCase(userRepo repository.UserRepository, balanceRepo repository.BalanceRepository, walletClient *infrastructure.WalletClient) PlayerUseCase {
+	return &playerUseCase{userRepo, balanceRepo, walletClient}
 }
 
-func (uc *playerUseCase) GetPlayerInfo(userID uint) (*domain.User, error) {
-	user, err := uc.userRepo.FindByID(userID)
+func (uc *playerUseCase) GetPlayerInfo(ctx context.Context, userID uint) (user *domain.User, err error) {
+	ctx, span := tracer.Start(ctx, "PlayerUseCase.GetPlayerInfo")
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	user, err = uc.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		log.Printf("GetPlayerInfo: failed to find user: %v", err)
 		return nil, err
@@ -33,16 +38,30 @@ func (uc *playerUseCase) GetPlayerInfo(userID uint) (*domain.User, error) {
 		log.Printf("GetPlayerInfo: invalid wallet ID: %v", err)
 		return nil, err
 	}
-	profile, err := uc.walletClient.GetBalance(walletID)
+	profile, err := uc.walletClient.GetBalance(ctx, walletID)
 	if err != nil {
 		if errors.Is(err, infrastructure.ErrWalletUserNotFound) {
 			return nil, infrastructure.ErrWalletUserNotFound
 		}
+		if errors.Is(err, infrastructure.ErrCircuitOpen) {
+			return nil, ErrWalletServiceUnavailable
+		}
 		log.Printf("GetPlayerInfo: external wallet error: %v", err)
 		return nil, err
 	}
-	user.Balance = profile.Balance
-	user.Currency = profile.Currency
+	if user.Balances, err = uc.balanceRepo.ListByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	// The wallet reports one balance; it refreshes whichever of the
+	// player's balances is in its currency.
+	if profile.Currency == user.Currency {
+		user.Balance = profile.Balance
+	}
+	for i := range user.Balances {
+		if user.Balances[i].Currency == profile.Currency {
+			user.Balances[i].Balance = profile.Balance
+		}
+	}
 	log.Printf("GetPlayerInfo: success for user %d", userID)
 	return user, nil
 }
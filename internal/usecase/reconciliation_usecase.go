@@ -0,0 +1,276 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+var ErrReconciliationRunning = errors.New("a reconciliation is already running")
+
+// ReconciliationReport is a run with the mismatches it found.
+type ReconciliationReport struct {
+	Run        domain.ReconciliationRun
+	Mismatches []domain.ReconciliationMismatch
+}
+
+type ReconciliationUseCase interface {
+	// Run compares the UTC day containing day with the wallet, replacing
+	// any earlier run for it.
+	Run(ctx context.Context, day time.Time) (*domain.ReconciliationRun, error)
+	// Start runs the day in the background.
+	Start(ctx context.Context, day time.Time) error
+	ListRuns(ctx context.Context) ([]domain.ReconciliationRun, error)
+	// Report fails with gorm.ErrRecordNotFound if the day was not run.
+	Report(ctx context.Context, day time.Time) (*ReconciliationReport, error)
+}
+
+type reconciliationUseCase struct {
+	reconRepo       repository.ReconciliationRepository
+	userRepo        repository.UserRepository
+	transactionRepo repository.TransactionRepository
+	walletClient    *infrastructure.WalletClient
+}
+
+func NewReconciliationUseCase(reconRepo repository.ReconciliationRepository, userRepo repository.UserRepository, transactionRepo repository.TransactionRepository, walletClient *infrastructure.WalletClient) ReconciliationUseCase {
+	return &reconciliationUseCase{reconRepo: reconRepo, userRepo: userRepo, transactionRepo: transactionRepo, walletClient: walletClient}
+}
+
+func (uc *reconciliationUseCase) Run(ctx context.Context, day time.Time) (*domain.ReconciliationRun, error) {
+	day, unlock, err := uc.lock(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return uc.run(ctx, day)
+}
+
+func (uc *reconciliationUseCase) Start(ctx context.Context, day time.Time) error {
+	day, unlock, err := uc.lock(ctx, day)
+	if err != nil {
+		return err
+	}
+	go func() {
+		defer unlock()
+		if _, err := uc.run(context.WithoutCancel(ctx), day); err != nil {
+			log.Printf("Reconcile: run for %s failed: %v", day.Format(time.DateOnly), err)
+		}
+	}()
+	return nil
+}
+
+// lock claims the single reconciliation slot for day, shared by all
+// instances.
+func (uc *reconciliationUseCase) lock(ctx context.Context, day time.Time) (time.Time, func(), error) {
+	day = utcDay(day)
+	if day.After(time.Now()) {
+		return day, nil, &ValidationError{Msg: "day must not be in the future"}
+	}
+	unlock, ok, err := uc.reconRepo.LockRuns(ctx)
+	if err != nil {
+		return day, nil, err
+	}
+	if !ok {
+		return day, nil, ErrReconciliationRunning
+	}
+	return day, unlock, nil
+}
+
+func (uc *reconciliationUseCase) run(ctx context.Context, day time.Time) (run *domain.ReconciliationRun, err error) {
+	ctx, span := tracer.Start(ctx, "ReconciliationUseCase.Run", trace.WithAttributes(
+		attribute.String("reconciliation.day", day.Format(time.DateOnly)),
+	))
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	run, err = uc.reconRepo.StartRun(ctx, day, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.reconcile(ctx, run); err != nil {
+		run.Status, run.Error = domain.ReconciliationStatusFailed, err.Error()
+	} else {
+		run.Status = domain.ReconciliationStatusCompleted
+	}
+	finished := time.Now()
+	run.FinishedAt = &finished
+	if err := uc.reconRepo.FinishRun(context.WithoutCancel(ctx), run); err != nil {
+		return nil, err
+	}
+	log.Printf("Reconcile: %s %s, %d users checked, %d mismatches", day.Format(time.DateOnly), run.Status, run.Users, run.Mismatches)
+	return run, nil
+}
+
+// reconcile checks every user's day against their wallet ledger and adds
+// the totals to run. A user whose ledger cannot be read is recorded as
+// unchecked; the run goes on.
+func (uc *reconciliationUseCase) reconcile(ctx context.Context, run *domain.ReconciliationRun) error {
+	from, to := run.Day, run.Day.AddDate(0, 0, 1)
+	users, err := uc.userRepo.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+	txs, err := uc.transactionRepo.ListBetween(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	byUser := make(map[uint][]domain.Transaction)
+	for _, tx := range txs {
+		byUser[tx.UserID] = append(byUser[tx.UserID], tx)
+	}
+	for _, user := range users {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var mismatches []domain.ReconciliationMismatch
+		entries, err := uc.walletLedger(ctx, user, from, to)
+		if err != nil {
+			log.Printf("Reconcile: cannot read wallet ledger of user %d: %v", user.ID, err)
+			mismatches = []domain.ReconciliationMismatch{{UserID: user.ID, Kind: domain.MismatchUnchecked, Detail: err.Error()}}
+		} else {
+			var localNet, walletNet float64
+			mismatches, localNet, walletNet = reconcileUser(user, byUser[user.ID], entries)
+			run.Users++
+			run.LocalNet += localNet
+			run.WalletNet += walletNet
+		}
+		for i := range mismatches {
+			mismatches[i].RunID = run.ID
+			mismatches[i].CreatedAt = time.Now()
+		}
+		if err := uc.reconRepo.AddMismatches(ctx, mismatches); err != nil {
+			return err
+		}
+		run.Mismatches += len(mismatches)
+	}
+	return nil
+}
+
+func (uc *reconciliationUseCase) walletLedger(ctx context.Context, user domain.User, from, to time.Time) ([]infrastructure.WalletLedgerEntry, error) {
+	walletID, err := strconv.ParseInt(user.WalletID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wallet ID: %w", err)
+	}
+	return uc.walletClient.ListTransactions(ctx, walletID, from, to)
+}
+
+type ledgerSide struct {
+	amount   float64
+	currency string
+}
+
+// reconcileUser matches a user's local transactions with wallet movements
+// by reference and returns the disagreements and both sides' net totals.
+func reconcileUser(user domain.User, local []domain.Transaction, remote []infrastructure.WalletLedgerEntry) (mismatches []domain.ReconciliationMismatch, localNet, walletNet float64) {
+	localByRef := make(map[string]*ledgerSide)
+	for _, tx := range local {
+		amount := walletMovement(tx)
+		if amount == 0 {
+			continue
+		}
+		currency := tx.Currency
+		if currency == "" {
+			currency = user.Currency
+		}
+		if s, ok := localByRef[tx.ProviderTxID]; ok {
+			s.amount += amount
+		} else {
+			localByRef[tx.ProviderTxID] = &ledgerSide{amount, currency}
+		}
+		localNet += amount
+	}
+	remoteByRef := make(map[string]*ledgerSide)
+	for _, e := range remote {
+		amount := e.Amount
+		if e.Type == "withdraw" {
+			amount = -amount
+		}
+		if amount == 0 {
+			continue
+		}
+		if s, ok := remoteByRef[e.Reference]; ok {
+			s.amount += amount
+		} else {
+			remoteByRef[e.Reference] = &ledgerSide{amount, e.Currency}
+		}
+		walletNet += amount
+	}
+
+	refs := make([]string, 0, len(localByRef)+len(remoteByRef))
+	for ref := range localByRef {
+		refs = append(refs, ref)
+	}
+	for ref := range remoteByRef {
+		if _, ok := localByRef[ref]; !ok {
+			refs = append(refs, ref)
+		}
+	}
+	sort.Strings(refs)
+	for _, ref := range refs {
+		l, r := localByRef[ref], remoteByRef[ref]
+		m := domain.ReconciliationMismatch{UserID: user.ID, Reference: ref}
+		switch {
+		case r == nil:
+			m.Kind, m.Currency, m.LocalAmount = domain.MismatchMissingRemotely, l.currency, l.amount
+		case l == nil:
+			m.Kind, m.Currency, m.WalletAmount = domain.MismatchMissingLocally, r.currency, r.amount
+		case math.Abs(l.amount-r.amount) >= 0.005 || (r.currency != "" && r.currency != l.currency):
+			m.Kind, m.Currency, m.LocalAmount, m.WalletAmount = domain.MismatchAmountDiffers, l.currency, l.amount, r.amount
+			if r.currency != "" && r.currency != l.currency {
+				m.Detail = fmt.Sprintf("wallet booked %s", r.currency)
+			}
+		default:
+			continue
+		}
+		mismatches = append(mismatches, m)
+	}
+	return mismatches, localNet, walletNet
+}
+
+// walletMovement is the signed real-money amount a transaction moved on
+// the wallet. Held and rejected wins never reached it.
+func walletMovement(tx domain.Transaction) float64 {
+	if tx.Status == domain.TransactionStatusHeld || tx.Status == domain.TransactionStatusRejected {
+		return 0
+	}
+	amount := tx.Amount - tx.BonusAmount
+	if tx.Debit() {
+		return -amount
+	}
+	return amount
+}
+
+func (uc *reconciliationUseCase) ListRuns(ctx context.Context) ([]domain.ReconciliationRun, error) {
+	return uc.reconRepo.ListRuns(ctx, 90)
+}
+
+func (uc *reconciliationUseCase) Report(ctx context.Context, day time.Time) (*ReconciliationReport, error) {
+	run, err := uc.reconRepo.FindRun(ctx, utcDay(day))
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	mismatches, err := uc.reconRepo.ListMismatches(ctx, run.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &ReconciliationReport{Run: *run, Mismatches: mismatches}, nil
+}
+
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,456 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Error codes returned to providers so they can show the player a
+// responsible-gaming message instead of a generic failure.
+const (
+	LimitExceededCode  = "RG_LIMIT_EXCEEDED"
+	PlayerExcludedCode = "PLAYER_EXCLUDED"
+)
+
+const (
+	maxTimeoutDays       = 42
+	minSelfExclusionDays = 180
+)
+
+var ErrPlayerNotFound = errors.New("player not found")
+
+// LimitExceededError reports a stake or deposit that would break one of the
+// player's limits.
+type LimitExceededError struct {
+	Type      string
+	Period    string
+	Limit     float64
+	Remaining float64
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s %s limit of %.2f reached, %.2f remaining",
+		strings.ToLower(e.Period), strings.ToLower(e.Type), e.Limit, e.Remaining)
+}
+
+// ExcludedError is returned while the player is self-excluded or on a
+// time-out. Until is nil for a permanent exclusion.
+type ExcludedError struct {
+	Until *time.Time
+}
+
+func (e *ExcludedError) Error() string {
+	if e.Until == nil {
+		return "account is permanently excluded from betting"
+	}
+	return "account is excluded from betting until " + e.Until.UTC().Format(time.RFC3339)
+}
+
+// ExclusionInput requests a time-out of 1 to 42 days, or a self-exclusion of
+// at least 180 days or, with Permanent, forever.
+type ExclusionInput struct {
+	Type      string
+	Days      int
+	Permanent bool
+	Reason    string
+	CreatedBy string
+}
+
+// RealityCheck summarises the current playing session for a reminder.
+type RealityCheck struct {
+	SessionStartedAt time.Time
+	Elapsed          time.Duration
+	Wagered          float64
+	Won              float64
+}
+
+// DepositAuthorization asks whether the player owning WalletID may pay Amount into
+// their wallet. Reference is the cashier's ID for the deposit.
+type DepositAuthorization struct {
+	WalletID  string
+	Amount    float64
+	Currency  string
+	Reference string
+}
+
+// LimitInput sets one limit. An Amount of zero removes it.
+type LimitInput struct {
+	Type   string
+	Period string
+	Amount float64
+}
+
+// LimitStatus is a limit together with the player's usage in its window.
+type LimitStatus struct {
+	Type          string
+	Period        string
+	Amount        float64
+	Used          float64
+	PendingAmount float64
+	PendingFrom   *time.Time
+}
+
+type ResponsibleGamingUseCase interface {
+	GetLimits(ctx context.Context, userID uint) ([]LimitStatus, error)
+	SetLimits(ctx context.Context, userID uint, limits []LimitInput) ([]LimitStatus, error)
+	// CheckStake returns a *LimitExceededError if staking amount now would
+	// break a wager or loss limit, or an *ExcludedError if the player is
+	// excluded.
+	CheckStake(ctx context.Context, userID uint, amount float64) error
+	// AuthorizeDeposit is called by the cashier before the wallet credits a
+	// deposit. It records the deposit against the player's deposit limits,
+	// or returns a *LimitExceededError if it would break one or an
+	// *ExcludedError if the player is excluded. Asking again for a recorded
+	// reference approves it without counting it twice.
+	AuthorizeDeposit(ctx context.Context, in DepositAuthorization) (*domain.PlayerDeposit, error)
+	Exclude(ctx context.Context, userID uint, in ExclusionInput) (*domain.Exclusion, error)
+	// ActiveExclusion returns the exclusion in force, or nil.
+	ActiveExclusion(ctx context.Context, userID uint) (*domain.Exclusion, error)
+	ListExclusions(ctx context.Context) ([]domain.Exclusion, error)
+	// TouchSession records betting activity and returns a RealityCheck when
+	// a reminder is due, or nil.
+	TouchSession(ctx context.Context, userID uint) (*RealityCheck, error)
+}
+
+type responsibleGamingUseCase struct {
+	limitRepo       repository.PlayerLimitRepository
+	exclusionRepo   repository.ExclusionRepository
+	sessionRepo     repository.GameSessionRepository
+	userRepo        repository.UserRepository
+	transactionRepo repository.TransactionRepository
+	rates           infrastructure.RateProvider
+	cfg             infrastructure.ResponsibleGamingConfig
+}
+
+func NewResponsibleGamingUseCase(limitRepo repository.PlayerLimitRepository, exclusionRepo repository.ExclusionRepository, sessionRepo repository.GameSessionRepository, userRepo repository.UserRepository, transactionRepo repository.TransactionRepository, rates infrastructure.RateProvider, cfg infrastructure.ResponsibleGamingConfig) ResponsibleGamingUseCase {
+	return &responsibleGamingUseCase{limitRepo, exclusionRepo, sessionRepo, userRepo, transactionRepo, rates, cfg}
+}
+
+func (uc *responsibleGamingUseCase) GetLimits(ctx context.Context, userID uint) (statuses []LimitStatus, err error) {
+	ctx, span := tracer.Start(ctx, "ResponsibleGamingUseCase.GetLimits")
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	limits, err := uc.limitRepo.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.statuses(ctx, userID, limits, time.Now())
+}
+
+// SetLimits lowers limits immediately and schedules raises and removals for
+// after the cooling-off period, so a player cannot lift a limit on impulse.
+func (uc *responsibleGamingUseCase) SetLimits(ctx context.Context, userID uint, inputs []LimitInput) (statuses []LimitStatus, err error) {
+	ctx, span := tracer.Start(ctx, "ResponsibleGamingUseCase.SetLimits")
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	for i := range inputs {
+		inputs[i].Type = strings.ToUpper(inputs[i].Type)
+		inputs[i].Period = strings.ToUpper(inputs[i].Period)
+		in := inputs[i]
+		if in.Type != domain.LimitTypeLoss && in.Type != domain.LimitTypeWager && in.Type != domain.LimitTypeDeposit {
+			return nil, &ValidationError{Msg: "limit type must be loss, wager or deposit"}
+		}
+		if _, ok := domain.LimitPeriods[in.Period]; !ok {
+			return nil, &ValidationError{Msg: "limit period must be daily, weekly or monthly"}
+		}
+		if in.Amount < 0 {
+			return nil, &ValidationError{Msg: "limit amount must not be negative"}
+		}
+	}
+
+	existing, err := uc.limitRepo.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]domain.PlayerLimit, len(existing))
+	for _, l := range existing {
+		byKey[l.Type+"/"+l.Period] = l
+	}
+
+	now := time.Now()
+	for _, in := range inputs {
+		limit, ok := byKey[in.Type+"/"+in.Period]
+		if !ok {
+			limit = domain.PlayerLimit{UserID: userID, Type: in.Type, Period: in.Period, CreatedAt: now}
+		}
+		current := limit.Effective(now)
+		limit.Amount = current
+		limit.PendingAmount, limit.PendingFrom = 0, nil
+		switch {
+		case in.Amount == current:
+		case in.Amount > 0 && (current == 0 || in.Amount < current):
+			limit.Amount = in.Amount
+		default:
+			from := now.Add(uc.cfg.LimitCoolingOff)
+			limit.PendingAmount, limit.PendingFrom = in.Amount, &from
+		}
+		limit.UpdatedAt = now
+		if err := uc.limitRepo.Save(ctx, &limit); err != nil {
+			log.Printf("SetLimits: failed to save %s/%s limit for user %d: %v", in.Type, in.Period, userID, err)
+			return nil, err
+		}
+		byKey[in.Type+"/"+in.Period] = limit
+		log.Printf("SetLimits: user %d %s/%s limit %.2f, pending %.2f", userID, in.Type, in.Period, limit.Amount, limit.PendingAmount)
+	}
+
+	limits := make([]domain.PlayerLimit, 0, len(byKey))
+	for _, l := range byKey {
+		limits = append(limits, l)
+	}
+	return uc.statuses(ctx, userID, limits, now)
+}
+
+func (uc *responsibleGamingUseCase) CheckStake(ctx context.Context, userID uint, amount float64) (err error) {
+	ctx, span := tracer.Start(ctx, "ResponsibleGamingUseCase.CheckStake")
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	now := time.Now()
+	exclusion, err := uc.exclusionRepo.FindActive(ctx, userID, now)
+	if err != nil {
+		return err
+	}
+	if exclusion != nil {
+		log.Printf("CheckStake: user %d is excluded", userID)
+		return &ExcludedError{Until: exclusion.EndsAt}
+	}
+	limits, err := uc.limitRepo.FindByUser(ctx, userID)
+	if err != nil || len(limits) == 0 {
+		return err
+	}
+	statuses, err := uc.statuses(ctx, userID, limits, now)
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		// The whole stake counts towards the loss limit since it may be lost.
+		if status.Type == domain.LimitTypeDeposit || status.Amount == 0 || status.Used+amount <= status.Amount {
+			continue
+		}
+		log.Printf("CheckStake: user %d stake %.2f exceeds %s/%s limit", userID, amount, status.Type, status.Period)
+		return &LimitExceededError{
+			Type:      status.Type,
+			Period:    status.Period,
+			Limit:     status.Amount,
+			Remaining: math.Max(0, status.Amount-status.Used),
+		}
+	}
+	return nil
+}
+
+func (uc *responsibleGamingUseCase) AuthorizeDeposit(ctx context.Context, in DepositAuthorization) (deposit *domain.PlayerDeposit, err error) {
+	ctx, span := tracer.Start(ctx, "ResponsibleGamingUseCase.AuthorizeDeposit")
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	if in.Amount <= 0 {
+		return nil, &ValidationError{Msg: "deposit amount must be positive"}
+	}
+	if in.Reference == "" {
+		return nil, &ValidationError{Msg: "deposit reference is required"}
+	}
+	user, err := uc.userRepo.FindByWalletID(ctx, in.WalletID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPlayerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Limits are set in the player's currency.
+	if !strings.EqualFold(in.Currency, user.Currency) {
+		return nil, &ValidationError{Msg: fmt.Sprintf("deposit currency must be %s", user.Currency)}
+	}
+
+	now := time.Now()
+	exclusion, err := uc.exclusionRepo.FindActive(ctx, user.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if exclusion != nil {
+		log.Printf("AuthorizeDeposit: user %d is excluded", user.ID)
+		return nil, &ExcludedError{Until: exclusion.EndsAt}
+	}
+	limits, err := uc.limitRepo.FindByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	deposit = &domain.PlayerDeposit{UserID: user.ID, Reference: in.Reference, Amount: in.Amount, CreatedAt: now}
+	deposit, err = uc.limitRepo.AddDeposit(ctx, deposit, func(locked repository.PlayerLimitRepository) error {
+		for _, l := range limits {
+			if l.Type != domain.LimitTypeDeposit {
+				continue
+			}
+			limit := l.Effective(now)
+			if limit == 0 {
+				continue
+			}
+			used, err := locked.SumDeposits(ctx, user.ID, now.Add(-domain.LimitPeriods[l.Period]))
+			if err != nil {
+				return err
+			}
+			if used+in.Amount > limit {
+				log.Printf("AuthorizeDeposit: user %d deposit %.2f exceeds %s limit", user.ID, in.Amount, l.Period)
+				return &LimitExceededError{Type: l.Type, Period: l.Period, Limit: limit, Remaining: math.Max(0, limit-used)}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("AuthorizeDeposit: user %d deposit %s of %.2f approved", user.ID, deposit.Reference, deposit.Amount)
+	return deposit, nil
+}
+
+// Exclude starts an exclusion immediately. An exclusion that would end
+// before one already in force is rejected, since neither can be shortened.
+func (uc *responsibleGamingUseCase) Exclude(ctx context.Context, userID uint, in ExclusionInput) (exclusion *domain.Exclusion, err error) {
+	ctx, span := tracer.Start(ctx, "ResponsibleGamingUseCase.Exclude")
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	in.Type = strings.ToUpper(in.Type)
+	switch {
+	case in.Type == domain.ExclusionTypeTimeout && !in.Permanent && in.Days >= 1 && in.Days <= maxTimeoutDays:
+	case in.Type == domain.ExclusionTypeSelf && (in.Permanent || in.Days >= minSelfExclusionDays):
+	case in.Type == domain.ExclusionTypeTimeout:
+		return nil, &ValidationError{Msg: fmt.Sprintf("a time-out lasts between 1 and %d days", maxTimeoutDays)}
+	case in.Type == domain.ExclusionTypeSelf:
+		return nil, &ValidationError{Msg: fmt.Sprintf("a self-exclusion lasts at least %d days or is permanent", minSelfExclusionDays)}
+	default:
+		return nil, &ValidationError{Msg: "exclusion type must be timeout or self_exclusion"}
+	}
+	if _, err := uc.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	exclusion = &domain.Exclusion{
+		UserID:    userID,
+		Type:      in.Type,
+		StartsAt:  now,
+		Reason:    in.Reason,
+		CreatedBy: in.CreatedBy,
+		CreatedAt: now,
+	}
+	if !in.Permanent {
+		ends := now.AddDate(0, 0, in.Days)
+		exclusion.EndsAt = &ends
+	}
+	current, err := uc.exclusionRepo.FindActive(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && (current.EndsAt == nil || (exclusion.EndsAt != nil && exclusion.EndsAt.Before(*current.EndsAt))) {
+		return nil, &ValidationError{Msg: "an exclusion that lasts longer is already in force"}
+	}
+	if err := uc.exclusionRepo.Create(ctx, exclusion); err != nil {
+		log.Printf("Exclude: failed to create exclusion for user %d: %v", userID, err)
+		return nil, err
+	}
+	log.Printf("Exclude: user %d excluded (%s) by %s", userID, exclusion.Type, in.CreatedBy)
+	return exclusion, nil
+}
+
+func (uc *responsibleGamingUseCase) ActiveExclusion(ctx context.Context, userID uint) (*domain.Exclusion, error) {
+	return uc.exclusionRepo.FindActive(ctx, userID, time.Now())
+}
+
+func (uc *responsibleGamingUseCase) ListExclusions(ctx context.Context) ([]domain.Exclusion, error) {
+	return uc.exclusionRepo.ListActive(ctx, time.Now())
+}
+
+func (uc *responsibleGamingUseCase) TouchSession(ctx context.Context, userID uint) (check *RealityCheck, err error) {
+	ctx, span := tracer.Start(ctx, "ResponsibleGamingUseCase.TouchSession")
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	now := time.Now()
+	session, err := uc.sessionRepo.FindCurrent(ctx, userID, now.Add(-uc.cfg.SessionIdleTimeout))
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		session = &domain.GameSession{UserID: userID, StartedAt: now, NextRealityCheckAt: now.Add(uc.cfg.RealityCheckInterval)}
+	}
+	session.LastActivityAt = now
+	if uc.cfg.RealityCheckInterval > 0 && !now.Before(session.NextRealityCheckAt) {
+		user, err := uc.userRepo.FindByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		wagered, won, err := sumActivity(ctx, uc.transactionRepo, uc.rates, userID, session.StartedAt, user.Currency)
+		if err != nil {
+			return nil, err
+		}
+		check = &RealityCheck{SessionStartedAt: session.StartedAt, Elapsed: now.Sub(session.StartedAt), Wagered: wagered, Won: won}
+		session.NextRealityCheckAt = now.Add(uc.cfg.RealityCheckInterval)
+	}
+	if err := uc.sessionRepo.Save(ctx, session); err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// statuses resolves each limit at now and adds the player's usage over its
+// rolling window: stakes for wager limits, stakes minus wins for loss limits
+// and approved deposits for deposit limits. Stakes and wins are counted in
+// the player's primary currency.
+func (uc *responsibleGamingUseCase) statuses(ctx context.Context, userID uint, limits []domain.PlayerLimit, now time.Time) ([]LimitStatus, error) {
+	type activity struct{ wagered, won float64 }
+	byPeriod := make(map[string]activity)
+	var user *domain.User
+	statuses := make([]LimitStatus, 0, len(limits))
+	for _, l := range limits {
+		status := LimitStatus{Type: l.Type, Period: l.Period, Amount: l.Effective(now)}
+		if l.PendingFrom != nil && now.Before(*l.PendingFrom) {
+			status.PendingAmount, status.PendingFrom = l.PendingAmount, l.PendingFrom
+		} else if status.Amount == 0 {
+			continue // removed
+		}
+		if l.Type == domain.LimitTypeDeposit {
+			used, err := uc.limitRepo.SumDeposits(ctx, userID, now.Add(-domain.LimitPeriods[l.Period]))
+			if err != nil {
+				return nil, err
+			}
+			status.Used = used
+			statuses = append(statuses, status)
+			continue
+		}
+		a, ok := byPeriod[l.Period]
+		if !ok {
+			if user == nil {
+				var err error
+				if user, err = uc.userRepo.FindByID(ctx, userID); err != nil {
+					return nil, err
+				}
+			}
+			wagered, won, err := sumActivity(ctx, uc.transactionRepo, uc.rates, userID, now.Add(-domain.LimitPeriods[l.Period]), user.Currency)
+			if err != nil {
+				return nil, err
+			}
+			a = activity{wagered, won}
+			byPeriod[l.Period] = a
+		}
+		if l.Type == domain.LimitTypeWager {
+			status.Used = a.wagered
+		} else {
+			status.Used = math.Max(0, a.wagered-a.won)
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Type != statuses[j].Type {
+			return statuses[i].Type < statuses[j].Type
+		}
+		return domain.LimitPeriods[statuses[i].Period] < domain.LimitPeriods[statuses[j].Period]
+	})
+	return statuses, nil
+}
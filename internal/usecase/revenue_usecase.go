@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"log"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// revenueGroupings is the order report groupings are applied in.
+var revenueGroupings = []string{"day", "game", "provider", "currency", "player"}
+
+// RevenueLine is one grouping of a revenue report. GGR is turnover less
+// wins. NGR is GGR less bonus costs: the bonus-funded part of GGR and
+// bonus money released to players.
+type RevenueLine struct {
+	repository.RevenueTotal
+	GGR     float64
+	NGR     float64
+	BaseGGR float64
+}
+
+type RevenueReport struct {
+	From    time.Time
+	To      time.Time
+	GroupBy []string
+	Lines   []RevenueLine
+}
+
+type RevenueUseCase interface {
+	// Aggregate rebuilds the daily aggregates of the UTC day containing
+	// day. Running it again replaces the day rather than adding to it.
+	Aggregate(ctx context.Context, day time.Time) (int64, error)
+	// Report sums the aggregated days in [from, to) by groupBy, a subset
+	// of day, game, provider, currency and player. Amounts in different
+	// currencies are never added up, so currency is always grouped by.
+	Report(ctx context.Context, from, to time.Time, groupBy []string) (*RevenueReport, error)
+}
+
+type revenueUseCase struct {
+	revenueRepo repository.RevenueRepository
+}
+
+func NewRevenueUseCase(revenueRepo repository.RevenueRepository) RevenueUseCase {
+	return &revenueUseCase{revenueRepo: revenueRepo}
+}
+
+func (uc *revenueUseCase) Aggregate(ctx context.Context, day time.Time) (rows int64, err error) {
+	day = utcDay(day)
+	ctx, span := tracer.Start(ctx, "RevenueUseCase.Aggregate", trace.WithAttributes(
+		attribute.String("revenue.day", day.Format(time.DateOnly)),
+	))
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	if day.After(time.Now()) {
+		return 0, &ValidationError{Msg: "day must not be in the future"}
+	}
+	rows, err = uc.revenueRepo.AggregateDay(ctx, day, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Aggregate: %s rebuilt, %d rows", day.Format(time.DateOnly), rows)
+	return rows, nil
+}
+
+func (uc *revenueUseCase) Report(ctx context.Context, from, to time.Time, groupBy []string) (*RevenueReport, error) {
+	from, to = utcDay(from), utcDay(to)
+	if !to.After(from) {
+		return nil, &ValidationError{Msg: "to must be after from"}
+	}
+	wanted := map[string]bool{"currency": true}
+	for _, g := range groupBy {
+		g = strings.ToLower(strings.TrimSpace(g))
+		if _, ok := repository.RevenueGroupColumns[g]; !ok {
+			return nil, &ValidationError{Msg: fmt.Sprintf("cannot group by %q", g)}
+		}
+		wanted[g] = true
+	}
+	groups := make([]string, 0, len(wanted))
+	for _, g := range revenueGroupings {
+		if wanted[g] {
+			groups = append(groups, g)
+		}
+	}
+
+	totals, err := uc.revenueRepo.Sum(ctx, from, to, groups)
+	if err != nil {
+		return nil, err
+	}
+	report := &RevenueReport{From: from, To: to, GroupBy: groups, Lines: make([]RevenueLine, 0, len(totals))}
+	for _, t := range totals {
+		ggr := t.Turnover - t.Wins
+		report.Lines = append(report.Lines, RevenueLine{
+			RevenueTotal: t,
+			GGR:          ggr,
+			NGR:          ggr - (t.BonusStakes - t.BonusWins) - t.BonusReleased,
+			BaseGGR:      t.BaseTurnover - t.BaseWins,
+		})
+	}
+	return report, nil
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"log"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrRoundNotFound = errors.New("round not found")
+
+// Where the provider's description of a round came from.
+const (
+	RoundSourceStored      = "stored"
+	RoundSourceProvider    = "provider"
+	RoundSourceUnavailable = "unavailable"
+)
+
+// RoundDetails is our ledger of a round next to the provider's description
+// of it. Error explains an unavailable description.
+type RoundDetails struct {
+	RoundID    string
+	ProviderID string
+	GameID     string
+	UserID     uint
+	Entries    []domain.Transaction
+	Source     string
+	Data       json.RawMessage
+	Error      string
+}
+
+type RoundUseCase interface {
+	// Details returns a round's ledger entries with the round payload the
+	// provider sent with its latest bet or, failing that, the one its round
+	// history API returns. A zero userID looks the round up for any player;
+	// providerID is needed when several providers used the round ID.
+	Details(ctx context.Context, roundID string, userID uint, providerID string) (*RoundDetails, error)
+}
+
+type roundUseCase struct {
+	transactionRepo repository.TransactionRepository
+	history         *infrastructure.RoundHistoryClient
+}
+
+func NewRoundUseCase(transactionRepo repository.TransactionRepository, history *infrastructure.RoundHistoryClient) RoundUseCase {
+	return &roundUseCase{transactionRepo: transactionRepo, history: history}
+}
+
+func (uc *roundUseCase) Details(ctx context.Context, roundID string, userID uint, providerID string) (details *RoundDetails, err error) {
+	ctx, span := tracer.Start(ctx, "RoundUseCase.Details", trace.WithAttributes(
+		attribute.String("round.id", roundID),
+		attribute.String("round.provider_id", providerID),
+	))
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	entries, err := uc.transactionRepo.ListByRound(ctx, roundID, userID, providerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrRoundNotFound
+	}
+	for _, tx := range entries[1:] {
+		if tx.ProviderID != entries[0].ProviderID {
+			return nil, &ValidationError{Msg: "round ID is used by several providers; pass provider_id"}
+		}
+	}
+	details = &RoundDetails{
+		RoundID:    roundID,
+		ProviderID: entries[0].ProviderID,
+		GameID:     entries[0].ProviderGameID,
+		UserID:     entries[0].UserID,
+		Entries:    entries,
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		var rec domain.PlatformRecord
+		if json.Unmarshal([]byte(entries[i].PlatformResponse), &rec) == nil && len(rec.RoundDetails) > 0 {
+			details.Source, details.Data = RoundSourceStored, rec.RoundDetails
+			return details, nil
+		}
+	}
+	data, err := uc.history.Fetch(ctx, details.ProviderID, roundID)
+	if err != nil {
+		// The ledger is still worth showing without the provider's side.
+		log.Printf("Details: no round history for round %q of provider %q: %v", roundID, details.ProviderID, err)
+		details.Source, details.Error = RoundSourceUnavailable, err.Error()
+		return details, nil
+	}
+	details.Source, details.Data = RoundSourceProvider, data
+	return details, nil
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Statement is the header of a player's statement of one balance over
+// [From, To). Opening and Closing are the balance at From and To; money the
+// wallet moved outside game play shows as a gap between lines.
+type Statement struct {
+	UserID   uint
+	Username string
+	Currency string
+	From     time.Time
+	To       time.Time
+	Opening  float64
+	Closing  float64
+}
+
+// StatementWriter renders a statement as it is read. Begin is called
+// before any line; once it has been called the statement is being sent.
+type StatementWriter interface {
+	Begin(s *Statement) error
+	Line(tx *domain.Transaction) error
+	End(s *Statement) error
+}
+
+type StatementUseCase interface {
+	// Export writes the statement of the user's balance in currency, the
+	// primary one when empty, to w. Transactions are streamed rather than
+	// loaded, so the range is not limited.
+	Export(ctx context.Context, userID uint, currency string, from, to time.Time, w StatementWriter) error
+}
+
+type statementUseCase struct {
+	userRepo        repository.UserRepository
+	balanceRepo     repository.BalanceRepository
+	transactionRepo repository.TransactionRepository
+}
+
+func NewStatementUseCase(userRepo repository.UserRepository, balanceRepo repository.BalanceRepository, transactionRepo repository.TransactionRepository) StatementUseCase {
+	return &statementUseCase{userRepo: userRepo, balanceRepo: balanceRepo, transactionRepo: transactionRepo}
+}
+
+func (uc *statementUseCase) Export(ctx context.Context, userID uint, currency string, from, to time.Time, w StatementWriter) (err error) {
+	ctx, span := tracer.Start(ctx, "StatementUseCase.Export", trace.WithAttributes(
+		attribute.Int("user.id", int(userID)),
+		attribute.String("statement.from", from.Format(time.RFC3339)),
+		attribute.String("statement.to", to.Format(time.RFC3339)),
+	))
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	if !to.After(from) {
+		return &ValidationError{Msg: "to must be after from"}
+	}
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	scope := repository.StatementScope{UserID: user.ID, Currency: strings.ToUpper(currency), Primary: true}
+	current := user.Balance
+	if scope.Currency == "" {
+		scope.Currency = user.Currency
+	}
+	if scope.Currency != user.Currency {
+		balance, err := uc.balanceRepo.Find(ctx, user.ID, scope.Currency)
+		if err != nil {
+			return err
+		}
+		if balance == nil {
+			return &ValidationError{Msg: fmt.Sprintf("no %s balance", scope.Currency)}
+		}
+		scope.Primary, current = false, balance.Balance
+	}
+
+	s := &Statement{UserID: user.ID, Username: user.Username, Currency: scope.Currency, From: from, To: to}
+	if s.Opening, err = uc.balanceAt(ctx, scope, from, current); err != nil {
+		return err
+	}
+	if s.Closing, err = uc.balanceAt(ctx, scope, to, current); err != nil {
+		return err
+	}
+	if err := w.Begin(s); err != nil {
+		return err
+	}
+	if err := uc.transactionRepo.Stream(ctx, scope, from, to, w.Line); err != nil {
+		return err
+	}
+	return w.End(s)
+}
+
+// balanceAt falls back to the current balance for a balance that has never
+// been played.
+func (uc *statementUseCase) balanceAt(ctx context.Context, scope repository.StatementScope, at time.Time, current float64) (float64, error) {
+	balance, ok, err := uc.transactionRepo.BalanceAt(ctx, scope, at)
+	if err != nil || !ok {
+		return current, err
+	}
+	return balance, nil
+}
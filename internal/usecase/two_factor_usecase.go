@@ -0,0 +1,276 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"log"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled    = errors.New("two-factor enrolment has not been started")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorRequired       = errors.New("two-factor authentication is required for this role")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrEnrollmentOutOfBand     = errors.New("two-factor authentication for this role is set up by an operator")
+)
+
+type TOTPEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+type TwoFactorUseCase interface {
+	Enroll(ctx context.Context, userID uint, password string) (*TOTPEnrollment, error)
+	Confirm(ctx context.Context, userID uint, code string) ([]string, error)
+	Disable(ctx context.Context, userID uint, password, code string) error
+	// Provision enrols username and enables 2FA at once. It is how roles
+	// other than player are enrolled, and is only reachable from the
+	// "admin enroll" command.
+	Provision(ctx context.Context, username string) (*TOTPEnrollment, []string, error)
+}
+
+type twoFactorUseCase struct {
+	userRepo repository.UserRepository
+	factor   *secondFactor
+	cfg      infrastructure.AuthConfig
+}
+
+func NewTwoFactorUseCase(userRepo repository.UserRepository, recoveryRepo repository.RecoveryCodeRepository, box *infrastructure.SecretBox, cfg infrastructure.AuthConfig) TwoFactorUseCase {
+	return &twoFactorUseCase{userRepo, &secondFactor{userRepo, recoveryRepo, box}, cfg}
+}
+
+// Enroll generates a new TOTP secret after re-checking the password, so that
+// a stolen session cannot bind an attacker's authenticator. It is not
+// enforced until Confirm proves the user's authenticator produces matching
+// codes. Roles other than player are enrolled with Provision instead, since
+// their password alone must not be enough to obtain an MFA session.
+func (uc *twoFactorUseCase) Enroll(ctx context.Context, userID uint, password string) (enrollment *TOTPEnrollment, err error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorUseCase.Enroll")
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role != domain.RolePlayer {
+		return nil, ErrEnrollmentOutOfBand
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	enrollment, err = uc.newSecret(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Enroll2FA: enrolment started for user %d", userID)
+	return enrollment, nil
+}
+
+func (uc *twoFactorUseCase) Provision(ctx context.Context, username string) (enrollment *TOTPEnrollment, codes []string, err error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorUseCase.Provision")
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	user, err := uc.userRepo.FindByUsername(ctx, username)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, nil, ErrTwoFactorAlreadyEnabled
+	}
+	enrollment, err = uc.newSecret(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	codes, err = uc.factor.issueRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	user.TOTPEnabled = true
+	if err := uc.userRepo.UpdateTOTP(ctx, user); err != nil {
+		return nil, nil, err
+	}
+	log.Printf("Provision2FA: two-factor enabled for user %d", user.ID)
+	return enrollment, codes, nil
+}
+
+// newSecret stores a fresh TOTP secret for user without enabling it.
+func (uc *twoFactorUseCase) newSecret(ctx context.Context, user *domain.User) (*TOTPEnrollment, error) {
+	secret, err := infrastructure.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := uc.factor.box.Seal(secret)
+	if err != nil {
+		return nil, err
+	}
+	user.TOTPSecret = sealed
+	user.TOTPLastStep = 0
+	if err := uc.userRepo.UpdateTOTP(ctx, user); err != nil {
+		return nil, err
+	}
+	return &TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: infrastructure.TOTPProvisioningURI(uc.cfg.TOTPIssuer, user.Username, secret),
+	}, nil
+}
+
+// Confirm enables 2FA once code matches the enrolled secret and returns a
+// fresh set of recovery codes. The codes are shown only this once.
+func (uc *twoFactorUseCase) Confirm(ctx context.Context, userID uint, code string) (codes []string, err error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorUseCase.Confirm")
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role != domain.RolePlayer {
+		return nil, ErrEnrollmentOutOfBand
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+	ok, err := uc.factor.verifyTOTP(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+	codes, err = uc.factor.issueRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	user.TOTPEnabled = true
+	if err := uc.userRepo.UpdateTOTP(ctx, user); err != nil {
+		return nil, err
+	}
+	log.Printf("Confirm2FA: two-factor enabled for user %d", userID)
+	return codes, nil
+}
+
+// Disable turns 2FA off after re-checking the password and a current code.
+// Roles other than player cannot disable it.
+func (uc *twoFactorUseCase) Disable(ctx context.Context, userID uint, password, code string) (err error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorUseCase.Disable")
+	defer func() { infrastructure.EndSpan(span, err) }()
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+	if user.Role != domain.RolePlayer {
+		return ErrTwoFactorRequired
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return ErrInvalidCredentials
+	}
+	ok, err := uc.factor.verify(ctx, user, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPLastStep = 0
+	if err := uc.userRepo.UpdateTOTP(ctx, user); err != nil {
+		return err
+	}
+	if err := uc.factor.recoveryRepo.DeleteForUser(ctx, user.ID); err != nil {
+		return err
+	}
+	log.Printf("Disable2FA: two-factor disabled for user %d", userID)
+	return nil
+}
+
+// secondFactor verifies TOTP and recovery codes and is shared by login and
+// enrolment.
+type secondFactor struct {
+	userRepo     repository.UserRepository
+	recoveryRepo repository.RecoveryCodeRepository
+	box          *infrastructure.SecretBox
+}
+
+// verify accepts either a current TOTP code or an unused recovery code.
+func (f *secondFactor) verify(ctx context.Context, user *domain.User, code string) (bool, error) {
+	ok, err := f.verifyTOTP(ctx, user, code)
+	if err != nil || ok {
+		return ok, err
+	}
+	err = f.recoveryRepo.Consume(ctx, user.ID, hashRecoveryCode(code), time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (f *secondFactor) verifyTOTP(ctx context.Context, user *domain.User, code string) (bool, error) {
+	secret, err := f.box.Open(user.TOTPSecret)
+	if err != nil {
+		return false, err
+	}
+	step, ok := infrastructure.ValidateTOTP(secret, strings.TrimSpace(code), time.Now(), user.TOTPLastStep)
+	if !ok {
+		return false, nil
+	}
+	advanced, err := f.userRepo.AdvanceTOTPStep(ctx, user.ID, step)
+	if err != nil || !advanced {
+		return false, err
+	}
+	user.TOTPLastStep = step
+	return true, nil
+}
+
+func (f *secondFactor) issueRecoveryCodes(ctx context.Context, userID uint) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 6)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		s := strings.ToLower(enc.EncodeToString(raw))
+		code := s[:5] + "-" + s[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	if err := f.recoveryRepo.ReplaceForUser(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode normalises case, spaces and dashes before hashing so that
+// codes can be typed loosely.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type userLockKey struct{}
+
+// userLock is the row lock on a player held by a wallet operation, carried in
+// its context so that what it calls joins the transaction holding it instead
+// of waiting on it.
+type userLock struct {
+	userID uint
+	db     *gorm.DB
+	// settle is set when a stake drew on a bonus. Wagering is settled once
+	// the lock is released.
+	settle bool
+}
+
+func lockFrom(ctx context.Context) *userLock {
+	l, _ := ctx.Value(userLockKey{}).(*userLock)
+	return l
+}
+
+// lockedDB returns the transaction holding userID's lock if ctx carries it,
+// and db otherwise.
+func lockedDB(ctx context.Context, db *gorm.DB, userID uint) *gorm.DB {
+	if l := lockFrom(ctx); l != nil && l.userID == userID {
+		return l.db
+	}
+	return db
+}
+
+// newLockSlots bounds the player locks held at once to limit, or to half of
+// db's connection pool when limit is zero. An operation holding a lock keeps
+// its connection while it reads through the pool, so locks waiting on each
+// other must not be able to take every connection. It returns nil when the
+// pool is unbounded and no limit is set.
+func newLockSlots(db *gorm.DB, limit int) chan struct{} {
+	if limit > 0 {
+		return make(chan struct{}, limit)
+	}
+	if db == nil {
+		return nil
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil
+	}
+	conns := sqlDB.Stats().MaxOpenConnections
+	if conns == 0 {
+		return nil
+	}
+	return make(chan struct{}, max(conns/2, 1))
+}
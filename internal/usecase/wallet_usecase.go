@@ -1,21 +1,88 @@
 package usecase
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"gameintegrationapi/internal/domain"
 	"gameintegrationapi/internal/infrastructure"
 	"gameintegrationapi/internal/repository"
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
+// WithdrawInput describes a stake placed by a game provider. Amount is in
+// Currency, the game's currency; a stake in a currency the player holds no
+// balance in is converted to their primary currency.
+type WithdrawInput struct {
+	UserID       uint
+	Amount       float64
+	Currency     string
+	ProviderTxID string
+	RoundID      string
+	GameID       string
+	ProviderID   string
+	// FreeRound plays one round of a free-round grant for GameID; Amount
+	// must equal the grant's bet value and is not debited.
+	FreeRound bool
+	// RoundDetails is the provider's round payload, kept for replays.
+	RoundDetails json.RawMessage
+}
+
+// DepositInput settles the stake ProviderParentTxID. FreeRound, when set,
+// asserts that the stake was a free round.
+type DepositInput struct {
+	UserID             uint
+	Amount             float64
+	Currency           string
+	ProviderTxID       string
+	ProviderParentTxID string
+	FreeRound          bool
+	// JackpotID pays out the pool's current value instead of Amount.
+	JackpotID    uint
+	RoundDetails json.RawMessage
+}
+
+// CancelInput reverses the transaction ProviderTxID: a stake is refunded, a
+// win clawed back.
+type CancelInput struct {
+	UserID       uint
+	ProviderTxID string
+	// Amount is the part to reverse, in the currency the transaction was
+	// played in. Zero reverses all that is left.
+	Amount float64
+	// CancelTxID is the provider's ID for the cancel, "cancel-" +
+	// ProviderTxID when empty. Each partial cancel needs its own.
+	CancelTxID string
+}
+
+func (in CancelInput) reference() string {
+	if in.CancelTxID != "" {
+		return in.CancelTxID
+	}
+	return "cancel-" + in.ProviderTxID
+}
+
 type WalletUseCase interface {
-	Withdraw(userID uint, amount float64, currency, providerTxID, roundID, gameID string) (*domain.Transaction, error)
-	Deposit(userID uint, amount float64, currency, providerTxID, providerParentTxID string) (*domain.Transaction, error)
-	Cancel(userID uint, providerTxID string) (*domain.Transaction, error)
+	Withdraw(ctx context.Context, in WithdrawInput) (*domain.Transaction, error)
+	Deposit(ctx context.Context, in DepositInput) (*domain.Transaction, error)
+	// Cancel reverses all or part of a stake or win. A cancel of a
+	// transaction that has not arrived is recorded as a tombstone and
+	// succeeds; a stake with that ID arriving later is ignored.
+	Cancel(ctx context.Context, in CancelInput) (*domain.Transaction, error)
+	// Batch runs several operations for one player, see BatchInput.
+	Batch(ctx context.Context, in BatchInput) ([]BatchItemResult, error)
+	ListHeldWins(ctx context.Context) ([]domain.Transaction, error)
+	// ReviewHeldWin credits a held win when approve is true and rejects it
+	// otherwise.
+	ReviewHeldWin(ctx context.Context, txID uint, approve bool, reviewer string) (*domain.Transaction, error)
 }
 
 type walletUseCase struct {
@@ -23,116 +90,392 @@ type walletUseCase struct {
 	transactionRepo repository.TransactionRepository
 	db              *gorm.DB
 	walletClient    *infrastructure.WalletClient
+	tracker         *OperationTracker
+	limits          ResponsibleGamingUseCase
+	rules           BetRuleUseCase
+	bonuses         BonusUseCase
+	freeRounds      FreeRoundUseCase
+	jackpots        JackpotUseCase
+	currencies      CurrencyUseCase
+	// lockSlots bounds the player locks held at once, see newLockSlots.
+	lockSlots chan struct{}
 }
 
-var ErrWalletServiceUnavailable = errors.New("wallet service is not available")
+var (
+	ErrWalletServiceUnavailable = errors.New("wallet service is not available")
+	ErrNotHeld                  = errors.New("transaction is not held for review")
+	ErrAlreadyCancelled         = errors.New("transaction already cancelled")
+	ErrCancelExceedsAmount      = errors.New("cancel amount exceeds what is left to cancel")
+	ErrDuplicateTransaction     = errors.New("provider transaction ID is already used")
+)
 
-func NewWalletUseCase(userRepo repository.UserRepository, transactionRepo repository.TransactionRepository, db *gorm.DB, walletClient *infrastructure.WalletClient) WalletUseCase {
-	return &walletUseCase{userRepo, transactionRepo, db, walletClient}
+var tracer = otel.Tracer("gameintegrationapi/usecase")
+
+func NewWalletUseCase(userRepo repository.UserRepository, transactionRepo repository.TransactionRepository, db *gorm.DB, walletClient *infrastructure.WalletClient, tracker *OperationTracker, limits ResponsibleGamingUseCase, rules BetRuleUseCase, bonuses BonusUseCase, freeRounds FreeRoundUseCase, jackpots JackpotUseCase, currencies CurrencyUseCase, maxPlayerLocks int) WalletUseCase {
+	return &walletUseCase{userRepo, transactionRepo, db, walletClient, tracker, limits, rules, bonuses, freeRounds, jackpots, currencies, newLockSlots(db, maxPlayerLocks)}
 }
 
-func (uc *walletUseCase) Withdraw(userID uint, amount float64, currency, providerTxID, roundID, gameID string) (*domain.Transaction, error) {
-	user, err := uc.userRepo.FindByID(userID)
+func (uc *walletUseCase) Withdraw(ctx context.Context, in WithdrawInput) (result *domain.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "WalletUseCase.Withdraw", trace.WithAttributes(
+		attribute.Int("user.id", int(in.UserID)),
+		attribute.String("provider.tx_id", in.ProviderTxID),
+	))
+	defer func() { infrastructure.EndSpan(span, err) }()
+	if err := uc.tracker.Begin(); err != nil {
+		return nil, err
+	}
+	defer uc.tracker.Done()
+	err = uc.serialize(ctx, in.UserID, func(ctx context.Context, locked *walletUseCase) error {
+		result, err = locked.withdraw(ctx, in)
+		return err
+	})
+	return result, err
+}
+
+// withdraw places a stake while the player's lock is held.
+func (uc *walletUseCase) withdraw(ctx context.Context, in WithdrawInput) (*domain.Transaction, error) {
+	if in.Amount <= 0 {
+		return nil, ErrInvalidStake
+	}
+	userID, providerTxID := in.UserID, in.ProviderTxID
+	cancel, err := uc.transactionRepo.FindCancel(ctx, userID, providerTxID)
+	if err != nil {
+		return nil, err
+	}
+	if cancel != nil {
+		return uc.voidStake(ctx, cancel)
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		log.Printf("Withdraw: failed to find user: %v", err)
 		return nil, err
 	}
+	if in.FreeRound {
+		return uc.withdrawFreeRound(ctx, user, in)
+	}
 	walletID, err := strconv.ParseInt(user.WalletID, 10, 64)
 	if err != nil {
 		log.Printf("Withdraw: invalid wallet ID: %v", err)
 		return nil, err
 	}
+	booking, err := uc.currencies.Book(ctx, user, in.Currency, in.Amount, "")
+	if err != nil {
+		return nil, err
+	}
+	amount := booking.Amount
+	rule, err := uc.rules.Resolve(ctx, in.GameID, in.ProviderID, booking.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStake(rule, amount); err != nil {
+		log.Printf("Withdraw: stake %.2f rejected for game %q: %v", amount, in.GameID, err)
+		return nil, err
+	}
+	// Limits are set in the player's primary currency.
+	limitAmount := amount
+	if !booking.Primary {
+		if limitAmount, err = uc.currencies.Convert(ctx, amount, booking.Currency, user.Currency); err != nil {
+			return nil, err
+		}
+	}
+	if err := uc.limits.CheckStake(ctx, userID, limitAmount); err != nil {
+		return nil, err
+	}
+	// Bonus funds are held in the primary currency only.
+	funding := &StakeFunding{FromReal: amount}
+	if booking.Primary {
+		if funding, err = uc.bonuses.FundStake(ctx, user, amount); err != nil {
+			log.Printf("Withdraw: cannot fund stake %.2f for user %d: %v", amount, userID, err)
+			return nil, err
+		}
+	}
+	contributions, err := uc.jackpots.Contributions(ctx, in.GameID, booking.Currency, amount)
+	if err != nil {
+		log.Printf("Withdraw: failed to resolve jackpots for game %q: %v", in.GameID, err)
+		return nil, err
+	}
 	withdrawReq := infrastructure.WalletWithdrawRequest{
-		Currency: user.Currency,
+		Currency: booking.Currency,
 		Transactions: []struct {
 			Amount    float64 `json:"amount"`
 			BetID     int     `json:"betId"`
 			Reference string  `json:"reference"`
 		}{
 			{
-				Amount:    amount,
+				Amount:    funding.FromReal,
 				BetID:     0,
 				Reference: providerTxID,
 			},
 		},
 		UserID: walletID,
 	}
-	_, err = uc.walletClient.Withdraw(withdrawReq)
-	if err != nil {
-		log.Printf("Withdraw: external wallet error: %v", err)
-		return nil, err
-	}
-	if user.Balance < amount {
+	// The balance is read under the player's lock, so it is checked before
+	// the wallet is debited.
+	if booking.Balance < funding.FromReal {
 		log.Printf("Withdraw: insufficient funds for user %d", userID)
-		return nil, errors.New("insufficient funds")
+		return nil, ErrInsufficientFunds
+	}
+	// A stake paid entirely from bonus funds does not touch the wallet.
+	if funding.FromReal > 0 {
+		err = uc.walletWithdraw(ctx, withdrawReq)
+		if err != nil {
+			log.Printf("Withdraw: external wallet error: %v", err)
+			if errors.Is(err, infrastructure.ErrCircuitOpen) {
+				return nil, ErrWalletServiceUnavailable
+			}
+			return nil, err
+		}
 	}
-	oldBalance := user.Balance
-	newBalance := oldBalance - amount
+	oldBalance := booking.Balance
+	newBalance := oldBalance - funding.FromReal
 	tx := &domain.Transaction{
 		UserID:           userID,
 		Type:             "WITHDRAW",
 		Amount:           amount,
+		BonusAmount:      funding.FromBonus,
 		OldBalance:       oldBalance,
 		NewBalance:       newBalance,
 		Status:           "COMPLETED",
 		ProviderTxID:     providerTxID,
-		ProviderRoundID:  roundID,
-		ProviderGameID:   gameID,
-		PlatformResponse: "{}",
+		ProviderRoundID:  in.RoundID,
+		ProviderGameID:   in.GameID,
+		ProviderID:       in.ProviderID,
+		PlatformResponse: platformResponse(ctx, domain.PlatformRecord{RoundDetails: in.RoundDetails}),
+		Currency:         booking.Currency,
+		FX:               booking.FX,
 		CreatedAt:        time.Now(),
 	}
-	err = uc.db.Transaction(func(txDb *gorm.DB) error {
-		if err := repository.NewTransactionRepository(txDb).Create(tx); err != nil {
+	if funding.FromBonus > 0 {
+		tx.PlayerBonusID = &funding.Bonus.ID
+	}
+	for _, c := range contributions {
+		tx.JackpotContribution += c.Amount
+	}
+	ctx = context.WithoutCancel(ctx)
+	err = uc.db.WithContext(ctx).Transaction(func(txDb *gorm.DB) error {
+		if err := repository.NewTransactionRepository(txDb).Create(ctx, tx); err != nil {
 			log.Printf("Withdraw: failed to create transaction: %v", err)
 			return err
 		}
-		if err := repository.NewUserRepository(txDb).UpdateBalance(user, newBalance); err != nil {
+		if err := updateBalance(ctx, txDb, user, booking, newBalance); err != nil {
 			log.Printf("Withdraw: failed to update balance: %v", err)
 			return err
 		}
+		users := repository.NewUserRepository(txDb)
+		if funding.FromBonus > 0 {
+			if err := users.AddBonusBalance(ctx, user.ID, -funding.FromBonus); err != nil {
+				log.Printf("Withdraw: failed to update bonus balance: %v", err)
+				return err
+			}
+		}
+		jackpots := repository.NewJackpotRepository(txDb)
+		for _, c := range contributions {
+			if err := jackpots.Contribute(ctx, c.PoolID, c.Amount); err != nil {
+				log.Printf("Withdraw: failed to contribute to jackpot %d: %v", c.PoolID, err)
+				return err
+			}
+		}
 		return nil
 	})
 	if err != nil {
 		log.Printf("Withdraw: db transaction error: %v", err)
 		return nil, err
 	}
-	log.Printf("Withdraw: success for user %d, amount %.2f", userID, amount)
+	log.Printf("Withdraw: success for user %d, amount %.2f %s (%.2f bonus)", userID, amount, booking.Currency, funding.FromBonus)
+	if funding.Bonus != nil {
+		// Settling writes the balances, so it waits for the lock.
+		lockFrom(ctx).settle = true
+	}
+	return tx, nil
+}
+
+// withdrawFreeRound records a zero-cost stake played from a free-round grant.
+// The wallet is not called.
+func (uc *walletUseCase) withdrawFreeRound(ctx context.Context, user *domain.User, in WithdrawInput) (*domain.Transaction, error) {
+	// A free round costs nothing but is still play, which an exclusion stops.
+	exclusion, err := uc.limits.ActiveExclusion(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if exclusion != nil {
+		log.Printf("Withdraw: user %d is excluded, free round refused", user.ID)
+		return nil, &ExcludedError{Until: exclusion.EndsAt}
+	}
+	grant, err := uc.freeRounds.FindUsable(ctx, user.ID, in.GameID, in.Amount)
+	if err != nil {
+		log.Printf("Withdraw: no free round for user %d on %q at %.2f: %v", user.ID, in.GameID, in.Amount, err)
+		return nil, err
+	}
+	booking, err := uc.currencies.Book(ctx, user, user.Currency, 0, user.Currency)
+	if err != nil {
+		return nil, err
+	}
+	tx := &domain.Transaction{
+		UserID:           user.ID,
+		Type:             "WITHDRAW",
+		Amount:           0,
+		OldBalance:       user.Balance,
+		NewBalance:       user.Balance,
+		Status:           "COMPLETED",
+		ProviderTxID:     in.ProviderTxID,
+		ProviderRoundID:  in.RoundID,
+		ProviderGameID:   in.GameID,
+		ProviderID:       in.ProviderID,
+		FreeRoundGrantID: &grant.ID,
+		PlatformResponse: platformResponse(ctx, domain.PlatformRecord{RoundDetails: in.RoundDetails}),
+		Currency:         booking.Currency,
+		FX:               booking.FX,
+		CreatedAt:        time.Now(),
+	}
+	err = uc.db.WithContext(ctx).Transaction(func(txDb *gorm.DB) error {
+		if err := repository.NewFreeRoundRepository(txDb).Consume(ctx, grant.ID, tx.CreatedAt); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNoFreeRounds
+			}
+			return err
+		}
+		return repository.NewTransactionRepository(txDb).Create(ctx, tx)
+	})
+	if err != nil {
+		log.Printf("Withdraw: free round for user %d failed: %v", user.ID, err)
+		return nil, err
+	}
+	log.Printf("Withdraw: free round of %.2f from grant %d for user %d", in.Amount, grant.ID, user.ID)
 	return tx, nil
 }
 
-func (uc *walletUseCase) Deposit(userID uint, amount float64, currency, providerTxID, providerParentTxID string) (*domain.Transaction, error) {
-	user, err := uc.userRepo.FindByID(userID)
+func (uc *walletUseCase) Deposit(ctx context.Context, in DepositInput) (result *domain.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "WalletUseCase.Deposit", trace.WithAttributes(
+		attribute.Int("user.id", int(in.UserID)),
+		attribute.String("provider.tx_id", in.ProviderTxID),
+	))
+	defer func() { infrastructure.EndSpan(span, err) }()
+	if err := uc.tracker.Begin(); err != nil {
+		return nil, err
+	}
+	defer uc.tracker.Done()
+	err = uc.serialize(ctx, in.UserID, func(ctx context.Context, locked *walletUseCase) error {
+		result, err = locked.deposit(ctx, in)
+		return err
+	})
+	return result, err
+}
+
+// deposit settles a stake while the player's lock is held.
+func (uc *walletUseCase) deposit(ctx context.Context, in DepositInput) (*domain.Transaction, error) {
+	userID, amount, providerTxID, providerParentTxID := in.UserID, in.Amount, in.ProviderTxID, in.ProviderParentTxID
+	if amount < 0 {
+		return nil, ErrInvalidAmount
+	}
+	user, err := uc.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		log.Printf("Deposit: failed to find user: %v", err)
 		return nil, err
 	}
+	// Settle against the stake's round so the round's payout limit applies.
+	var stake *domain.Transaction
+	var roundID, gameID, providerID string
+	if parent, err := uc.transactionRepo.FindByProviderTxID(ctx, providerParentTxID); err == nil && parent.UserID == userID {
+		stake = parent
+		roundID, gameID, providerID = parent.ProviderRoundID, parent.ProviderGameID, parent.ProviderID
+	}
+	if in.FreeRound && (stake == nil || stake.FreeRoundGrantID == nil) {
+		return nil, ErrNotFreeRound
+	}
+	if in.JackpotID != 0 {
+		return uc.depositJackpot(ctx, user, in, stake)
+	}
+	// A win is credited to the balance its stake was taken from.
+	var balanceCurrency string
+	if stake != nil {
+		balanceCurrency = stake.Currency
+	}
+	booking, err := uc.currencies.Book(ctx, user, in.Currency, amount, balanceCurrency)
+	if err != nil {
+		return nil, err
+	}
+	amount = booking.Amount
+	rule, err := uc.rules.Resolve(ctx, gameID, providerID, booking.Currency)
+	if err != nil {
+		return nil, err
+	}
+	var roundWins float64
+	if roundID != "" {
+		if roundWins, err = uc.transactionRepo.SumRoundWins(ctx, userID, roundID); err != nil {
+			return nil, err
+		}
+	}
+	if exceedsMaxWin(rule, roundWins, amount) {
+		held := &domain.Transaction{
+			UserID:             userID,
+			Type:               "DEPOSIT",
+			Amount:             amount,
+			OldBalance:         booking.Balance,
+			NewBalance:         booking.Balance,
+			Status:             domain.TransactionStatusHeld,
+			ProviderTxID:       providerTxID,
+			ProviderParentTxID: providerParentTxID,
+			ProviderRoundID:    roundID,
+			ProviderGameID:     gameID,
+			ProviderID:         providerID,
+			PlatformResponse:   platformResponse(ctx, domain.PlatformRecord{RoundDetails: in.RoundDetails}),
+			Currency:           booking.Currency,
+			FX:                 booking.FX,
+			CreatedAt:          time.Now(),
+		}
+		if stake != nil {
+			held.FreeRoundGrantID = stake.FreeRoundGrantID
+		}
+		if err := uc.transactionRepo.Create(context.WithoutCancel(ctx), held); err != nil {
+			log.Printf("Deposit: failed to record held win: %v", err)
+			return nil, err
+		}
+		log.Printf("Deposit: win %.2f for user %d held for review (round %q limit %.2f)", amount, userID, roundID, rule.MaxWinPerRound)
+		return held, nil
+	}
+	var bonusWin float64
+	var bonusID *uint
+	if booking.Primary {
+		if bonusWin, bonusID, err = uc.bonusPart(ctx, user, stake, amount); err != nil {
+			return nil, err
+		}
+	}
+	realWin := amount - bonusWin
 	walletID, err := strconv.ParseInt(user.WalletID, 10, 64)
 	if err != nil {
 		log.Printf("Deposit: invalid wallet ID: %v", err)
 		return nil, err
 	}
 	depositReq := infrastructure.WalletDepositRequest{
-		Currency: user.Currency,
+		Currency: booking.Currency,
 		Transactions: []struct {
 			Amount    float64 `json:"amount"`
 			BetID     int     `json:"betId"`
 			Reference string  `json:"reference"`
 		}{
 			{
-				Amount:    amount,
+				Amount:    realWin,
 				BetID:     0,
 				Reference: providerTxID,
 			},
 		},
 		UserID: walletID,
 	}
-	_, err = uc.walletClient.Deposit(depositReq)
-	if err != nil {
-		log.Printf("Deposit: external wallet error: %v", err)
-		return nil, err
+	// Losses are still reported to the wallet; a win paid entirely into the
+	// bonus balance is not.
+	if realWin > 0 || amount == 0 {
+		err = uc.walletDeposit(ctx, depositReq)
+		if err != nil {
+			log.Printf("Deposit: external wallet error: %v", err)
+			if errors.Is(err, infrastructure.ErrCircuitOpen) {
+				return nil, ErrWalletServiceUnavailable
+			}
+			return nil, err
+		}
 	}
-	oldBalance := user.Balance
-	newBalance := oldBalance + amount
+	oldBalance := booking.Balance
+	newBalance := oldBalance + realWin
 	status := "WON"
 	if amount == 0 {
 		status = "LOST"
@@ -141,108 +484,616 @@ func (uc *walletUseCase) Deposit(userID uint, amount float64, currency, provider
 		UserID:             userID,
 		Type:               "DEPOSIT",
 		Amount:             amount,
+		BonusAmount:        bonusWin,
 		OldBalance:         oldBalance,
 		NewBalance:         newBalance,
 		Status:             status,
 		ProviderTxID:       providerTxID,
 		ProviderParentTxID: providerParentTxID,
-		PlatformResponse:   "{}",
+		ProviderRoundID:    roundID,
+		ProviderGameID:     gameID,
+		ProviderID:         providerID,
+		PlatformResponse:   platformResponse(ctx, domain.PlatformRecord{RoundDetails: in.RoundDetails}),
+		Currency:           booking.Currency,
+		FX:                 booking.FX,
 		CreatedAt:          time.Now(),
 	}
-	err = uc.db.Transaction(func(txDb *gorm.DB) error {
-		if err := repository.NewTransactionRepository(txDb).Create(tx); err != nil {
+	if bonusWin > 0 {
+		tx.PlayerBonusID = bonusID
+	}
+	if stake != nil {
+		tx.FreeRoundGrantID = stake.FreeRoundGrantID
+	}
+	ctx = context.WithoutCancel(ctx)
+	err = uc.db.WithContext(ctx).Transaction(func(txDb *gorm.DB) error {
+		if err := repository.NewTransactionRepository(txDb).Create(ctx, tx); err != nil {
 			log.Printf("Deposit: failed to create transaction: %v", err)
 			return err
 		}
-		if err := repository.NewUserRepository(txDb).UpdateBalance(user, newBalance); err != nil {
+		if err := updateBalance(ctx, txDb, user, booking, newBalance); err != nil {
 			log.Printf("Deposit: failed to update balance: %v", err)
 			return err
 		}
+		users := repository.NewUserRepository(txDb)
+		if bonusWin > 0 {
+			if err := users.AddBonusBalance(ctx, user.ID, bonusWin); err != nil {
+				log.Printf("Deposit: failed to update bonus balance: %v", err)
+				return err
+			}
+		}
 		return nil
 	})
 	if err != nil {
 		log.Printf("Deposit: db transaction error: %v", err)
 		return nil, err
 	}
-	log.Printf("Deposit: success for user %d, amount %.2f", userID, amount)
+	log.Printf("Deposit: success for user %d, amount %.2f %s", userID, amount, booking.Currency)
 	return tx, nil
 }
 
-func (uc *walletUseCase) Cancel(userID uint, providerTxID string) (*domain.Transaction, error) {
-	originalTx, err := uc.transactionRepo.FindByProviderTxID(providerTxID)
+// depositJackpot pays out a jackpot pool to the stake it settles. The pool
+// is claimed before the wallet call and put back if the payout fails. A
+// retried payout returns the recorded one without claiming the pool again.
+// Jackpot wins are paid in real money and are never held for review.
+func (uc *walletUseCase) depositJackpot(ctx context.Context, user *domain.User, in DepositInput, stake *domain.Transaction) (*domain.Transaction, error) {
+	prior, err := uc.transactionRepo.FindByProviderTxID(ctx, in.ProviderTxID)
+	switch {
+	case err == nil && prior.UserID == user.ID && prior.JackpotPoolID != nil && *prior.JackpotPoolID == in.JackpotID:
+		log.Printf("Deposit: jackpot payout %q already recorded for user %d", in.ProviderTxID, user.ID)
+		return prior, nil
+	case err == nil:
+		return nil, ErrDuplicateTransaction
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+	if stake == nil || stake.Type != "WITHDRAW" || stake.Status == "CANCELLED" {
+		log.Printf("Deposit: jackpot %d for user %d does not settle a stake", in.JackpotID, user.ID)
+		return nil, ErrJackpotNotQualified
+	}
+	walletID, err := strconv.ParseInt(user.WalletID, 10, 64)
 	if err != nil {
-		log.Printf("Cancel: original transaction not found: %v", err)
-		return nil, errors.New("original transaction not found")
+		log.Printf("Deposit: invalid wallet ID: %v", err)
+		return nil, err
 	}
-	user, err := uc.userRepo.FindByID(userID)
+	won, err := uc.jackpots.Claim(ctx, in.JackpotID, stake.ProviderGameID, stake.Currency)
 	if err != nil {
-		log.Printf("Cancel: failed to find user: %v", err)
+		log.Printf("Deposit: cannot claim jackpot %d for user %d: %v", in.JackpotID, user.ID, err)
 		return nil, err
 	}
-	walletID, err := strconv.ParseInt(user.WalletID, 10, 64)
+	// The win is paid into the balance the stake came from.
+	booking, err := uc.currencies.Book(ctx, user, stake.Currency, won, stake.Currency)
 	if err != nil {
-		log.Printf("Cancel: invalid wallet ID: %v", err)
+		if err := uc.jackpots.Unclaim(context.WithoutCancel(ctx), in.JackpotID, won); err != nil {
+			return nil, err
+		}
 		return nil, err
 	}
-	cancelAmount := originalTx.Amount
-	cancelReq := infrastructure.WalletDepositRequest{
-		Currency: user.Currency,
+	depositReq := infrastructure.WalletDepositRequest{
+		Currency: booking.Currency,
 		Transactions: []struct {
 			Amount    float64 `json:"amount"`
 			BetID     int     `json:"betId"`
 			Reference string  `json:"reference"`
 		}{
 			{
-				Amount:    cancelAmount,
+				Amount:    won,
 				BetID:     0,
-				Reference: "cancel-" + providerTxID,
+				Reference: in.ProviderTxID,
 			},
 		},
 		UserID: walletID,
 	}
-	_, err = uc.walletClient.Deposit(cancelReq)
+	if err := uc.walletDeposit(ctx, depositReq); err != nil {
+		log.Printf("Deposit: external wallet error on jackpot payout: %v", err)
+		if err := uc.jackpots.Unclaim(context.WithoutCancel(ctx), in.JackpotID, won); err != nil {
+			return nil, err
+		}
+		if errors.Is(err, infrastructure.ErrCircuitOpen) {
+			return nil, ErrWalletServiceUnavailable
+		}
+		return nil, err
+	}
+	tx := &domain.Transaction{
+		UserID:             user.ID,
+		Type:               "DEPOSIT",
+		Amount:             won,
+		OldBalance:         booking.Balance,
+		NewBalance:         booking.Balance + won,
+		Status:             "WON",
+		ProviderTxID:       in.ProviderTxID,
+		ProviderParentTxID: in.ProviderParentTxID,
+		ProviderRoundID:    stake.ProviderRoundID,
+		ProviderGameID:     stake.ProviderGameID,
+		ProviderID:         stake.ProviderID,
+		JackpotPoolID:      &in.JackpotID,
+		PlatformResponse:   platformResponse(ctx, domain.PlatformRecord{RoundDetails: in.RoundDetails}),
+		Currency:           booking.Currency,
+		FX:                 booking.FX,
+		CreatedAt:          time.Now(),
+	}
+	ctx = context.WithoutCancel(ctx)
+	err = uc.db.WithContext(ctx).Transaction(func(txDb *gorm.DB) error {
+		if err := repository.NewTransactionRepository(txDb).Create(ctx, tx); err != nil {
+			log.Printf("Deposit: failed to create transaction: %v", err)
+			return err
+		}
+		if err := updateBalance(ctx, txDb, user, booking, tx.NewBalance); err != nil {
+			log.Printf("Deposit: failed to update balance: %v", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Deposit: db transaction error: %v", err)
+		return nil, err
+	}
+	log.Printf("Deposit: jackpot %d of %.2f paid to user %d", in.JackpotID, won, user.ID)
+	return tx, nil
+}
+
+func (uc *walletUseCase) Cancel(ctx context.Context, in CancelInput) (result *domain.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "WalletUseCase.Cancel", trace.WithAttributes(
+		attribute.Int("user.id", int(in.UserID)),
+		attribute.String("provider.tx_id", in.ProviderTxID),
+	))
+	defer func() { infrastructure.EndSpan(span, err) }()
+	if err := uc.tracker.Begin(); err != nil {
+		return nil, err
+	}
+	defer uc.tracker.Done()
+	err = uc.serialize(ctx, in.UserID, func(ctx context.Context, locked *walletUseCase) error {
+		result, err = locked.cancel(ctx, in)
+		return err
+	})
+	return result, err
+}
+
+// cancel reverses a transaction while the player's lock is held.
+func (uc *walletUseCase) cancel(ctx context.Context, in CancelInput) (*domain.Transaction, error) {
+	userID, providerTxID, reference := in.UserID, in.ProviderTxID, in.reference()
+	if in.Amount < 0 {
+		return nil, ErrInvalidAmount
+	}
+	originalTx, err := uc.transactionRepo.FindByProviderTxID(ctx, providerTxID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Providers cancel stakes that timed out on their side, which may
+		// never arrive or arrive late. Either way the cancel succeeds.
+		return uc.tombstone(ctx, in)
+	}
 	if err != nil {
-		log.Printf("Cancel: external wallet error: %v", err)
+		log.Printf("Cancel: failed to find original transaction: %v", err)
 		return nil, err
 	}
 	if originalTx.UserID != userID {
 		log.Printf("Cancel: transaction does not belong to user %d", userID)
 		return nil, errors.New("transaction does not belong to user")
 	}
-	if originalTx.Status == "CANCELLED" {
+	if cancelled, err := uc.transactionRepo.FindByProviderTxID(ctx, reference); err == nil && cancelled.UserID == userID {
+		// A repeated cancel gets the same answer.
+		log.Printf("Cancel: %q already recorded for user %d", reference, userID)
+		return cancelled, nil
+	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if originalTx.Type != "WITHDRAW" && originalTx.Type != "DEPOSIT" {
+		return nil, &ValidationError{Msg: "only stakes and wins can be cancelled"}
+	}
+	if originalTx.Status == "CANCELLED" || originalTx.Status == domain.TransactionStatusRejected {
 		log.Printf("Cancel: transaction already cancelled for user %d", userID)
-		return nil, errors.New("transaction already cancelled")
+		return nil, ErrAlreadyCancelled
+	}
+	held := originalTx.Status == domain.TransactionStatusHeld
+	if held && in.Amount > 0 {
+		return nil, &ValidationError{Msg: "a held win can only be cancelled whole"}
+	}
+	amount, err := cancelAmount(originalTx, in.Amount)
+	if err != nil {
+		return nil, err
+	}
+	fully := amount >= originalTx.Amount-originalTx.CancelledAmount
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		log.Printf("Cancel: failed to find user: %v", err)
+		return nil, err
+	}
+	walletID, err := strconv.ParseInt(user.WalletID, 10, 64)
+	if err != nil {
+		log.Printf("Cancel: invalid wallet ID: %v", err)
+		return nil, err
+	}
+	// Reverse exactly what was booked, on the balance it was booked on.
+	booking, err := uc.currencies.Book(ctx, user, originalTx.Currency, amount, originalTx.Currency)
+	if err != nil {
+		return nil, err
+	}
+	// A held win never reached the balance, so nothing moves.
+	var bonusPart float64
+	var bonusID *uint
+	if booking.Primary && !held {
+		if bonusPart, bonusID, err = uc.bonusPart(ctx, user, originalTx, amount); err != nil {
+			return nil, err
+		}
+	}
+	refund := originalTx.Type == "WITHDRAW"
+	if !refund && bonusPart > user.BonusBalance {
+		// Bonus funds already wagered away are clawed back as real money.
+		bonusPart = user.BonusBalance
+	}
+	realPart := amount - bonusPart
+	if held {
+		realPart = 0
+	}
+	lines := []walletLine{{Amount: realPart, BetID: 0, Reference: reference}}
+	oldBalance := booking.Balance
+	newBalance := oldBalance + realPart
+	if !refund {
+		newBalance = oldBalance - realPart
+		if newBalance < 0 {
+			log.Printf("Cancel: insufficient funds to claw back %.2f from user %d", realPart, userID)
+			return nil, ErrInsufficientFunds
+		}
+	}
+	if realPart > 0 {
+		if refund {
+			err = uc.walletDeposit(ctx, infrastructure.WalletDepositRequest{Currency: booking.Currency, Transactions: lines, UserID: walletID})
+		} else {
+			err = uc.walletWithdraw(ctx, infrastructure.WalletWithdrawRequest{Currency: booking.Currency, Transactions: lines, UserID: walletID})
+		}
+		if err != nil {
+			log.Printf("Cancel: external wallet error: %v", err)
+			if errors.Is(err, infrastructure.ErrCircuitOpen) {
+				return nil, ErrWalletServiceUnavailable
+			}
+			return nil, err
+		}
 	}
-	oldBalance := user.Balance
-	newBalance := oldBalance + originalTx.Amount
 	cancelTx := &domain.Transaction{
 		UserID:             userID,
 		Type:               "CANCEL",
-		Amount:             originalTx.Amount,
+		Amount:             amount,
+		BonusAmount:        bonusPart,
 		OldBalance:         oldBalance,
 		NewBalance:         newBalance,
 		Status:             "CANCELLED",
-		ProviderTxID:       "cancel-" + providerTxID,
+		ProviderTxID:       reference,
 		ProviderParentTxID: providerTxID,
-		PlatformResponse:   "{}",
+		PlatformResponse:   platformResponse(ctx, domain.PlatformRecord{}),
+		Currency:           booking.Currency,
+		FX:                 booking.FX,
 		CreatedAt:          time.Now(),
 	}
-	err = uc.db.Transaction(func(txDb *gorm.DB) error {
-		if err := repository.NewTransactionRepository(txDb).Create(cancelTx); err != nil {
+	if !refund {
+		cancelTx.Type = "CLAWBACK"
+	}
+	if held {
+		cancelTx.Amount = 0
+	}
+	if bonusPart > 0 {
+		cancelTx.PlayerBonusID = bonusID
+	}
+	ctx = context.WithoutCancel(ctx)
+	err = uc.db.WithContext(ctx).Transaction(func(txDb *gorm.DB) error {
+		if err := repository.NewTransactionRepository(txDb).Create(ctx, cancelTx); err != nil {
 			log.Printf("Cancel: failed to create transaction: %v", err)
 			return err
 		}
-		if err := repository.NewUserRepository(txDb).UpdateBalance(user, newBalance); err != nil {
+		if err := updateBalance(ctx, txDb, user, booking, newBalance); err != nil {
 			log.Printf("Cancel: failed to update balance: %v", err)
 			return err
 		}
-		originalTx.Status = "CANCELLED"
+		users := repository.NewUserRepository(txDb)
+		if bonusPart > 0 {
+			delta := bonusPart
+			if !refund {
+				delta = -bonusPart
+			}
+			if err := users.AddBonusBalance(ctx, user.ID, delta); err != nil {
+				log.Printf("Cancel: failed to update bonus balance: %v", err)
+				return err
+			}
+		}
+		// A cancelled free-round stake gives the round back.
+		if fully && originalTx.Type == "WITHDRAW" && originalTx.FreeRoundGrantID != nil {
+			if err := repository.NewFreeRoundRepository(txDb).Restore(ctx, *originalTx.FreeRoundGrantID); err != nil {
+				log.Printf("Cancel: failed to restore free round: %v", err)
+				return err
+			}
+		}
+		originalTx.CancelledAmount += cancelTx.Amount
+		if fully {
+			originalTx.Status = "CANCELLED"
+		}
 		return txDb.Save(originalTx).Error
 	})
 	if err != nil {
 		log.Printf("Cancel: db transaction error: %v", err)
 		return nil, err
 	}
-	log.Printf("Cancel: success for user %d, amount %.2f", userID, cancelAmount)
+	log.Printf("Cancel: success for user %d, %s %.2f of %q", userID, strings.ToLower(cancelTx.Type), amount, providerTxID)
 	return cancelTx, nil
 }
+
+// voidStake answers a stake that was cancelled before it arrived. Nothing
+// is booked or sent to the wallet; the cancel is returned with the balance
+// as it is now.
+func (uc *walletUseCase) voidStake(ctx context.Context, cancel *domain.Transaction) (*domain.Transaction, error) {
+	user, err := uc.userRepo.FindByID(ctx, cancel.UserID)
+	if err != nil {
+		log.Printf("Withdraw: failed to find user: %v", err)
+		return nil, err
+	}
+	booking, err := uc.currencies.Book(ctx, user, cancel.Currency, 0, cancel.Currency)
+	if err != nil {
+		return nil, err
+	}
+	void := *cancel
+	void.OldBalance, void.NewBalance = booking.Balance, booking.Balance
+	log.Printf("Withdraw: %q was cancelled before it arrived for user %d, ignored", cancel.ProviderParentTxID, user.ID)
+	return &void, nil
+}
+
+// cancelAmount converts amount, in the currency the transaction was played
+// in, to the balance it was booked on, and checks it against what is left
+// to cancel. Zero cancels all that is left.
+func cancelAmount(tx *domain.Transaction, amount float64) (float64, error) {
+	left := tx.Amount - tx.CancelledAmount
+	if amount == 0 {
+		return left, nil
+	}
+	if tx.FX.GameAmount > 0 {
+		amount = roundAmount(amount * tx.Amount / tx.FX.GameAmount)
+	}
+	switch {
+	case amount > left+0.005:
+		return 0, ErrCancelExceedsAmount
+	case amount > left-0.005:
+		// What rounding left over goes with the last part.
+		return left, nil
+	}
+	return amount, nil
+}
+
+// tombstone records a cancel of a transaction that has not arrived, keyed on
+// its provider transaction ID, so that it is void if it does. A replay of an
+// existing cancel was answered by Cancel already.
+func (uc *walletUseCase) tombstone(ctx context.Context, in CancelInput) (*domain.Transaction, error) {
+	user, err := uc.userRepo.FindByID(ctx, in.UserID)
+	if err != nil {
+		log.Printf("Cancel: failed to find user: %v", err)
+		return nil, err
+	}
+	if cancel, err := uc.transactionRepo.FindCancel(ctx, user.ID, in.ProviderTxID); err != nil || cancel != nil {
+		// Already void; a partial cancel under a new ID changes nothing.
+		return cancel, err
+	}
+	tx := &domain.Transaction{
+		UserID:             user.ID,
+		Type:               "CANCEL",
+		Amount:             0,
+		OldBalance:         user.Balance,
+		NewBalance:         user.Balance,
+		Status:             "CANCELLED",
+		ProviderTxID:       in.reference(),
+		ProviderParentTxID: in.ProviderTxID,
+		PlatformResponse:   platformResponse(ctx, domain.PlatformRecord{}),
+		Currency:           user.Currency,
+		CreatedAt:          time.Now(),
+	}
+	if err := uc.transactionRepo.Create(context.WithoutCancel(ctx), tx); err != nil {
+		log.Printf("Cancel: failed to record tombstone: %v", err)
+		return nil, err
+	}
+	log.Printf("Cancel: %q not found for user %d, tombstone recorded", in.ProviderTxID, user.ID)
+	return tx, nil
+}
+
+// updateBalance moves the balance a booking was made on to newBalance. It
+// is written as an increment, so that no write can be lost even if the
+// balance changed since it was booked.
+func updateBalance(ctx context.Context, txDb *gorm.DB, user *domain.User, booking *Booking, newBalance float64) error {
+	delta := newBalance - booking.Balance
+	if booking.Primary {
+		return repository.NewUserRepository(txDb).AddBalance(ctx, user.ID, delta)
+	}
+	return repository.NewBalanceRepository(txDb).AddBalance(ctx, user.ID, booking.Currency, delta)
+}
+
+// serialize runs fn holding the lock on the player's row, with a copy of uc
+// that reads and writes through the transaction holding it, so operations
+// on one player run one at a time across all instances. An operation called
+// from one that already holds the lock runs inside it. The lock and its
+// connection are held across the wallet call, so an instance completes at
+// most len(lockSlots) operations per wallet round trip.
+//
+// Once the wallet has been called, the local commit must not be abandoned if
+// the caller disconnects, or the wallet and the ledger disagree. The lock's
+// transaction, and every commit made after a wallet call, therefore runs on
+// context.WithoutCancel(ctx).
+func (uc *walletUseCase) serialize(ctx context.Context, userID uint, fn func(ctx context.Context, locked *walletUseCase) error) error {
+	if l := lockFrom(ctx); l != nil && l.userID == userID {
+		return fn(ctx, uc)
+	}
+	if uc.lockSlots != nil {
+		select {
+		case uc.lockSlots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		defer func() { <-uc.lockSlots }()
+	}
+	l := &userLock{userID: userID}
+	err := uc.db.WithContext(context.WithoutCancel(ctx)).Transaction(func(txDb *gorm.DB) error {
+		if _, err := repository.NewUserRepository(txDb).FindForUpdate(ctx, userID); err != nil {
+			return err
+		}
+		l.db = txDb
+		return fn(context.WithValue(ctx, userLockKey{}, l), uc.bound(txDb))
+	})
+	if err == nil && l.settle {
+		// The stake is placed; the release is retried on the next one.
+		if err := uc.bonuses.SettleWagering(context.WithoutCancel(ctx), userID); err != nil {
+			log.Printf("Withdraw: failed to settle bonus wagering for user %d: %v", userID, err)
+		}
+	}
+	return err
+}
+
+// bound returns a copy of uc that reads and writes through txDb.
+func (uc *walletUseCase) bound(txDb *gorm.DB) *walletUseCase {
+	b := *uc
+	b.userRepo = repository.NewUserRepository(txDb)
+	b.transactionRepo = repository.NewTransactionRepository(txDb)
+	b.db = txDb
+	b.currencies = &txCurrencies{CurrencyUseCase: uc.currencies, balanceRepo: repository.NewBalanceRepository(txDb)}
+	return &b
+}
+
+// bonusPart returns the share of amount that belongs to the bonus balance
+// and the bonus it is credited to: the same share the stake drew from bonus
+// funds, or all of it for a free round whose wins go to a bonus. If that
+// bonus is no longer active, it is all real money.
+func (uc *walletUseCase) bonusPart(ctx context.Context, user *domain.User, stake *domain.Transaction, amount float64) (float64, *uint, error) {
+	if stake == nil {
+		return 0, nil, nil
+	}
+	var bonusID *uint
+	var share float64
+	switch {
+	case stake.FreeRoundGrantID != nil:
+		grant, err := uc.freeRounds.FindGrant(ctx, *stake.FreeRoundGrantID)
+		if err != nil {
+			return 0, nil, err
+		}
+		if grant.WinTo == domain.FreeRoundsWinToBonus {
+			bonusID, share = grant.PlayerBonusID, 1
+		}
+	case stake.BonusAmount > 0 && stake.Amount > 0:
+		bonusID, share = stake.PlayerBonusID, stake.BonusAmount/stake.Amount
+	}
+	if bonusID == nil || share == 0 {
+		return 0, nil, nil
+	}
+	bonus, err := uc.bonuses.ActiveBonus(ctx, user)
+	if err != nil || bonus == nil || bonus.ID != *bonusID {
+		return 0, nil, err
+	}
+	return amount * share, bonusID, nil
+}
+
+func (uc *walletUseCase) ListHeldWins(ctx context.Context) ([]domain.Transaction, error) {
+	return uc.transactionRepo.ListByStatus(ctx, domain.TransactionStatusHeld)
+}
+
+func (uc *walletUseCase) ReviewHeldWin(ctx context.Context, txID uint, approve bool, reviewer string) (result *domain.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "WalletUseCase.ReviewHeldWin", trace.WithAttributes(
+		attribute.Int("transaction.id", int(txID)),
+		attribute.Bool("approve", approve),
+	))
+	defer func() { infrastructure.EndSpan(span, err) }()
+	if err := uc.tracker.Begin(); err != nil {
+		return nil, err
+	}
+	defer uc.tracker.Done()
+
+	held, err := uc.transactionRepo.FindByID(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	err = uc.serialize(ctx, held.UserID, func(ctx context.Context, locked *walletUseCase) error {
+		result, err = locked.reviewHeldWin(ctx, txID, approve, reviewer)
+		return err
+	})
+	return result, err
+}
+
+// reviewHeldWin credits or rejects a held win while the player's lock is
+// held.
+func (uc *walletUseCase) reviewHeldWin(ctx context.Context, txID uint, approve bool, reviewer string) (*domain.Transaction, error) {
+	held, err := uc.transactionRepo.FindByID(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	if held.Status != domain.TransactionStatusHeld {
+		return nil, ErrNotHeld
+	}
+	now := time.Now()
+	held.ReviewedBy, held.ReviewedAt = reviewer, &now
+	if !approve {
+		held.Status = domain.TransactionStatusRejected
+		if err := uc.transactionRepo.UpdateStatus(ctx, held, domain.TransactionStatusHeld); err != nil {
+			return nil, err
+		}
+		log.Printf("ReviewHeldWin: win %d rejected by %s", txID, reviewer)
+		return held, nil
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, held.UserID)
+	if err != nil {
+		return nil, err
+	}
+	walletID, err := strconv.ParseInt(user.WalletID, 10, 64)
+	if err != nil {
+		log.Printf("ReviewHeldWin: invalid wallet ID: %v", err)
+		return nil, err
+	}
+	booking, err := uc.currencies.Book(ctx, user, held.Currency, held.Amount, held.Currency)
+	if err != nil {
+		return nil, err
+	}
+	depositReq := infrastructure.WalletDepositRequest{
+		Currency: booking.Currency,
+		Transactions: []struct {
+			Amount    float64 `json:"amount"`
+			BetID     int     `json:"betId"`
+			Reference string  `json:"reference"`
+		}{
+			{
+				Amount:    held.Amount,
+				BetID:     0,
+				Reference: held.ProviderTxID,
+			},
+		},
+		UserID: walletID,
+	}
+	err = uc.walletDeposit(ctx, depositReq)
+	if err != nil {
+		log.Printf("ReviewHeldWin: external wallet error: %v", err)
+		if errors.Is(err, infrastructure.ErrCircuitOpen) {
+			return nil, ErrWalletServiceUnavailable
+		}
+		return nil, err
+	}
+	held.Status = "WON"
+	held.OldBalance = booking.Balance
+	held.NewBalance = booking.Balance + held.Amount
+	ctx = context.WithoutCancel(ctx)
+	err = uc.db.WithContext(ctx).Transaction(func(txDb *gorm.DB) error {
+		if err := repository.NewTransactionRepository(txDb).UpdateStatus(ctx, held, domain.TransactionStatusHeld); err != nil {
+			log.Printf("ReviewHeldWin: failed to update transaction: %v", err)
+			return err
+		}
+		if err := updateBalance(ctx, txDb, user, booking, held.NewBalance); err != nil {
+			log.Printf("ReviewHeldWin: failed to update balance: %v", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("ReviewHeldWin: db transaction error: %v", err)
+		return nil, err
+	}
+	log.Printf("ReviewHeldWin: win %d of %.2f approved by %s", txID, held.Amount, reviewer)
+	return held, nil
+}
+
+// platformResponse encodes rec for Transaction.PlatformResponse, with the
+// provider call being served, if it is recorded.
+func platformResponse(ctx context.Context, rec domain.PlatformRecord) string {
+	rec.Exchange = infrastructure.ExchangeFrom(ctx).Attach()
+	data, err := json.Marshal(rec)
+	if err != nil {
+		log.Printf("platformResponse: %v", err)
+		return "{}"
+	}
+	return string(data)
+}
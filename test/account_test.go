@@ -0,0 +1,184 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAccountUseCase struct{}
+
+func (m *mockAccountUseCase) Register(ctx context.Context, in usecase.RegisterInput) (*domain.User, error) {
+	if in.Username == "taken" {
+		return nil, usecase.ErrUsernameTaken
+	}
+	if len(in.Password) < 8 {
+		return nil, &usecase.ValidationError{Msg: "password must be between 8 and 72 characters"}
+	}
+	return &domain.User{ID: 7, Username: in.Username, Currency: in.Currency}, nil
+}
+
+func (m *mockAccountUseCase) ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) error {
+	return nil
+}
+
+func (m *mockAccountUseCase) RequestPasswordReset(ctx context.Context, username string) error {
+	return nil
+}
+
+func (m *mockAccountUseCase) ResetPassword(ctx context.Context, token, newPassword string) error {
+	return usecase.ErrInvalidResetToken
+}
+
+func postJSON(r *gin.Engine, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+	b, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", path, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRegisterMapsErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{AccountUseCase: &mockAccountUseCase{}}
+	r := gin.New()
+	r.POST("/auth/register", h.Register)
+
+	valid := map[string]interface{}{
+		"username":     "newplayer",
+		"password":     "s3cretpass",
+		"currency":     "USD",
+		"wallet_id":    "34633089486",
+		"wallet_token": "1767225600.5d41402abc4b2a76b9719d911017c592",
+	}
+	assert.Equal(t, 201, postJSON(r, "/auth/register", valid).Code)
+
+	taken := map[string]interface{}{"username": "taken", "password": "s3cretpass", "currency": "USD", "wallet_id": "1", "wallet_token": "t"}
+	assert.Equal(t, 409, postJSON(r, "/auth/register", taken).Code)
+
+	weak := map[string]interface{}{"username": "weak", "password": "short", "currency": "USD", "wallet_id": "1", "wallet_token": "t"}
+	w := postJSON(r, "/auth/register", weak)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "password")
+}
+
+func TestConfirmPasswordResetRejectsInvalidToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{AccountUseCase: &mockAccountUseCase{}}
+	r := gin.New()
+	r.POST("/auth/password-reset/confirm", h.ConfirmPasswordReset)
+
+	w := postJSON(r, "/auth/password-reset/confirm", map[string]interface{}{"token": "nope", "new_password": "n3wpassword"})
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "reset token")
+}
+
+// registeredUserRepository holds the users created through Register.
+type registeredUserRepository struct {
+	repository.UserRepository
+	users []domain.User
+}
+
+func (m *registeredUserRepository) ExistsByUsernameOrWalletID(ctx context.Context, username, walletID string) (bool, bool, error) {
+	var usernameTaken, walletTaken bool
+	for _, u := range m.users {
+		usernameTaken = usernameTaken || u.Username == username
+		walletTaken = walletTaken || u.WalletID == walletID
+	}
+	return usernameTaken, walletTaken, nil
+}
+
+func (m *registeredUserRepository) Create(ctx context.Context, user *domain.User) error {
+	user.ID = uint(len(m.users) + 1)
+	m.users = append(m.users, *user)
+	return nil
+}
+
+const linkSecret = "link-secret"
+
+func registerAccounts(t *testing.T, secret string) (usecase.AccountUseCase, *registeredUserRepository) {
+	wallet := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"balance": "25.50", "currency": "USD"}`))
+	}))
+	t.Cleanup(wallet.Close)
+	client := infrastructure.NewWalletClient(infrastructure.WalletConfig{URL: wallet.URL, Timeout: time.Second, BreakerThreshold: 3, BreakerCooldown: time.Second})
+	users := &registeredUserRepository{}
+	cfg := infrastructure.AuthConfig{PasswordMinLength: 8, WalletLinkSecret: secret}
+	return usecase.NewAccountUseCase(users, nil, client, nil, cfg), users
+}
+
+func registration(username, walletID, token string) usecase.RegisterInput {
+	return usecase.RegisterInput{Username: username, Password: "s3cretpass", Currency: "usd", WalletID: walletID, WalletToken: token}
+}
+
+func TestRegisterRequiresWalletLinkToken(t *testing.T) {
+	accounts, users := registerAccounts(t, linkSecret)
+	ctx := context.Background()
+	valid := infrastructure.NewWalletLinkToken(linkSecret, "123", time.Now().Add(time.Hour))
+	tampered := valid[:len(valid)-1] + "0"
+	if tampered == valid {
+		tampered = valid[:len(valid)-1] + "1"
+	}
+
+	for name, token := range map[string]string{
+		"missing":      "",
+		"malformed":    "not-a-token",
+		"other wallet": infrastructure.NewWalletLinkToken(linkSecret, "124", time.Now().Add(time.Hour)),
+		"other secret": infrastructure.NewWalletLinkToken("guessed", "123", time.Now().Add(time.Hour)),
+		"expired":      infrastructure.NewWalletLinkToken(linkSecret, "123", time.Now().Add(-time.Second)),
+		"tampered":     tampered,
+		"later expiry": "9999999999" + valid[strings.Index(valid, "."):],
+	} {
+		_, err := accounts.Register(ctx, registration("player", "123", token))
+		assert.ErrorIs(t, err, usecase.ErrInvalidWallet, name)
+	}
+	assert.Empty(t, users.users)
+
+	user, err := accounts.Register(ctx, registration("player", "123", valid))
+	require.NoError(t, err)
+	assert.Equal(t, "123", user.WalletID)
+	assert.Equal(t, "USD", user.Currency)
+	assert.Equal(t, 25.5, user.Balance)
+}
+
+func TestRegisterNormalisesWalletID(t *testing.T) {
+	accounts, _ := registerAccounts(t, linkSecret)
+	ctx := context.Background()
+	token := infrastructure.NewWalletLinkToken(linkSecret, "123", time.Now().Add(time.Hour))
+
+	_, err := accounts.Register(ctx, registration("player", "123", token))
+	require.NoError(t, err)
+
+	// "0123" is the same wallet, and its token is the one issued for "123".
+	_, err = accounts.Register(ctx, registration("player2", "0123", token))
+	assert.ErrorIs(t, err, usecase.ErrWalletAlreadyLinked)
+
+	var validation *usecase.ValidationError
+	for _, walletID := range []string{"123abc", "12 3", "-123", "0", ""} {
+		_, err = accounts.Register(ctx, registration("player3", walletID, token))
+		assert.ErrorAs(t, err, &validation, walletID)
+	}
+}
+
+func TestRegisterClosedWithoutLinkSecret(t *testing.T) {
+	accounts, _ := registerAccounts(t, "")
+	token := infrastructure.NewWalletLinkToken("", "123", time.Now().Add(time.Hour))
+	_, err := accounts.Register(context.Background(), registration("player", "123", token))
+	assert.ErrorIs(t, err, usecase.ErrRegistrationClosed)
+}
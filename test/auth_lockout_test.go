@@ -0,0 +1,260 @@
+package http_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// memoryAttemptRepository keeps lockouts and audit records in memory.
+type memoryAttemptRepository struct {
+	mu       sync.Mutex
+	lockouts map[string]*domain.LoginLockout
+	attempts []domain.LoginAttempt
+}
+
+func newMemoryAttemptRepository() *memoryAttemptRepository {
+	return &memoryAttemptRepository{lockouts: map[string]*domain.LoginLockout{}}
+}
+
+func (m *memoryAttemptRepository) Record(ctx context.Context, attempt *domain.LoginAttempt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.attempts = append(m.attempts, *attempt)
+	return nil
+}
+
+func (m *memoryAttemptRepository) FindLockout(ctx context.Context, key string) (*domain.LoginLockout, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l, ok := m.lockouts[key]; ok {
+		copied := *l
+		return &copied, nil
+	}
+	return &domain.LoginLockout{Key: key}, nil
+}
+
+func (m *memoryAttemptRepository) UpdateLockout(ctx context.Context, key string, update func(*domain.LoginLockout)) (*domain.LoginLockout, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l, ok := m.lockouts[key]
+	if !ok {
+		l = &domain.LoginLockout{ID: uint(len(m.lockouts) + 1), Key: key}
+		m.lockouts[key] = l
+	}
+	update(l)
+	copied := *l
+	return &copied, nil
+}
+
+func (m *memoryAttemptRepository) ListLockouts(ctx context.Context, lockedAt time.Time) ([]domain.LoginLockout, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var locked []domain.LoginLockout
+	for _, l := range m.lockouts {
+		if l.IsLocked(lockedAt) {
+			locked = append(locked, *l)
+		}
+	}
+	return locked, nil
+}
+
+func (m *memoryAttemptRepository) DeleteLockout(ctx context.Context, id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, l := range m.lockouts {
+		if l.ID == id {
+			delete(m.lockouts, key)
+		}
+	}
+	return nil
+}
+
+func (m *memoryAttemptRepository) ResetLockout(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.lockouts, key)
+	return nil
+}
+
+// lockedFor returns how long key was last locked for.
+func (m *memoryAttemptRepository) lockedFor(key string) time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l, ok := m.lockouts[key]
+	if !ok || l.LockedUntil == nil {
+		return 0
+	}
+	return l.LockedUntil.Sub(l.LastFailureAt)
+}
+
+// passwordUserRepository knows players by username and plain password.
+type passwordUserRepository struct {
+	repository.UserRepository
+	passwords map[string]string
+}
+
+func (m *passwordUserRepository) FindByCredentials(ctx context.Context, username, password string) (*domain.User, error) {
+	want, ok := m.passwords[username]
+	if !ok || want != password {
+		return nil, repository.ErrInvalidCredentials
+	}
+	return &domain.User{ID: 1, Username: username, Role: domain.RolePlayer}, nil
+}
+
+func lockoutAuth(t *testing.T, cfg infrastructure.AuthConfig) (usecase.AuthUseCase, *memoryAttemptRepository) {
+	keys, err := infrastructure.NewEphemeralJWTKeys()
+	require.NoError(t, err)
+	attempts := newMemoryAttemptRepository()
+	users := &passwordUserRepository{passwords: map[string]string{"alice": "right1", "bob": "right2"}}
+	return usecase.NewAuthUseCase(users, attempts, nil, nil, keys, cfg), attempts
+}
+
+func TestLoginLocksUsernameAtThreshold(t *testing.T) {
+	auth, attempts := lockoutAuth(t, infrastructure.AuthConfig{
+		MaxFailedAttempts:      3,
+		MaxFailedAttemptsPerIP: 100,
+		FailureWindow:          time.Hour,
+		LockoutDuration:        time.Minute,
+		MaxLockoutDuration:     time.Hour,
+	})
+	ctx := context.Background()
+
+	// Failures from different addresses all count against the username.
+	for i, ip := range []string{"192.0.2.1", "192.0.2.2"} {
+		_, err := auth.Login(ctx, "alice", "wrong", ip)
+		assert.ErrorIs(t, err, usecase.ErrInvalidCredentials, "attempt %d", i+1)
+	}
+	_, err := auth.Login(ctx, "alice", "right1", "192.0.2.3")
+	require.NoError(t, err, "a success below the threshold resets the count")
+
+	for i := 0; i < 3; i++ {
+		_, err := auth.Login(ctx, "alice", "wrong", "192.0.2.4")
+		assert.ErrorIs(t, err, usecase.ErrInvalidCredentials)
+	}
+	assert.Equal(t, time.Minute, attempts.lockedFor("user:alice"))
+
+	var locked *usecase.AccountLockedError
+	_, err = auth.Login(ctx, "alice", "right1", "192.0.2.5")
+	require.ErrorAs(t, err, &locked)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), locked.Until, 5*time.Second)
+
+	// Other players are not affected.
+	_, err = auth.Login(ctx, "bob", "right2", "192.0.2.5")
+	assert.NoError(t, err)
+}
+
+func TestLoginLockoutDoublesUpToMaximum(t *testing.T) {
+	auth, attempts := lockoutAuth(t, infrastructure.AuthConfig{
+		MaxFailedAttempts:      2,
+		MaxFailedAttemptsPerIP: 100,
+		FailureWindow:          time.Hour,
+		LockoutDuration:        10 * time.Millisecond,
+		MaxLockoutDuration:     30 * time.Millisecond,
+	})
+	ctx := context.Background()
+
+	for _, want := range []time.Duration{0, 10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond, 30 * time.Millisecond} {
+		_, err := auth.Login(ctx, "alice", "wrong", "192.0.2.1")
+		require.ErrorIs(t, err, usecase.ErrInvalidCredentials)
+		assert.Equal(t, want, attempts.lockedFor("user:alice"))
+
+		// Wait out the lockout; failures inside the window keep counting.
+		time.Sleep(want + 5*time.Millisecond)
+	}
+}
+
+func TestLoginFailuresOutsideWindowAreForgotten(t *testing.T) {
+	auth, attempts := lockoutAuth(t, infrastructure.AuthConfig{
+		MaxFailedAttempts:      2,
+		MaxFailedAttemptsPerIP: 100,
+		FailureWindow:          10 * time.Millisecond,
+		LockoutDuration:        time.Minute,
+		MaxLockoutDuration:     time.Hour,
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_, err := auth.Login(ctx, "alice", "wrong", "192.0.2.1")
+		require.ErrorIs(t, err, usecase.ErrInvalidCredentials)
+		time.Sleep(20 * time.Millisecond)
+	}
+	assert.Zero(t, attempts.lockedFor("user:alice"))
+}
+
+func TestLoginLocksClientAddressAcrossUsernames(t *testing.T) {
+	auth, attempts := lockoutAuth(t, infrastructure.AuthConfig{
+		MaxFailedAttempts:      100,
+		MaxFailedAttemptsPerIP: 3,
+		FailureWindow:          time.Hour,
+		LockoutDuration:        time.Minute,
+		MaxLockoutDuration:     time.Hour,
+	})
+	ctx := context.Background()
+
+	for _, username := range []string{"alice", "bob", "carol"} {
+		_, err := auth.Login(ctx, username, "wrong", "192.0.2.1")
+		require.ErrorIs(t, err, usecase.ErrInvalidCredentials)
+	}
+	assert.Equal(t, time.Minute, attempts.lockedFor("ip:192.0.2.1"))
+	assert.Zero(t, attempts.lockedFor("user:alice"))
+
+	var locked *usecase.AccountLockedError
+	_, err := auth.Login(ctx, "bob", "right2", "192.0.2.1")
+	assert.ErrorAs(t, err, &locked)
+
+	// The same players log in from elsewhere.
+	_, err = auth.Login(ctx, "bob", "right2", "198.51.100.1")
+	assert.NoError(t, err)
+}
+
+func TestLoginFailuresAreDelayed(t *testing.T) {
+	auth, _ := lockoutAuth(t, infrastructure.AuthConfig{
+		MaxFailedAttempts:      100,
+		MaxFailedAttemptsPerIP: 100,
+		FailureWindow:          time.Hour,
+		LockoutDuration:        time.Minute,
+		MaxLockoutDuration:     time.Hour,
+		FailureDelay:           20 * time.Millisecond,
+		MaxFailureDelay:        40 * time.Millisecond,
+	})
+	ctx := context.Background()
+
+	for _, want := range []time.Duration{20 * time.Millisecond, 40 * time.Millisecond, 40 * time.Millisecond} {
+		start := time.Now()
+		_, err := auth.Login(ctx, "alice", "wrong", "192.0.2.1")
+		require.ErrorIs(t, err, usecase.ErrInvalidCredentials)
+		assert.GreaterOrEqual(t, time.Since(start), want)
+	}
+}
+
+func TestUnknownUsernameTakesAsLongAsWrongPassword(t *testing.T) {
+	db := concurrencyDB(t)
+	hash, err := bcrypt.GenerateFromPassword([]byte("right1"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	user := concurrencyUser(t, db, 0)
+	require.NoError(t, db.Model(user).Update("password", string(hash)).Error)
+	users := repository.NewUserRepository(db)
+	ctx := context.Background()
+
+	timed := func(username string) time.Duration {
+		start := time.Now()
+		_, err := users.FindByCredentials(ctx, username, "wrong")
+		require.ErrorIs(t, err, repository.ErrInvalidCredentials)
+		return time.Since(start)
+	}
+	known := timed(user.Username)
+	unknown := timed(user.Username + "-missing")
+	// Without a hash comparison the lookup alone takes a fraction of it.
+	assert.Greater(t, unknown, known/2)
+}
@@ -2,11 +2,15 @@ package http_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/usecase"
 
 	"errors"
 
@@ -16,11 +20,32 @@ import (
 
 type mockAuthUseCase struct{}
 
-func (m *mockAuthUseCase) Login(username, password string) (string, error) {
+func (m *mockAuthUseCase) Login(ctx context.Context, username, password, ip string) (*usecase.LoginResult, error) {
+	if username == "locked" {
+		return nil, &usecase.AccountLockedError{Until: time.Now().Add(90 * time.Second)}
+	}
+	if username == "mfauser" && password == "pass" {
+		return &usecase.LoginResult{Username: username, ChallengeToken: "mockchallenge"}, nil
+	}
 	if username == "user" && password == "pass" {
-		return "mocktoken", nil
+		return &usecase.LoginResult{Username: username, Token: "mocktoken"}, nil
+	}
+	return nil, errors.New("invalid credentials")
+}
+
+func (m *mockAuthUseCase) CompleteLogin(ctx context.Context, challengeToken, code, ip string) (*usecase.LoginResult, error) {
+	if challengeToken == "mockchallenge" && code == "123456" {
+		return &usecase.LoginResult{Username: "mfauser", Token: "mocktoken"}, nil
 	}
-	return "", errors.New("invalid credentials")
+	return nil, usecase.ErrInvalidTwoFactorCode
+}
+
+func (m *mockAuthUseCase) ListLockouts(ctx context.Context) ([]domain.LoginLockout, error) {
+	return nil, nil
+}
+
+func (m *mockAuthUseCase) ClearLockout(ctx context.Context, id uint) error {
+	return nil
 }
 
 func TestLoginRejectsExtraFields(t *testing.T) {
@@ -61,3 +86,57 @@ func TestLoginAcceptsValidRequest(t *testing.T) {
 	assert.Equal(t, 200, w.Code)
 	assert.Contains(t, w.Body.String(), "mocktoken")
 }
+
+func TestLoginLockedOutReturns429(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{AuthUseCase: &mockAuthUseCase{}}
+	r := gin.New()
+	r.POST("/auth/login", h.Login)
+
+	body := map[string]interface{}{
+		"username": "locked",
+		"password": "pass",
+	}
+	b, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/auth/login", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, 429, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestLoginWithTwoFactorReturnsChallenge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{AuthUseCase: &mockAuthUseCase{}}
+	r := gin.New()
+	r.POST("/auth/login", h.Login)
+	r.POST("/auth/login/2fa", h.LoginTwoFactor)
+
+	b, _ := json.Marshal(map[string]interface{}{"username": "mfauser", "password": "pass"})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/auth/login", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	var resp httpdelivery.LoginResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.MFARequired)
+	assert.Empty(t, resp.Token)
+	assert.Equal(t, "mockchallenge", resp.ChallengeToken)
+
+	b, _ = json.Marshal(map[string]interface{}{"challenge_token": "mockchallenge", "code": "000000"})
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/auth/login/2fa", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, 401, w.Code)
+
+	b, _ = json.Marshal(map[string]interface{}{"challenge_token": "mockchallenge", "code": "123456"})
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/auth/login/2fa", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), "mocktoken")
+}
@@ -0,0 +1,187 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchWalletUseCase replays items whose provider transaction is "seen",
+// fails stakes above 50 and records the batch it was given.
+type batchWalletUseCase struct {
+	mockWalletUseCase
+	got usecase.BatchInput
+}
+
+func (m *batchWalletUseCase) Batch(ctx context.Context, in usecase.BatchInput) ([]usecase.BatchItemResult, error) {
+	m.got = in
+	results := make([]usecase.BatchItemResult, len(in.Items))
+	for i, item := range in.Items {
+		if item.Op == usecase.BatchWithdraw && item.Withdraw.Amount > 50 {
+			err := &usecase.LimitExceededError{}
+			if in.Atomic {
+				return nil, &usecase.BatchError{Index: i, Err: err}
+			}
+			results[i].Err = err
+			continue
+		}
+		results[i].Transaction = &domain.Transaction{ID: uint(i + 1), ProviderTxID: item.Withdraw.ProviderTxID, Status: "COMPLETED"}
+		results[i].Replayed = item.Withdraw.ProviderTxID == "seen"
+	}
+	return results, nil
+}
+
+func batchRouter(wallet usecase.WalletUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{WalletUseCase: wallet, ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{}}
+	r := gin.New()
+	r.POST("/bet/batch", func(c *gin.Context) {
+		c.Set("userID", uint(7))
+		h.Batch(c)
+	})
+	return r
+}
+
+func TestBatchMapsItems(t *testing.T) {
+	wallet := &batchWalletUseCase{}
+	r := batchRouter(wallet)
+	w := postJSON(r, "/bet/batch", map[string]interface{}{
+		"atomic": true,
+		"items": []map[string]interface{}{
+			{"type": "withdraw", "currency": "USD", "amount": 10, "provider_transaction_id": "w1", "round_id": "r1", "game_id": "g1"},
+			{"type": "deposit", "currency": "USD", "amount": 25, "provider_transaction_id": "d1", "provider_withdrawn_transaction_id": "w1"},
+			{"type": "cancel", "provider_transaction_id": "w0"},
+		},
+	})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, uint(7), wallet.got.UserID)
+	assert.True(t, wallet.got.Atomic)
+	if assert.Len(t, wallet.got.Items, 3) {
+		assert.Equal(t, usecase.BatchWithdraw, wallet.got.Items[0].Op)
+		assert.Equal(t, "r1", wallet.got.Items[0].Withdraw.RoundID)
+		assert.Equal(t, 10.0, wallet.got.Items[0].Withdraw.Amount)
+		assert.Equal(t, "w1", wallet.got.Items[1].Deposit.ProviderParentTxID)
+		assert.Equal(t, 25.0, wallet.got.Items[1].Deposit.Amount)
+		assert.Equal(t, usecase.BatchCancel, wallet.got.Items[2].Op)
+		assert.Equal(t, "w0", wallet.got.Items[2].Cancel.ProviderTxID)
+	}
+}
+
+func TestBatchReportsEachItem(t *testing.T) {
+	r := batchRouter(&batchWalletUseCase{})
+	w := postJSON(r, "/bet/batch", map[string]interface{}{
+		"items": []map[string]interface{}{
+			{"type": "withdraw", "currency": "USD", "amount": 10, "provider_transaction_id": "seen"},
+			{"type": "withdraw", "currency": "USD", "amount": 80, "provider_transaction_id": "w2"},
+			{"type": "withdraw", "currency": "USD", "amount": 20, "provider_transaction_id": "w3"},
+		},
+	})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp httpdelivery.BatchResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Items, 3) {
+		assert.Equal(t, "replayed", resp.Items[0].Status)
+		assert.Equal(t, "seen", resp.Items[0].Transaction.ProviderTransactionID)
+		assert.Equal(t, "failed", resp.Items[1].Status)
+		assert.Equal(t, usecase.LimitExceededCode, resp.Items[1].Code)
+		assert.Nil(t, resp.Items[1].Transaction)
+		assert.Equal(t, "ok", resp.Items[2].Status)
+		assert.Equal(t, 2, resp.Items[2].Index)
+	}
+}
+
+func TestBatchAtomicFailureNamesItem(t *testing.T) {
+	r := batchRouter(&batchWalletUseCase{})
+	w := postJSON(r, "/bet/batch", map[string]interface{}{
+		"atomic": true,
+		"items": []map[string]interface{}{
+			{"type": "withdraw", "currency": "USD", "amount": 10, "provider_transaction_id": "w1"},
+			{"type": "withdraw", "currency": "USD", "amount": 80, "provider_transaction_id": "w2"},
+		},
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	var resp httpdelivery.BatchErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Index)
+	assert.Equal(t, usecase.LimitExceededCode, resp.Code)
+}
+
+func TestBatchRejectsInvalidItems(t *testing.T) {
+	r := batchRouter(&batchWalletUseCase{})
+	for name, items := range map[string][]map[string]interface{}{
+		"empty":          {},
+		"unknown type":   {{"type": "refund", "currency": "USD", "provider_transaction_id": "x"}},
+		"no currency":    {{"type": "withdraw", "amount": 10, "provider_transaction_id": "x"}},
+		"no parent":      {{"type": "deposit", "currency": "USD", "amount": 10, "provider_transaction_id": "x"}},
+		"unknown field":  {{"type": "cancel", "provider_transaction_id": "x", "extra": 1}},
+		"round details":  {{"type": "withdraw", "currency": "USD", "amount": 10, "provider_transaction_id": "x", "round_details": []int{1}}},
+		"no transaction": {{"type": "cancel"}},
+	} {
+		w := postJSON(r, "/bet/batch", map[string]interface{}{"items": items})
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+	}
+}
+
+func TestBatchStakesRefusedForExcludedPlayer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	wallet := &batchWalletUseCase{}
+	h := &httpdelivery.Handlers{WalletUseCase: wallet, ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{}}
+	r := gin.New()
+	// User 2 is excluded.
+	r.POST("/bet/batch", func(c *gin.Context) {
+		c.Set("userID", uint(2))
+		h.Batch(c)
+	})
+
+	w := postJSON(r, "/bet/batch", map[string]interface{}{
+		"items": []map[string]interface{}{
+			{"type": "deposit", "currency": "USD", "amount": 5, "provider_transaction_id": "d1", "provider_withdrawn_transaction_id": "w0"},
+			{"type": "withdraw", "currency": "USD", "amount": 1, "provider_transaction_id": "fr1", "game_id": "g1", "free_round": true},
+		},
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), usecase.PlayerExcludedCode)
+	assert.Empty(t, wallet.got.Items, "nothing is applied")
+
+	// Settling earlier bets is still allowed.
+	w = postJSON(r, "/bet/batch", map[string]interface{}{
+		"items": []map[string]interface{}{
+			{"type": "deposit", "currency": "USD", "amount": 5, "provider_transaction_id": "d1", "provider_withdrawn_transaction_id": "w0"},
+		},
+	})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestExcludedPlayerCannotPlayFreeRound(t *testing.T) {
+	db := concurrencyDB(t)
+	wallet := concurrencyWallet(t, db)
+	user := concurrencyUser(t, db, 100)
+	game := user.Username + "-slot"
+	now := time.Now()
+	grant := &domain.FreeRoundGrant{UserID: user.ID, GameID: game, Count: 3, Remaining: 3, BetValue: 1, WinTo: domain.FreeRoundsWinToReal, Status: domain.FreeRoundsStatusActive, ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+	require.NoError(t, db.Create(grant).Error)
+	until := now.Add(24 * time.Hour)
+	require.NoError(t, db.Create(&domain.Exclusion{UserID: user.ID, Type: domain.ExclusionTypeTimeout, StartsAt: now.Add(-time.Minute), EndsAt: &until, CreatedBy: user.Username}).Error)
+
+	results, err := wallet.Batch(context.Background(), usecase.BatchInput{UserID: user.ID, Items: []usecase.BatchItem{{
+		Op:       usecase.BatchWithdraw,
+		Withdraw: usecase.WithdrawInput{Amount: 1, Currency: "USD", ProviderTxID: user.Username + "-fr1", GameID: game, FreeRound: true},
+	}}})
+	require.NoError(t, err)
+	var excluded *usecase.ExcludedError
+	assert.ErrorAs(t, results[0].Err, &excluded)
+
+	var stored domain.FreeRoundGrant
+	require.NoError(t, db.First(&stored, grant.ID).Error)
+	assert.Equal(t, 3, stored.Remaining)
+}
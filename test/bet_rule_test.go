@@ -0,0 +1,153 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type ruledWalletUseCase struct {
+	mockWalletUseCase
+}
+
+// Withdraw rejects stakes above 50.
+func (m *ruledWalletUseCase) Withdraw(ctx context.Context, in usecase.WithdrawInput) (*domain.Transaction, error) {
+	if in.Amount > 50 {
+		return nil, &usecase.StakeRuleError{Code: usecase.StakeTooHighCode, Limit: 50}
+	}
+	return m.mockWalletUseCase.Withdraw(ctx, in)
+}
+
+// Deposit holds every win for review.
+func (m *ruledWalletUseCase) Deposit(ctx context.Context, in usecase.DepositInput) (*domain.Transaction, error) {
+	return &domain.Transaction{ID: 2, ProviderTxID: in.ProviderTxID, OldBalance: 900, NewBalance: 900, Status: domain.TransactionStatusHeld}, nil
+}
+
+func ruledBetRouter(wallet usecase.WalletUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{WalletUseCase: wallet, ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{}}
+	r := gin.New()
+	withUser := func(c *gin.Context) { c.Set("userID", uint(1)) }
+	r.POST("/bet/withdraw", withUser, h.Withdraw)
+	r.POST("/bet/deposit", withUser, h.Deposit)
+	return r
+}
+
+func TestWithdrawRejectsNonPositiveStake(t *testing.T) {
+	r := ruledBetRouter(&ruledWalletUseCase{})
+	for _, amount := range []float64{0, -10} {
+		w := postJSON(r, "/bet/withdraw", map[string]interface{}{
+			"currency":                "USD",
+			"amount":                  amount,
+			"provider_transaction_id": "provider-tx-1",
+		})
+		assert.Equal(t, 400, w.Code, "amount %v", amount)
+	}
+}
+
+func TestWithdrawAboveMaxStakeReturnsCode(t *testing.T) {
+	r := ruledBetRouter(&ruledWalletUseCase{})
+
+	w := postJSON(r, "/bet/withdraw", map[string]interface{}{
+		"currency":                "USD",
+		"amount":                  80,
+		"provider_transaction_id": "provider-tx-1",
+		"game_id":                 "book-of-ra",
+	})
+	assert.Equal(t, 422, w.Code)
+	var resp httpdelivery.BetErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, usecase.StakeTooHighCode, resp.Code)
+}
+
+func TestDepositRejectsNegativeAmount(t *testing.T) {
+	r := ruledBetRouter(&ruledWalletUseCase{})
+	w := postJSON(r, "/bet/deposit", map[string]interface{}{
+		"currency":                          "USD",
+		"amount":                            -5,
+		"provider_transaction_id":           "provider-tx-2",
+		"provider_withdrawn_transaction_id": "provider-tx-1",
+	})
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestDepositOverMaxWinIsHeld(t *testing.T) {
+	r := ruledBetRouter(&ruledWalletUseCase{})
+	w := postJSON(r, "/bet/deposit", map[string]interface{}{
+		"currency":                          "USD",
+		"amount":                            100000,
+		"provider_transaction_id":           "provider-tx-2",
+		"provider_withdrawn_transaction_id": "provider-tx-1",
+	})
+	assert.Equal(t, 200, w.Code)
+	var resp httpdelivery.BetResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, domain.TransactionStatusHeld, resp.Status)
+	assert.Equal(t, resp.OldBalance, resp.NewBalance)
+}
+
+// providerWalletUseCase records the provider stakes are resolved for.
+type providerWalletUseCase struct {
+	mockWalletUseCase
+	providers []string
+}
+
+func (m *providerWalletUseCase) Withdraw(ctx context.Context, in usecase.WithdrawInput) (*domain.Transaction, error) {
+	m.providers = append(m.providers, in.ProviderID)
+	return m.mockWalletUseCase.Withdraw(ctx, in)
+}
+
+func (m *providerWalletUseCase) Batch(ctx context.Context, in usecase.BatchInput) ([]usecase.BatchItemResult, error) {
+	for _, item := range in.Items {
+		m.providers = append(m.providers, item.Withdraw.ProviderID)
+	}
+	return make([]usecase.BatchItemResult, len(in.Items)), nil
+}
+
+func TestStakeRulesUseAuthenticatedProvider(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, tc := range []struct {
+		name string
+		keys map[string]string
+		key  string
+		want string
+	}{
+		// Without configured keys nobody is verified, whatever the header says.
+		{name: "unverified header", want: ""},
+		{name: "verified provider", keys: map[string]string{"loose": "k1"}, key: "k1", want: "loose"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			wallet := &providerWalletUseCase{}
+			h := &httpdelivery.Handlers{WalletUseCase: wallet, ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{}, ProviderKeys: tc.keys}
+			r := gin.New()
+			setUser := func(c *gin.Context) { c.Set("userID", uint(1)) }
+			r.POST("/bet/withdraw", h.ProviderIdentity(), setUser, h.Withdraw)
+			r.POST("/bet/batch", h.ProviderIdentity(), setUser, h.Batch)
+
+			post := func(path string, body map[string]interface{}) int {
+				b, _ := json.Marshal(body)
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest("POST", path, bytes.NewReader(b))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set(httpdelivery.ProviderIDHeader, "loose")
+				req.Header.Set(httpdelivery.ProviderKeyHeader, tc.key)
+				r.ServeHTTP(w, req)
+				return w.Code
+			}
+			assert.Equal(t, 200, post("/bet/withdraw", map[string]interface{}{"currency": "USD", "amount": 10, "provider_transaction_id": "w1"}))
+			assert.Equal(t, 200, post("/bet/batch", map[string]interface{}{"items": []map[string]interface{}{
+				{"type": "withdraw", "currency": "USD", "amount": 10, "provider_transaction_id": "w2"},
+			}}))
+			assert.Equal(t, []string{tc.want, tc.want}, wallet.providers)
+		})
+	}
+}
@@ -2,12 +2,14 @@ package http_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http/httptest"
 	"testing"
 
 	httpdelivery "gameintegrationapi/internal/delivery/http"
 	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/usecase"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -15,26 +17,35 @@ import (
 
 type mockWalletUseCase struct{}
 
-func (m *mockWalletUseCase) Withdraw(userID uint, amount float64, currency, providerTx, roundID, gameID string) (*domain.Transaction, error) {
+func (m *mockWalletUseCase) Withdraw(ctx context.Context, in usecase.WithdrawInput) (*domain.Transaction, error) {
 	return &domain.Transaction{
 		ID:           1,
-		ProviderTxID: providerTx,
+		ProviderTxID: in.ProviderTxID,
 		OldBalance:   1000,
 		NewBalance:   900,
 		Status:       "PLACED",
 	}, nil
 }
 
-func (m *mockWalletUseCase) Deposit(userID uint, amount float64, currency, providerTx, providerWithdrawnTxID string) (*domain.Transaction, error) {
+func (m *mockWalletUseCase) Deposit(ctx context.Context, in usecase.DepositInput) (*domain.Transaction, error) {
 	return nil, nil
 }
-func (m *mockWalletUseCase) Cancel(userID uint, providerTx string) (*domain.Transaction, error) {
+func (m *mockWalletUseCase) Cancel(ctx context.Context, in usecase.CancelInput) (*domain.Transaction, error) {
+	return nil, nil
+}
+func (m *mockWalletUseCase) Batch(ctx context.Context, in usecase.BatchInput) ([]usecase.BatchItemResult, error) {
+	return nil, nil
+}
+func (m *mockWalletUseCase) ListHeldWins(ctx context.Context) ([]domain.Transaction, error) {
+	return nil, nil
+}
+func (m *mockWalletUseCase) ReviewHeldWin(ctx context.Context, txID uint, approve bool, reviewer string) (*domain.Transaction, error) {
 	return nil, nil
 }
 
 func TestWithdrawRejectsExtraFields(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	h := &httpdelivery.Handlers{WalletUseCase: &mockWalletUseCase{}}
+	h := &httpdelivery.Handlers{WalletUseCase: &mockWalletUseCase{}, ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{}}
 	r := gin.New()
 	r.POST("/bet/withdraw", func(c *gin.Context) {
 		c.Set("userID", uint(1))
@@ -58,7 +69,7 @@ func TestWithdrawRejectsExtraFields(t *testing.T) {
 
 func TestWithdrawAcceptsValidRequest(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	h := &httpdelivery.Handlers{WalletUseCase: &mockWalletUseCase{}}
+	h := &httpdelivery.Handlers{WalletUseCase: &mockWalletUseCase{}, ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{}}
 	r := gin.New()
 	r.POST("/bet/withdraw", func(c *gin.Context) {
 		c.Set("userID", uint(1))
@@ -78,4 +89,49 @@ func TestWithdrawAcceptsValidRequest(t *testing.T) {
 	assert.Equal(t, 200, w.Code)
 	assert.Contains(t, w.Body.String(), "tx-1")
 }
- 
\ No newline at end of file
+ 
+// failingWalletUseCase fails every bet operation with err.
+type failingWalletUseCase struct {
+	mockWalletUseCase
+	err error
+}
+
+func (m *failingWalletUseCase) Withdraw(ctx context.Context, in usecase.WithdrawInput) (*domain.Transaction, error) {
+	return nil, m.err
+}
+func (m *failingWalletUseCase) Deposit(ctx context.Context, in usecase.DepositInput) (*domain.Transaction, error) {
+	return nil, m.err
+}
+func (m *failingWalletUseCase) Cancel(ctx context.Context, in usecase.CancelInput) (*domain.Transaction, error) {
+	return nil, m.err
+}
+
+func TestBetErrorsMapToSameStatusOnEveryEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	bodies := map[string]map[string]interface{}{
+		"/bet/withdraw": {"currency": "USD", "amount": 10, "provider_transaction_id": "w1"},
+		"/bet/deposit":  {"currency": "USD", "amount": 10, "provider_transaction_id": "d1", "provider_withdrawn_transaction_id": "w1"},
+		"/bet/cancel":   {"provider_transaction_id": "w1"},
+	}
+	for _, tc := range []struct {
+		err  error
+		code int
+	}{
+		{usecase.ErrInsufficientFunds, 422},
+		{usecase.ErrCurrencyUnsupported, 422},
+		{usecase.ErrDuplicateTransaction, 409},
+		{usecase.ErrWalletServiceUnavailable, 503},
+	} {
+		h := &httpdelivery.Handlers{WalletUseCase: &failingWalletUseCase{err: tc.err}, ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{}}
+		r := gin.New()
+		setUser := func(c *gin.Context) { c.Set("userID", uint(1)) }
+		r.POST("/bet/withdraw", setUser, h.Withdraw)
+		r.POST("/bet/deposit", setUser, h.Deposit)
+		r.POST("/bet/cancel", setUser, h.Cancel)
+		for path, body := range bodies {
+			w := postJSON(r, path, body)
+			assert.Equal(t, tc.code, w.Code, "%s: %v", path, tc.err)
+			assert.Contains(t, w.Body.String(), tc.err.Error())
+		}
+	}
+}
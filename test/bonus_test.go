@@ -0,0 +1,226 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type mockBonusUseCase struct {
+	created *domain.BonusCampaign
+}
+
+func (m *mockBonusUseCase) ListCampaigns(ctx context.Context) ([]domain.BonusCampaign, error) {
+	return nil, nil
+}
+
+func (m *mockBonusUseCase) CreateCampaign(ctx context.Context, campaign *domain.BonusCampaign) error {
+	campaign.ID, campaign.Active = 1, true
+	m.created = campaign
+	return nil
+}
+
+// Grant only knows campaign 1 and rejects user 3, who already has a bonus.
+func (m *mockBonusUseCase) Grant(ctx context.Context, userID, campaignID uint) (*domain.PlayerBonus, error) {
+	if campaignID != 1 {
+		return nil, &usecase.ValidationError{Msg: "campaign not found"}
+	}
+	if userID == 3 {
+		return nil, &usecase.ValidationError{Msg: "player already has an active bonus"}
+	}
+	return &domain.PlayerBonus{ID: 7, UserID: userID, CampaignID: 1, Amount: 50, WageringRequired: 1500, Status: domain.BonusStatusActive}, nil
+}
+
+func (m *mockBonusUseCase) ListBonuses(ctx context.Context, userID uint) ([]usecase.BonusProgress, error) {
+	return []usecase.BonusProgress{{
+		PlayerBonus: domain.PlayerBonus{
+			ID: 7, UserID: userID, CampaignID: 1, Campaign: &domain.BonusCampaign{Name: "Welcome bonus"},
+			Amount: 50, WageringRequired: 1500, Status: domain.BonusStatusActive, ExpiresAt: time.Now().Add(24 * time.Hour),
+		},
+		Wagered: 420,
+	}}, nil
+}
+
+// Forfeit treats bonus 7 as user 1's active bonus and bonus 8 as closed.
+func (m *mockBonusUseCase) Forfeit(ctx context.Context, bonusID, userID uint) (*domain.PlayerBonus, error) {
+	switch {
+	case bonusID == 8:
+		return nil, usecase.ErrBonusNotActive
+	case bonusID != 7 || (userID != 0 && userID != 1):
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &domain.PlayerBonus{ID: 7, UserID: 1, Status: domain.BonusStatusForfeited, ForfeitedAmount: 20}, nil
+}
+
+func (m *mockBonusUseCase) ExpireDue(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (m *mockBonusUseCase) ActiveBonus(ctx context.Context, user *domain.User) (*domain.PlayerBonus, error) {
+	return nil, nil
+}
+
+func (m *mockBonusUseCase) FundStake(ctx context.Context, user *domain.User, amount float64) (*usecase.StakeFunding, error) {
+	return &usecase.StakeFunding{FromReal: amount}, nil
+}
+
+func (m *mockBonusUseCase) SettleWagering(ctx context.Context, userID uint) error {
+	return nil
+}
+
+func bonusRouter(userID uint, bonuses *mockBonusUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{BonusUseCase: bonuses}
+	r := gin.New()
+	withUser := func(c *gin.Context) { c.Set("userID", userID) }
+	r.GET("/bonuses", withUser, h.ListBonuses)
+	r.POST("/bonuses/:id/forfeit", withUser, h.ForfeitBonus)
+	r.POST("/admin/bonus-campaigns", h.CreateBonusCampaign)
+	r.POST("/admin/users/:id/bonuses", h.GrantBonus)
+	return r
+}
+
+func TestListBonusesShowsProgress(t *testing.T) {
+	r := bonusRouter(1, &mockBonusUseCase{})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/bonuses", nil))
+	assert.Equal(t, 200, w.Code)
+
+	var resp []httpdelivery.BonusResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp, 1)
+	assert.Equal(t, "active", resp[0].Status)
+	assert.Equal(t, "Welcome bonus", resp[0].CampaignName)
+	assert.Equal(t, 420.0, resp[0].Wagered)
+	assert.Equal(t, 1500.0, resp[0].WageringRequired)
+}
+
+func TestForfeitBonusMapsErrors(t *testing.T) {
+	assert.Equal(t, 200, postJSON(bonusRouter(1, &mockBonusUseCase{}), "/bonuses/7/forfeit", nil).Code)
+	assert.Equal(t, 404, postJSON(bonusRouter(2, &mockBonusUseCase{}), "/bonuses/7/forfeit", nil).Code)
+	assert.Equal(t, 409, postJSON(bonusRouter(1, &mockBonusUseCase{}), "/bonuses/8/forfeit", nil).Code)
+	assert.Equal(t, 400, postJSON(bonusRouter(1, &mockBonusUseCase{}), "/bonuses/abc/forfeit", nil).Code)
+}
+
+func TestCreateBonusCampaignValidates(t *testing.T) {
+	bonuses := &mockBonusUseCase{}
+	r := bonusRouter(1, bonuses)
+
+	assert.Equal(t, 400, postJSON(r, "/admin/bonus-campaigns", map[string]interface{}{
+		"name": "Welcome bonus", "amount": 0, "wagering_multiplier": 30, "valid_days": 14,
+	}).Code)
+	assert.Equal(t, 400, postJSON(r, "/admin/bonus-campaigns", map[string]interface{}{
+		"name": "Welcome bonus", "amount": 50, "valid_days": 14, "currency": "EUR",
+	}).Code)
+
+	w := postJSON(r, "/admin/bonus-campaigns", map[string]interface{}{
+		"name": "Welcome bonus", "amount": 50, "wagering_multiplier": 30, "valid_days": 14,
+	})
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, 30.0, bonuses.created.WageringMultiplier)
+}
+
+func TestGrantBonusRejectsSecondActiveBonus(t *testing.T) {
+	r := bonusRouter(1, &mockBonusUseCase{})
+	assert.Equal(t, 201, postJSON(r, "/admin/users/5/bonuses", map[string]interface{}{"campaign_id": 1}).Code)
+	assert.Equal(t, 400, postJSON(r, "/admin/users/3/bonuses", map[string]interface{}{"campaign_id": 1}).Code)
+	assert.Equal(t, 400, postJSON(r, "/admin/users/5/bonuses", map[string]interface{}{"campaign_id": 2}).Code)
+}
+
+// memoryBonusRepository keeps player bonuses in memory.
+type memoryBonusRepository struct {
+	repository.BonusRepository
+	bonuses []domain.PlayerBonus
+}
+
+func (m *memoryBonusRepository) FindActive(ctx context.Context, userID uint) (*domain.PlayerBonus, error) {
+	for _, b := range m.bonuses {
+		if b.UserID == userID && b.Status == domain.BonusStatusActive {
+			return &b, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryBonusRepository) ListByUser(ctx context.Context, userID uint) ([]domain.PlayerBonus, error) {
+	var bonuses []domain.PlayerBonus
+	for _, b := range m.bonuses {
+		if b.UserID == userID {
+			bonuses = append(bonuses, b)
+		}
+	}
+	return bonuses, nil
+}
+
+func TestBonusWageringCountsStakesInPrimaryCurrency(t *testing.T) {
+	now := time.Now()
+	bonuses := &memoryBonusRepository{bonuses: []domain.PlayerBonus{
+		{ID: 1, UserID: 1, Amount: 10, WageringRequired: 50, Status: domain.BonusStatusCompleted, ExpiresAt: now, CreatedAt: now.AddDate(0, 0, -7)},
+		{ID: 2, UserID: 1, Amount: 20, WageringRequired: 100, Status: domain.BonusStatusActive, ExpiresAt: now.AddDate(0, 0, 7), CreatedAt: now.Add(-time.Hour)},
+	}}
+	users := &walletUserRepository{user: domain.User{ID: 1, WalletID: "123", Currency: "USD"}}
+	// 40 EUR staked are 50 USD towards the requirement.
+	txs := &activityTransactionRepository{totals: []repository.ActivityTotal{
+		{Currency: "USD", Wagered: 30, Won: 100},
+		{Currency: "EUR", Wagered: 40},
+	}}
+	uc := usecase.NewBonusUseCase(bonuses, users, txs, nil, nil, eurRates(t), infrastructure.BonusConfig{SpendOrder: infrastructure.BonusSpendRealFirst})
+	ctx := context.Background()
+
+	progress, err := uc.ListBonuses(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, progress, 2)
+	assert.Equal(t, 50.0, progress[0].Wagered, "completed")
+	assert.InDelta(t, 80, progress[1].Wagered, 1e-9)
+
+	txs.totals = append(txs.totals, repository.ActivityTotal{Currency: "KES", Wagered: 1000})
+	_, err = uc.ListBonuses(ctx, 1)
+	assert.ErrorIs(t, err, usecase.ErrCurrencyUnsupported)
+}
+
+func TestFundStakeFollowsSpendOrder(t *testing.T) {
+	bonuses := &memoryBonusRepository{bonuses: []domain.PlayerBonus{
+		{ID: 1, UserID: 1, Amount: 50, WageringRequired: 500, Status: domain.BonusStatusActive, ExpiresAt: time.Now().AddDate(0, 0, 7)},
+	}}
+	user := &domain.User{ID: 1, Currency: "USD", Balance: 30, BonusBalance: 50}
+	ctx := context.Background()
+	fund := func(order string, amount float64) (*usecase.StakeFunding, error) {
+		uc := usecase.NewBonusUseCase(bonuses, nil, nil, nil, nil, nil, infrastructure.BonusConfig{SpendOrder: order})
+		return uc.FundStake(ctx, user, amount)
+	}
+
+	funding, err := fund(infrastructure.BonusSpendRealFirst, 40)
+	require.NoError(t, err)
+	assert.Equal(t, [2]float64{30, 10}, [2]float64{funding.FromReal, funding.FromBonus})
+	assert.Equal(t, uint(1), funding.Bonus.ID)
+
+	funding, err = fund(infrastructure.BonusSpendBonusFirst, 40)
+	require.NoError(t, err)
+	assert.Equal(t, [2]float64{0, 40}, [2]float64{funding.FromReal, funding.FromBonus})
+
+	// Together the balances hold 80.
+	for _, order := range []string{infrastructure.BonusSpendRealFirst, infrastructure.BonusSpendBonusFirst} {
+		_, err = fund(order, 90)
+		assert.ErrorIs(t, err, usecase.ErrInsufficientFunds, order)
+	}
+
+	// Without an active bonus the stake is all real money.
+	bonuses.bonuses[0].Status = domain.BonusStatusCompleted
+	funding, err = fund(infrastructure.BonusSpendBonusFirst, 40)
+	require.NoError(t, err)
+	assert.Nil(t, funding.Bonus)
+	assert.Equal(t, 40.0, funding.FromReal)
+}
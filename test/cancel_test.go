@@ -0,0 +1,68 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// cancelWalletUseCase has a stake "w1" of 10 with 4 already refunded.
+type cancelWalletUseCase struct {
+	mockWalletUseCase
+	got usecase.CancelInput
+}
+
+func (m *cancelWalletUseCase) Cancel(ctx context.Context, in usecase.CancelInput) (*domain.Transaction, error) {
+	m.got = in
+	switch {
+	case in.CancelTxID == "c1":
+		return nil, usecase.ErrAlreadyCancelled
+	case in.Amount > 6:
+		return nil, usecase.ErrCancelExceedsAmount
+	}
+	return &domain.Transaction{ID: 10, ProviderTxID: in.CancelTxID, OldBalance: 100, NewBalance: 100 + in.Amount, Status: "CANCELLED"}, nil
+}
+
+func cancelRouter(wallet usecase.WalletUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{WalletUseCase: wallet, ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{}}
+	r := gin.New()
+	setUser := func(c *gin.Context) { c.Set("userID", uint(1)) }
+	r.POST("/bet/cancel", setUser, h.Cancel)
+	return r
+}
+
+func TestCancelPartialAmount(t *testing.T) {
+	wallet := &cancelWalletUseCase{}
+	r := cancelRouter(wallet)
+	w := postJSON(r, "/bet/cancel", map[string]interface{}{
+		"provider_transaction_id": "w1",
+		"amount":                  5,
+		"cancel_transaction_id":   "c2",
+	})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, usecase.CancelInput{UserID: 1, ProviderTxID: "w1", Amount: 5, CancelTxID: "c2"}, wallet.got)
+	assert.Contains(t, w.Body.String(), `"new_balance":105`)
+}
+
+func TestCancelMapsErrors(t *testing.T) {
+	r := cancelRouter(&cancelWalletUseCase{})
+	for _, tc := range []struct {
+		body map[string]interface{}
+		code int
+	}{
+		{map[string]interface{}{"provider_transaction_id": "w1", "amount": 7, "cancel_transaction_id": "c2"}, http.StatusUnprocessableEntity},
+		{map[string]interface{}{"provider_transaction_id": "w1", "cancel_transaction_id": "c1"}, http.StatusConflict},
+		{map[string]interface{}{"provider_transaction_id": "w1", "amount": -1}, http.StatusBadRequest},
+	} {
+		w := postJSON(r, "/bet/cancel", tc.body)
+		assert.Equal(t, tc.code, w.Code, w.Body.String())
+	}
+}
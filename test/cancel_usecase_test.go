@@ -0,0 +1,277 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// walletCall is one transaction line sent to the wallet service.
+type walletCall struct {
+	Path     string
+	Currency string
+	Amount   float64
+}
+
+// recordingWallet is a wallet service that accepts every call and records
+// what it was sent.
+type recordingWallet struct {
+	mu    sync.Mutex
+	calls []walletCall
+}
+
+func (m *recordingWallet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Withdraw and deposit requests have the same shape.
+	var req infrastructure.WalletDepositRequest
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	m.mu.Lock()
+	for _, line := range req.Transactions {
+		m.calls = append(m.calls, walletCall{Path: r.URL.Path, Currency: req.Currency, Amount: line.Amount})
+	}
+	m.mu.Unlock()
+	w.Write([]byte(`{}`))
+}
+
+// mark returns the number of calls so far, for since.
+func (m *recordingWallet) mark() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.calls)
+}
+
+// since returns the calls made after mark returned n.
+func (m *recordingWallet) since(n int) []walletCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]walletCall(nil), m.calls[n:]...)
+}
+
+// cancelWallet wires the wallet use case on the test database against a
+// recording wallet service, with EUR at 0.8 to the dollar.
+func cancelWallet(t *testing.T) (*gorm.DB, usecase.WalletUseCase, *recordingWallet) {
+	db := concurrencyDB(t)
+	recorder := &recordingWallet{}
+	server := httptest.NewServer(recorder)
+	t.Cleanup(server.Close)
+	path := filepath.Join(t.TempDir(), "rates.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("rates:\n  USD: 1\n  EUR: 0.8\n"), 0o600))
+	rates, err := infrastructure.NewStaticRateProvider(infrastructure.FXConfig{BaseCurrency: "USD", RatesFile: path})
+	require.NoError(t, err)
+	return db, walletUseCaseOn(db, server.URL, rates), recorder
+}
+
+func storedTransaction(t *testing.T, db *gorm.DB, providerTxID string) *domain.Transaction {
+	var tx domain.Transaction
+	require.NoError(t, db.Where("provider_tx_id = ?", providerTxID).First(&tx).Error)
+	return &tx
+}
+
+func TestCancelConvertsFromGameCurrency(t *testing.T) {
+	db, wallet, recorder := cancelWallet(t)
+	user := concurrencyUser(t, db, 100)
+	ctx := context.Background()
+	stakeID := user.Username + "-s1"
+
+	// 8 EUR is staked as 10 USD from the primary balance.
+	stake, err := wallet.Withdraw(ctx, usecase.WithdrawInput{UserID: user.ID, Amount: 8, Currency: "EUR", ProviderTxID: stakeID})
+	require.NoError(t, err)
+	require.Equal(t, 10.0, stake.Amount)
+
+	n := recorder.mark()
+	cancel, err := wallet.Cancel(ctx, usecase.CancelInput{UserID: user.ID, ProviderTxID: stakeID, Amount: 4, CancelTxID: user.Username + "-c1"})
+	require.NoError(t, err)
+	assert.Equal(t, "CANCEL", cancel.Type)
+	assert.Equal(t, 5.0, cancel.Amount)
+	assert.Equal(t, []walletCall{{infrastructure.WalletDepositEndpoint, "USD", 5}}, recorder.since(n))
+	assert.InDelta(t, 95, storedBalance(t, db, user.ID), 1e-9)
+
+	// 4.01 EUR is 5.01 USD, past the tolerance on the 5 USD left.
+	n = recorder.mark()
+	_, err = wallet.Cancel(ctx, usecase.CancelInput{UserID: user.ID, ProviderTxID: stakeID, Amount: 4.01, CancelTxID: user.Username + "-c2"})
+	assert.ErrorIs(t, err, usecase.ErrCancelExceedsAmount)
+	assert.Empty(t, recorder.since(n))
+	assert.InDelta(t, 95, storedBalance(t, db, user.ID), 1e-9)
+
+	cancel, err = wallet.Cancel(ctx, usecase.CancelInput{UserID: user.ID, ProviderTxID: stakeID, CancelTxID: user.Username + "-c3"})
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, cancel.Amount)
+	stored := storedTransaction(t, db, stakeID)
+	assert.Equal(t, "CANCELLED", stored.Status)
+	assert.Equal(t, 10.0, stored.CancelledAmount)
+	assert.InDelta(t, 100, storedBalance(t, db, user.ID), 1e-9)
+}
+
+func TestPartialCancelsAccumulate(t *testing.T) {
+	db, wallet, recorder := cancelWallet(t)
+	user := concurrencyUser(t, db, 100)
+	ctx := context.Background()
+	stakeID := user.Username + "-s1"
+	cancel := func(id string, amount float64) (*domain.Transaction, error) {
+		return wallet.Cancel(ctx, usecase.CancelInput{UserID: user.ID, ProviderTxID: stakeID, Amount: amount, CancelTxID: user.Username + id})
+	}
+
+	_, err := wallet.Withdraw(ctx, usecase.WithdrawInput{UserID: user.ID, Amount: 0.3, Currency: "USD", ProviderTxID: stakeID})
+	require.NoError(t, err)
+
+	first, err := cancel("-c1", 0.1)
+	require.NoError(t, err)
+	stored := storedTransaction(t, db, stakeID)
+	assert.InDelta(t, 0.1, stored.CancelledAmount, 1e-9)
+	assert.NotEqual(t, "CANCELLED", stored.Status)
+
+	// A repeated cancel is answered without refunding again.
+	n := recorder.mark()
+	replay, err := cancel("-c1", 0.1)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, replay.ID)
+	assert.Empty(t, recorder.since(n))
+
+	// 0.3 - 0.1 is a hair under 0.2 in floating point; the tolerance lets
+	// the last part take what is left.
+	_, err = cancel("-c2", 0.2)
+	require.NoError(t, err)
+	stored = storedTransaction(t, db, stakeID)
+	assert.Equal(t, "CANCELLED", stored.Status)
+	assert.InDelta(t, 0.3, stored.CancelledAmount, 1e-9)
+	assert.InDelta(t, 100, storedBalance(t, db, user.ID), 1e-9)
+
+	_, err = cancel("-c3", 0.01)
+	assert.ErrorIs(t, err, usecase.ErrAlreadyCancelled)
+}
+
+func TestCancelBeforeStakeVoidsStake(t *testing.T) {
+	db, wallet, recorder := cancelWallet(t)
+	user := concurrencyUser(t, db, 100)
+	ctx := context.Background()
+	stakeID := user.Username + "-late"
+
+	n := recorder.mark()
+	tombstone, err := wallet.Cancel(ctx, usecase.CancelInput{UserID: user.ID, ProviderTxID: stakeID})
+	require.NoError(t, err)
+	assert.Equal(t, "CANCELLED", tombstone.Status)
+
+	// The stake arrives after its cancel: it is answered with the cancel
+	// and neither reaches the wallet nor debits the player.
+	void, err := wallet.Withdraw(ctx, usecase.WithdrawInput{UserID: user.ID, Amount: 10, Currency: "USD", ProviderTxID: stakeID})
+	require.NoError(t, err)
+	assert.Equal(t, tombstone.ID, void.ID)
+	assert.Equal(t, "CANCELLED", void.Status)
+	assert.Equal(t, 100.0, void.NewBalance)
+	assert.Empty(t, recorder.since(n))
+	assert.InDelta(t, 100, storedBalance(t, db, user.ID), 1e-9)
+	assert.ErrorIs(t, db.Where("provider_tx_id = ? AND type = 'WITHDRAW'", stakeID).First(&domain.Transaction{}).Error, gorm.ErrRecordNotFound)
+}
+
+// stakeAndWin places a stake of 10 and settles it with a win of 30.
+func stakeAndWin(t *testing.T, wallet usecase.WalletUseCase, user *domain.User) (stakeID, winID string) {
+	ctx := context.Background()
+	stakeID, winID = user.Username+"-s1", user.Username+"-d1"
+	_, err := wallet.Withdraw(ctx, usecase.WithdrawInput{UserID: user.ID, Amount: 10, Currency: "USD", ProviderTxID: stakeID})
+	require.NoError(t, err)
+	_, err = wallet.Deposit(ctx, usecase.DepositInput{UserID: user.ID, Amount: 30, Currency: "USD", ProviderTxID: winID, ProviderParentTxID: stakeID})
+	require.NoError(t, err)
+	return stakeID, winID
+}
+
+func TestCancelWinClawsBack(t *testing.T) {
+	db, wallet, recorder := cancelWallet(t)
+	user := concurrencyUser(t, db, 100)
+	_, winID := stakeAndWin(t, wallet, user)
+	require.InDelta(t, 120, storedBalance(t, db, user.ID), 1e-9)
+
+	n := recorder.mark()
+	clawback, err := wallet.Cancel(context.Background(), usecase.CancelInput{UserID: user.ID, ProviderTxID: winID})
+	require.NoError(t, err)
+	assert.Equal(t, "CLAWBACK", clawback.Type)
+	assert.Equal(t, 30.0, clawback.Amount)
+	assert.Equal(t, []walletCall{{infrastructure.WalletWithdrawEndpoint, "USD", 30}}, recorder.since(n))
+	assert.InDelta(t, 90, storedBalance(t, db, user.ID), 1e-9)
+	assert.Equal(t, "CANCELLED", storedTransaction(t, db, winID).Status)
+}
+
+func TestClawbackWithoutFundsChangesNothing(t *testing.T) {
+	db, wallet, recorder := cancelWallet(t)
+	user := concurrencyUser(t, db, 100)
+	_, winID := stakeAndWin(t, wallet, user)
+	// The player has since spent most of the win.
+	require.NoError(t, db.Model(user).Update("balance", 5).Error)
+
+	n := recorder.mark()
+	_, err := wallet.Cancel(context.Background(), usecase.CancelInput{UserID: user.ID, ProviderTxID: winID})
+	assert.ErrorIs(t, err, usecase.ErrInsufficientFunds)
+	assert.Empty(t, recorder.since(n))
+	assert.InDelta(t, 5, storedBalance(t, db, user.ID), 1e-9)
+	win := storedTransaction(t, db, winID)
+	assert.Equal(t, "WON", win.Status)
+	assert.Zero(t, win.CancelledAmount)
+	assert.ErrorIs(t, db.Where("provider_tx_id = ?", "cancel-"+winID).First(&domain.Transaction{}).Error, gorm.ErrRecordNotFound)
+}
+
+func TestCancelReturnsBonusShareToBonusBalance(t *testing.T) {
+	db, wallet, recorder := cancelWallet(t)
+	user := concurrencyUser(t, db, 5)
+	campaign := &domain.BonusCampaign{Name: user.Username, Amount: 20, WageringMultiplier: 50, ValidDays: 1}
+	require.NoError(t, db.Create(campaign).Error)
+	bonus := &domain.PlayerBonus{UserID: user.ID, CampaignID: campaign.ID, Amount: 20, WageringRequired: 1000, Status: domain.BonusStatusActive, ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, db.Create(bonus).Error)
+	require.NoError(t, db.Model(user).Update("bonus_balance", 20).Error)
+	ctx := context.Background()
+	stakeID := user.Username + "-s1"
+
+	// Real money is spent first, so half of the stake is bonus funds.
+	stake, err := wallet.Withdraw(ctx, usecase.WithdrawInput{UserID: user.ID, Amount: 10, Currency: "USD", ProviderTxID: stakeID})
+	require.NoError(t, err)
+	require.Equal(t, 5.0, stake.BonusAmount)
+
+	n := recorder.mark()
+	cancel, err := wallet.Cancel(ctx, usecase.CancelInput{UserID: user.ID, ProviderTxID: stakeID})
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, cancel.Amount)
+	assert.Equal(t, 5.0, cancel.BonusAmount)
+	assert.Equal(t, []walletCall{{infrastructure.WalletDepositEndpoint, "USD", 5}}, recorder.since(n))
+
+	var stored domain.User
+	require.NoError(t, db.First(&stored, user.ID).Error)
+	assert.InDelta(t, 5, stored.Balance, 1e-9)
+	assert.InDelta(t, 20, stored.BonusBalance, 1e-9)
+}
+
+func TestCancelFreeRoundRestoresRound(t *testing.T) {
+	db, wallet, recorder := cancelWallet(t)
+	user := concurrencyUser(t, db, 100)
+	game := user.Username + "-slot"
+	grant := &domain.FreeRoundGrant{UserID: user.ID, GameID: game, Count: 3, Remaining: 3, BetValue: 1, WinTo: domain.FreeRoundsWinToReal, Status: domain.FreeRoundsStatusActive, ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now()}
+	require.NoError(t, db.Create(grant).Error)
+	ctx := context.Background()
+	stakeID := user.Username + "-s1"
+	remaining := func() int {
+		var stored domain.FreeRoundGrant
+		require.NoError(t, db.First(&stored, grant.ID).Error)
+		return stored.Remaining
+	}
+
+	_, err := wallet.Withdraw(ctx, usecase.WithdrawInput{UserID: user.ID, Amount: 1, Currency: "USD", ProviderTxID: stakeID, GameID: game, FreeRound: true})
+	require.NoError(t, err)
+	require.Equal(t, 2, remaining())
+
+	n := recorder.mark()
+	_, err = wallet.Cancel(ctx, usecase.CancelInput{UserID: user.ID, ProviderTxID: stakeID})
+	require.NoError(t, err)
+	assert.Equal(t, 3, remaining())
+	assert.Empty(t, recorder.since(n), "a free round moved no money")
+	assert.InDelta(t, 100, storedBalance(t, db, user.ID), 1e-9)
+}
@@ -0,0 +1,238 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// concurrencyDB connects to the Postgres database named by TEST_POSTGRES_DSN,
+// since the player's row lock needs a real database. The tests are skipped
+// without one.
+func concurrencyDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&domain.User{},
+		&domain.Transaction{},
+		&domain.PlayerLimit{},
+		&domain.PlayerDeposit{},
+		&domain.Exclusion{},
+		&domain.GameSession{},
+		&domain.BetRule{},
+		&domain.BonusCampaign{},
+		&domain.PlayerBonus{},
+		&domain.FreeRoundGrant{},
+		&domain.JackpotPool{},
+		&domain.JackpotGame{},
+		&domain.PlayerBalance{},
+	))
+	// Fewer connections than parallel operations, as in production.
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(10)
+	return db
+}
+
+// concurrencyWallet wires the wallet use case on db against a wallet service
+// that accepts every call.
+func concurrencyWallet(t *testing.T, db *gorm.DB) usecase.WalletUseCase {
+	wallet := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Widen the window in which unserialized operations would interleave.
+		time.Sleep(5 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(wallet.Close)
+	rates, err := infrastructure.NewStaticRateProvider(infrastructure.FXConfig{BaseCurrency: "USD"})
+	require.NoError(t, err)
+	return walletUseCaseOn(db, wallet.URL, rates)
+}
+
+// walletUseCaseOn wires the wallet use case on db against the wallet service
+// at walletURL, converting currencies at rates.
+func walletUseCaseOn(db *gorm.DB, walletURL string, rates infrastructure.RateProvider) usecase.WalletUseCase {
+	client := infrastructure.NewWalletClient(infrastructure.WalletConfig{URL: walletURL, Timeout: 5 * time.Second, BreakerThreshold: 1000, BreakerCooldown: time.Second})
+
+	userRepo := repository.NewUserRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+	limits := usecase.NewResponsibleGamingUseCase(repository.NewPlayerLimitRepository(db), repository.NewExclusionRepository(db), repository.NewGameSessionRepository(db), userRepo, txRepo, rates, infrastructure.ResponsibleGamingConfig{})
+	bonuses := usecase.NewBonusUseCase(repository.NewBonusRepository(db), userRepo, txRepo, db, client, rates, infrastructure.BonusConfig{})
+	freeRounds := usecase.NewFreeRoundUseCase(repository.NewFreeRoundRepository(db), userRepo, bonuses)
+	jackpots := usecase.NewJackpotUseCase(repository.NewJackpotRepository(db))
+	currencies := usecase.NewCurrencyUseCase(repository.NewBalanceRepository(db), userRepo, txRepo, rates)
+	rules := usecase.NewBetRuleUseCase(repository.NewBetRuleRepository(db))
+	return usecase.NewWalletUseCase(userRepo, txRepo, db, client, usecase.NewOperationTracker(), limits, rules, bonuses, freeRounds, jackpots, currencies, 0)
+}
+
+// concurrencyUser creates a player holding balance, named uniquely so runs
+// against the same database do not collide.
+func concurrencyUser(t *testing.T, db *gorm.DB, balance float64) *domain.User {
+	id := time.Now().UnixNano()
+	user := &domain.User{
+		WalletID: fmt.Sprint(id % 1_000_000_000),
+		Username: fmt.Sprintf("concurrency-%d", id),
+		Password: "x",
+		Currency: "USD",
+		Balance:  balance,
+	}
+	require.NoError(t, db.Create(user).Error)
+	return user
+}
+
+func storedBalance(t *testing.T, db *gorm.DB, userID uint) float64 {
+	var user domain.User
+	require.NoError(t, db.First(&user, userID).Error)
+	return user.Balance
+}
+
+func TestParallelWithdrawsLoseNoDebit(t *testing.T) {
+	db := concurrencyDB(t)
+	wallet := concurrencyWallet(t, db)
+	user := concurrencyUser(t, db, 1000)
+
+	const n = 40
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = wallet.Withdraw(context.Background(), usecase.WithdrawInput{
+				UserID:       user.ID,
+				Amount:       10,
+				Currency:     "USD",
+				ProviderTxID: fmt.Sprintf("%s-w%d", user.Username, i),
+			})
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.InDelta(t, 1000-n*10, storedBalance(t, db, user.ID), 1e-9)
+}
+
+func TestParallelWithdrawsDoNotOverdraw(t *testing.T) {
+	db := concurrencyDB(t)
+	wallet := concurrencyWallet(t, db)
+	user := concurrencyUser(t, db, 50)
+
+	const n = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	placed, refused := 0, 0
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := wallet.Withdraw(context.Background(), usecase.WithdrawInput{
+				UserID:       user.ID,
+				Amount:       10,
+				Currency:     "USD",
+				ProviderTxID: fmt.Sprintf("%s-w%d", user.Username, i),
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			if errors.Is(err, usecase.ErrInsufficientFunds) {
+				refused++
+			} else if assert.NoError(t, err) {
+				placed++
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 5, placed)
+	assert.Equal(t, n-5, refused)
+	assert.InDelta(t, 0, storedBalance(t, db, user.ID), 1e-9)
+}
+
+func TestParallelMixedOperationsBalance(t *testing.T) {
+	db := concurrencyDB(t)
+	wallet := concurrencyWallet(t, db)
+	user := concurrencyUser(t, db, 1000)
+
+	// Stakes to settle and cancel while new ones are placed.
+	const n = 15
+	for i := 0; i < 2*n; i++ {
+		_, err := wallet.Withdraw(context.Background(), usecase.WithdrawInput{
+			UserID:       user.ID,
+			Amount:       10,
+			Currency:     "USD",
+			ProviderTxID: fmt.Sprintf("%s-s%d", user.Username, i),
+		})
+		require.NoError(t, err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 3*n)
+	for i := 0; i < n; i++ {
+		wg.Add(3)
+		go func(i int) {
+			defer wg.Done()
+			_, err := wallet.Withdraw(context.Background(), usecase.WithdrawInput{
+				UserID:       user.ID,
+				Amount:       7,
+				Currency:     "USD",
+				ProviderTxID: fmt.Sprintf("%s-w%d", user.Username, i),
+			})
+			errs <- err
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			_, err := wallet.Deposit(context.Background(), usecase.DepositInput{
+				UserID:             user.ID,
+				Amount:             25,
+				Currency:           "USD",
+				ProviderTxID:       fmt.Sprintf("%s-d%d", user.Username, i),
+				ProviderParentTxID: fmt.Sprintf("%s-s%d", user.Username, i),
+			})
+			errs <- err
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			_, err := wallet.Cancel(context.Background(), usecase.CancelInput{
+				UserID:       user.ID,
+				ProviderTxID: fmt.Sprintf("%s-s%d", user.Username, n+i),
+			})
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	// 30 stakes of 10, 15 of 7, 15 wins of 25 and 15 refunds of 10.
+	assert.InDelta(t, 1000-2*n*10-n*7+n*25+n*10, storedBalance(t, db, user.ID), 1e-9)
+}
+
+func TestStakeOverBalanceNeverReachesWallet(t *testing.T) {
+	db, wallet, recorder := cancelWallet(t)
+	user := concurrencyUser(t, db, 5)
+
+	_, err := wallet.Withdraw(context.Background(), usecase.WithdrawInput{UserID: user.ID, Amount: 10, Currency: "USD", ProviderTxID: user.Username + "-w1"})
+	assert.ErrorIs(t, err, usecase.ErrInsufficientFunds)
+	assert.Empty(t, recorder.since(0), "the wallet is not debited")
+	assert.InDelta(t, 5, storedBalance(t, db, user.ID), 1e-9)
+}
@@ -0,0 +1,252 @@
+package http_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gameintegrationapi/internal/infrastructure"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requiredConfig holds the values that have no default.
+const requiredConfig = `
+db:
+  user: gameuser
+  name: gamedb
+wallet:
+  url: http://wallet:8000
+  token: wallet-token
+`
+
+// configEnv lists the variables the tests set, cleared first so the
+// environment running them cannot leak in.
+var configEnv = []string{"APP_PROFILE", "CONFIG_FILE", "PORT", "DB_HOST", "DB_USER", "DB_NAME", "DB_PASSWORD", "WALLET_URL", "WALLET_TOKEN", "WALLET_TOKEN_FILE", "WALLET_TIMEOUT", "JWT_KEYS_DIR", "TRUSTED_PROXIES", "PROVIDER_KEYS"}
+
+// inConfigDir runs the test from a directory whose config/ holds files, with
+// env set on top of a cleared environment.
+func inConfigDir(t *testing.T, files, env map[string]string) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "config"), 0o755))
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "config", name), []byte(content), 0o600))
+	}
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) })
+	for _, key := range configEnv {
+		t.Setenv(key, "")
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+}
+
+func TestConfigLayers(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		files map[string]string
+		env   map[string]string
+		port  string
+		host  string
+	}{
+		{
+			name:  "defaults",
+			files: map[string]string{"config.yaml": requiredConfig},
+			port:  "8080",
+			host:  "localhost",
+		},
+		{
+			name:  "shared file",
+			files: map[string]string{"config.yaml": requiredConfig + "server:\n  port: \"9000\"\n"},
+			port:  "9000",
+			host:  "localhost",
+		},
+		{
+			name: "profile file over shared file",
+			files: map[string]string{
+				"config.yaml":      requiredConfig + "server:\n  port: \"9000\"\n",
+				"config.test.yaml": "server:\n  port: \"9100\"\ndb:\n  host: db\n",
+				"config.prod.yaml": "server:\n  port: \"9900\"\n",
+			},
+			env:  map[string]string{"APP_PROFILE": "test"},
+			port: "9100",
+			host: "db",
+		},
+		{
+			name: "env over files",
+			files: map[string]string{
+				"config.yaml":      requiredConfig + "server:\n  port: \"9000\"\n",
+				"config.test.yaml": "server:\n  port: \"9100\"\n",
+			},
+			env:  map[string]string{"APP_PROFILE": "test", "PORT": "9200", "DB_HOST": "pg"},
+			port: "9200",
+			host: "pg",
+		},
+		{
+			name: "CONFIG_FILE replaces the shared file",
+			files: map[string]string{
+				"config.yaml":     "server:\n  port: \"9000\"\n",
+				"custom.yaml":     requiredConfig + "server:\n  port: \"9300\"\n",
+				"config.dev.yaml": "db:\n  host: dev-db\n",
+			},
+			env:  map[string]string{"CONFIG_FILE": filepath.Join("config", "custom.yaml")},
+			port: "9300",
+			host: "dev-db",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			inConfigDir(t, tc.files, tc.env)
+			cfg, err := infrastructure.LoadConfig()
+			require.NoError(t, err)
+			assert.Equal(t, tc.port, cfg.Server.Port)
+			assert.Equal(t, tc.host, cfg.DB.Host)
+		})
+	}
+}
+
+func TestConfigTOML(t *testing.T) {
+	inConfigDir(t, map[string]string{
+		"config.toml": `
+[db]
+user = "gameuser"
+name = "gamedb"
+
+[wallet]
+url = "http://wallet:8000"
+token = "wallet-token"
+timeout = "3s"
+
+[rate_limit.bet]
+per_second = 5.5
+burst = 10
+`,
+		"config.test.toml": "[server]\nport = \"9100\"\n",
+	}, map[string]string{"APP_PROFILE": "test"})
+	cfg, err := infrastructure.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "9100", cfg.Server.Port)
+	assert.Equal(t, "wallet-token", cfg.Wallet.Token)
+	assert.Equal(t, 3*time.Second, cfg.Wallet.Timeout)
+	assert.Equal(t, 5.5, cfg.RateLimit.Bet.PerSecond)
+	assert.Equal(t, 10, cfg.RateLimit.Bet.Burst)
+
+	inConfigDir(t, map[string]string{"config.toml": "[wallet]\nuri = \"http://wallet:8000\"\n"}, nil)
+	_, err = infrastructure.LoadConfig()
+	assert.ErrorContains(t, err, "uri")
+}
+
+func TestConfigSecretFiles(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "wallet_token")
+	require.NoError(t, os.WriteFile(secret, []byte("from-file\n"), 0o600))
+
+	for _, tc := range []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"file", map[string]string{"WALLET_TOKEN_FILE": secret}, "from-file"},
+		{"variable wins", map[string]string{"WALLET_TOKEN_FILE": secret, "WALLET_TOKEN": "from-env"}, "from-env"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			inConfigDir(t, map[string]string{"config.yaml": requiredConfig}, tc.env)
+			cfg, err := infrastructure.LoadConfig()
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, cfg.Wallet.Token)
+		})
+	}
+
+	inConfigDir(t, map[string]string{"config.yaml": requiredConfig}, map[string]string{"WALLET_TOKEN_FILE": secret + ".missing"})
+	_, err := infrastructure.LoadConfig()
+	assert.ErrorContains(t, err, "WALLET_TOKEN_FILE")
+}
+
+func TestConfigValidationErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		files map[string]string
+		env   map[string]string
+		want  []string
+	}{
+		{
+			name:  "missing required values",
+			files: map[string]string{},
+			want:  []string{"db.user: required", "db.name: required", "wallet.url: required", "wallet.token: required"},
+		},
+		{
+			name:  "bad values",
+			files: map[string]string{"config.yaml": requiredConfig + "server:\n  port: \"http\"\n"},
+			env:   map[string]string{"WALLET_URL": "wallet:8000"},
+			want:  []string{"server.port: must be a port number", "wallet.url: must be an absolute URL"},
+		},
+		{
+			name:  "prod needs signing keys",
+			files: map[string]string{"config.yaml": requiredConfig},
+			env:   map[string]string{"APP_PROFILE": "prod"},
+			want:  []string{"auth.jwt_keys_dir: required in the prod profile", "auth.totp_encryption_key: required"},
+		},
+		{
+			name:  "unknown profile",
+			files: map[string]string{"config.yaml": requiredConfig},
+			env:   map[string]string{"APP_PROFILE": "staging"},
+			want:  []string{`unknown profile "staging"`},
+		},
+		{
+			name:  "unparsable env",
+			files: map[string]string{"config.yaml": requiredConfig},
+			env:   map[string]string{"WALLET_TIMEOUT": "soon"},
+			want:  []string{"invalid value for WALLET_TIMEOUT"},
+		},
+		{
+			name:  "bad proxies and provider keys",
+			files: map[string]string{"config.yaml": requiredConfig},
+			env:   map[string]string{"TRUSTED_PROXIES": "10.0.0.0/8, proxy.local", "PROVIDER_KEYS": "p1=k1,p2"},
+			want:  []string{`server.trusted_proxies: "proxy.local" is not an IP or CIDR`, "providers.keys: must be comma-separated id=key pairs"},
+		},
+		{
+			name:  "player locks take the whole pool",
+			files: map[string]string{"config.yaml": requiredConfig},
+			env:   map[string]string{"DB_MAX_OPEN_CONNS": "10", "DB_MAX_PLAYER_LOCKS": "10"},
+			want:  []string{"db.max_player_locks: must be at least 0 and below db.max_open_conns"},
+		},
+		{
+			name:  "unknown key",
+			files: map[string]string{"config.yaml": requiredConfig + "walet:\n  url: x\n"},
+			want:  []string{"walet"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			inConfigDir(t, tc.files, tc.env)
+			_, err := infrastructure.LoadConfig()
+			require.Error(t, err)
+			for _, want := range tc.want {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestConfigPrintRedactsSecrets(t *testing.T) {
+	inConfigDir(t, map[string]string{"config.yaml": requiredConfig}, map[string]string{"DB_PASSWORD": "db-pass"})
+	cfg, err := infrastructure.LoadConfig()
+	require.NoError(t, err)
+
+	out, err := cfg.YAML(true)
+	require.NoError(t, err)
+	for _, secret := range []string{"db-pass", "wallet-token", cfg.Auth.TOTPEncryptionKey} {
+		assert.NotContains(t, string(out), secret)
+	}
+	assert.Equal(t, 3, strings.Count(string(out), "******"))
+	assert.Contains(t, string(out), "url: http://wallet:8000")
+	assert.Contains(t, string(out), "read_timeout: 10s")
+
+	out, err = cfg.YAML(false)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "token: wallet-token")
+	assert.Contains(t, string(out), "password: db-pass")
+}
@@ -0,0 +1,140 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type currencyWalletUseCase struct {
+	mockWalletUseCase
+}
+
+// Withdraw supports stakes in USD and EUR only.
+func (m *currencyWalletUseCase) Withdraw(ctx context.Context, in usecase.WithdrawInput) (*domain.Transaction, error) {
+	if in.Currency != "USD" && in.Currency != "EUR" {
+		return nil, usecase.ErrCurrencyUnsupported
+	}
+	return &domain.Transaction{ID: 1, ProviderTxID: in.ProviderTxID, OldBalance: 100, NewBalance: 100 - in.Amount, Status: "COMPLETED"}, nil
+}
+
+type mockCurrencyUseCase struct{}
+
+func (m *mockCurrencyUseCase) ListBalances(ctx context.Context, userID uint) ([]domain.PlayerBalance, error) {
+	return nil, nil
+}
+
+// OpenBalance knows EUR as already held and XXX as unquoted.
+func (m *mockCurrencyUseCase) OpenBalance(ctx context.Context, userID uint, currency string) (*domain.PlayerBalance, error) {
+	switch currency {
+	case "EUR":
+		return nil, usecase.ErrBalanceExists
+	case "XXX":
+		return nil, usecase.ErrCurrencyUnsupported
+	}
+	return &domain.PlayerBalance{ID: 1, UserID: userID, Currency: currency}, nil
+}
+
+func (m *mockCurrencyUseCase) Book(ctx context.Context, user *domain.User, currency string, amount float64, balanceCurrency string) (*usecase.Booking, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockCurrencyUseCase) Convert(ctx context.Context, amount float64, from, to string) (float64, error) {
+	return amount, nil
+}
+
+func (m *mockCurrencyUseCase) Report(ctx context.Context, from, to time.Time) (*usecase.CurrencyReport, error) {
+	return &usecase.CurrencyReport{From: from, To: to, BaseCurrency: "USD", Totals: []repository.CurrencyTotal{
+		{Currency: "EUR", BaseCurrency: "USD", Wagered: 92, BaseWagered: 100, Transactions: 3},
+	}}, nil
+}
+
+func currencyRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{
+		WalletUseCase:            &currencyWalletUseCase{},
+		ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{},
+		CurrencyUseCase:          &mockCurrencyUseCase{},
+	}
+	r := gin.New()
+	withUser := func(c *gin.Context) { c.Set("userID", uint(1)) }
+	r.POST("/bet/withdraw", withUser, h.Withdraw)
+	r.POST("/balances", withUser, h.OpenBalance)
+	r.GET("/admin/reports/currencies", withUser, h.CurrencyReport)
+	return r
+}
+
+func TestStaticRateProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("as_of: 2025-01-01T00:00:00Z\nrates:\n  USD: 1\n  EUR: 0.8\n  KES: 120\n"), 0o600))
+
+	rates, err := infrastructure.NewStaticRateProvider(infrastructure.FXConfig{BaseCurrency: "USD", RatesFile: path})
+	assert.NoError(t, err)
+	rate, err := rates.Rate(context.Background(), "eur", "KES")
+	assert.NoError(t, err)
+	assert.InDelta(t, 150, rate.Rate, 1e-9)
+	_, err = rates.Rate(context.Background(), "EUR", "GBP")
+	assert.ErrorIs(t, err, infrastructure.ErrRateUnavailable)
+
+	bare, err := infrastructure.NewStaticRateProvider(infrastructure.FXConfig{BaseCurrency: "USD"})
+	assert.NoError(t, err)
+	rate, err = bare.Rate(context.Background(), "KES", "KES")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, rate.Rate)
+	_, err = bare.Rate(context.Background(), "KES", "USD")
+	assert.ErrorIs(t, err, infrastructure.ErrRateUnavailable)
+}
+
+func TestWithdrawInUnsupportedCurrency(t *testing.T) {
+	r := currencyRouter()
+	w := postJSON(r, "/bet/withdraw", map[string]interface{}{
+		"currency":                "JPY",
+		"amount":                  10,
+		"provider_transaction_id": "provider-tx-1",
+	})
+	assert.Equal(t, 422, w.Code)
+	var errResp httpdelivery.BetErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResp))
+	assert.Equal(t, usecase.CurrencyUnsupportedCode, errResp.Code)
+}
+
+func TestOpenBalance(t *testing.T) {
+	r := currencyRouter()
+	assert.Equal(t, 201, postJSON(r, "/balances", map[string]interface{}{"currency": "GBP"}).Code)
+	assert.Equal(t, 409, postJSON(r, "/balances", map[string]interface{}{"currency": "EUR"}).Code)
+	assert.Equal(t, 400, postJSON(r, "/balances", map[string]interface{}{"currency": "XXX"}).Code)
+}
+
+func TestCurrencyReport(t *testing.T) {
+	r := currencyRouter()
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/admin/reports/currencies"+query, nil)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, 400, get("?from=yesterday&to=2025-01-02").Code)
+
+	w := get("?from=2025-01-01&to=2025-01-02")
+	assert.Equal(t, 200, w.Code)
+	var resp httpdelivery.CurrencyReportResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Totals, 1)
+	assert.Equal(t, 100.0, resp.Totals[0].BaseWagered)
+}
@@ -0,0 +1,54 @@
+package http_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExcludedPlayerCanSettleButNotBet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys, err := infrastructure.NewEphemeralJWTKeys()
+	require.NoError(t, err)
+	h := &httpdelivery.Handlers{JWTKeys: keys, ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{}}
+	r := gin.New()
+	ok := func(c *gin.Context) { c.Status(204) }
+	r.POST("/bet/withdraw", h.AuthMiddleware(), ok)
+	r.POST("/bet/deposit", h.AuthMiddlewareAllowExcluded(), ok)
+
+	call := func(path string, userID uint) *httptest.ResponseRecorder {
+		token, err := keys.GenerateJWT(userID, "player", "player", false)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := call("/bet/withdraw", 2)
+	assert.Equal(t, 403, w.Code)
+	assert.Contains(t, w.Body.String(), usecase.PlayerExcludedCode)
+	assert.Equal(t, 204, call("/bet/deposit", 2).Code)
+	assert.Equal(t, 204, call("/bet/withdraw", 1).Code)
+}
+
+func TestExcludeRejectsUnknownType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{}}
+	r := gin.New()
+	r.POST("/exclusion", func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		h.Exclude(c)
+	})
+
+	assert.Equal(t, 400, postJSON(r, "/exclusion", map[string]interface{}{"type": "forever"}).Code)
+	assert.Equal(t, 201, postJSON(r, "/exclusion", map[string]interface{}{"type": "timeout", "days": 7}).Code)
+}
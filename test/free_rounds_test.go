@@ -0,0 +1,250 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type freeRoundWalletUseCase struct {
+	mockWalletUseCase
+}
+
+// Withdraw has free rounds only for book-of-ra.
+func (m *freeRoundWalletUseCase) Withdraw(ctx context.Context, in usecase.WithdrawInput) (*domain.Transaction, error) {
+	if in.FreeRound && in.GameID != "book-of-ra" {
+		return nil, usecase.ErrNoFreeRounds
+	}
+	return &domain.Transaction{ID: 1, ProviderTxID: in.ProviderTxID, OldBalance: 1000, NewBalance: 1000, Status: "COMPLETED"}, nil
+}
+
+// Deposit treats every stake as a paid one.
+func (m *freeRoundWalletUseCase) Deposit(ctx context.Context, in usecase.DepositInput) (*domain.Transaction, error) {
+	if in.FreeRound {
+		return nil, usecase.ErrNotFreeRound
+	}
+	return &domain.Transaction{ID: 2, ProviderTxID: in.ProviderTxID, Status: "WON"}, nil
+}
+
+type mockFreeRoundUseCase struct {
+	granted usecase.FreeRoundInput
+}
+
+func (m *mockFreeRoundUseCase) Grant(ctx context.Context, in usecase.FreeRoundInput) (*domain.FreeRoundGrant, error) {
+	m.granted = in
+	return &domain.FreeRoundGrant{
+		ID: 3, UserID: in.UserID, GameID: in.GameID, Count: in.Count, Remaining: in.Count, BetValue: in.BetValue,
+		WinTo: "REAL", Status: domain.FreeRoundsStatusActive, ExpiresAt: time.Now().AddDate(0, 0, in.ValidDays),
+	}, nil
+}
+
+// Revoke knows grant 3 as active and grant 4 as already revoked.
+func (m *mockFreeRoundUseCase) Revoke(ctx context.Context, grantID uint, revokedBy string) (*domain.FreeRoundGrant, error) {
+	switch grantID {
+	case 3:
+		now := time.Now()
+		return &domain.FreeRoundGrant{ID: 3, Status: domain.FreeRoundsStatusRevoked, RevokedBy: revokedBy, RevokedAt: &now}, nil
+	case 4:
+		return nil, usecase.ErrFreeRoundsRevoked
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockFreeRoundUseCase) ListGrants(ctx context.Context, userID uint) ([]domain.FreeRoundGrant, error) {
+	return nil, nil
+}
+
+func (m *mockFreeRoundUseCase) FindGrant(ctx context.Context, grantID uint) (*domain.FreeRoundGrant, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockFreeRoundUseCase) FindUsable(ctx context.Context, userID uint, gameID string, betValue float64) (*domain.FreeRoundGrant, error) {
+	return nil, usecase.ErrNoFreeRounds
+}
+
+func freeRoundRouter(freeRounds *mockFreeRoundUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{
+		WalletUseCase:            &freeRoundWalletUseCase{},
+		ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{},
+		FreeRoundUseCase:         freeRounds,
+	}
+	r := gin.New()
+	withUser := func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Set("username", "admin")
+	}
+	r.POST("/bet/withdraw", withUser, h.Withdraw)
+	r.POST("/bet/deposit", withUser, h.Deposit)
+	r.POST("/admin/users/:id/free-rounds", withUser, h.GrantFreeRounds)
+	r.POST("/admin/free-rounds/:id/revoke", withUser, h.RevokeFreeRounds)
+	return r
+}
+
+func TestFreeRoundWithdraw(t *testing.T) {
+	r := freeRoundRouter(&mockFreeRoundUseCase{})
+	stake := func(gameID string) map[string]interface{} {
+		body := map[string]interface{}{
+			"currency":                "USD",
+			"amount":                  0.2,
+			"provider_transaction_id": "provider-tx-1",
+			"free_round":              true,
+		}
+		if gameID != "" {
+			body["game_id"] = gameID
+		}
+		return body
+	}
+
+	assert.Equal(t, 400, postJSON(r, "/bet/withdraw", stake("")).Code)
+
+	w := postJSON(r, "/bet/withdraw", stake("starburst"))
+	assert.Equal(t, 409, w.Code)
+	var errResp httpdelivery.BetErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResp))
+	assert.Equal(t, usecase.NoFreeRoundsCode, errResp.Code)
+
+	w = postJSON(r, "/bet/withdraw", stake("book-of-ra"))
+	assert.Equal(t, 200, w.Code)
+	var resp httpdelivery.BetResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, resp.OldBalance, resp.NewBalance)
+}
+
+func TestFreeRoundDepositOnPaidStakeIsRejected(t *testing.T) {
+	r := freeRoundRouter(&mockFreeRoundUseCase{})
+	w := postJSON(r, "/bet/deposit", map[string]interface{}{
+		"currency":                          "USD",
+		"amount":                            5,
+		"provider_transaction_id":           "provider-tx-2",
+		"provider_withdrawn_transaction_id": "provider-tx-1",
+		"free_round":                        true,
+	})
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestGrantFreeRoundsValidates(t *testing.T) {
+	freeRounds := &mockFreeRoundUseCase{}
+	r := freeRoundRouter(freeRounds)
+	grant := map[string]interface{}{"game_id": "book-of-ra", "count": 20, "bet_value": 0.2, "valid_days": 7, "win_to": "cash"}
+
+	assert.Equal(t, 400, postJSON(r, "/admin/users/5/free-rounds", grant).Code)
+
+	grant["win_to"] = "bonus"
+	w := postJSON(r, "/admin/users/5/free-rounds", grant)
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, usecase.FreeRoundInput{UserID: 5, GameID: "book-of-ra", Count: 20, BetValue: 0.2, ValidDays: 7, WinTo: "bonus", CreatedBy: "admin"}, freeRounds.granted)
+}
+
+func TestRevokeFreeRoundsMapsErrors(t *testing.T) {
+	r := freeRoundRouter(&mockFreeRoundUseCase{})
+	assert.Equal(t, 200, postJSON(r, "/admin/free-rounds/3/revoke", nil).Code)
+	assert.Equal(t, 409, postJSON(r, "/admin/free-rounds/4/revoke", nil).Code)
+	assert.Equal(t, 404, postJSON(r, "/admin/free-rounds/9/revoke", nil).Code)
+}
+
+// memoryFreeRoundRepository keeps grants in memory.
+type memoryFreeRoundRepository struct {
+	repository.FreeRoundRepository
+	grants []domain.FreeRoundGrant
+}
+
+func (m *memoryFreeRoundRepository) Create(ctx context.Context, grant *domain.FreeRoundGrant) error {
+	grant.ID = uint(len(m.grants) + 1)
+	m.grants = append(m.grants, *grant)
+	return nil
+}
+
+func (m *memoryFreeRoundRepository) FindByID(ctx context.Context, id uint) (*domain.FreeRoundGrant, error) {
+	for _, g := range m.grants {
+		if g.ID == id {
+			return &g, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *memoryFreeRoundRepository) FindUsable(ctx context.Context, userID uint, gameID string, betValue float64, now time.Time) (*domain.FreeRoundGrant, error) {
+	var usable *domain.FreeRoundGrant
+	for i, g := range m.grants {
+		if g.UserID == userID && g.GameID == gameID && g.BetValue == betValue && g.UsableAt(now) &&
+			(usable == nil || g.ExpiresAt.Before(usable.ExpiresAt)) {
+			usable = &m.grants[i]
+		}
+	}
+	return usable, nil
+}
+
+func (m *memoryFreeRoundRepository) Revoke(ctx context.Context, grant *domain.FreeRoundGrant) error {
+	for i, g := range m.grants {
+		if g.ID == grant.ID && g.Status == domain.FreeRoundsStatusActive {
+			m.grants[i] = *grant
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func TestGrantFreeRoundsWinningToBonusNeedsActiveBonus(t *testing.T) {
+	bonuses := &memoryBonusRepository{}
+	users := &walletUserRepository{user: domain.User{ID: 1, WalletID: "123", Currency: "USD"}}
+	grants := &memoryFreeRoundRepository{}
+	freeRounds := usecase.NewFreeRoundUseCase(grants, users, usecase.NewBonusUseCase(bonuses, users, nil, nil, nil, nil, infrastructure.BonusConfig{}))
+	ctx := context.Background()
+	in := usecase.FreeRoundInput{UserID: 1, GameID: "book-of-ra", Count: 10, BetValue: 0.2, ValidDays: 7, WinTo: "bonus", CreatedBy: "admin"}
+
+	var validation *usecase.ValidationError
+	_, err := freeRounds.Grant(ctx, in)
+	assert.ErrorAs(t, err, &validation)
+	assert.Empty(t, grants.grants)
+
+	bonuses.bonuses = []domain.PlayerBonus{{ID: 4, UserID: 1, Status: domain.BonusStatusActive, ExpiresAt: time.Now().AddDate(0, 0, 7)}}
+	grant, err := freeRounds.Grant(ctx, in)
+	require.NoError(t, err)
+	require.NotNil(t, grant.PlayerBonusID)
+	assert.Equal(t, uint(4), *grant.PlayerBonusID)
+	assert.Equal(t, domain.FreeRoundsWinToBonus, grant.WinTo)
+	assert.Equal(t, 10, grant.Remaining)
+}
+
+func TestFreeRoundsUsableUntilRevoked(t *testing.T) {
+	users := &walletUserRepository{user: domain.User{ID: 1, WalletID: "123", Currency: "USD"}}
+	freeRounds := usecase.NewFreeRoundUseCase(&memoryFreeRoundRepository{}, users, nil)
+	ctx := context.Background()
+
+	grant, err := freeRounds.Grant(ctx, usecase.FreeRoundInput{UserID: 1, GameID: "book-of-ra", Count: 10, BetValue: 0.2, ValidDays: 7, WinTo: "real"})
+	require.NoError(t, err)
+	usable, err := freeRounds.FindUsable(ctx, 1, "book-of-ra", 0.2)
+	require.NoError(t, err)
+	assert.Equal(t, grant.ID, usable.ID)
+	for name, stake := range map[string]struct {
+		game  string
+		value float64
+	}{
+		"other game":      {"starburst", 0.2},
+		"other bet value": {"book-of-ra", 1},
+	} {
+		_, err := freeRounds.FindUsable(ctx, 1, stake.game, stake.value)
+		assert.ErrorIs(t, err, usecase.ErrNoFreeRounds, name)
+	}
+
+	revoked, err := freeRounds.Revoke(ctx, grant.ID, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", revoked.RevokedBy)
+	_, err = freeRounds.FindUsable(ctx, 1, "book-of-ra", 0.2)
+	assert.ErrorIs(t, err, usecase.ErrNoFreeRounds)
+	_, err = freeRounds.Revoke(ctx, grant.ID, "admin")
+	assert.ErrorIs(t, err, usecase.ErrFreeRoundsRevoked)
+}
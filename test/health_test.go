@@ -0,0 +1,41 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/infrastructure"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthzReturnsOK(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{}
+	r := gin.New()
+	r.GET("/healthz", h.Healthz)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/healthz", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), "ok")
+}
+
+func TestWalletClientCallsThroughBreaker(t *testing.T) {
+	wallet := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"balance": "12.50", "currency": "USD"}`))
+	}))
+	defer wallet.Close()
+
+	client := infrastructure.NewWalletClient(infrastructure.WalletConfig{URL: wallet.URL, Timeout: time.Second, BreakerThreshold: 3, BreakerCooldown: time.Second})
+	balance, err := client.GetBalance(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 12.5, balance.Balance)
+	assert.Equal(t, infrastructure.BreakerClosed, client.BreakerState())
+}
@@ -0,0 +1,297 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/repository"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type jackpotWalletUseCase struct {
+	mockWalletUseCase
+}
+
+// Deposit pays jackpot 1 at 5000 for stake provider-tx-1 and knows no other
+// pool.
+func (m *jackpotWalletUseCase) Deposit(ctx context.Context, in usecase.DepositInput) (*domain.Transaction, error) {
+	if in.JackpotID == 0 {
+		return &domain.Transaction{ID: 2, ProviderTxID: in.ProviderTxID, Amount: in.Amount, Status: "WON"}, nil
+	}
+	if in.JackpotID != 1 {
+		return nil, usecase.ErrJackpotNotFound
+	}
+	if in.ProviderParentTxID != "provider-tx-1" {
+		return nil, usecase.ErrJackpotNotQualified
+	}
+	return &domain.Transaction{ID: 2, ProviderTxID: in.ProviderTxID, Amount: 5000, NewBalance: 6000, Status: "WON", JackpotPoolID: &in.JackpotID}, nil
+}
+
+type mockJackpotUseCase struct {
+	created  *domain.JackpotPool
+	currency string
+}
+
+func (m *mockJackpotUseCase) ListPools(ctx context.Context, currency, gameGroup string) ([]domain.JackpotPool, error) {
+	m.currency = currency
+	return []domain.JackpotPool{{ID: 1, Name: "Mega Fruits", GameGroup: "fruits", Currency: "USD", ContributionPct: 1.5, SeedAmount: 1000, Amount: 1234.5}}, nil
+}
+
+func (m *mockJackpotUseCase) CreatePool(ctx context.Context, pool *domain.JackpotPool) error {
+	pool.ID, pool.Amount = 2, pool.SeedAmount
+	m.created = pool
+	return nil
+}
+
+func (m *mockJackpotUseCase) AssignGame(ctx context.Context, gameID, gameGroup string) error {
+	return nil
+}
+
+func (m *mockJackpotUseCase) Contributions(ctx context.Context, gameID, currency string, stake float64) ([]usecase.JackpotContribution, error) {
+	return nil, nil
+}
+
+func (m *mockJackpotUseCase) Claim(ctx context.Context, poolID uint, gameID, currency string) (float64, error) {
+	return 0, usecase.ErrJackpotNotFound
+}
+
+func (m *mockJackpotUseCase) Unclaim(ctx context.Context, poolID uint, amount float64) error {
+	return nil
+}
+
+func jackpotRouter(jackpots *mockJackpotUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{
+		WalletUseCase:            &jackpotWalletUseCase{},
+		ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{},
+		JackpotUseCase:           jackpots,
+	}
+	r := gin.New()
+	withUser := func(c *gin.Context) { c.Set("userID", uint(1)) }
+	r.GET("/jackpots", h.ListJackpots)
+	r.POST("/bet/deposit", withUser, h.Deposit)
+	r.POST("/admin/jackpots", withUser, h.CreateJackpot)
+	return r
+}
+
+func TestListJackpots(t *testing.T) {
+	jackpots := &mockJackpotUseCase{}
+	r := jackpotRouter(jackpots)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/jackpots?currency=usd", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "usd", jackpots.currency)
+	var resp []httpdelivery.JackpotResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp, 1)
+	assert.Equal(t, 1234.5, resp[0].Amount)
+}
+
+func TestJackpotDeposit(t *testing.T) {
+	r := jackpotRouter(&mockJackpotUseCase{})
+	win := func(jackpotID uint) map[string]interface{} {
+		return map[string]interface{}{
+			"currency":                          "USD",
+			"amount":                            0,
+			"provider_transaction_id":           "provider-tx-2",
+			"provider_withdrawn_transaction_id": "provider-tx-1",
+			"jackpot_id":                        jackpotID,
+		}
+	}
+
+	assert.Equal(t, 404, postJSON(r, "/bet/deposit", win(7)).Code)
+
+	unsettled := win(1)
+	unsettled["provider_withdrawn_transaction_id"] = "provider-tx-9"
+	w := postJSON(r, "/bet/deposit", unsettled)
+	assert.Equal(t, 422, w.Code)
+	assert.Contains(t, w.Body.String(), usecase.JackpotNotQualifiedCode)
+
+	w = postJSON(r, "/bet/deposit", win(1))
+	assert.Equal(t, 200, w.Code)
+	var resp httpdelivery.BetResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 6000.0, resp.NewBalance)
+}
+
+func TestCreateJackpotValidates(t *testing.T) {
+	jackpots := &mockJackpotUseCase{}
+	r := jackpotRouter(jackpots)
+	pool := map[string]interface{}{"name": "Mega Fruits", "game_group": "fruits", "currency": "USD", "contribution_pct": 150, "seed_amount": 1000}
+
+	assert.Equal(t, 400, postJSON(r, "/admin/jackpots", pool).Code)
+
+	pool["contribution_pct"] = 1.5
+	w := postJSON(r, "/admin/jackpots", pool)
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, 1000.0, jackpots.created.Amount)
+}
+
+// jackpotPool creates a pool for a group holding only gameID, named
+// uniquely so runs against the same database do not collide.
+func jackpotPool(t *testing.T, db *gorm.DB, gameID, currency string) *domain.JackpotPool {
+	jackpots := usecase.NewJackpotUseCase(repository.NewJackpotRepository(db))
+	ctx := context.Background()
+	pool := &domain.JackpotPool{Name: "pool-" + gameID, GameGroup: "group-" + gameID, Currency: currency, ContributionPct: 1, SeedAmount: 500}
+	require.NoError(t, jackpots.CreatePool(ctx, pool))
+	require.NoError(t, jackpots.AssignGame(ctx, gameID, pool.GameGroup))
+	return pool
+}
+
+func TestJackpotPaysOnlyQualifyingStakeOnce(t *testing.T) {
+	db := concurrencyDB(t)
+	wallet := concurrencyWallet(t, db)
+	user := concurrencyUser(t, db, 100)
+	ctx := context.Background()
+	game := user.Username + "-slot"
+	pool := jackpotPool(t, db, game, "USD")
+	other := jackpotPool(t, db, user.Username+"-other", "USD")
+
+	stake := func(id, gameID string) {
+		_, err := wallet.Withdraw(ctx, usecase.WithdrawInput{UserID: user.ID, Amount: 10, Currency: "USD", ProviderTxID: id, GameID: gameID})
+		require.NoError(t, err)
+	}
+	win := func(id, stakeID string, poolID uint) (*domain.Transaction, error) {
+		return wallet.Deposit(ctx, usecase.DepositInput{UserID: user.ID, Currency: "USD", ProviderTxID: id, ProviderParentTxID: stakeID, JackpotID: poolID})
+	}
+	stake(user.Username+"-s1", game)
+
+	// No stake, a stake on a game outside the pool's group, and another
+	// player's pool are all refused without touching the pool.
+	_, err := win(user.Username+"-j0", "", pool.ID)
+	assert.ErrorIs(t, err, usecase.ErrJackpotNotQualified)
+	_, err = win(user.Username+"-j0", user.Username+"-s1", other.ID)
+	assert.ErrorIs(t, err, usecase.ErrJackpotNotQualified)
+	var stored domain.JackpotPool
+	require.NoError(t, db.First(&stored, other.ID).Error)
+	assert.Equal(t, 500.0, stored.Amount)
+
+	require.NoError(t, db.Model(pool).Update("amount", 2500).Error)
+	paid, err := win(user.Username+"-j1", user.Username+"-s1", pool.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2500.0, paid.Amount)
+
+	// A retry returns the payout instead of claiming the reseeded pool.
+	retried, err := win(user.Username+"-j1", user.Username+"-s1", pool.ID)
+	require.NoError(t, err)
+	assert.Equal(t, paid.ID, retried.ID)
+	assert.InDelta(t, 100-10+2500, storedBalance(t, db, user.ID), 1e-9)
+
+	// The provider ID of the stake cannot be reused for a payout.
+	_, err = win(user.Username+"-s1", user.Username+"-s1", pool.ID)
+	assert.ErrorIs(t, err, usecase.ErrDuplicateTransaction)
+}
+
+// memoryJackpotRepository keeps pools and game groups in memory.
+type memoryJackpotRepository struct {
+	repository.JackpotRepository
+	pools []domain.JackpotPool
+	games map[string]string
+}
+
+func (m *memoryJackpotRepository) FindPoolsForGame(ctx context.Context, gameID, currency string) ([]domain.JackpotPool, error) {
+	var pools []domain.JackpotPool
+	for _, p := range m.pools {
+		if group, ok := m.games[gameID]; ok && p.GameGroup == group && p.Currency == currency {
+			pools = append(pools, p)
+		}
+	}
+	return pools, nil
+}
+
+func (m *memoryJackpotRepository) pool(id uint) *domain.JackpotPool {
+	for i := range m.pools {
+		if m.pools[i].ID == id {
+			return &m.pools[i]
+		}
+	}
+	return nil
+}
+
+func (m *memoryJackpotRepository) Contribute(ctx context.Context, poolID uint, amount float64) error {
+	m.pool(poolID).Amount += amount
+	return nil
+}
+
+func (m *memoryJackpotRepository) Claim(ctx context.Context, poolID uint, now time.Time) (*domain.JackpotPool, error) {
+	p := m.pool(poolID)
+	if p == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	won := *p
+	p.Amount, p.LastWinAmount, p.LastWonAt = p.SeedAmount, won.Amount, &now
+	return &won, nil
+}
+
+func (m *memoryJackpotRepository) Unclaim(ctx context.Context, poolID uint, amount float64) error {
+	p := m.pool(poolID)
+	p.Amount += amount - p.SeedAmount
+	return nil
+}
+
+func TestJackpotClaimRequiresQualifyingStake(t *testing.T) {
+	repo := &memoryJackpotRepository{
+		pools: []domain.JackpotPool{
+			{ID: 1, Name: "mega", GameGroup: "slots", Currency: "EUR", ContributionPct: 1, SeedAmount: 1000, Amount: 5000},
+			{ID: 2, Name: "table", GameGroup: "tables", Currency: "EUR", ContributionPct: 2, SeedAmount: 100, Amount: 300},
+		},
+		games: map[string]string{"book-of-ra": "slots", "roulette": "tables"},
+	}
+	jackpots := usecase.NewJackpotUseCase(repo)
+	ctx := context.Background()
+
+	// A stake on another group's game, or in another currency, wins nothing
+	// and leaves the pool as it was.
+	for name, stake := range map[string][2]string{
+		"other group":    {"roulette", "EUR"},
+		"other currency": {"book-of-ra", "USD"},
+		"no group":       {"starburst", "EUR"},
+	} {
+		_, err := jackpots.Claim(ctx, 1, stake[0], stake[1])
+		assert.ErrorIs(t, err, usecase.ErrJackpotNotQualified, name)
+	}
+	assert.Equal(t, 5000.0, repo.pool(1).Amount)
+
+	won, err := jackpots.Claim(ctx, 1, "book-of-ra", "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, 5000.0, won)
+	assert.Equal(t, 1000.0, repo.pool(1).Amount)
+	assert.Equal(t, 300.0, repo.pool(2).Amount)
+}
+
+func TestJackpotUnclaimKeepsLaterContributions(t *testing.T) {
+	repo := &memoryJackpotRepository{
+		pools: []domain.JackpotPool{{ID: 1, Name: "mega", GameGroup: "slots", Currency: "EUR", ContributionPct: 1, SeedAmount: 1000, Amount: 5000}},
+		games: map[string]string{"book-of-ra": "slots"},
+	}
+	jackpots := usecase.NewJackpotUseCase(repo)
+	ctx := context.Background()
+
+	won, err := jackpots.Claim(ctx, 1, "book-of-ra", "EUR")
+	require.NoError(t, err)
+	contributions, err := jackpots.Contributions(ctx, "book-of-ra", "EUR", 200)
+	require.NoError(t, err)
+	require.Equal(t, []usecase.JackpotContribution{{PoolID: 1, Amount: 2}}, contributions)
+	require.NoError(t, repo.Contribute(ctx, 1, contributions[0].Amount))
+
+	// The payout failed: the win goes back, and so does the stake made since.
+	require.NoError(t, jackpots.Unclaim(ctx, 1, won))
+	assert.Equal(t, 5002.0, repo.pool(1).Amount)
+
+	none, err := jackpots.Contributions(ctx, "starburst", "EUR", 200)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
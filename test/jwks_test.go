@@ -0,0 +1,119 @@
+package http_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http/httptest"
+	"testing"
+
+	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/infrastructure"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pemBlock(typ string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+}
+
+// rotatedKeys returns the key set before and after rotating from an RSA key
+// to an Ed25519 key. After rotation only the old public key is kept.
+func rotatedKeys(t *testing.T) (before, after *infrastructure.JWTKeys) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	rsaDER, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	require.NoError(t, err)
+	rsaPublicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+	oldPrivate := pemBlock("PRIVATE KEY", rsaDER)
+	oldPublic := pemBlock("PUBLIC KEY", rsaPublicDER)
+	newPrivate := pemBlock("PRIVATE KEY", edDER)
+
+	before, err = infrastructure.NewJWTKeysFromPEM("old", map[string][]byte{"old": oldPrivate})
+	require.NoError(t, err)
+	after, err = infrastructure.NewJWTKeysFromPEM("new", map[string][]byte{"old": oldPublic, "new": newPrivate})
+	require.NoError(t, err)
+	return before, after
+}
+
+func TestJWTKeyRotationKeepsOldTokensValid(t *testing.T) {
+	before, after := rotatedKeys(t)
+
+	oldToken, err := before.GenerateJWT(1, "user", "player", false)
+	require.NoError(t, err)
+	claims, err := after.ParseJWT(oldToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user", claims.Username)
+
+	newToken, err := after.GenerateJWT(2, "other", "player", false)
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &infrastructure.Claims{})
+	require.NoError(t, err)
+	assert.Equal(t, "new", parsed.Header["kid"])
+	assert.Equal(t, "EdDSA", parsed.Header["alg"])
+
+	_, err = before.ParseJWT(newToken)
+	assert.Error(t, err, "a key set without the new key must reject its tokens")
+}
+
+func TestAuthMiddlewareRejectsSharedSecretTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, keys := rotatedKeys(t)
+	h := &httpdelivery.Handlers{JWTKeys: keys, ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{}}
+	r := gin.New()
+	r.GET("/profile", h.AuthMiddleware(), func(c *gin.Context) { c.Status(204) })
+
+	hs := jwt.NewWithClaims(jwt.SigningMethodHS256, infrastructure.Claims{UserID: 1, Username: "user"})
+	hs.Header["kid"] = "old"
+	forged, err := hs.SignedString([]byte("your-secret-key"))
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+forged)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, 401, w.Code)
+
+	valid, err := keys.GenerateJWT(1, "user", "player", false)
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+valid)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, 204, w.Code)
+}
+
+func TestJWKSListsAllPublicKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, keys := rotatedKeys(t)
+	h := &httpdelivery.Handlers{JWTKeys: keys}
+	r := gin.New()
+	r.GET("/.well-known/jwks.json", h.JWKS)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+	assert.Equal(t, 200, w.Code)
+
+	var set infrastructure.JWKSet
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &set))
+	require.Len(t, set.Keys, 2)
+	assert.Equal(t, "new", set.Keys[0].Kid)
+	assert.Equal(t, "OKP", set.Keys[0].Kty)
+	assert.NotEmpty(t, set.Keys[0].X)
+	assert.Equal(t, "old", set.Keys[1].Kid)
+	assert.Equal(t, "RS256", set.Keys[1].Alg)
+	assert.NotEmpty(t, set.Keys[1].N)
+	assert.NotContains(t, w.Body.String(), "\"d\"")
+}
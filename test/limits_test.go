@@ -0,0 +1,351 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type mockResponsibleGamingUseCase struct {
+	set []usecase.LimitInput
+}
+
+func (m *mockResponsibleGamingUseCase) GetLimits(ctx context.Context, userID uint) ([]usecase.LimitStatus, error) {
+	from := time.Now().Add(24 * time.Hour)
+	return []usecase.LimitStatus{
+		{Type: domain.LimitTypeLoss, Period: domain.LimitPeriodDaily, Amount: 100, Used: 40, PendingAmount: 200, PendingFrom: &from},
+	}, nil
+}
+
+func (m *mockResponsibleGamingUseCase) SetLimits(ctx context.Context, userID uint, limits []usecase.LimitInput) ([]usecase.LimitStatus, error) {
+	m.set = limits
+	return nil, nil
+}
+
+func (m *mockResponsibleGamingUseCase) CheckStake(ctx context.Context, userID uint, amount float64) error {
+	return nil
+}
+
+// AuthorizeDeposit refuses deposits over 100.
+func (m *mockResponsibleGamingUseCase) AuthorizeDeposit(ctx context.Context, in usecase.DepositAuthorization) (*domain.PlayerDeposit, error) {
+	if in.Amount > 100 {
+		return nil, &usecase.LimitExceededError{Type: domain.LimitTypeDeposit, Period: domain.LimitPeriodDaily, Limit: 100, Remaining: 100}
+	}
+	return &domain.PlayerDeposit{UserID: 1, Reference: in.Reference, Amount: in.Amount}, nil
+}
+
+func (m *mockResponsibleGamingUseCase) Exclude(ctx context.Context, userID uint, in usecase.ExclusionInput) (*domain.Exclusion, error) {
+	return &domain.Exclusion{ID: 1, UserID: userID, Type: in.Type, StartsAt: time.Now()}, nil
+}
+
+// ActiveExclusion reports user 2 as excluded.
+func (m *mockResponsibleGamingUseCase) ActiveExclusion(ctx context.Context, userID uint) (*domain.Exclusion, error) {
+	if userID == 2 {
+		until := time.Now().Add(7 * 24 * time.Hour)
+		return &domain.Exclusion{ID: 1, UserID: userID, Type: domain.ExclusionTypeTimeout, EndsAt: &until}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockResponsibleGamingUseCase) ListExclusions(ctx context.Context) ([]domain.Exclusion, error) {
+	return nil, nil
+}
+
+func (m *mockResponsibleGamingUseCase) TouchSession(ctx context.Context, userID uint) (*usecase.RealityCheck, error) {
+	return nil, nil
+}
+
+type limitedWalletUseCase struct {
+	mockWalletUseCase
+}
+
+func (m *limitedWalletUseCase) Withdraw(ctx context.Context, in usecase.WithdrawInput) (*domain.Transaction, error) {
+	return nil, &usecase.LimitExceededError{Type: domain.LimitTypeWager, Period: domain.LimitPeriodDaily, Limit: 50, Remaining: 10}
+}
+
+func limitsRouter(rg usecase.ResponsibleGamingUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{ResponsibleGamingUseCase: rg}
+	r := gin.New()
+	withUser := func(c *gin.Context) { c.Set("userID", uint(1)) }
+	r.GET("/limits", withUser, h.GetLimits)
+	r.PUT("/limits", withUser, h.SetLimits)
+	return r
+}
+
+func putLimits(r *gin.Engine, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/limits", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGetLimitsShowsPendingRaise(t *testing.T) {
+	r := limitsRouter(&mockResponsibleGamingUseCase{})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/limits", nil))
+	assert.Equal(t, 200, w.Code)
+
+	var resp httpdelivery.LimitsResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Limits, 1)
+	assert.Equal(t, "loss", resp.Limits[0].Type)
+	assert.Equal(t, "daily", resp.Limits[0].Period)
+	assert.Equal(t, 200.0, *resp.Limits[0].PendingAmount)
+}
+
+func TestSetLimitsValidatesItems(t *testing.T) {
+	rg := &mockResponsibleGamingUseCase{}
+	r := limitsRouter(rg)
+
+	assert.Equal(t, 400, putLimits(r, `{"limits":[{"type":"loss","period":"yearly","amount":10}]}`).Code)
+	assert.Equal(t, 400, putLimits(r, `{"limits":[{"type":"loss","period":"daily","amount":-1}]}`).Code)
+	assert.Equal(t, 400, putLimits(r, `{"limits":[{"type":"loss","period":"daily","amount":10,"extra":1}]}`).Code)
+	assert.Equal(t, 400, putLimits(r, `{"limits":[]}`).Code)
+
+	w := putLimits(r, `{"limits":[{"type":"wager","period":"weekly","amount":500}]}`)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, []usecase.LimitInput{{Type: "wager", Period: "weekly", Amount: 500}}, rg.set)
+}
+
+func TestWithdrawOverLimitReturnsCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{WalletUseCase: &limitedWalletUseCase{}, ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{}}
+	r := gin.New()
+	r.POST("/bet/withdraw", func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		h.Withdraw(c)
+	})
+
+	w := postJSON(r, "/bet/withdraw", map[string]interface{}{
+		"currency":                "USD",
+		"amount":                  40,
+		"provider_transaction_id": "provider-tx-1",
+	})
+	assert.Equal(t, 403, w.Code)
+	var resp httpdelivery.BetErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, usecase.LimitExceededCode, resp.Code)
+}
+
+func postDeposit(r *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/cashier/deposits", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(httpdelivery.CashierKeyHeader, key)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthorizeDepositRequiresCashierKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := `{"wallet_id":"34633089486","amount":50,"currency":"USD","reference":"dep-1"}`
+	for _, h := range []*httpdelivery.Handlers{
+		{ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{}},
+		{ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{}, CashierKey: "cashier"},
+	} {
+		r := gin.New()
+		r.POST("/cashier/deposits", h.CashierAuth(), h.AuthorizeDeposit)
+		assert.Equal(t, 401, postDeposit(r, "", body).Code)
+		assert.Equal(t, 401, postDeposit(r, "guess", body).Code)
+		if h.CashierKey != "" {
+			assert.Equal(t, 200, postDeposit(r, "cashier", body).Code)
+		}
+	}
+}
+
+func TestAuthorizeDepositOverLimitReturnsCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{}, CashierKey: "cashier"}
+	r := gin.New()
+	r.POST("/cashier/deposits", h.CashierAuth(), h.AuthorizeDeposit)
+
+	w := postDeposit(r, "cashier", `{"wallet_id":"34633089486","amount":150,"currency":"USD","reference":"dep-1"}`)
+	assert.Equal(t, 403, w.Code)
+	var resp httpdelivery.BetErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, usecase.LimitExceededCode, resp.Code)
+
+	assert.Equal(t, 400, postDeposit(r, "cashier", `{"wallet_id":"34633089486","amount":-5,"currency":"USD","reference":"dep-2"}`).Code)
+}
+
+// memoryLimitRepository keeps limits and deposits in memory.
+type memoryLimitRepository struct {
+	limits   []domain.PlayerLimit
+	deposits []domain.PlayerDeposit
+}
+
+func (m *memoryLimitRepository) FindByUser(ctx context.Context, userID uint) ([]domain.PlayerLimit, error) {
+	return m.limits, nil
+}
+
+func (m *memoryLimitRepository) Save(ctx context.Context, limit *domain.PlayerLimit) error {
+	for i, l := range m.limits {
+		if l.Type == limit.Type && l.Period == limit.Period {
+			m.limits[i] = *limit
+			return nil
+		}
+	}
+	m.limits = append(m.limits, *limit)
+	return nil
+}
+
+func (m *memoryLimitRepository) SumDeposits(ctx context.Context, userID uint, since time.Time) (float64, error) {
+	var total float64
+	for _, d := range m.deposits {
+		if d.UserID == userID && !d.CreatedAt.Before(since) {
+			total += d.Amount
+		}
+	}
+	return total, nil
+}
+
+func (m *memoryLimitRepository) AddDeposit(ctx context.Context, deposit *domain.PlayerDeposit, check func(repository.PlayerLimitRepository) error) (*domain.PlayerDeposit, error) {
+	for _, d := range m.deposits {
+		if d.Reference == deposit.Reference {
+			return &d, nil
+		}
+	}
+	if err := check(m); err != nil {
+		return nil, err
+	}
+	m.deposits = append(m.deposits, *deposit)
+	return deposit, nil
+}
+
+type noExclusionRepository struct {
+	repository.ExclusionRepository
+}
+
+func (m *noExclusionRepository) FindActive(ctx context.Context, userID uint, now time.Time) (*domain.Exclusion, error) {
+	return nil, nil
+}
+
+type walletUserRepository struct {
+	repository.UserRepository
+	user domain.User
+}
+
+func (m *walletUserRepository) FindByWalletID(ctx context.Context, walletID string) (*domain.User, error) {
+	if walletID != m.user.WalletID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	user := m.user
+	return &user, nil
+}
+
+func (m *walletUserRepository) FindByID(ctx context.Context, id uint) (*domain.User, error) {
+	user := m.user
+	return &user, nil
+}
+
+// activityTransactionRepository reports fixed activity per balance currency.
+type activityTransactionRepository struct {
+	repository.TransactionRepository
+	totals []repository.ActivityTotal
+}
+
+func (m *activityTransactionRepository) SumActivity(ctx context.Context, userID uint, since time.Time) ([]repository.ActivityTotal, error) {
+	return m.totals, nil
+}
+
+// eurRates converts at 0.8 EUR to the USD.
+func eurRates(t *testing.T) infrastructure.RateProvider {
+	path := filepath.Join(t.TempDir(), "rates.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("rates:\n  USD: 1\n  EUR: 0.8\n"), 0o600))
+	rates, err := infrastructure.NewStaticRateProvider(infrastructure.FXConfig{BaseCurrency: "USD", RatesFile: path})
+	require.NoError(t, err)
+	return rates
+}
+
+func TestLimitsCountSecondaryBalancesInPrimaryCurrency(t *testing.T) {
+	rates := eurRates(t)
+	limits := &memoryLimitRepository{}
+	users := &walletUserRepository{user: domain.User{ID: 1, WalletID: "123", Currency: "USD"}}
+	// 80 EUR staked and 40 EUR won are 100 and 50 USD.
+	txs := &activityTransactionRepository{totals: []repository.ActivityTotal{
+		{Currency: "USD", Wagered: 100, Won: 20},
+		{Currency: "EUR", Wagered: 80, Won: 40},
+	}}
+	rg := usecase.NewResponsibleGamingUseCase(limits, &noExclusionRepository{}, nil, users, txs, rates, infrastructure.ResponsibleGamingConfig{LimitCoolingOff: time.Hour})
+	ctx := context.Background()
+
+	statuses, err := rg.SetLimits(ctx, 1, []usecase.LimitInput{
+		{Type: "wager", Period: "daily", Amount: 250},
+		{Type: "loss", Period: "daily", Amount: 1000},
+	})
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.Equal(t, domain.LimitTypeLoss, statuses[0].Type)
+	assert.InDelta(t, 130, statuses[0].Used, 1e-9)
+	assert.Equal(t, domain.LimitTypeWager, statuses[1].Type)
+	assert.InDelta(t, 200, statuses[1].Used, 1e-9)
+
+	// Summing raw amounts would leave 70 of the wager limit, not 50.
+	var limitErr *usecase.LimitExceededError
+	require.ErrorAs(t, rg.CheckStake(ctx, 1, 60), &limitErr)
+	assert.InDelta(t, 50, limitErr.Remaining, 1e-9)
+	assert.NoError(t, rg.CheckStake(ctx, 1, 50))
+
+	// Activity that cannot be converted is not silently left out.
+	txs.totals = append(txs.totals, repository.ActivityTotal{Currency: "KES", Wagered: 1000})
+	assert.ErrorIs(t, rg.CheckStake(ctx, 1, 1), usecase.ErrCurrencyUnsupported)
+}
+
+func TestDepositLimitCapsDepositsNotStakes(t *testing.T) {
+	limits := &memoryLimitRepository{}
+	users := &walletUserRepository{user: domain.User{ID: 1, WalletID: "123", Currency: "USD"}}
+	rg := usecase.NewResponsibleGamingUseCase(limits, &noExclusionRepository{}, nil, users, nil, nil, infrastructure.ResponsibleGamingConfig{LimitCoolingOff: time.Hour})
+	ctx := context.Background()
+
+	_, err := rg.SetLimits(ctx, 1, []usecase.LimitInput{{Type: "deposit", Period: "daily", Amount: 100}})
+	require.NoError(t, err)
+
+	deposit := func(reference string, amount float64) error {
+		_, err := rg.AuthorizeDeposit(ctx, usecase.DepositAuthorization{WalletID: "123", Amount: amount, Currency: "usd", Reference: reference})
+		return err
+	}
+	require.NoError(t, deposit("dep-1", 60))
+	// A repeated reference is approved again without counting twice.
+	require.NoError(t, deposit("dep-1", 60))
+	require.NoError(t, deposit("dep-2", 40))
+
+	var limitErr *usecase.LimitExceededError
+	require.ErrorAs(t, deposit("dep-3", 0.01), &limitErr)
+	assert.Equal(t, domain.LimitTypeDeposit, limitErr.Type)
+	assert.Equal(t, 0.0, limitErr.Remaining)
+	assert.Len(t, limits.deposits, 2)
+
+	statuses, err := rg.GetLimits(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, 100.0, statuses[0].Used)
+
+	// Deposits do not count towards stakes, nor a deposit limit against them.
+	assert.NoError(t, rg.CheckStake(ctx, 1, 500))
+
+	var validation *usecase.ValidationError
+	_, err = rg.AuthorizeDeposit(ctx, usecase.DepositAuthorization{WalletID: "123", Amount: 5, Currency: "EUR", Reference: "dep-4"})
+	assert.ErrorAs(t, err, &validation)
+	_, err = rg.AuthorizeDeposit(ctx, usecase.DepositAuthorization{WalletID: "999", Amount: 5, Currency: "USD", Reference: "dep-5"})
+	assert.ErrorIs(t, err, usecase.ErrPlayerNotFound)
+}
@@ -0,0 +1,108 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var platformLogConfig = infrastructure.PlatformLogConfig{
+	Enabled:      true,
+	Retention:    time.Hour,
+	MaxBodyBytes: 1024,
+	RedactFields: []string{"password", "Email"},
+}
+
+type exchangeTransactionRepository struct {
+	repository.TransactionRepository
+	saved []*domain.PlatformExchange
+}
+
+func (m *exchangeTransactionRepository) SaveExchange(ctx context.Context, userID uint, exchange *domain.PlatformExchange) error {
+	m.saved = append(m.saved, exchange)
+	return nil
+}
+
+func TestExchangeRedactsBodies(t *testing.T) {
+	logger := infrastructure.NewExchangeLogger(platformLogConfig)
+	_, e := logger.Start(context.Background(), "POST", "/bet/withdraw", []byte(`{"amount": 10.10, "player": {"EMAIL": "a@b.c", "tags": [{"password": "x"}]}}`))
+	record := e.Attach()
+	assert.JSONEq(t, `{"amount": 10.10, "player": {"EMAIL": "[REDACTED]", "tags": [{"password": "[REDACTED]"}]}}`, string(record.Request.Body))
+
+	_, e = logger.Start(context.Background(), "POST", "/bet/withdraw", []byte("amount=10&password=x"))
+	assert.JSONEq(t, `"[20 bytes omitted: not JSON]"`, string(e.Attach().Request.Body))
+
+	disabled := infrastructure.NewExchangeLogger(infrastructure.PlatformLogConfig{})
+	_, e = disabled.Start(context.Background(), "POST", "/bet/withdraw", nil)
+	assert.Nil(t, e)
+	assert.Nil(t, e.Attach())
+}
+
+func TestWalletCallsAreRecorded(t *testing.T) {
+	wallet := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"balance": 90, "email": "player@example.com"}`))
+	}))
+	defer wallet.Close()
+	client := infrastructure.NewWalletClient(infrastructure.WalletConfig{URL: wallet.URL, Timeout: time.Second, BreakerThreshold: 3, BreakerCooldown: time.Second})
+
+	ctx, e := infrastructure.NewExchangeLogger(platformLogConfig).Start(context.Background(), "POST", "/bet/withdraw", nil)
+	_, err := client.Withdraw(ctx, infrastructure.WalletWithdrawRequest{Currency: "USD", UserID: 7})
+	assert.NoError(t, err)
+
+	record := e.Attach()
+	assert.Len(t, record.Wallet, 1)
+	call := record.Wallet[0]
+	assert.Equal(t, wallet.URL+infrastructure.WalletWithdrawEndpoint, call.Request.URL)
+	assert.Contains(t, string(call.Request.Body), `"userId":7`)
+	assert.Equal(t, 200, call.Response.Status)
+	assert.Contains(t, string(call.Response.Body), `"email":"[REDACTED]"`)
+	assert.GreaterOrEqual(t, call.DurationMS, 0.0)
+}
+
+func TestRecordExchangeStoresResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &exchangeTransactionRepository{}
+	h := &httpdelivery.Handlers{PlatformLogUseCase: usecase.NewPlatformLogUseCase(repo, platformLogConfig)}
+	r := gin.New()
+	bet := r.Group("/bet", h.RecordExchange(), func(c *gin.Context) { c.Set("userID", uint(1)) })
+	// record stands in for a handler whose bet is recorded.
+	bet.POST("/record", func(c *gin.Context) {
+		var req map[string]interface{}
+		assert.NoError(t, c.ShouldBindJSON(&req))
+		infrastructure.ExchangeFrom(c.Request.Context()).Attach()
+		c.JSON(http.StatusOK, gin.H{"transaction_id": 1, "amount": req["amount"]})
+	})
+	bet.POST("/reject", func(c *gin.Context) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+	})
+
+	assert.Equal(t, 400, postJSON(r, "/bet/reject", map[string]interface{}{"amount": 5}).Code)
+	assert.Empty(t, repo.saved)
+
+	w := postJSON(r, "/bet/record", map[string]interface{}{"amount": 5, "password": "secret"})
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"transaction_id": 1, "amount": 5}`, w.Body.String())
+	assert.Len(t, repo.saved, 1)
+	saved := repo.saved[0]
+	assert.Equal(t, "/bet/record", saved.Request.URL)
+	assert.JSONEq(t, `{"amount": 5, "password": "[REDACTED]"}`, string(saved.Request.Body))
+	assert.Equal(t, 200, saved.Response.Status)
+	assert.JSONEq(t, `{"transaction_id": 1, "amount": 5}`, string(saved.Response.Body))
+
+	var doc domain.PlatformRecord
+	data, _ := json.Marshal(domain.PlatformRecord{Exchange: saved})
+	assert.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, saved.ID, doc.Exchange.ID)
+}
@@ -0,0 +1,138 @@
+package http_test
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/infrastructure"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginRateLimitReturns429WithRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := infrastructure.NewRateLimiter(infrastructure.NewMemoryRateLimitStore(), map[string]infrastructure.Rate{
+		infrastructure.RateLimitPolicyLogin: {PerSecond: 0.5, Burst: 2},
+	})
+	h := &httpdelivery.Handlers{RateLimiter: limiter}
+	r := gin.New()
+	r.GET("/limited", h.RateLimit(infrastructure.RateLimitPolicyLogin, httpdelivery.ClientIPKey), func(c *gin.Context) {
+		c.Status(200)
+	})
+
+	codes := make([]int, 0, 3)
+	var last *httptest.ResponseRecorder
+	for i := 0; i < 3; i++ {
+		last = httptest.NewRecorder()
+		r.ServeHTTP(last, httptest.NewRequest("GET", "/limited", nil))
+		codes = append(codes, last.Code)
+	}
+	assert.Equal(t, []int{200, 200, 429}, codes)
+	assert.Equal(t, "2", last.Header().Get("Retry-After"))
+}
+
+func providerRouter(keys map[string]string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	limiter := infrastructure.NewRateLimiter(infrastructure.NewMemoryRateLimitStore(), map[string]infrastructure.Rate{
+		infrastructure.RateLimitPolicyProvider: {PerSecond: 0.01, Burst: 1},
+	})
+	h := &httpdelivery.Handlers{RateLimiter: limiter, ProviderKeys: keys}
+	r := gin.New()
+	r.GET("/bet", h.ProviderIdentity(), h.RateLimit(infrastructure.RateLimitPolicyProvider, httpdelivery.ProviderKey), func(c *gin.Context) {
+		c.Status(200)
+	})
+	return r
+}
+
+func providerRequest(r *gin.Engine, id, key string) int {
+	req := httptest.NewRequest("GET", "/bet", nil)
+	if id != "" {
+		req.Header.Set(httpdelivery.ProviderIDHeader, id)
+		req.Header.Set(httpdelivery.ProviderKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestProviderLimitKeyedOnVerifiedProvider(t *testing.T) {
+	r := providerRouter(map[string]string{"p1": "k1", "p2": "k2"})
+	assert.Equal(t, 200, providerRequest(r, "p1", "k1"))
+	assert.Equal(t, 429, providerRequest(r, "p1", "k1"))
+	assert.Equal(t, 200, providerRequest(r, "p2", "k2"))
+
+	// Naming a provider without its key neither passes nor opens a bucket.
+	assert.Equal(t, 401, providerRequest(r, "p2", "k1"))
+	assert.Equal(t, 401, providerRequest(r, "p3", ""))
+}
+
+func TestProviderLimitKeyedOnClientIPWithoutIdentity(t *testing.T) {
+	r := providerRouter(map[string]string{"p1": "k1"})
+	assert.Equal(t, 200, providerRequest(r, "", ""))
+	assert.Equal(t, 429, providerRequest(r, "", ""))
+
+	// Another unidentified caller has a bucket of its own.
+	req := httptest.NewRequest("GET", "/bet", nil)
+	req.RemoteAddr = "198.51.100.7:1234"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	// Without configured keys the header is not an identity, so rotating it
+	// does not open new buckets.
+	r = providerRouter(nil)
+	assert.Equal(t, 200, providerRequest(r, "a", ""))
+	assert.Equal(t, 429, providerRequest(r, "b", ""))
+}
+
+func TestLoginLimitTrustsForwardedForOnlyFromProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	login := func(proxies []string) []int {
+		limiter := infrastructure.NewRateLimiter(infrastructure.NewMemoryRateLimitStore(), map[string]infrastructure.Rate{
+			infrastructure.RateLimitPolicyLogin: {PerSecond: 0.01, Burst: 1},
+		})
+		r, err := httpdelivery.NewRouter(&httpdelivery.Handlers{AuthUseCase: &mockAuthUseCase{}, RateLimiter: limiter}, proxies)
+		assert.NoError(t, err)
+		var codes []int
+		for _, ip := range []string{"198.51.100.1", "198.51.100.2"} {
+			req := httptest.NewRequest("POST", "/auth/login", strings.NewReader(`{"username":"u","password":"p"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Forwarded-For", ip)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			codes = append(codes, w.Code)
+		}
+		return codes
+	}
+	assert.Equal(t, 429, login(nil)[1])
+	// httptest requests come from 192.0.2.1.
+	assert.NotEqual(t, 429, login([]string{"192.0.2.1"})[1])
+}
+
+func TestPostgresRateLimitStoreDeletesRefilledBuckets(t *testing.T) {
+	db := concurrencyDB(t)
+	store, err := infrastructure.NewPostgresRateLimitStore(db)
+	require.NoError(t, err)
+	ctx := context.Background()
+	rate := infrastructure.Rate{PerSecond: 1, Burst: 2}
+	idle := fmt.Sprintf("idle-%d", time.Now().UnixNano())
+	busy := fmt.Sprintf("busy-%d", time.Now().UnixNano())
+
+	start := time.Now()
+	_, err = store.Take(ctx, idle, rate, start)
+	require.NoError(t, err)
+	// The next sweep is due a minute on, by when idle has long refilled.
+	_, err = store.Take(ctx, busy, rate, start.Add(2*time.Minute))
+	require.NoError(t, err)
+
+	var keys []string
+	require.NoError(t, db.Model(&infrastructure.RateLimitBucket{}).Where("key IN ?", []string{idle, busy}).Pluck("key", &keys).Error)
+	assert.Equal(t, []string{busy}, keys)
+}
@@ -0,0 +1,141 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type mockReconciliationUseCase struct {
+	running bool
+}
+
+func (m *mockReconciliationUseCase) Run(ctx context.Context, day time.Time) (*domain.ReconciliationRun, error) {
+	return nil, nil
+}
+
+func (m *mockReconciliationUseCase) Start(ctx context.Context, day time.Time) error {
+	if m.running {
+		return usecase.ErrReconciliationRunning
+	}
+	m.running = true
+	return nil
+}
+
+func (m *mockReconciliationUseCase) ListRuns(ctx context.Context) ([]domain.ReconciliationRun, error) {
+	return nil, nil
+}
+
+// Report knows only 2025-01-31.
+func (m *mockReconciliationUseCase) Report(ctx context.Context, day time.Time) (*usecase.ReconciliationReport, error) {
+	if day.Format(time.DateOnly) != "2025-01-31" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &usecase.ReconciliationReport{
+		Run: domain.ReconciliationRun{Day: day, Status: domain.ReconciliationStatusCompleted, Users: 2, Mismatches: 2},
+		Mismatches: []domain.ReconciliationMismatch{
+			{UserID: 1, Kind: domain.MismatchMissingRemotely, Reference: "provider-tx-1", Currency: "USD", LocalAmount: -10},
+			{UserID: 2, Kind: domain.MismatchAmountDiffers, Reference: "provider-tx-2", Currency: "EUR", LocalAmount: 5, WalletAmount: 4.5},
+		},
+	}, nil
+}
+
+func reconciliationRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{ReconciliationUseCase: &mockReconciliationUseCase{}}
+	r := gin.New()
+	r.GET("/admin/reconciliations/:day", h.GetReconciliation)
+	r.POST("/admin/reconciliations/:day/run", h.RunReconciliation)
+	return r
+}
+
+func getPath(r *gin.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGetReconciliation(t *testing.T) {
+	r := reconciliationRouter()
+	assert.Equal(t, 400, getPath(r, "/admin/reconciliations/yesterday").Code)
+	assert.Equal(t, 404, getPath(r, "/admin/reconciliations/2025-01-30").Code)
+
+	w := getPath(r, "/admin/reconciliations/2025-01-31")
+	assert.Equal(t, 200, w.Code)
+	var resp httpdelivery.ReconciliationReportResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "completed", resp.Status)
+	assert.Len(t, resp.Items, 2)
+	assert.Equal(t, "missing_remotely", resp.Items[0].Kind)
+}
+
+func TestExportReconciliationCSV(t *testing.T) {
+	r := reconciliationRouter()
+	w := getPath(r, "/admin/reconciliations/2025-01-31?format=csv")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, "2025-01-31,2,amount_differs,provider-tx-2,EUR,5.00,4.50,", lines[2])
+
+	assert.Equal(t, 400, getPath(r, "/admin/reconciliations/2025-01-31?format=xlsx").Code)
+}
+
+func TestRunReconciliationOneAtATime(t *testing.T) {
+	r := reconciliationRouter()
+	assert.Equal(t, 202, postJSON(r, "/admin/reconciliations/2025-01-31/run", nil).Code)
+	assert.Equal(t, 409, postJSON(r, "/admin/reconciliations/2025-01-31/run", nil).Code)
+}
+
+func TestReconciliationRunsOneAtATimeAcrossInstances(t *testing.T) {
+	db := concurrencyDB(t)
+	ctx := context.Background()
+	// Each use case stands for an instance of the service.
+	first := usecase.NewReconciliationUseCase(repository.NewReconciliationRepository(db), nil, nil, nil)
+	second := usecase.NewReconciliationUseCase(repository.NewReconciliationRepository(db), nil, nil, nil)
+
+	unlock, ok, err := repository.NewReconciliationRepository(db).LockRuns(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.ErrorIs(t, first.Start(ctx, time.Now()), usecase.ErrReconciliationRunning)
+	_, err = second.Run(ctx, time.Now())
+	assert.ErrorIs(t, err, usecase.ErrReconciliationRunning)
+
+	unlock()
+	unlock, ok, err = repository.NewReconciliationRepository(db).LockRuns(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok, "released")
+	unlock()
+}
+
+func TestWalletListTransactions(t *testing.T) {
+	wallet := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/transactions/7", r.URL.Path)
+		assert.Equal(t, "2025-01-31T00:00:00Z", r.URL.Query().Get("from"))
+		w.Write([]byte(`{"transactions": [{"id": 1, "reference": "provider-tx-1", "type": "withdraw", "amount": 10, "currency": "USD"}]}`))
+	}))
+	defer wallet.Close()
+
+	client := infrastructure.NewWalletClient(infrastructure.WalletConfig{URL: wallet.URL, Timeout: time.Second, BreakerThreshold: 3, BreakerCooldown: time.Second})
+	day := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	entries, err := client.ListTransactions(context.Background(), 7, day, day.AddDate(0, 0, 1))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "withdraw", entries[0].Type)
+}
@@ -0,0 +1,150 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/repository"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRevenueRepository struct {
+	days    map[string]int
+	groupBy []string
+}
+
+func (m *mockRevenueRepository) AggregateDay(ctx context.Context, day, now time.Time) (int64, error) {
+	m.days[day.Format(time.DateOnly)]++
+	return 3, nil
+}
+
+func (m *mockRevenueRepository) Sum(ctx context.Context, from, to time.Time, groupBy []string) ([]repository.RevenueTotal, error) {
+	m.groupBy = groupBy
+	return []repository.RevenueTotal{{
+		Day: from, GameID: "game-1", Currency: "EUR", Bets: 10,
+		Turnover: 100, Wins: 70, BonusStakes: 20, BonusWins: 5, BonusReleased: 2,
+		BaseTurnover: 110, BaseWins: 77,
+	}}, nil
+}
+
+func revenueRouter(repo *mockRevenueRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{RevenueUseCase: usecase.NewRevenueUseCase(repo)}
+	r := gin.New()
+	r.GET("/admin/reports/revenue", h.RevenueReport)
+	r.POST("/admin/reports/revenue/:day/aggregate", h.AggregateRevenue)
+	return r
+}
+
+func TestRevenueReport(t *testing.T) {
+	repo := &mockRevenueRepository{}
+	r := revenueRouter(repo)
+	assert.Equal(t, 400, getPath(r, "/admin/reports/revenue?from=2025-01-31&to=2025-01-01").Code)
+	assert.Equal(t, 400, getPath(r, "/admin/reports/revenue?from=2025-01-01&to=2025-02-01&group_by=country").Code)
+
+	w := getPath(r, "/admin/reports/revenue?from=2025-01-01&to=2025-02-01&group_by=player,game")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, []string{"game", "currency", "player"}, repo.groupBy)
+	var resp httpdelivery.RevenueReportResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Lines, 1)
+	assert.Equal(t, 30.0, resp.Lines[0].GGR)
+	assert.Equal(t, 13.0, resp.Lines[0].NGR)
+	assert.Equal(t, 33.0, resp.Lines[0].BaseGGR)
+}
+
+func TestRevenueReportCSV(t *testing.T) {
+	r := revenueRouter(&mockRevenueRepository{})
+	w := getPath(r, "/admin/reports/revenue?from=2025-01-01&to=2025-01-02&format=csv")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "day,currency,bets,turnover,wins,ggr,"))
+	assert.Equal(t, "2025-01-01,EUR,10,100.00,70.00,30.00,20.00,5.00,2.00,13.00,110.00,77.00,33.00", lines[1])
+}
+
+func TestAggregateRevenueDay(t *testing.T) {
+	repo := &mockRevenueRepository{days: map[string]int{}}
+	r := revenueRouter(repo)
+	assert.Equal(t, 200, postJSON(r, "/admin/reports/revenue/2025-01-31/aggregate", nil).Code)
+	assert.Equal(t, 200, postJSON(r, "/admin/reports/revenue/2025-01-31/aggregate", nil).Code)
+	assert.Equal(t, 2, repo.days["2025-01-31"])
+	tomorrow := time.Now().UTC().AddDate(0, 0, 2).Format(time.DateOnly)
+	assert.Equal(t, 400, postJSON(r, "/admin/reports/revenue/"+tomorrow+"/aggregate", nil).Code)
+}
+
+// memoryRevenueRepository rebuilds a day's aggregates from fixed activity,
+// replacing what was aggregated for it before.
+type memoryRevenueRepository struct {
+	activity   map[string]repository.RevenueTotal
+	aggregates map[string]repository.RevenueTotal
+}
+
+func (m *memoryRevenueRepository) AggregateDay(ctx context.Context, day, now time.Time) (int64, error) {
+	key := day.Format(time.DateOnly)
+	total, ok := m.activity[key]
+	if !ok {
+		delete(m.aggregates, key)
+		return 0, nil
+	}
+	total.Day = day
+	m.aggregates[key] = total
+	return 1, nil
+}
+
+func (m *memoryRevenueRepository) Sum(ctx context.Context, from, to time.Time, groupBy []string) ([]repository.RevenueTotal, error) {
+	var sum repository.RevenueTotal
+	for _, a := range m.aggregates {
+		if !a.Day.Before(from) && a.Day.Before(to) {
+			sum.Currency = a.Currency
+			sum.Bets += a.Bets
+			sum.Turnover += a.Turnover
+			sum.Wins += a.Wins
+		}
+	}
+	return []repository.RevenueTotal{sum}, nil
+}
+
+func TestAggregateRevenueTwiceCountsDayOnce(t *testing.T) {
+	repo := &memoryRevenueRepository{
+		activity: map[string]repository.RevenueTotal{
+			"2025-01-31": {Currency: "EUR", Bets: 10, Turnover: 100, Wins: 70},
+			"2025-02-01": {Currency: "EUR", Bets: 1, Turnover: 5},
+		},
+		aggregates: map[string]repository.RevenueTotal{},
+	}
+	revenue := usecase.NewRevenueUseCase(repo)
+	ctx := context.Background()
+
+	// Every time on the UTC day, in any zone, rebuilds the same day.
+	moscow := time.FixedZone("MSK", 3*60*60)
+	for _, at := range []time.Time{
+		time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC),
+		time.Date(2025, 2, 1, 1, 0, 0, 0, moscow),
+	} {
+		_, err := revenue.Aggregate(ctx, at)
+		require.NoError(t, err)
+	}
+	require.Len(t, repo.aggregates, 1)
+
+	report, err := revenue.Report(ctx, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	require.Len(t, report.Lines, 1)
+	assert.Equal(t, int64(10), report.Lines[0].Bets)
+	assert.Equal(t, 100.0, report.Lines[0].Turnover)
+	assert.Equal(t, 30.0, report.Lines[0].GGR)
+
+	_, err = revenue.Aggregate(ctx, time.Now().AddDate(0, 0, 2))
+	var validation *usecase.ValidationError
+	assert.ErrorAs(t, err, &validation)
+}
@@ -0,0 +1,117 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// roundTransactionRepository serves the rounds round-1 (details sent with
+// the win), round-2 (no details) and shared (played on two providers).
+type roundTransactionRepository struct {
+	repository.TransactionRepository
+}
+
+func (m *roundTransactionRepository) ListByRound(ctx context.Context, roundID string, userID uint, providerID string) ([]domain.Transaction, error) {
+	all := []domain.Transaction{
+		{ID: 1, UserID: 1, Type: "WITHDRAW", Status: "COMPLETED", ProviderRoundID: "round-1", ProviderID: "p1", Amount: 10, PlatformResponse: "{}"},
+		{ID: 2, UserID: 1, Type: "DEPOSIT", Status: "WON", ProviderRoundID: "round-1", ProviderID: "p1", Amount: 25, PlatformResponse: `{"round_details":{"reels":[1,2,3]}}`},
+		{ID: 3, UserID: 1, Type: "WITHDRAW", Status: "LOST", ProviderRoundID: "round-2", ProviderID: "p1", Amount: 5, PlatformResponse: "{}"},
+		{ID: 4, UserID: 2, Type: "WITHDRAW", Status: "LOST", ProviderRoundID: "shared", ProviderID: "p1", Amount: 5, PlatformResponse: "{}"},
+		{ID: 5, UserID: 2, Type: "WITHDRAW", Status: "LOST", ProviderRoundID: "shared", ProviderID: "p2", Amount: 5, PlatformResponse: "{}"},
+	}
+	var txs []domain.Transaction
+	for _, tx := range all {
+		if tx.ProviderRoundID == roundID && (userID == 0 || tx.UserID == userID) && (providerID == "" || tx.ProviderID == providerID) {
+			txs = append(txs, tx)
+		}
+	}
+	return txs, nil
+}
+
+func roundsRouter(historyURL string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	history := infrastructure.NewRoundHistoryClient(infrastructure.RoundHistoryConfig{
+		URLs:    map[string]string{"p1": historyURL + "/rounds/{round_id}"},
+		Timeout: time.Second,
+	})
+	h := &httpdelivery.Handlers{
+		RoundUseCase:             usecase.NewRoundUseCase(&roundTransactionRepository{}, history),
+		WalletUseCase:            &mockWalletUseCase{},
+		ResponsibleGamingUseCase: &mockResponsibleGamingUseCase{},
+	}
+	r := gin.New()
+	withUser := func(c *gin.Context) { c.Set("userID", uint(1)) }
+	r.GET("/rounds/:id/details", withUser, h.RoundDetails)
+	r.GET("/admin/rounds/:id/details", h.UserRoundDetails)
+	r.POST("/bet/withdraw", withUser, h.Withdraw)
+	return r
+}
+
+func TestRoundDetails(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rounds/round-2", r.URL.Path)
+		w.Write([]byte(`{"reels":[7,7,7]}`))
+	}))
+	defer provider.Close()
+	r := roundsRouter(provider.URL)
+
+	w := getPath(r, "/rounds/round-1/details")
+	assert.Equal(t, 200, w.Code)
+	var resp httpdelivery.RoundDetailsResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Entries, 2)
+	assert.Equal(t, "stored", resp.Source)
+	assert.JSONEq(t, `{"reels":[1,2,3]}`, string(resp.Provider))
+
+	w = getPath(r, "/rounds/round-2/details")
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "provider", resp.Source)
+	assert.JSONEq(t, `{"reels":[7,7,7]}`, string(resp.Provider))
+
+	// The round belongs to another player.
+	assert.Equal(t, 404, getPath(r, "/rounds/shared/details").Code)
+	assert.Equal(t, 400, getPath(r, "/admin/rounds/shared/details").Code)
+	w = getPath(r, "/admin/rounds/shared/details?provider_id=p2")
+	assert.Equal(t, 200, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "unavailable", resp.Source)
+	assert.NotEmpty(t, resp.Error)
+}
+
+func TestRoundDetailsProviderDown(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer provider.Close()
+
+	w := getPath(roundsRouter(provider.URL), "/rounds/round-2/details")
+	assert.Equal(t, 200, w.Code)
+	var resp httpdelivery.RoundDetailsResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "unavailable", resp.Source)
+	assert.Len(t, resp.Entries, 1)
+}
+
+func TestWithdrawRoundDetailsMustBeObject(t *testing.T) {
+	r := roundsRouter("http://localhost")
+	w := postJSON(r, "/bet/withdraw", map[string]interface{}{
+		"currency":                "USD",
+		"amount":                  10,
+		"provider_transaction_id": "provider-tx-1",
+		"round_details":           []int{1, 2},
+	})
+	assert.Equal(t, 400, w.Code)
+}
@@ -0,0 +1,58 @@
+package http_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackerRefusesOperationsAfterClose(t *testing.T) {
+	tracker := usecase.NewOperationTracker()
+	assert.NoError(t, tracker.Begin())
+	tracker.Done()
+
+	tracker.Close()
+	assert.ErrorIs(t, tracker.Begin(), usecase.ErrShuttingDown)
+	assert.NoError(t, tracker.Wait(context.Background()))
+}
+
+func TestTrackerWaitReturnsOnDrain(t *testing.T) {
+	tracker := usecase.NewOperationTracker()
+	assert.NoError(t, tracker.Begin())
+	assert.NoError(t, tracker.Begin())
+	tracker.Close()
+
+	waited := make(chan error, 1)
+	go func() { waited <- tracker.Wait(context.Background()) }()
+
+	tracker.Done()
+	select {
+	case <-waited:
+		t.Fatal("Wait returned with an operation in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+	tracker.Done()
+	select {
+	case err := <-waited:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return once operations drained")
+	}
+}
+
+func TestTrackerWaitStopsAtDeadline(t *testing.T) {
+	tracker := usecase.NewOperationTracker()
+	assert.NoError(t, tracker.Begin())
+	defer tracker.Done()
+	tracker.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.ErrorIs(t, tracker.Wait(ctx), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,115 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	httpdelivery "gameintegrationapi/internal/delivery/http"
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStatementUseCase struct{}
+
+// Export knows no XXX balance and fails after the first line for user 2.
+func (m *mockStatementUseCase) Export(ctx context.Context, userID uint, currency string, from, to time.Time, w usecase.StatementWriter) error {
+	if currency == "XXX" {
+		return &usecase.ValidationError{Msg: "no XXX balance"}
+	}
+	s := &usecase.Statement{UserID: userID, Username: "player", Currency: "EUR", From: from, To: to, Opening: 100, Closing: 105}
+	if err := w.Begin(s); err != nil {
+		return err
+	}
+	at := from.Add(time.Hour)
+	lines := []domain.Transaction{
+		{Type: "WITHDRAW", Status: "LOST", ProviderGameID: "game-1", ProviderRoundID: "round-1", ProviderTxID: "tx-1", Amount: 10, NewBalance: 90, Currency: "EUR", CreatedAt: at},
+		{Type: "DEPOSIT", Status: "WON", ProviderGameID: "game-1", ProviderRoundID: "round-1", ProviderTxID: "tx-2", Amount: 15, NewBalance: 105, Currency: "EUR", CreatedAt: at},
+	}
+	for i := range lines {
+		if err := w.Line(&lines[i]); err != nil {
+			return err
+		}
+		if userID == 2 {
+			return errors.New("connection lost")
+		}
+	}
+	return w.End(s)
+}
+
+func statementRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := &httpdelivery.Handlers{StatementUseCase: &mockStatementUseCase{}}
+	r := gin.New()
+	r.GET("/transactions/export", func(c *gin.Context) { c.Set("userID", uint(1)) }, h.ExportTransactions)
+	r.GET("/admin/users/:id/transactions/export", h.ExportUserTransactions)
+	return r
+}
+
+func TestExportStatementCSV(t *testing.T) {
+	r := statementRouter()
+	assert.Equal(t, 400, getPath(r, "/transactions/export?from=2025-01-01").Code)
+	assert.Equal(t, 400, getPath(r, "/transactions/export?from=2025-01-01&to=2025-02-01&format=xlsx").Code)
+	assert.Equal(t, 400, getPath(r, "/transactions/export?from=2025-01-01&to=2025-02-01&currency=XXX").Code)
+
+	w := getPath(r, "/transactions/export?from=2025-01-01&to=2025-02-01")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "statement-1-2025-01-01-2025-02-01.csv")
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Len(t, lines, 5)
+	assert.Equal(t, "2025-01-01T00:00:00Z,opening_balance,,,,,,100.00,EUR", lines[1])
+	assert.Equal(t, "2025-01-01T01:00:00Z,withdraw,lost,game-1,round-1,tx-1,-10.00,90.00,EUR", lines[2])
+	assert.Equal(t, "2025-02-01T00:00:00Z,closing_balance,,,,,,105.00,EUR", lines[4])
+}
+
+func TestExportStatementCutShort(t *testing.T) {
+	w := getPath(statementRouter(), "/admin/users/2/transactions/export?from=2025-01-01&to=2025-02-01")
+	assert.Equal(t, 200, w.Code)
+	assert.NotContains(t, w.Body.String(), "closing_balance")
+}
+
+func TestExportStatementPDF(t *testing.T) {
+	w := getPath(statementRouter(), "/admin/users/7/transactions/export?from=2025-01-01&to=2025-02-01&format=pdf")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, "%PDF-1.4"))
+	assert.Contains(t, body, "(Opening balance: 100.00 EUR) Tj")
+	assert.Contains(t, body, "(Closing balance: 105.00 EUR) Tj")
+}
+
+func TestPDFTextWriterPagesAndXref(t *testing.T) {
+	var buf bytes.Buffer
+	p := infrastructure.NewPDFTextWriter(&buf)
+	for i := 0; i < 150; i++ {
+		assert.NoError(t, p.Line(fmt.Sprintf("line %d (escaped) \\ é", i)))
+	}
+	assert.NoError(t, p.Close())
+	doc := buf.String()
+
+	assert.Contains(t, doc, "/Count 3")
+	assert.Contains(t, doc, `(line 0 \(escaped\) \\ ?) Tj`)
+	assert.True(t, strings.HasSuffix(doc, "%%EOF\n"))
+
+	// Every xref entry must point at its object.
+	start, err := strconv.Atoi(regexp.MustCompile(`startxref\n(\d+)`).FindStringSubmatch(doc)[1])
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(doc[start:], "xref\n"))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(doc[start:], -1)
+	assert.Len(t, entries, 3+2*3)
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(e[1])
+		assert.True(t, strings.HasPrefix(doc[offset:], fmt.Sprintf("%d 0 obj\n", i+1)), "object %d", i+1)
+	}
+}
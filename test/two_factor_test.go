@@ -0,0 +1,160 @@
+package http_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gameintegrationapi/internal/domain"
+	"gameintegrationapi/internal/infrastructure"
+	"gameintegrationapi/internal/repository"
+	"gameintegrationapi/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// rfc6238Secret is the SHA-1 key of the RFC 6238 test vectors,
+// "12345678901234567890", in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPMatchesRFC6238Vectors(t *testing.T) {
+	// The RFC lists 8-digit codes; 6-digit codes are their last six digits.
+	for _, tc := range []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	} {
+		step, ok := infrastructure.ValidateTOTP(rfc6238Secret, tc.code, time.Unix(tc.unix, 0), 0)
+		assert.True(t, ok, "T=%d", tc.unix)
+		assert.Equal(t, tc.unix/30, step, "T=%d", tc.unix)
+	}
+}
+
+func TestTOTPAcceptsOneStepEitherSide(t *testing.T) {
+	// 081804 belongs to the step holding 1111111109.
+	const code = "081804"
+	issued := time.Unix(1111111109, 0)
+	step := issued.Unix() / 30
+
+	for _, tc := range []struct {
+		name string
+		now  time.Time
+		ok   bool
+	}{
+		{"same step", issued, true},
+		{"one step later", issued.Add(30 * time.Second), true},
+		{"one step earlier", issued.Add(-30 * time.Second), true},
+		{"two steps later", issued.Add(60 * time.Second), false},
+		{"two steps earlier", issued.Add(-60 * time.Second), false},
+	} {
+		got, ok := infrastructure.ValidateTOTP(rfc6238Secret, code, tc.now, 0)
+		assert.Equal(t, tc.ok, ok, tc.name)
+		if tc.ok {
+			assert.Equal(t, step, got, tc.name)
+		}
+	}
+}
+
+func TestTOTPRejectsReplayAndMalformedCodes(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	step := now.Unix() / 30
+
+	_, ok := infrastructure.ValidateTOTP(rfc6238Secret, "081804", now, step-1)
+	assert.True(t, ok)
+	_, ok = infrastructure.ValidateTOTP(rfc6238Secret, "081804", now, step)
+	assert.False(t, ok, "replayed step")
+
+	for _, code := range []string{"81804", "0081804", "081805", ""} {
+		_, ok = infrastructure.ValidateTOTP(rfc6238Secret, code, now, 0)
+		assert.False(t, ok, code)
+	}
+	_, ok = infrastructure.ValidateTOTP("not base32!", "081804", now, 0)
+	assert.False(t, ok)
+}
+
+// totpUserRepository holds one user whose TOTP fields the use case updates.
+type totpUserRepository struct {
+	repository.UserRepository
+	user domain.User
+}
+
+func (m *totpUserRepository) FindByID(ctx context.Context, id uint) (*domain.User, error) {
+	user := m.user
+	return &user, nil
+}
+
+func (m *totpUserRepository) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
+	return m.FindByID(ctx, m.user.ID)
+}
+
+func (m *totpUserRepository) UpdateTOTP(ctx context.Context, user *domain.User) error {
+	m.user.TOTPSecret = user.TOTPSecret
+	m.user.TOTPEnabled = user.TOTPEnabled
+	m.user.TOTPLastStep = user.TOTPLastStep
+	return nil
+}
+
+type memoryRecoveryCodeRepository struct {
+	repository.RecoveryCodeRepository
+	hashes []string
+}
+
+func (m *memoryRecoveryCodeRepository) ReplaceForUser(ctx context.Context, userID uint, codeHashes []string) error {
+	m.hashes = codeHashes
+	return nil
+}
+
+func twoFactorFor(t *testing.T, role string) (usecase.TwoFactorUseCase, *totpUserRepository, *memoryRecoveryCodeRepository) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cretpass"), bcrypt.MinCost)
+	require.NoError(t, err)
+	box, err := infrastructure.NewSecretBox("totp-key")
+	require.NoError(t, err)
+	users := &totpUserRepository{user: domain.User{ID: 1, Username: "someone", Password: string(hash), Role: role}}
+	codes := &memoryRecoveryCodeRepository{}
+	return usecase.NewTwoFactorUseCase(users, codes, box, infrastructure.AuthConfig{TOTPIssuer: "Test"}), users, codes
+}
+
+func TestEnrollRequiresPassword(t *testing.T) {
+	twoFactor, users, _ := twoFactorFor(t, domain.RolePlayer)
+	ctx := context.Background()
+
+	_, err := twoFactor.Enroll(ctx, 1, "wrong")
+	assert.ErrorIs(t, err, usecase.ErrInvalidCredentials)
+	assert.Empty(t, users.user.TOTPSecret)
+
+	enrollment, err := twoFactor.Enroll(ctx, 1, "s3cretpass")
+	require.NoError(t, err)
+	assert.NotEmpty(t, enrollment.Secret)
+	assert.NotEmpty(t, users.user.TOTPSecret)
+	assert.False(t, users.user.TOTPEnabled)
+}
+
+func TestAdminsCannotEnrollThroughAPI(t *testing.T) {
+	twoFactor, users, codes := twoFactorFor(t, domain.RoleAdmin)
+	ctx := context.Background()
+
+	_, err := twoFactor.Enroll(ctx, 1, "s3cretpass")
+	assert.ErrorIs(t, err, usecase.ErrEnrollmentOutOfBand)
+	_, err = twoFactor.Confirm(ctx, 1, "123456")
+	assert.ErrorIs(t, err, usecase.ErrEnrollmentOutOfBand)
+	assert.Empty(t, users.user.TOTPSecret)
+
+	// The operator enrols them instead.
+	enrollment, recovery, err := twoFactor.Provision(ctx, "someone")
+	require.NoError(t, err)
+	assert.NotEmpty(t, enrollment.Secret)
+	assert.Len(t, recovery, 10)
+	assert.Len(t, codes.hashes, 10)
+	assert.True(t, users.user.TOTPEnabled)
+
+	_, _, err = twoFactor.Provision(ctx, "someone")
+	assert.ErrorIs(t, err, usecase.ErrTwoFactorAlreadyEnabled)
+}